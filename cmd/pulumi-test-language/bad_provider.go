@@ -19,6 +19,8 @@ import (
 	"fmt"
 
 	"github.com/blang/semver"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
@@ -121,3 +123,9 @@ func (p *badProvider) GetMapping(key, provider string) ([]byte, string, error) {
 func (p *badProvider) GetMappings(key string) ([]string, error) {
 	return nil, nil
 }
+
+func (p *badProvider) DiscoverResources(typ tokens.Type,
+	scope resource.PropertyMap,
+) ([]plugin.DiscoveredResource, error) {
+	return nil, status.Error(codes.Unimplemented, "DiscoverResources is not implemented")
+}
@@ -19,6 +19,8 @@ import (
 	"fmt"
 
 	"github.com/blang/semver"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
@@ -166,3 +168,9 @@ func (p *simpleProvider) GetMapping(key, provider string) ([]byte, string, error
 func (p *simpleProvider) GetMappings(key string) ([]string, error) {
 	return nil, nil
 }
+
+func (p *simpleProvider) DiscoverResources(typ tokens.Type,
+	scope resource.PropertyMap,
+) ([]plugin.DiscoveredResource, error) {
+	return nil, status.Error(codes.Unimplemented, "DiscoverResources is not implemented")
+}
@@ -84,7 +84,7 @@ func ShowEvents(
 		return
 	}
 
-	if opts.Type != DisplayProgress {
+	if opts.Type != DisplayProgress && opts.Type != DisplayMarkdown {
 		printPermalinkNonInteractive(os.Stdout, opts, permalink)
 	}
 
@@ -98,6 +98,8 @@ func ShowEvents(
 			"directly instead of through ShowEvents")
 	case DisplayWatch:
 		ShowWatchEvents(op, events, done, opts)
+	case DisplayMarkdown:
+		ShowMarkdownEvents(op, stack, permalink, events, done, opts)
 	default:
 		contract.Failf("Unknown display type %d", opts.Type)
 	}
@@ -201,7 +203,8 @@ func shouldShow(step engine.StepEventMetadata, opts Options) bool {
 
 	// For logical replacement operations, only show them during progress-style updates (since this is integrated
 	// into the resource status update), or if it is requested explicitly (for diffs and JSON outputs).
-	if (opts.Type == DisplayDiff || opts.JSONDisplay) && !step.Logical && !opts.ShowReplacementSteps {
+	nonProgress := opts.Type == DisplayDiff || opts.Type == DisplayMarkdown || opts.JSONDisplay
+	if nonProgress && !step.Logical && !opts.ShowReplacementSteps {
 		return false
 	}
 
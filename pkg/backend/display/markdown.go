@@ -0,0 +1,348 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/display"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+const (
+	// markdownMaxLength bounds the length of a markdown summary so that it fits in a pull request comment. GitHub
+	// rejects comments longer than 65536 characters.
+	markdownMaxLength = 60000
+	// markdownMaxDiffLines bounds the number of lines of the property diff of a single resource.
+	markdownMaxDiffLines = 100
+	// markdownReserve is the room kept at the end of a summary for closing tags and the truncation notice.
+	markdownReserve = 512
+)
+
+// markdownOpGroup is a group of step operations that are shown together in a markdown summary.
+type markdownOpGroup struct {
+	title string
+	ops   []display.StepOp
+}
+
+// markdownOpGroups lists the groups of a markdown summary in the order they are shown.
+var markdownOpGroups = []markdownOpGroup{
+	{"Create", []display.StepOp{deploy.OpCreate}},
+	{"Update", []display.StepOp{deploy.OpUpdate}},
+	{"Replace", []display.StepOp{deploy.OpReplace, deploy.OpCreateReplacement, deploy.OpDeleteReplaced}},
+	{"Delete", []display.StepOp{deploy.OpDelete}},
+	{"Import", []display.StepOp{deploy.OpImport, deploy.OpImportReplacement}},
+	{"Read", []display.StepOp{deploy.OpRead, deploy.OpReadReplacement}},
+	{"Refresh", []display.StepOp{deploy.OpRefresh}},
+	{"Discard", []display.StepOp{deploy.OpReadDiscard, deploy.OpDiscardReplaced}},
+	{"Unchanged", []display.StepOp{deploy.OpSame}},
+}
+
+// markdownSummary accumulates the events of an operation for a markdown summary.
+type markdownSummary struct {
+	title     string
+	permalink string
+
+	steps       []engine.StepEventMetadata
+	failed      map[resource.URN]bool
+	diagnostics []engine.DiagEventPayload
+	policies    []engine.PolicyViolationEventPayload
+
+	changes  display.ResourceChanges
+	duration time.Duration
+}
+
+// ShowMarkdownEvents renders the events of an operation as a markdown summary that is suitable for a pull request
+// comment. Resources are grouped by operation in collapsible sections with their property diffs, secrets are masked,
+// and the summary is truncated deterministically to fit in a comment. Nothing is written until the operation ends.
+func ShowMarkdownEvents(
+	op string, stack tokens.StackName, permalink string, events <-chan engine.Event, done chan<- bool, opts Options,
+) {
+	defer close(done)
+
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
+	title := op
+	if title != "" {
+		title = strings.ToUpper(title[:1]) + title[1:]
+	}
+	s := &markdownSummary{
+		title:     fmt.Sprintf("%s (`%s`)", title, stack),
+		permalink: permalink,
+		failed:    map[resource.URN]bool{},
+	}
+
+	for e := range events {
+		if e.Type == engine.CancelEvent {
+			break
+		}
+		s.add(e, opts)
+	}
+
+	_, err := io.WriteString(stdout, renderMarkdownSummary(s, markdownMaxLength))
+	contract.IgnoreError(err)
+}
+
+func (s *markdownSummary) add(e engine.Event, opts Options) {
+	switch e.Type {
+	case engine.ResourcePreEvent:
+		m := e.Payload().(engine.ResourcePreEventPayload).Metadata
+		if !isRootStack(m) && shouldShow(m, opts) {
+			s.steps = append(s.steps, maskStepSecrets(m))
+		}
+	case engine.ResourceOperationFailed:
+		s.failed[e.Payload().(engine.ResourceOperationFailedPayload).Metadata.URN] = true
+	case engine.DiagEvent:
+		p := e.Payload().(engine.DiagEventPayload)
+		if !p.Ephemeral && (p.Severity == diag.Error || p.Severity == diag.Warning) {
+			s.diagnostics = append(s.diagnostics, p)
+		}
+	case engine.PolicyViolationEvent:
+		s.policies = append(s.policies, e.Payload().(engine.PolicyViolationEventPayload))
+	case engine.SummaryEvent:
+		p := e.Payload().(engine.SummaryEventPayload)
+		s.changes, s.duration = p.ResourceChanges, p.Duration
+	}
+}
+
+// maskStepSecrets returns a copy of a step whose secret property values are replaced with "[secret]", the same way as
+// MassageSecrets.
+func maskStepSecrets(step engine.StepEventMetadata) engine.StepEventMetadata {
+	mask := func(s *engine.StepEventStateMetadata) *engine.StepEventStateMetadata {
+		if s == nil {
+			return nil
+		}
+		masked := *s
+		masked.Inputs = MassageSecrets(s.Inputs, false)
+		masked.Outputs = MassageSecrets(s.Outputs, false)
+		return &masked
+	}
+	step.Old, step.New = mask(step.Old), mask(step.New)
+	return step
+}
+
+// markdownStepDiff renders the property diff of a step as the body of a `diff` code block. The operation prefix of
+// each line is moved to the first column so that the lines are highlighted, and long diffs are cut short.
+func markdownStepDiff(step engine.StepEventMetadata) string {
+	// Unlike the diff display, prefix the properties of created and deleted resources with their operation too.
+	var details string
+	switch {
+	case step.Old == nil && step.New != nil:
+		props := step.New.Inputs
+		if len(step.New.Outputs) > 0 {
+			props = step.New.Outputs
+		}
+		var b bytes.Buffer
+		PrintObject(&b, props, true /*planning*/, 1, step.Op, true /*prefix*/, true /*truncateOutput*/, false /*debug*/)
+		details = b.String()
+	case step.New == nil && step.Old != nil:
+		var b bytes.Buffer
+		PrintObject(&b, step.Old.Inputs, true /*planning*/, 1, step.Op, true /*prefix*/, true /*truncateOutput*/,
+			false /*debug*/)
+		details = b.String()
+	default:
+		details = getResourcePropertiesDetails(
+			step, 0, true /*planning*/, false /*summary*/, true /*truncateOutput*/, false /*debug*/)
+	}
+	details = colors.Never.Colorize(details)
+	lines := strings.Split(strings.TrimRight(details, "\n"), "\n")
+	if len(lines) == 1 && strings.TrimSpace(lines[0]) == "" {
+		return ""
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if indent > 0 && trimmed != "" && strings.ContainsRune("+-~<>", rune(trimmed[0])) {
+			lines[i] = trimmed[:1] + line[:indent] + trimmed[1:]
+		}
+	}
+	if len(lines) > markdownMaxDiffLines {
+		omitted := len(lines) - markdownMaxDiffLines
+		lines = append(lines[:markdownMaxDiffLines], fmt.Sprintf("... %d more line(s)", omitted))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// markdownChangeCounts renders the number of resources of each group, in group order.
+func markdownChangeCounts(s *markdownSummary) string {
+	counts := map[display.StepOp]int{}
+	if s.changes != nil {
+		for op, n := range s.changes {
+			counts[op] = n
+		}
+	} else {
+		for _, step := range s.steps {
+			counts[step.Op]++
+		}
+	}
+
+	var parts []string
+	for _, g := range markdownOpGroups {
+		n := 0
+		for _, op := range g.ops {
+			n += counts[op]
+		}
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", strings.ToLower(g.title), n))
+		}
+	}
+	if len(parts) == 0 {
+		return "No changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderMarkdownSummary renders a summary that is at most maxLength bytes long. The header, counts and diagnostics are
+// always shown; resources are added in a fixed order until the next one would not fit, so the same events always
+// produce the same output.
+func renderMarkdownSummary(s *markdownSummary, maxLength int) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "### %s\n\n", s.title)
+	fmt.Fprintf(&b, "**%s**", markdownChangeCounts(s))
+	if s.duration != 0 {
+		fmt.Fprintf(&b, " (%s)", s.duration.Round(time.Second))
+	}
+	b.WriteString("\n\n")
+	if s.permalink != "" {
+		fmt.Fprintf(&b, "[View in Pulumi Cloud](%s)\n\n", s.permalink)
+	}
+
+	// Diagnostics get at most a quarter of the summary, so that resources are always shown.
+	diagBudget := b.Len() + maxLength/4
+	if len(s.diagnostics) > 0 || len(s.policies) > 0 {
+		var lines []string
+		for _, d := range s.diagnostics {
+			msg := strings.TrimSpace(colors.Never.Colorize(d.Prefix + d.Message))
+			if d.URN != "" {
+				msg = fmt.Sprintf("`%s`: %s", d.URN.Name(), msg)
+			}
+			lines = append(lines, fmt.Sprintf("- **%s**: %s", d.Severity, markdownInline(msg)))
+		}
+		for _, p := range s.policies {
+			severity := diag.Warning
+			if p.EnforcementLevel == "mandatory" {
+				severity = diag.Error
+			}
+			msg := strings.TrimSpace(colors.Never.Colorize(p.Message))
+			lines = append(lines, fmt.Sprintf("- **%s**: policy `%s/%s` on `%s`: %s", severity,
+				p.PolicyPackName, p.PolicyName, p.ResourceURN.Name(), markdownInline(msg)))
+		}
+
+		b.WriteString("#### Diagnostics\n\n")
+		for i, line := range lines {
+			if b.Len()+len(line) > diagBudget {
+				fmt.Fprintf(&b, "- ... %d more diagnostic(s)\n", len(lines)-i)
+				break
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	omitted := 0
+	for _, g := range markdownOpGroups {
+		var steps []engine.StepEventMetadata
+		for _, step := range s.steps {
+			for _, op := range g.ops {
+				if step.Op == op {
+					steps = append(steps, step)
+				}
+			}
+		}
+		if len(steps) == 0 {
+			continue
+		}
+		sort.SliceStable(steps, func(i, j int) bool { return steps[i].URN < steps[j].URN })
+
+		if omitted > 0 {
+			omitted += len(steps)
+			continue
+		}
+
+		open := fmt.Sprintf("<details><summary><b>%s (%d)</b></summary>\n\n", g.title, len(steps))
+		const closing = "</details>\n\n"
+		if b.Len()+len(open)+len(closing)+markdownReserve > maxLength {
+			omitted += len(steps)
+			continue
+		}
+		b.WriteString(open)
+		for i, step := range steps {
+			block := markdownStepBlock(step, s.failed[step.URN])
+			if b.Len()+len(block)+len(closing)+markdownReserve > maxLength {
+				omitted += len(steps) - i
+				break
+			}
+			b.WriteString(block)
+		}
+		b.WriteString(closing)
+	}
+
+	if omitted > 0 {
+		fmt.Fprintf(&b, "_%d more resource(s) are not shown because the summary was truncated._\n", omitted)
+	}
+	return b.String()
+}
+
+// markdownStepBlock renders a single resource of a markdown summary.
+func markdownStepBlock(step engine.StepEventMetadata, failed bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "`%s` **%s**", step.Type, step.URN.Name())
+	if step.Op != deploy.OpSame {
+		fmt.Fprintf(&b, " (%s)", step.Op)
+	}
+	if failed {
+		b.WriteString(", **failed**")
+	}
+	if len(step.Keys) > 0 && (step.Op == deploy.OpReplace || step.Op == deploy.OpCreateReplacement) {
+		keys := make([]string, len(step.Keys))
+		for i, k := range step.Keys {
+			keys[i] = "`" + string(k) + "`"
+		}
+		fmt.Fprintf(&b, ", replaced because of %s", strings.Join(keys, ", "))
+	}
+	b.WriteString("\n\n")
+	if diff := markdownStepDiff(step); diff != "" {
+		// Use a fence that is longer than any run of backticks in the diff, so that it cannot end the block early.
+		fence := "```"
+		for strings.Contains(diff, fence) {
+			fence += "`"
+		}
+		fmt.Fprintf(&b, "%sdiff\n%s%s\n\n", fence, diff, fence)
+	}
+	return b.String()
+}
+
+// markdownInline flattens a message to a single line of markdown.
+func markdownInline(msg string) string {
+	return strings.Join(strings.Fields(msg), " ")
+}
@@ -0,0 +1,125 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/display"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+func markdownTestStep(name string, op display.StepOp, oldInputs, newInputs resource.PropertyMap) engine.Event {
+	urn := resource.NewURN("dev", "proj", "", "aws:s3/bucket:Bucket", name)
+	m := engine.StepEventMetadata{Op: op, URN: urn, Type: "aws:s3/bucket:Bucket", Logical: true}
+	if oldInputs != nil {
+		m.Old = &engine.StepEventStateMetadata{URN: urn, Type: m.Type, Inputs: oldInputs}
+	}
+	if newInputs != nil {
+		m.New = &engine.StepEventStateMetadata{URN: urn, Type: m.Type, Inputs: newInputs}
+	}
+	if op == deploy.OpUpdate {
+		m.Diffs = []resource.PropertyKey{"acl"}
+	}
+	return engine.NewEvent(engine.ResourcePreEventPayload{Metadata: m, Planning: true})
+}
+
+func showMarkdown(t *testing.T, events []engine.Event) string {
+	eventChannel, doneChannel := make(chan engine.Event), make(chan bool)
+	var stdout bytes.Buffer
+	go ShowMarkdownEvents("previewing update", tokens.MustParseStackName("dev"), "",
+		eventChannel, doneChannel, Options{Type: DisplayMarkdown, Stdout: &stdout})
+	for _, e := range events {
+		eventChannel <- e
+	}
+	close(eventChannel)
+	<-doneChannel
+	return stdout.String()
+}
+
+func TestShowMarkdownEvents(t *testing.T) {
+	t.Parallel()
+
+	out := showMarkdown(t, []engine.Event{
+		markdownTestStep("site", deploy.OpUpdate,
+			resource.PropertyMap{"acl": resource.NewStringProperty("private")},
+			resource.PropertyMap{"acl": resource.NewStringProperty("public-read")}),
+		markdownTestStep("logs", deploy.OpCreate, nil, resource.PropertyMap{
+			"bucket": resource.NewStringProperty("logs"),
+			"token":  resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		}),
+		markdownTestStep("old", deploy.OpDelete, resource.PropertyMap{}, nil),
+		engine.NewEvent(engine.DiagEventPayload{
+			Severity: diag.Warning,
+			Message:  "bucket acls are deprecated\n",
+		}),
+		engine.NewEvent(engine.SummaryEventPayload{
+			ResourceChanges: display.ResourceChanges{deploy.OpCreate: 1, deploy.OpUpdate: 1, deploy.OpDelete: 1},
+		}),
+	})
+
+	assert.True(t, strings.HasPrefix(out, "### Previewing update (`dev`)\n\n**create: 1, update: 1, delete: 1**\n"))
+	assert.Contains(t, out, "- **warning**: bucket acls are deprecated\n")
+
+	// Groups are shown in a fixed order, whatever the order of the events.
+	create := strings.Index(out, "<details><summary><b>Create (1)</b></summary>")
+	update := strings.Index(out, "<details><summary><b>Update (1)</b></summary>")
+	del := strings.Index(out, "<details><summary><b>Delete (1)</b></summary>")
+	require.True(t, create >= 0 && update > create && del > update, out)
+
+	assert.Contains(t, out, "`aws:s3/bucket:Bucket` **logs** (create)")
+	assert.Contains(t, out, "[secret]")
+	assert.NotContains(t, out, "hunter2")
+
+	// Diff lines start with their operation so that they are highlighted.
+	assert.Regexp(t, `(?m)^~ +acl: "private" => "public-read"$`, out)
+	assert.Regexp(t, `(?m)^\+ +bucket: "logs"$`, out)
+}
+
+func TestRenderMarkdownSummaryTruncates(t *testing.T) {
+	t.Parallel()
+
+	var events []engine.Event
+	for i := 0; i < 200; i++ {
+		events = append(events, markdownTestStep(fmt.Sprintf("bucket-%03d", i), deploy.OpCreate, nil,
+			resource.PropertyMap{"bucket": resource.NewStringProperty(strings.Repeat("x", 100))}))
+	}
+
+	s := &markdownSummary{title: "Previewing update (`dev`)", failed: map[resource.URN]bool{}}
+	for _, e := range events {
+		s.add(e, Options{Type: DisplayMarkdown})
+	}
+
+	const maxLength = 4000
+	out := renderMarkdownSummary(s, maxLength)
+	assert.LessOrEqual(t, len(out), maxLength)
+	assert.Contains(t, out, "bucket-000")
+	assert.NotContains(t, out, "bucket-199")
+	assert.Regexp(t, `_\d+ more resource\(s\) are not shown because the summary was truncated._`, out)
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(strings.Split(out, "_")[0]), "</details>"))
+
+	// Truncation is deterministic.
+	assert.Equal(t, out, renderMarkdownSummary(s, maxLength))
+}
@@ -33,6 +33,8 @@ const (
 	DisplayQuery
 	// DisplayWatch displays watch output.
 	DisplayWatch
+	// DisplayMarkdown displays a markdown summary once the operation ends.
+	DisplayMarkdown
 )
 
 // Options controls how the output of events are rendered
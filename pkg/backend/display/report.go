@@ -0,0 +1,405 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+)
+
+// ReportDiagnostic is a diagnostic message in a deployment report.
+type ReportDiagnostic struct {
+	Severity string
+	Message  string
+}
+
+// ReportStep is a single resource step in a deployment report.
+type ReportStep struct {
+	URN  string
+	Type string
+	Op   apitype.OpType
+
+	// Failed is true if the step failed or an error was reported for its resource.
+	Failed bool
+	// Done is true if the step finished, either successfully or not.
+	Done bool
+
+	Start time.Time
+	End   time.Time
+
+	Diagnostics []ReportDiagnostic
+}
+
+// Duration returns how long the step took, or zero if it did not finish. Engine event timestamps only have a
+// resolution of one second, so neither does the duration: a step that took less than a second has a zero duration.
+func (s *ReportStep) Duration() time.Duration {
+	if !s.Done || s.End.Before(s.Start) {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// Report summarizes the resource steps of a deployment from its engine events.
+type Report struct {
+	Steps []*ReportStep
+	// Diagnostics are the errors and warnings that were not reported for a particular resource.
+	Diagnostics []ReportDiagnostic
+
+	Start time.Time
+	End   time.Time
+}
+
+// Failed returns true if any step failed or any error was reported.
+func (r *Report) Failed() bool {
+	for _, s := range r.Steps {
+		if s.Failed {
+			return true
+		}
+	}
+	for _, d := range r.Diagnostics {
+		if d.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+// NewReport builds a report from the engine events of a deployment, as written by `--event-log`. Each non-planning
+// resource step becomes a step of the report; errors and warnings are attached to the step of their resource. Times
+// are taken from the events' timestamps, which have a resolution of one second.
+func NewReport(events []apitype.EngineEvent) *Report {
+	r := &Report{}
+
+	// Steps are keyed by URN and operation, since a replacement has several steps for the same resource. Diagnostics
+	// are attached to the most recent step of their resource.
+	type stepKey struct {
+		urn string
+		op  apitype.OpType
+	}
+	steps := map[stepKey]*ReportStep{}
+	latest := map[string]*ReportStep{}
+
+	addDiagnostic := func(urn string, d ReportDiagnostic) {
+		if s, ok := latest[urn]; ok {
+			s.Diagnostics = append(s.Diagnostics, d)
+			if d.Severity == "error" {
+				s.Failed = true
+			}
+			return
+		}
+		r.Diagnostics = append(r.Diagnostics, d)
+	}
+
+	for _, e := range events {
+		at := time.Unix(int64(e.Timestamp), 0).UTC()
+		if e.Timestamp != 0 {
+			if r.Start.IsZero() || at.Before(r.Start) {
+				r.Start = at
+			}
+			if at.After(r.End) {
+				r.End = at
+			}
+		}
+
+		switch {
+		case e.ResourcePreEvent != nil && !e.ResourcePreEvent.Planning:
+			md := e.ResourcePreEvent.Metadata
+			s := &ReportStep{URN: md.URN, Type: md.Type, Op: md.Op, Start: at}
+			steps[stepKey{md.URN, md.Op}], latest[md.URN] = s, s
+			r.Steps = append(r.Steps, s)
+		case e.ResOutputsEvent != nil && !e.ResOutputsEvent.Planning:
+			md := e.ResOutputsEvent.Metadata
+			if s, ok := steps[stepKey{md.URN, md.Op}]; ok {
+				s.Done, s.End = true, at
+			}
+		case e.ResOpFailedEvent != nil:
+			md := e.ResOpFailedEvent.Metadata
+			if s, ok := steps[stepKey{md.URN, md.Op}]; ok {
+				s.Done, s.Failed, s.End = true, true, at
+			}
+		case e.DiagnosticEvent != nil:
+			d := e.DiagnosticEvent
+			if d.Ephemeral || (d.Severity != "error" && d.Severity != "warning") {
+				continue
+			}
+			addDiagnostic(d.URN, ReportDiagnostic{
+				Severity: d.Severity,
+				Message:  strings.TrimSpace(colors.Never.Colorize(d.Prefix + d.Message)),
+			})
+		case e.PolicyEvent != nil:
+			p := e.PolicyEvent
+			severity := "warning"
+			if p.EnforcementLevel == "mandatory" {
+				severity = "error"
+			}
+			addDiagnostic(p.ResourceURN, ReportDiagnostic{
+				Severity: severity,
+				Message: fmt.Sprintf("[%s] %s: %s", p.PolicyPackName, p.PolicyName,
+					strings.TrimSpace(colors.Never.Colorize(p.Message))),
+			})
+		}
+	}
+
+	return r
+}
+
+// OpCounts returns the number of steps of each operation in the report.
+func (r *Report) OpCounts() map[apitype.OpType]int {
+	counts := map[apitype.OpType]int{}
+	for _, s := range r.Steps {
+		counts[s.Op]++
+	}
+	return counts
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr,omitempty"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr,omitempty"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSeconds formats a duration for a JUnit time attribute. Durations of less than a second are below the
+// resolution of engine event timestamps, so they are omitted rather than reported as zero.
+func junitSeconds(d time.Duration) string {
+	if d < time.Second {
+		return ""
+	}
+	return fmt.Sprintf("%d", d/time.Second)
+}
+
+// htmlDuration formats a duration for the HTML report, which states that durations have a resolution of one second.
+func htmlDuration(d time.Duration) string {
+	if d < time.Second {
+		return "<1s"
+	}
+	return d.String()
+}
+
+// RenderJUnit writes the report as a JUnit XML document with one test case per resource step. Failed steps are
+// failures whose text is the step's diagnostics. Errors that were not reported for a resource are a failure of an
+// additional test case named after the suite.
+func (r *Report) RenderJUnit(w io.Writer, name string) error {
+	suite := junitTestSuite{
+		Name: name,
+		Time: junitSeconds(r.End.Sub(r.Start)),
+	}
+	if !r.Start.IsZero() {
+		suite.Timestamp = r.Start.Format("2006-01-02T15:04:05")
+	}
+
+	for _, s := range r.Steps {
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("%s (%s)", s.URN, s.Op),
+			Classname: s.Type,
+			Time:      junitSeconds(s.Duration()),
+		}
+		messages := make([]string, len(s.Diagnostics))
+		for i, d := range s.Diagnostics {
+			messages[i] = fmt.Sprintf("%s: %s", d.Severity, d.Message)
+		}
+		if s.Failed {
+			message := fmt.Sprintf("%s failed", s.Op)
+			for _, d := range s.Diagnostics {
+				if d.Severity == "error" {
+					message = d.Message
+					break
+				}
+			}
+			tc.Failure = &junitFailure{Message: message, Type: string(s.Op), Text: strings.Join(messages, "\n")}
+		} else if len(messages) > 0 {
+			tc.SystemOut = strings.Join(messages, "\n")
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	var errs []string
+	for _, d := range r.Diagnostics {
+		if d.Severity == "error" {
+			errs = append(errs, d.Message)
+		}
+	}
+	if len(errs) > 0 {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      name,
+			Classname: "pulumi",
+			Failure:   &junitFailure{Message: errs[0], Type: "error", Text: strings.Join(errs, "\n")},
+		})
+	}
+
+	suite.Tests = len(suite.Cases)
+	for _, tc := range suite.Cases {
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+	}
+
+	doc := junitTestSuites{
+		Name:     name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code, pre { font-family: SFMono-Regular, Consolas, Menlo, monospace; font-size: 0.9em; }
+pre { margin: 0.3em 0 0; white-space: pre-wrap; }
+.failed { color: #cf222e; font-weight: bold; }
+.succeeded { color: #1a7f37; }
+.pending { color: #9a6700; }
+.error { color: #cf222e; }
+.warning { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>
+{{if .Failed}}<span class="failed">Failed</span>{{else}}<span class="succeeded">Succeeded</span>{{end}}
+{{- if .Start}} &middot; started {{.Start}}{{end}}
+{{- if .Duration}} &middot; took {{.Duration}}{{end}}
+</p>
+{{if .Counts}}<ul>
+{{range .Counts}}<li>{{.Op}}: {{.Count}}</li>
+{{end}}</ul>{{end}}
+{{if .Diagnostics}}<h2>Diagnostics</h2>
+{{range .Diagnostics}}<pre class="{{.Severity}}">{{.Severity}}: {{.Message}}</pre>
+{{end}}{{end}}
+<h2>Resources</h2>
+{{if .Steps}}<table>
+<tr><th>Status</th><th>Operation</th><th>Type</th><th>URN</th><th>Duration</th></tr>
+{{range .Steps}}<tr>
+<td class="{{.Status}}">{{.Status}}</td>
+<td>{{.Op}}</td>
+<td><code>{{.Type}}</code></td>
+<td><code>{{.URN}}</code>{{range .Diagnostics}}<pre class="{{.Severity}}">{{.Severity}}: {{.Message}}</pre>{{end}}</td>
+<td>{{.Duration}}</td>
+</tr>
+{{end}}</table>
+<p><small>Durations are measured from engine event timestamps, which have a resolution of one second.</small></p>
+{{- else}}<p>No resource steps.</p>{{end}}
+</body>
+</html>
+`))
+
+// RenderHTML writes the report as a self-contained HTML page.
+func (r *Report) RenderHTML(w io.Writer, title string) error {
+	type opCount struct {
+		Op    apitype.OpType
+		Count int
+	}
+	type step struct {
+		*ReportStep
+		Status   string
+		Duration string
+	}
+
+	counts := r.OpCounts()
+	ops := make([]opCount, 0, len(counts))
+	for op, count := range counts {
+		ops = append(ops, opCount{Op: op, Count: count})
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Op < ops[j].Op })
+
+	steps := make([]step, len(r.Steps))
+	for i, s := range r.Steps {
+		status := "succeeded"
+		switch {
+		case s.Failed:
+			status = "failed"
+		case !s.Done:
+			status = "pending"
+		}
+		duration := ""
+		if s.Done {
+			duration = htmlDuration(s.Duration())
+		}
+		steps[i] = step{ReportStep: s, Status: status, Duration: duration}
+	}
+
+	data := struct {
+		Title       string
+		Failed      bool
+		Start       string
+		Duration    string
+		Counts      []opCount
+		Diagnostics []ReportDiagnostic
+		Steps       []step
+	}{
+		Title:       title,
+		Failed:      r.Failed(),
+		Counts:      ops,
+		Diagnostics: r.Diagnostics,
+		Steps:       steps,
+	}
+	if !r.Start.IsZero() {
+		data.Start = r.Start.Format(time.RFC3339)
+		data.Duration = htmlDuration(r.End.Sub(r.Start))
+	}
+	return htmlReportTemplate.Execute(w, data)
+}
@@ -0,0 +1,125 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+)
+
+func reportTestEvents() []apitype.EngineEvent {
+	step := func(name string, op apitype.OpType) apitype.StepEventMetadata {
+		return apitype.StepEventMetadata{
+			URN:  "urn:pulumi:dev::proj::test:index:Resource::" + name,
+			Op:   op,
+			Type: "test:index:Resource",
+		}
+	}
+	return []apitype.EngineEvent{
+		{Timestamp: 100, PreludeEvent: &apitype.PreludeEvent{}},
+		{Timestamp: 100, ResourcePreEvent: &apitype.ResourcePreEvent{Metadata: step("a", apitype.OpCreate), Planning: true}},
+		{Timestamp: 100, ResourcePreEvent: &apitype.ResourcePreEvent{Metadata: step("a", apitype.OpCreate)}},
+		{Timestamp: 105, ResOutputsEvent: &apitype.ResOutputsEvent{Metadata: step("a", apitype.OpCreate)}},
+		{Timestamp: 106, ResourcePreEvent: &apitype.ResourcePreEvent{Metadata: step("b", apitype.OpUpdate)}},
+		{Timestamp: 107, DiagnosticEvent: &apitype.DiagnosticEvent{
+			URN:      step("b", apitype.OpUpdate).URN,
+			Severity: "error",
+			Message:  "<{%fg 1%}>access denied<{%reset%}>\n",
+		}},
+		{Timestamp: 108, ResOpFailedEvent: &apitype.ResOpFailedEvent{Metadata: step("b", apitype.OpUpdate)}},
+		{Timestamp: 108, DiagnosticEvent: &apitype.DiagnosticEvent{Severity: "warning", Message: "deprecated"}},
+		{Timestamp: 110, SummaryEvent: &apitype.SummaryEvent{}},
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	t.Parallel()
+
+	r := NewReport(reportTestEvents())
+	require.Len(t, r.Steps, 2)
+
+	assert.Equal(t, apitype.OpCreate, r.Steps[0].Op)
+	assert.True(t, r.Steps[0].Done)
+	assert.False(t, r.Steps[0].Failed)
+	assert.Equal(t, 5*time.Second, r.Steps[0].Duration())
+
+	assert.True(t, r.Steps[1].Failed)
+	assert.Equal(t, []ReportDiagnostic{{Severity: "error", Message: "access denied"}}, r.Steps[1].Diagnostics)
+
+	assert.Equal(t, []ReportDiagnostic{{Severity: "warning", Message: "deprecated"}}, r.Diagnostics)
+	assert.True(t, r.Failed())
+	assert.Equal(t, 10*time.Second, r.End.Sub(r.Start))
+}
+
+func TestReportRenderJUnit(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, NewReport(reportTestEvents()).RenderJUnit(&buf, "pulumi update"))
+
+	var doc junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, 2, doc.Tests)
+	assert.Equal(t, 1, doc.Failures)
+	require.Len(t, doc.Suites, 1)
+	require.Len(t, doc.Suites[0].Cases, 2)
+
+	passed, failed := doc.Suites[0].Cases[0], doc.Suites[0].Cases[1]
+	assert.Equal(t, "urn:pulumi:dev::proj::test:index:Resource::a (create)", passed.Name)
+	assert.Equal(t, "test:index:Resource", passed.Classname)
+	assert.Equal(t, "5", passed.Time)
+	assert.Nil(t, passed.Failure)
+	require.NotNil(t, failed.Failure)
+	assert.Equal(t, "access denied", failed.Failure.Message)
+	assert.Equal(t, "update", failed.Failure.Type)
+}
+
+func TestReportRenderJUnitSubSecond(t *testing.T) {
+	t.Parallel()
+
+	// Steps that took less than a second have no time, rather than a misleading time of zero.
+	events := reportTestEvents()
+	for i := range events {
+		events[i].Timestamp = 100
+	}
+	var buf bytes.Buffer
+	require.NoError(t, NewReport(events).RenderJUnit(&buf, "pulumi update"))
+	assert.NotContains(t, buf.String(), "time=")
+}
+
+func TestReportRenderHTML(t *testing.T) {
+	t.Parallel()
+
+	events := reportTestEvents()
+	events[5].DiagnosticEvent.Message = "<script>alert(1)</script>"
+
+	var buf bytes.Buffer
+	require.NoError(t, NewReport(events).RenderHTML(&buf, "pulumi update"))
+	html := buf.String()
+	assert.Contains(t, html, "<title>pulumi update</title>")
+	assert.Contains(t, html, `<td class="failed">failed</td>`)
+	assert.Contains(t, html, "<li>create: 1</li>")
+	assert.Contains(t, html, "<td>5s</td>")
+	assert.Contains(t, html, "resolution of one second")
+	assert.Contains(t, html, "&lt;script&gt;")
+	assert.NotContains(t, html, "<script>")
+}
@@ -89,6 +89,11 @@ type Backend interface {
 
 	// Upgrade to the latest state store version.
 	Upgrade(ctx context.Context, opts *UpgradeOptions) error
+
+	// AddStackToPolicyGroup makes a policy group apply to a stack, or to the stacks matching a pattern.
+	AddStackToPolicyGroup(ctx context.Context, policyGroup, stack string) error
+	// RemoveStackFromPolicyGroup stops a policy group from applying to a stack, or to the stacks matching a pattern.
+	RemoveStackFromPolicyGroup(ctx context.Context, policyGroup, stack string) error
 }
 
 type localBackend struct {
@@ -589,19 +594,80 @@ func (b *localBackend) SetCurrentProject(project *workspace.Project) {
 func (b *localBackend) GetPolicyPack(ctx context.Context, policyPack string,
 	d diag.Sink,
 ) (backend.PolicyPack, error) {
-	return nil, fmt.Errorf("File state backend does not support resource policy")
+	ref, err := b.parsePolicyPackReference(policyPack)
+	if err != nil {
+		return nil, err
+	}
+	return &localPolicyPack{ref: ref, b: b}, nil
 }
 
+// ListPolicyGroups lists the policy groups in the backend. Self-managed backends have no organizations, so
+// orgName is ignored.
 func (b *localBackend) ListPolicyGroups(ctx context.Context, orgName string, _ backend.ContinuationToken) (
 	apitype.ListPolicyGroupsResponse, backend.ContinuationToken, error,
 ) {
-	return apitype.ListPolicyGroupsResponse{}, nil, fmt.Errorf("File state backend does not support resource policy")
+	groups, err := b.listPolicyGroups(ctx)
+	if err != nil {
+		return apitype.ListPolicyGroupsResponse{}, nil, err
+	}
+
+	refs, err := b.store.ListReferences(ctx)
+	if err != nil {
+		return apitype.ListPolicyGroupsResponse{}, nil, err
+	}
+
+	var resp apitype.ListPolicyGroupsResponse
+	for _, group := range groups {
+		numStacks := 0
+		for _, ref := range refs {
+			if group.appliesTo(ref) {
+				numStacks++
+			}
+		}
+		resp.PolicyGroups = append(resp.PolicyGroups, apitype.PolicyGroupSummary{
+			Name:                  group.Name,
+			IsOrgDefault:          group.isDefault(),
+			NumStacks:             numStacks,
+			NumEnabledPolicyPacks: len(group.PolicyPacks),
+		})
+	}
+	return resp, nil, nil
 }
 
+// ListPolicyPacks lists the policy packs published to the backend. Self-managed backends have no organizations,
+// so orgName is ignored.
 func (b *localBackend) ListPolicyPacks(ctx context.Context, orgName string, _ backend.ContinuationToken) (
 	apitype.ListPolicyPacksResponse, backend.ContinuationToken, error,
 ) {
-	return apitype.ListPolicyPacksResponse{}, nil, fmt.Errorf("File state backend does not support resource policy")
+	dirs, err := listBucket(ctx, b.bucket, PoliciesDir)
+	if err != nil {
+		return apitype.ListPolicyPacksResponse{}, nil, err
+	}
+
+	var resp apitype.ListPolicyPacksResponse
+	for _, dir := range dirs {
+		if !dir.IsDir {
+			continue
+		}
+		versions, err := b.listPolicyPackVersions(ctx, tokens.QName(objectName(dir)))
+		if err != nil {
+			return apitype.ListPolicyPacksResponse{}, nil, err
+		}
+		if len(versions) == 0 {
+			continue
+		}
+
+		pack := apitype.PolicyPackWithVersions{
+			Name:        versions[0].Name,
+			DisplayName: versions[len(versions)-1].DisplayName,
+		}
+		for _, v := range versions {
+			pack.Versions = append(pack.Versions, v.Version)
+			pack.VersionTags = append(pack.VersionTags, v.VersionTag)
+		}
+		resp.PolicyPacks = append(resp.PolicyPacks, pack)
+	}
+	return resp, nil, nil
 }
 
 func (b *localBackend) SupportsTags() bool {
@@ -970,12 +1036,20 @@ func (b *localBackend) apply(
 
 	actionLabel := backend.ActionLabel(kind, opts.DryRun)
 
-	if !(op.Opts.Display.JSONDisplay || op.Opts.Display.Type == display.DisplayWatch) {
+	if !(op.Opts.Display.JSONDisplay || op.Opts.Display.Type == display.DisplayWatch ||
+		op.Opts.Display.Type == display.DisplayMarkdown) {
 		// Print a banner so it's clear this is a local deployment.
 		fmt.Printf(op.Opts.Display.Color.Colorize(
 			colors.SpecHeadline+"%s (%s):"+colors.Reset+"\n"), actionLabel, stackRef)
 	}
 
+	// Enforce the policy packs enabled for this stack by the backend's policy groups.
+	policies, err := b.requiredPolicies(ctx, localStackRef)
+	if err != nil {
+		return nil, nil, result.FromError(err)
+	}
+	op.Opts.Engine.RequiredPolicies = append(op.Opts.Engine.RequiredPolicies, policies...)
+
 	// Start the update.
 	update, err := b.newUpdate(ctx, op.SecretsProvider, localStackRef, op)
 	if err != nil {
@@ -1096,7 +1170,8 @@ func (b *localBackend) apply(
 	}
 
 	// Make sure to print a link to the stack's checkpoint before exiting.
-	if !op.Opts.Display.SuppressPermalink && opts.ShowLink && !op.Opts.Display.JSONDisplay {
+	if !op.Opts.Display.SuppressPermalink && opts.ShowLink && !op.Opts.Display.JSONDisplay &&
+		op.Opts.Display.Type != display.DisplayMarkdown {
 		// Note we get a real signed link for aws/azure/gcp links.  But no such option exists for
 		// file:// links so we manually create the link ourselves.
 		var link string
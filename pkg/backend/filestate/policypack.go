@@ -0,0 +1,654 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gocloud.dev/gcerrors"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	resourceanalyzer "github.com/pulumi/pulumi/pkg/v3/resource/analyzer"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+var (
+	// PoliciesDir is a path under the state's root directory
+	// where published policy packs are stored.
+	PoliciesDir = filepath.Join(workspace.BookkeepingDir, "policies")
+
+	// PolicyGroupsDir is a path under the state's root directory
+	// where policy groups are stored.
+	PolicyGroupsDir = filepath.Join(workspace.BookkeepingDir, "policy-groups")
+)
+
+// DefaultPolicyGroup is the name of the policy group that applies to every stack in the backend.
+const DefaultPolicyGroup = "default-policy-group"
+
+// policyGroup is the persisted form of a policy group.
+//
+// The default policy group applies to every stack in the backend. Any other group applies to the stacks
+// matching one of its Stacks patterns, which are matched against both the stack's name and its fully
+// qualified name (e.g. "organization/project/stack") using path.Match.
+type policyGroup struct {
+	Name        string                       `json:"name"`
+	Stacks      []string                     `json:"stacks,omitempty"`
+	PolicyPacks []apitype.PolicyPackMetadata `json:"policyPacks,omitempty"`
+}
+
+func (g *policyGroup) isDefault() bool {
+	return g.Name == DefaultPolicyGroup
+}
+
+// appliesTo returns true if the group's policy packs are enforced for the given stack.
+func (g *policyGroup) appliesTo(ref *localBackendReference) bool {
+	if g.isDefault() {
+		return true
+	}
+	for _, pattern := range g.Stacks {
+		for _, name := range []string{ref.Name().String(), string(ref.FullyQualifiedName())} {
+			if ok, err := path.Match(pattern, name); err == nil && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeStackPattern returns the form of a stack name or pattern that is stored in a policy group. Stack names
+// are fully qualified, so that a group only applies to the named stack of the current project rather than to every
+// project's stack of that name. Patterns are stored as they are.
+func (b *localBackend) normalizeStackPattern(stack string) (string, error) {
+	if strings.ContainsAny(stack, "*?[\\") {
+		if _, err := path.Match(stack, ""); err != nil {
+			return "", fmt.Errorf("invalid stack pattern %q: %w", stack, err)
+		}
+		return stack, nil
+	}
+	ref, err := b.parseStackReference(stack)
+	if err != nil {
+		return "", err
+	}
+	return string(ref.FullyQualifiedName()), nil
+}
+
+// AddStackToPolicyGroup makes a policy group apply to the given stack, or to the stacks matching the given pattern,
+// creating the group if it does not exist.
+func (b *localBackend) AddStackToPolicyGroup(ctx context.Context, policyGroup, stack string) error {
+	if policyGroup == DefaultPolicyGroup {
+		return fmt.Errorf("policy group %q already applies to every stack", DefaultPolicyGroup)
+	}
+	pattern, err := b.normalizeStackPattern(stack)
+	if err != nil {
+		return err
+	}
+	group, _, err := b.getPolicyGroup(ctx, policyGroup)
+	if err != nil {
+		return err
+	}
+	for _, s := range group.Stacks {
+		if s == pattern {
+			return nil
+		}
+	}
+	group.Stacks = append(group.Stacks, pattern)
+	return writeJSON(ctx, b.bucket, policyGroupPath(group.Name), group)
+}
+
+// RemoveStackFromPolicyGroup removes a stack, or a pattern, from the stacks a policy group applies to.
+func (b *localBackend) RemoveStackFromPolicyGroup(ctx context.Context, policyGroup, stack string) error {
+	if policyGroup == DefaultPolicyGroup {
+		return fmt.Errorf("policy group %q applies to every stack", DefaultPolicyGroup)
+	}
+	pattern, err := b.normalizeStackPattern(stack)
+	if err != nil {
+		return err
+	}
+	group, ok, err := b.getPolicyGroup(ctx, policyGroup)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("policy group %q does not exist", policyGroup)
+	}
+
+	stacks := group.Stacks[:0]
+	for _, s := range group.Stacks {
+		if s != pattern {
+			stacks = append(stacks, s)
+		}
+	}
+	if len(stacks) == len(group.Stacks) {
+		return fmt.Errorf("policy group %q does not apply to %s", policyGroup, pattern)
+	}
+	group.Stacks = stacks
+	return writeJSON(ctx, b.bucket, policyGroupPath(group.Name), group)
+}
+
+// policyNamespace returns the directory under the policy pack install directory that the backend's policy packs are
+// installed into. Self-managed backends have no organizations, so each backend gets its own directory, named after
+// its URL, to keep packs of the same name and version published to different backends apart.
+func (b *localBackend) policyNamespace() string {
+	sum := sha256.Sum256([]byte(b.url))
+	return "filestate-" + hex.EncodeToString(sum[:8])
+}
+
+func policyGroupPath(name string) string {
+	return filepath.Join(PolicyGroupsDir, name+".json")
+}
+
+func policyPackDir(name tokens.QName) string {
+	return filepath.Join(PoliciesDir, string(name))
+}
+
+func policyPackMetadataPath(name tokens.QName, versionTag string) string {
+	return filepath.Join(policyPackDir(name), versionTag+".json")
+}
+
+func policyPackArchivePath(name tokens.QName, versionTag string) string {
+	return filepath.Join(policyPackDir(name), versionTag+".tgz")
+}
+
+// readJSON unmarshals the object at key into v, returning false if the object does not exist.
+func readJSON(ctx context.Context, bucket Bucket, key string, v interface{}) (bool, error) {
+	b, err := bucket.ReadAll(ctx, key)
+	if err != nil {
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not read %s: %w", key, err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return false, fmt.Errorf("could not parse %s: %w", key, err)
+	}
+	return true, nil
+}
+
+func writeJSON(ctx context.Context, bucket Bucket, key string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := bucket.WriteAll(ctx, key, b, nil); err != nil {
+		return fmt.Errorf("could not write %s: %w", key, err)
+	}
+	return nil
+}
+
+// getPolicyGroup loads the named policy group. The default group always exists, even if it has never been saved.
+func (b *localBackend) getPolicyGroup(ctx context.Context, name string) (*policyGroup, bool, error) {
+	var group policyGroup
+	ok, err := readJSON(ctx, b.bucket, policyGroupPath(name), &group)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		return &policyGroup{Name: name}, name == DefaultPolicyGroup, nil
+	}
+	group.Name = name
+	return &group, true, nil
+}
+
+// listPolicyGroups loads every policy group in the backend, starting with the default group.
+func (b *localBackend) listPolicyGroups(ctx context.Context) ([]*policyGroup, error) {
+	defaultGroup, _, err := b.getPolicyGroup(ctx, DefaultPolicyGroup)
+	if err != nil {
+		return nil, err
+	}
+	groups := []*policyGroup{defaultGroup}
+
+	files, err := listBucket(ctx, b.bucket, PolicyGroupsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(objectName(file), ".json")
+		if file.IsDir || name == objectName(file) || name == DefaultPolicyGroup {
+			continue
+		}
+		group, _, err := b.getPolicyGroup(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// listPolicyPackVersions returns the metadata of every published version of the named policy pack, ordered by
+// version number.
+func (b *localBackend) listPolicyPackVersions(
+	ctx context.Context, name tokens.QName,
+) ([]apitype.PolicyPackMetadata, error) {
+	files, err := listBucket(ctx, b.bucket, policyPackDir(name))
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []apitype.PolicyPackMetadata
+	for _, file := range files {
+		if file.IsDir || path.Ext(file.Key) != ".json" {
+			continue
+		}
+		var meta apitype.PolicyPackMetadata
+		if _, err := readJSON(ctx, b.bucket, file.Key, &meta); err != nil {
+			return nil, err
+		}
+		versions = append(versions, meta)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, nil
+}
+
+// getPolicyPackVersion returns the metadata of the given version of the named policy pack, or the latest version
+// if versionTag is nil.
+func (b *localBackend) getPolicyPackVersion(
+	ctx context.Context, name tokens.QName, versionTag *string,
+) (apitype.PolicyPackMetadata, error) {
+	versions, err := b.listPolicyPackVersions(ctx, name)
+	if err != nil {
+		return apitype.PolicyPackMetadata{}, err
+	}
+	if len(versions) == 0 {
+		return apitype.PolicyPackMetadata{}, fmt.Errorf("policy pack %q has not been published", name)
+	}
+	if versionTag == nil {
+		return versions[len(versions)-1], nil
+	}
+	for _, v := range versions {
+		if v.VersionTag == *versionTag {
+			return v, nil
+		}
+	}
+	return apitype.PolicyPackMetadata{}, fmt.Errorf("policy pack %q has no version %q", name, *versionTag)
+}
+
+// requiredPolicies returns the policy packs enabled for the given stack by the backend's policy groups. A pack that
+// is enabled at different versions by several of the groups runs once, at the latest of those versions, with the
+// configuration of the first group that enables that version.
+func (b *localBackend) requiredPolicies(
+	ctx context.Context, ref *localBackendReference,
+) ([]engine.RequiredPolicy, error) {
+	groups, err := b.listPolicyGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var packs []apitype.PolicyPackMetadata
+	index := map[string]int{}
+	for _, group := range groups {
+		if !group.appliesTo(ref) {
+			continue
+		}
+		for _, pack := range group.PolicyPacks {
+			i, ok := index[pack.Name]
+			if !ok {
+				index[pack.Name] = len(packs)
+				packs = append(packs, pack)
+			} else if pack.Version > packs[i].Version {
+				packs[i] = pack
+			}
+		}
+	}
+
+	policies := make([]engine.RequiredPolicy, len(packs))
+	for i, pack := range packs {
+		policies[i] = &localRequiredPolicy{
+			PolicyPackMetadata: pack,
+			bucket:             b.bucket,
+			namespace:          b.policyNamespace(),
+		}
+	}
+	return policies, nil
+}
+
+func (b *localBackend) parsePolicyPackReference(s string) (*localPolicyPackReference, error) {
+	split := strings.Split(s, "/")
+	if len(split) != 2 {
+		return nil, fmt.Errorf("could not parse policy pack name '%s'; must be of the form "+
+			"<org-name>/<policy-pack-name>", s)
+	}
+
+	orgName := split[0]
+	if orgName == "" {
+		currentUser, _, _, err := b.CurrentUser()
+		if err != nil {
+			return nil, err
+		}
+		orgName = currentUser
+	}
+	return &localPolicyPackReference{orgName: orgName, name: tokens.QName(split[1])}, nil
+}
+
+// localPolicyPackReference is a reference to a policy pack stored in a filestate backend. Self-managed backends
+// have no organizations, so the organization name is only kept for display.
+type localPolicyPackReference struct {
+	orgName string
+	name    tokens.QName
+}
+
+var _ backend.PolicyPackReference = (*localPolicyPackReference)(nil)
+
+func (r *localPolicyPackReference) String() string {
+	return fmt.Sprintf("%s/%s", r.orgName, r.name)
+}
+
+func (r *localPolicyPackReference) OrgName() string {
+	return r.orgName
+}
+
+func (r *localPolicyPackReference) Name() tokens.QName {
+	return r.name
+}
+
+func (r *localPolicyPackReference) CloudConsoleURL() string {
+	return ""
+}
+
+// localPolicyPack is the filestate implementation of the PolicyPack interface.
+type localPolicyPack struct {
+	ref *localPolicyPackReference
+	b   *localBackend
+}
+
+var _ backend.PolicyPack = (*localPolicyPack)(nil)
+
+func (pack *localPolicyPack) Ref() backend.PolicyPackReference {
+	return pack.ref
+}
+
+func (pack *localPolicyPack) Backend() backend.Backend {
+	return pack.b
+}
+
+func (pack *localPolicyPack) Publish(ctx context.Context, op backend.PublishOperation) result.Result {
+	fmt.Println("Obtaining policy metadata from policy plugin")
+
+	abs, err := filepath.Abs(op.PlugCtx.Pwd)
+	if err != nil {
+		return result.FromError(err)
+	}
+
+	analyzer, err := op.PlugCtx.Host.PolicyAnalyzer(tokens.QName(abs), op.PlugCtx.Pwd, nil /*opts*/)
+	if err != nil {
+		return result.FromError(err)
+	}
+
+	analyzerInfo, err := analyzer.GetAnalyzerInfo()
+	if err != nil {
+		return result.FromError(err)
+	}
+	pack.ref.name = tokens.QName(analyzerInfo.Name)
+
+	policies, err := convertAnalyzerPolicies(analyzerInfo.Policies)
+	if err != nil {
+		return result.FromError(err)
+	}
+
+	versions, err := pack.b.listPolicyPackVersions(ctx, pack.ref.name)
+	if err != nil {
+		return result.FromError(err)
+	}
+	version := 1
+	if len(versions) > 0 {
+		version = versions[len(versions)-1].Version + 1
+	}
+	versionTag := analyzerInfo.Version
+	if versionTag == "" {
+		// Older versions of pulumi/policy do not report a version, so fall back to the version number.
+		versionTag = strconv.Itoa(version)
+	}
+	for _, v := range versions {
+		if v.VersionTag == versionTag {
+			return result.Errorf("version %q of policy pack %q has already been published", versionTag, pack.ref.name)
+		}
+	}
+
+	fmt.Println("Compressing policy pack")
+
+	packTarball, err := backend.ArchivePolicyPack(ctx, op)
+	if err != nil {
+		return result.FromError(err)
+	}
+
+	fmt.Printf("Publishing %q - version %s to %s\n", analyzerInfo.Name, versionTag, pack.b.originalURL)
+
+	// Write the archive first so that a pack is never listed without one.
+	archivePath := policyPackArchivePath(pack.ref.name, versionTag)
+	if err := pack.b.bucket.WriteAll(ctx, archivePath, packTarball, nil); err != nil {
+		return result.FromError(fmt.Errorf("could not write %s: %w", archivePath, err))
+	}
+	meta := localPolicyPackMetadata{
+		PolicyPackMetadata: apitype.PolicyPackMetadata{
+			Name:        analyzerInfo.Name,
+			DisplayName: analyzerInfo.DisplayName,
+			Version:     version,
+			VersionTag:  versionTag,
+		},
+		Policies: policies,
+	}
+	if err := writeJSON(ctx, pack.b.bucket, policyPackMetadataPath(pack.ref.name, versionTag), meta); err != nil {
+		return result.FromError(err)
+	}
+
+	fmt.Printf("\nPublished policy pack %s version %s\n", pack.ref, versionTag)
+	return nil
+}
+
+func (pack *localPolicyPack) Enable(ctx context.Context, policyGroup string, op backend.PolicyPackOperation) error {
+	meta, err := pack.b.getPolicyPackVersion(ctx, pack.ref.name, op.VersionTag)
+	if err != nil {
+		return err
+	}
+	meta.Config = op.Config
+
+	if policyGroup == "" {
+		policyGroup = DefaultPolicyGroup
+	}
+	group, _, err := pack.b.getPolicyGroup(ctx, policyGroup)
+	if err != nil {
+		return err
+	}
+
+	// Only a single version of a pack can be enabled in a group, so replace any existing one.
+	packs := group.PolicyPacks[:0]
+	for _, p := range group.PolicyPacks {
+		if p.Name != meta.Name {
+			packs = append(packs, p)
+		}
+	}
+	group.PolicyPacks = append(packs, meta)
+
+	return writeJSON(ctx, pack.b.bucket, policyGroupPath(group.Name), group)
+}
+
+func (pack *localPolicyPack) Disable(ctx context.Context, policyGroup string, op backend.PolicyPackOperation) error {
+	if policyGroup == "" {
+		policyGroup = DefaultPolicyGroup
+	}
+	group, ok, err := pack.b.getPolicyGroup(ctx, policyGroup)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("policy group %q does not exist", policyGroup)
+	}
+
+	packs := group.PolicyPacks[:0]
+	found := false
+	for _, p := range group.PolicyPacks {
+		if p.Name == string(pack.ref.name) && (op.VersionTag == nil || p.VersionTag == *op.VersionTag) {
+			found = true
+			continue
+		}
+		packs = append(packs, p)
+	}
+	if !found {
+		return fmt.Errorf("policy pack %q is not enabled in policy group %q", pack.ref.name, policyGroup)
+	}
+	group.PolicyPacks = packs
+
+	return writeJSON(ctx, pack.b.bucket, policyGroupPath(group.Name), group)
+}
+
+func (pack *localPolicyPack) Validate(ctx context.Context, op backend.PolicyPackOperation) error {
+	version, err := pack.b.getPolicyPackVersion(ctx, pack.ref.name, op.VersionTag)
+	if err != nil {
+		return err
+	}
+
+	var meta localPolicyPackMetadata
+	metaPath := policyPackMetadataPath(pack.ref.name, version.VersionTag)
+	if _, err := readJSON(ctx, pack.b.bucket, metaPath, &meta); err != nil {
+		return err
+	}
+
+	schema := make(map[string]apitype.PolicyConfigSchema)
+	for _, policy := range meta.Policies {
+		if policy.ConfigSchema != nil {
+			schema[policy.Name] = *policy.ConfigSchema
+		}
+	}
+	return resourceanalyzer.ValidatePolicyPackConfig(schema, op.Config)
+}
+
+func (pack *localPolicyPack) Remove(ctx context.Context, op backend.PolicyPackOperation) error {
+	groups, err := pack.b.listPolicyGroups(ctx)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		for _, p := range group.PolicyPacks {
+			if p.Name == string(pack.ref.name) && (op.VersionTag == nil || p.VersionTag == *op.VersionTag) {
+				return fmt.Errorf("policy pack %q is enabled in policy group %q and must be disabled before "+
+					"it can be removed", pack.ref.name, group.Name)
+			}
+		}
+	}
+
+	versions, err := pack.b.listPolicyPackVersions(ctx, pack.ref.name)
+	if err != nil {
+		return err
+	}
+	removed := false
+	for _, v := range versions {
+		if op.VersionTag != nil && v.VersionTag != *op.VersionTag {
+			continue
+		}
+		for _, key := range []string{
+			policyPackMetadataPath(pack.ref.name, v.VersionTag),
+			policyPackArchivePath(pack.ref.name, v.VersionTag),
+		} {
+			if err := pack.b.bucket.Delete(ctx, key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+				return fmt.Errorf("could not delete %s: %w", key, err)
+			}
+		}
+		removed = true
+	}
+	if !removed {
+		return fmt.Errorf("policy pack %q has not been published", pack.ref.name)
+	}
+	return nil
+}
+
+// localPolicyPackMetadata is the persisted metadata of a published policy pack version.
+type localPolicyPackMetadata struct {
+	apitype.PolicyPackMetadata
+	Policies []apitype.Policy `json:"policies"`
+}
+
+func convertAnalyzerPolicies(infos []plugin.AnalyzerPolicyInfo) ([]apitype.Policy, error) {
+	policies := make([]apitype.Policy, len(infos))
+	for i, policy := range infos {
+		var configSchema *apitype.PolicyConfigSchema
+		if policy.ConfigSchema != nil {
+			properties := map[string]*json.RawMessage{}
+			for k, v := range policy.ConfigSchema.Properties {
+				bytes, err := json.Marshal(v)
+				if err != nil {
+					return nil, err
+				}
+				raw := json.RawMessage(bytes)
+				properties[k] = &raw
+			}
+			configSchema = &apitype.PolicyConfigSchema{
+				Type:       apitype.Object,
+				Properties: properties,
+				Required:   policy.ConfigSchema.Required,
+			}
+		}
+
+		policies[i] = apitype.Policy{
+			Name:             policy.Name,
+			DisplayName:      policy.DisplayName,
+			Description:      policy.Description,
+			EnforcementLevel: policy.EnforcementLevel,
+			Message:          policy.Message,
+			ConfigSchema:     configSchema,
+		}
+	}
+	return policies, nil
+}
+
+// localRequiredPolicy is a policy pack enabled in one of the backend's policy groups.
+type localRequiredPolicy struct {
+	apitype.PolicyPackMetadata
+	bucket    Bucket
+	namespace string // the directory that the pack is installed under
+}
+
+var _ engine.RequiredPolicy = (*localRequiredPolicy)(nil)
+
+func (rp *localRequiredPolicy) Name() string    { return rp.PolicyPackMetadata.Name }
+func (rp *localRequiredPolicy) Version() string { return rp.PolicyPackMetadata.VersionTag }
+
+func (rp *localRequiredPolicy) Install(ctx context.Context) (string, error) {
+	name := tokens.QName(rp.PolicyPackMetadata.Name)
+	policyPackPath, installed, err := workspace.GetPolicyPath(rp.namespace,
+		strings.ReplaceAll(string(name), tokens.QNameDelimiter, "_"), rp.VersionTag)
+	if err != nil {
+		return "", err
+	} else if installed {
+		return policyPackPath, nil
+	}
+
+	fmt.Printf("Installing policy pack %s %s...\n", name, rp.VersionTag)
+
+	tarball, err := rp.bucket.ReadAll(ctx, policyPackArchivePath(name, rp.VersionTag))
+	if err != nil {
+		return "", fmt.Errorf("could not read policy pack %s %s: %w", name, rp.VersionTag, err)
+	}
+	return policyPackPath, backend.InstallPolicyPack(ctx, policyPackPath, io.NopCloser(bytes.NewReader(tarball)))
+}
+
+func (rp *localRequiredPolicy) Config() map[string]*json.RawMessage {
+	return rp.PolicyPackMetadata.Config
+}
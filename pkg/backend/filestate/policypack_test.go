@@ -0,0 +1,176 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/testing/diagtest"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+// publishTestPolicyPack stores a policy pack version the same way Publish does, without needing a policy plugin.
+func publishTestPolicyPack(t *testing.T, b *localBackend, name, versionTag string, version int) {
+	ctx := context.Background()
+	schema := json.RawMessage(`{"type":"integer"}`)
+	meta := localPolicyPackMetadata{
+		PolicyPackMetadata: apitype.PolicyPackMetadata{Name: name, Version: version, VersionTag: versionTag},
+		Policies: []apitype.Policy{{
+			Name: "max-size",
+			ConfigSchema: &apitype.PolicyConfigSchema{
+				Type:       apitype.Object,
+				Properties: map[string]*json.RawMessage{"size": &schema},
+			},
+		}},
+	}
+	require.NoError(t, b.bucket.WriteAll(ctx, policyPackArchivePath(tokens.QName(name), versionTag), []byte("tgz"), nil))
+	require.NoError(t, writeJSON(ctx, b.bucket, policyPackMetadataPath(tokens.QName(name), versionTag), meta))
+}
+
+func TestPolicyPacks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	be, err := New(ctx, diagtest.LogSink(t), "file://"+filepath.ToSlash(tmpDir), nil)
+	require.NoError(t, err)
+	b := be.(*localBackend)
+
+	devRef, err := b.ParseStackReference("organization/proj/dev")
+	require.NoError(t, err)
+	_, err = b.CreateStack(ctx, devRef, "", nil)
+	require.NoError(t, err)
+	prodRef, err := b.ParseStackReference("organization/proj/prod")
+	require.NoError(t, err)
+	_, err = b.CreateStack(ctx, prodRef, "", nil)
+	require.NoError(t, err)
+
+	publishTestPolicyPack(t, b, "security", "1.0.0", 1)
+	publishTestPolicyPack(t, b, "security", "1.1.0", 2)
+
+	packs, token, err := b.ListPolicyPacks(ctx, "organization", nil)
+	require.NoError(t, err)
+	assert.Nil(t, token)
+	require.Len(t, packs.PolicyPacks, 1)
+	assert.Equal(t, "security", packs.PolicyPacks[0].Name)
+	assert.Equal(t, []string{"1.0.0", "1.1.0"}, packs.PolicyPacks[0].VersionTags)
+
+	pack, err := b.GetPolicyPack(ctx, "organization/security", nil)
+	require.NoError(t, err)
+
+	// Validate checks the config against the schema of the published version.
+	good := json.RawMessage(`{"size": 3}`)
+	bad := json.RawMessage(`{"size": "big"}`)
+	assert.NoError(t, pack.Validate(ctx, backend.PolicyPackOperation{
+		Config: map[string]*json.RawMessage{"max-size": &good},
+	}))
+	assert.Error(t, pack.Validate(ctx, backend.PolicyPackOperation{
+		Config: map[string]*json.RawMessage{"max-size": &bad},
+	}))
+
+	// Enabling without a version enables the latest one in the default group, which applies to every stack.
+	require.NoError(t, pack.Enable(ctx, "", backend.PolicyPackOperation{
+		Config: map[string]*json.RawMessage{"max-size": &good},
+	}))
+	for _, ref := range []backend.StackReference{devRef, prodRef} {
+		policies, err := b.requiredPolicies(ctx, ref.(*localBackendReference))
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		assert.Equal(t, "security", policies[0].Name())
+		assert.Equal(t, "1.1.0", policies[0].Version())
+		assert.JSONEq(t, string(good), string(*policies[0].Config()["max-size"]))
+	}
+
+	// Other groups only apply to the stacks they match.
+	v := "1.0.0"
+	require.NoError(t, pack.Enable(ctx, "production", backend.PolicyPackOperation{VersionTag: &v}))
+	require.NoError(t, b.AddStackToPolicyGroup(ctx, "production", "organization/*/prod"))
+	require.NoError(t, b.AddStackToPolicyGroup(ctx, "production", "organization/proj/dev"))
+	group, ok, err := b.getPolicyGroup(ctx, "production")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []string{"organization/*/prod", "organization/proj/dev"}, group.Stacks)
+	require.NoError(t, b.RemoveStackFromPolicyGroup(ctx, "production", "organization/proj/dev"))
+	assert.Error(t, b.RemoveStackFromPolicyGroup(ctx, "production", "organization/proj/dev"))
+	assert.Error(t, b.AddStackToPolicyGroup(ctx, DefaultPolicyGroup, "organization/proj/dev"))
+	assert.Error(t, b.AddStackToPolicyGroup(ctx, "production", "organization/[/prod"))
+
+	// The pack is enabled at two versions for prod, but only runs once, at the later version.
+	policies, err := b.requiredPolicies(ctx, prodRef.(*localBackendReference))
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "1.1.0", policies[0].Version())
+	policies, err = b.requiredPolicies(ctx, devRef.(*localBackendReference))
+	require.NoError(t, err)
+	assert.Len(t, policies, 1)
+
+	groups, _, err := b.ListPolicyGroups(ctx, "organization", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []apitype.PolicyGroupSummary{
+		{Name: DefaultPolicyGroup, IsOrgDefault: true, NumStacks: 2, NumEnabledPolicyPacks: 1},
+		{Name: "production", NumStacks: 1, NumEnabledPolicyPacks: 1},
+	}, groups.PolicyGroups)
+
+	// A pack can't be removed while it is enabled.
+	assert.ErrorContains(t, pack.Remove(ctx, backend.PolicyPackOperation{}), "must be disabled")
+
+	require.NoError(t, pack.Disable(ctx, "", backend.PolicyPackOperation{}))
+	require.NoError(t, pack.Disable(ctx, "production", backend.PolicyPackOperation{VersionTag: &v}))
+	assert.Error(t, pack.Disable(ctx, "production", backend.PolicyPackOperation{}))
+	policies, err = b.requiredPolicies(ctx, prodRef.(*localBackendReference))
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+
+	require.NoError(t, pack.Remove(ctx, backend.PolicyPackOperation{VersionTag: &v}))
+	packs, _, err = b.ListPolicyPacks(ctx, "organization", nil)
+	require.NoError(t, err)
+	require.Len(t, packs.PolicyPacks, 1)
+	assert.Equal(t, []string{"1.1.0"}, packs.PolicyPacks[0].VersionTags)
+}
+
+func TestPolicyNamespace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	newBackend := func() *localBackend {
+		be, err := New(ctx, diagtest.LogSink(t), "file://"+filepath.ToSlash(t.TempDir()), nil)
+		require.NoError(t, err)
+		return be.(*localBackend)
+	}
+
+	// Packs published to different backends are installed to different directories.
+	a, b := newBackend(), newBackend()
+	assert.Regexp(t, `^filestate-[0-9a-f]{16}$`, a.policyNamespace())
+	assert.NotEqual(t, a.policyNamespace(), b.policyNamespace())
+
+	publishTestPolicyPack(t, a, "security", "1.0.0", 1)
+	pack, err := a.GetPolicyPack(ctx, "organization/security", nil)
+	require.NoError(t, err)
+	require.NoError(t, pack.Enable(ctx, "", backend.PolicyPackOperation{}))
+	ref, err := a.parseStackReference("organization/proj/dev")
+	require.NoError(t, err)
+	policies, err := a.requiredPolicies(ctx, ref)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, a.policyNamespace(), policies[0].(*localRequiredPolicy).namespace)
+}
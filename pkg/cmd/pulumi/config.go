@@ -26,6 +26,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/erikgeiser/promptkit/confirmation"
 	zxcvbn "github.com/nbutton23/zxcvbn-go"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
@@ -36,10 +37,13 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
 	"github.com/pulumi/pulumi/pkg/v3/secrets"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/age"
 	"github.com/pulumi/pulumi/pkg/v3/secrets/cloud"
 	"github.com/pulumi/pulumi/pkg/v3/secrets/passphrase"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/pgp"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
@@ -106,6 +110,7 @@ func newConfigCmd() *cobra.Command {
 	cmd.AddCommand(newConfigRefreshCmd(&stack))
 	cmd.AddCommand(newConfigCopyCmd(&stack))
 	cmd.AddCommand(newConfigEnvCmd(&stack))
+	cmd.AddCommand(newConfigValidateCmd(&stack))
 
 	return cmd
 }
@@ -157,11 +162,12 @@ func newConfigCopyCmd(stack *string) *cobra.Command {
 			// Do we need to copy a single value or the entire map
 			if len(args) > 0 {
 				// A single key was specified so we only need to copy that specific value
-				return copySingleConfigKey(args[0], path, currentStack, currentProjectStack, destinationStack,
+				return copySingleConfigKey(project, args[0], path, currentStack, currentProjectStack, destinationStack,
 					destinationProjectStack)
 			}
 
-			return copyEntireConfigMap(currentStack, currentProjectStack, destinationStack, destinationProjectStack)
+			return copyEntireConfigMap(project, currentStack, currentProjectStack, destinationStack,
+				destinationProjectStack)
 		}),
 	}
 
@@ -175,7 +181,7 @@ func newConfigCopyCmd(stack *string) *cobra.Command {
 	return cpCommand
 }
 
-func copySingleConfigKey(configKey string, path bool, currentStack backend.Stack,
+func copySingleConfigKey(project *workspace.Project, configKey string, path bool, currentStack backend.Stack,
 	currentProjectStack *workspace.ProjectStack, destinationStack backend.Stack,
 	destinationProjectStack *workspace.ProjectStack,
 ) error {
@@ -218,10 +224,15 @@ func copySingleConfigKey(configKey string, path bool, currentStack backend.Stack
 		return err
 	}
 
+	err = validateConfigKeys(project, destinationStack, destinationProjectStack, []config.Key{key}, path)
+	if err != nil {
+		return err
+	}
+
 	return saveProjectStack(destinationStack, destinationProjectStack)
 }
 
-func copyEntireConfigMap(currentStack backend.Stack,
+func copyEntireConfigMap(project *workspace.Project, currentStack backend.Stack,
 	currentProjectStack *workspace.ProjectStack, destinationStack backend.Stack,
 	destinationProjectStack *workspace.ProjectStack,
 ) error {
@@ -249,13 +260,21 @@ func copyEntireConfigMap(currentStack backend.Stack,
 	}
 
 	var requiresSaving bool
+	keys := make([]config.Key, 0, len(newProjectConfig))
 	for key, val := range newProjectConfig {
 		err = destinationProjectStack.Config.Set(key, val, false)
 		if err != nil {
 			return err
 		}
+		keys = append(keys, key)
 		requiresSaving = true
 	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	err = validateConfigKeys(project, destinationStack, destinationProjectStack, keys, false)
+	if err != nil {
+		return err
+	}
 
 	// The use of `requiresSaving` here ensures that there was actually some config
 	// that needed saved, otherwise it's an unnecessary save call
@@ -479,6 +498,10 @@ func newConfigRefreshCmd(stk *string) *cobra.Command {
 					err = passphrase.EditProjectStack(ps, deployment.SecretsProviders.State)
 				} else if deployment.SecretsProviders.Type == cloud.Type {
 					err = cloud.EditProjectStack(ps, deployment.SecretsProviders.State)
+				} else if deployment.SecretsProviders.Type == age.Type {
+					err = age.EditProjectStack(ps, deployment.SecretsProviders.State)
+				} else if deployment.SecretsProviders.Type == pgp.Type {
+					err = pgp.EditProjectStack(ps, deployment.SecretsProviders.State)
 				} else {
 					// Anything else assume we can just clear all the secret bits
 					ps.EncryptionSalt = ""
@@ -628,6 +651,10 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 				return err
 			}
 
+			if err := validateConfigKeys(project, s, ps, []config.Key{key}, path); err != nil {
+				return err
+			}
+
 			return saveProjectStack(s, ps)
 		}),
 	}
@@ -649,6 +676,9 @@ func newConfigSetAllCmd(stack *string) *cobra.Command {
 	var plaintextArgs []string
 	var secretArgs []string
 	var path bool
+	var fromFiles []string
+	var fromEnv string
+	var yes bool
 
 	setCmd := &cobra.Command{
 		Use:   "set-all --plaintext key1=value1 --plaintext key2=value2 --secret key3=value3",
@@ -663,7 +693,16 @@ func newConfigSetAllCmd(stack *string) *cobra.Command {
 			"  - `pulumi config set-all --path --plaintext parent.nested=value --plaintext parent.other=value2` \n" +
 			"    will set the value of `parent` to a map `{nested: value, other: value2}`.\n" +
 			"  - `pulumi config set-all --path --plaintext '[\"parent.name\"].[\"nested.name\"]'=value` will set the \n" +
-			"    value of `parent.name` to a map `nested.name: value`.",
+			"    value of `parent.name` to a map `nested.name: value`.\n\n" +
+			"The `--from-file` flag loads a YAML or JSON document that maps keys to values of any shape, and merges\n" +
+			"objects into the existing values of their keys. Values tagged with `!secret` are encrypted:\n\n" +
+			"    database:\n" +
+			"      host: db.example.com\n" +
+			"      password: !secret hunter2\n\n" +
+			"The `--from-env` flag loads every environment variable whose name starts with the given prefix. The rest\n" +
+			"of the name is the key, with `__` separating the properties of an object, and values starting with\n" +
+			"`!secret ` are encrypted, so `APP_database__port=5432` sets `database.port` with `--from-env APP_`.\n\n" +
+			"The changes are shown before they are saved.",
 		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			ctx := commandContext()
@@ -676,6 +715,11 @@ func newConfigSetAllCmd(stack *string) *cobra.Command {
 				return err
 			}
 
+			fromDocument := len(fromFiles) > 0 || fromEnv != ""
+			if fromDocument && !yes && !cmdutil.Interactive() {
+				return errors.New("--yes must be passed in to proceed when running in non-interactive mode")
+			}
+
 			// Ensure the stack exists.
 			stack, err := requireStack(ctx, *stack, stackOfferNew, opts)
 			if err != nil {
@@ -686,12 +730,18 @@ func newConfigSetAllCmd(stack *string) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			oldConfig := make(config.Map, len(ps.Config))
+			for k, v := range ps.Config {
+				oldConfig[k] = v
+			}
 
+			var keys []config.Key
 			for _, ptArg := range plaintextArgs {
 				key, value, err := parseKeyValuePair(ptArg)
 				if err != nil {
 					return err
 				}
+				keys = append(keys, key)
 				v := config.NewValue(value)
 
 				err = ps.Config.Set(key, v, path)
@@ -705,6 +755,7 @@ func newConfigSetAllCmd(stack *string) *cobra.Command {
 				if err != nil {
 					return err
 				}
+				keys = append(keys, key)
 				// We're always going to save, so can ignore the bool for if getStackEncrypter changed the
 				// config data.
 				c, _, cerr := getStackEncrypter(stack, ps)
@@ -723,6 +774,75 @@ func newConfigSetAllCmd(stack *string) *cobra.Command {
 				}
 			}
 
+			if fromDocument {
+				values := map[config.Key]config.Plaintext{}
+				for _, file := range fromFiles {
+					fileValues, err := readConfigFile(project.Name.String(), file)
+					if err != nil {
+						return err
+					}
+					for k, v := range fileValues {
+						values[k] = v
+					}
+				}
+				if fromEnv != "" {
+					envValues, err := readConfigEnv(project.Name.String(), fromEnv, os.Environ())
+					if err != nil {
+						return err
+					}
+					for k, v := range envValues {
+						values[k] = v
+					}
+				}
+
+				docKeys := make([]config.Key, 0, len(values))
+				for k := range values {
+					docKeys = append(docKeys, k)
+				}
+				sort.Slice(docKeys, func(i, j int) bool { return docKeys[i].String() < docKeys[j].String() })
+
+				dec := &lazyDecrypter{get: func() (config.Decrypter, error) {
+					dec, _, err := getStackDecrypter(stack, ps)
+					return dec, err
+				}}
+				var enc config.Encrypter = config.NewPanicCrypter()
+				for _, v := range values {
+					if v.Secure() {
+						if enc, _, err = getStackEncrypter(stack, ps); err != nil {
+							return err
+						}
+						break
+					}
+				}
+				if err := mergeConfigValues(ctx, ps.Config, values, enc); err != nil {
+					return err
+				}
+				if err := validateConfigKeys(project, stack, ps, docKeys, false); err != nil {
+					return err
+				}
+
+				fmt.Printf("Configuration changes for stack %s:\n", stack.Ref())
+				changed, err := renderConfigDiff(ctx, os.Stdout, oldConfig, ps.Config, docKeys, dec)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					fmt.Println("  (no changes)")
+				} else if !yes {
+					save, err := confirmation.New("Save?", confirmation.Yes).RunPrompt()
+					if err != nil {
+						return err
+					}
+					if !save {
+						return errors.New("canceled")
+					}
+				}
+			}
+
+			if err := validateConfigKeys(project, stack, ps, keys, path); err != nil {
+				return err
+			}
+
 			return saveProjectStack(stack, ps)
 		}),
 	}
@@ -736,6 +856,15 @@ func newConfigSetAllCmd(stack *string) *cobra.Command {
 	setCmd.PersistentFlags().StringArrayVar(
 		&secretArgs, "secret", []string{},
 		"Marks a value as secret to be encrypted")
+	setCmd.PersistentFlags().StringArrayVar(
+		&fromFiles, "from-file", []string{},
+		"Load values from a YAML or JSON file, or from standard input if the path is `-`. May be repeated")
+	setCmd.PersistentFlags().StringVar(
+		&fromEnv, "from-env", "",
+		"Load values from the environment variables whose names start with the given prefix")
+	setCmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Save the values loaded with --from-file or --from-env without prompting")
 
 	return setCmd
 }
@@ -763,6 +892,74 @@ func parseKeyValuePair(pair string) (config.Key, string, error) {
 	return key, value, nil
 }
 
+// validateConfigKeys checks the values of the given keys against the config schema declared in the project before
+// they are saved, so that invalid values are rejected by `pulumi config set` rather than by the next update. If path
+// is true, the keys are paths and the root value of each is validated. Secure values are only decrypted if the project
+// declares a type for them.
+func validateConfigKeys(
+	project *workspace.Project, stack backend.Stack, ps *workspace.ProjectStack, keys []config.Key, path bool,
+) error {
+	if len(project.Config) == 0 {
+		return nil
+	}
+
+	rootKeys := make([]config.Key, 0, len(keys))
+	for _, key := range keys {
+		if path {
+			p, err := resource.ParsePropertyPath(key.Name())
+			if err != nil {
+				return fmt.Errorf("invalid configuration key: %w", err)
+			}
+			// Set has already accepted the path, so its first segment is the name of the root key.
+			key = config.MustMakeKey(key.Namespace(), p[0].(string))
+		}
+		rootKeys = append(rootKeys, key)
+	}
+
+	dec := &lazyDecrypter{get: func() (config.Decrypter, error) {
+		dec, _, err := getStackDecrypter(stack, ps)
+		if err != nil {
+			return nil, fmt.Errorf("could not create a decrypter: %w", err)
+		}
+		return dec, nil
+	}}
+	return workspace.ValidateStackConfigKeys(stack.Ref().Name().String(), project, ps.Config, rootKeys, dec)
+}
+
+// lazyDecrypter is a Decrypter that creates the decrypter it delegates to the first time a value is decrypted, so
+// that checking plaintext values never prompts for a passphrase.
+type lazyDecrypter struct {
+	get       func() (config.Decrypter, error)
+	decrypter config.Decrypter
+}
+
+func (d *lazyDecrypter) load() (config.Decrypter, error) {
+	if d.decrypter == nil {
+		dec, err := d.get()
+		if err != nil {
+			return nil, err
+		}
+		d.decrypter = dec
+	}
+	return d.decrypter, nil
+}
+
+func (d *lazyDecrypter) DecryptValue(ctx context.Context, ciphertext string) (string, error) {
+	dec, err := d.load()
+	if err != nil {
+		return "", err
+	}
+	return dec.DecryptValue(ctx, ciphertext)
+}
+
+func (d *lazyDecrypter) BulkDecrypt(ctx context.Context, ciphertexts []string) (map[string]string, error) {
+	dec, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+	return dec.BulkDecrypt(ctx, ciphertexts)
+}
+
 var stackConfigFile string
 
 func getProjectStackPath(stack backend.Stack) (string, error) {
@@ -0,0 +1,340 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+)
+
+// secretTag is the YAML tag that marks a value in a config document as secret.
+const secretTag = "!secret"
+
+// envPathSeparator separates the segments of a config path in the name of an environment variable, since
+// environment variable names cannot contain dots.
+const envPathSeparator = "__"
+
+// parseDocumentKey parses a key of a config document. Keys without a namespace belong to the project.
+func parseDocumentKey(projectName, key string) (config.Key, error) {
+	if !strings.Contains(key, ":") {
+		key = projectName + ":" + key
+	}
+	k, err := config.ParseKey(key)
+	if err != nil {
+		return config.Key{}, fmt.Errorf("invalid configuration key '%s': %w", key, err)
+	}
+	return k, nil
+}
+
+// readConfigDocument reads a YAML or JSON document that maps config keys to values of any shape. Values tagged with
+// `!secret` are marked as secret, including every value nested in a tagged object or list.
+func readConfigDocument(projectName string, r io.Reader) (map[config.Key]config.Plaintext, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return map[config.Key]config.Plaintext{}, nil
+		}
+		return nil, err
+	}
+
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) == 1 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping of configuration keys to values", root.Line)
+	}
+
+	values := make(map[config.Key]config.Plaintext, len(root.Content)/2)
+	for i := 0; i < len(root.Content); i += 2 {
+		keyNode, valueNode := root.Content[i], root.Content[i+1]
+		key, err := parseDocumentKey(projectName, keyNode.Value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", keyNode.Line, err)
+		}
+		value, err := yamlNodeToPlaintext(valueNode, false)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// yamlNodeToPlaintext converts a YAML node to a plaintext config value. If secret is true, every string in the value
+// is marked as secret.
+func yamlNodeToPlaintext(node *yaml.Node, secret bool) (config.Plaintext, error) {
+	secret = secret || node.Tag == secretTag
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlNodeToPlaintext(node.Alias, secret)
+	case yaml.MappingNode:
+		m := make(map[string]config.Plaintext, len(node.Content)/2)
+		for i := 0; i < len(node.Content); i += 2 {
+			v, err := yamlNodeToPlaintext(node.Content[i+1], secret)
+			if err != nil {
+				return config.Plaintext{}, err
+			}
+			m[node.Content[i].Value] = v
+		}
+		return config.NewPlaintext(m), nil
+	case yaml.SequenceNode:
+		vs := make([]config.Plaintext, len(node.Content))
+		for i, elem := range node.Content {
+			v, err := yamlNodeToPlaintext(elem, secret)
+			if err != nil {
+				return config.Plaintext{}, err
+			}
+			vs[i] = v
+		}
+		return config.NewPlaintext(vs), nil
+	case yaml.ScalarNode:
+		if secret {
+			return config.NewSecurePlaintext(node.Value), nil
+		}
+		switch node.ShortTag() {
+		case "!!bool":
+			b, err := strconv.ParseBool(node.Value)
+			if err == nil {
+				return config.NewPlaintext(b), nil
+			}
+		case "!!int":
+			i, err := strconv.ParseInt(node.Value, 0, 64)
+			if err == nil {
+				return config.NewPlaintext(i), nil
+			}
+		case "!!float":
+			f, err := strconv.ParseFloat(node.Value, 64)
+			if err == nil {
+				return config.NewPlaintext(f), nil
+			}
+		case "!!null":
+			return config.Plaintext{}, fmt.Errorf("line %d: null configuration values are not supported", node.Line)
+		}
+		return config.NewPlaintext(node.Value), nil
+	default:
+		return config.Plaintext{}, fmt.Errorf("line %d: unsupported configuration value", node.Line)
+	}
+}
+
+// readConfigEnv reads config values from the environment variables whose names start with prefix. The rest of the
+// name is the config key in the project's namespace, with `__` separating the segments of a path into an object,
+// so `APP_database__port=5432` sets the `port` property of the `database` key. Values that start with `!secret ` are
+// marked as secret.
+func readConfigEnv(projectName, prefix string, environ []string) (map[config.Key]config.Plaintext, error) {
+	values := map[config.Key]config.Plaintext{}
+	names := make([]string, 0, len(environ))
+	vars := make(map[string]string, len(environ))
+	for _, kvp := range environ {
+		name, value, ok := strings.Cut(kvp, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+		names = append(names, name)
+		vars[name] = value
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := config.NewPlaintext(vars[name])
+		if s, ok := strings.CutPrefix(vars[name], secretTag+" "); ok {
+			value = config.NewSecurePlaintext(s)
+		}
+
+		segments := strings.Split(strings.TrimPrefix(name, prefix), envPathSeparator)
+		key, err := parseDocumentKey(projectName, segments[0])
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", name, err)
+		}
+
+		// Build the value from the innermost segment outwards, then merge it with the values of other variables
+		// for the same key.
+		for i := len(segments) - 1; i > 0; i-- {
+			value = config.NewPlaintext(map[string]config.Plaintext{segments[i]: value})
+		}
+		if existing, ok := values[key]; ok {
+			value = mergePlaintext(existing, value)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// mergePlaintext merges overlay into base. Objects are merged property by property; any other value in overlay
+// replaces the value in base.
+func mergePlaintext(base, overlay config.Plaintext) config.Plaintext {
+	bm, ok := base.Value().(map[string]config.Plaintext)
+	if !ok {
+		return overlay
+	}
+	om, ok := overlay.Value().(map[string]config.Plaintext)
+	if !ok {
+		return overlay
+	}
+
+	merged := make(map[string]config.Plaintext, len(bm)+len(om))
+	for k, v := range bm {
+		merged[k] = v
+	}
+	for k, v := range om {
+		if existing, ok := merged[k]; ok {
+			v = mergePlaintext(existing, v)
+		}
+		merged[k] = v
+	}
+	return config.NewPlaintext(merged)
+}
+
+// mergeConfigValues merges the given plaintext values into the config map, merging objects into the existing values
+// of their keys. Secrets in the new values are encrypted with enc; existing secrets are kept as they are.
+func mergeConfigValues(
+	ctx context.Context, m config.Map, values map[config.Key]config.Plaintext, enc config.Encrypter,
+) error {
+	for key, value := range values {
+		v, err := value.Encrypt(ctx, enc)
+		if err != nil {
+			return fmt.Errorf("encrypting configuration key '%s': %w", key, err)
+		}
+		if existing, ok := m[key]; ok {
+			if v, err = v.Merge(existing); err != nil {
+				return fmt.Errorf("merging configuration key '%s': %w", key, err)
+			}
+		}
+		m[key] = v
+	}
+	return nil
+}
+
+// configLeaf is a scalar value in a config value, as shown in a config diff.
+type configLeaf struct {
+	text   string
+	secure bool
+}
+
+// flattenPlaintext returns the scalar values in a config value, keyed by their path.
+func flattenPlaintext(prefix string, v config.Plaintext, leaves map[string]configLeaf) {
+	switch value := v.Value().(type) {
+	case map[string]config.Plaintext:
+		if len(value) == 0 {
+			leaves[prefix] = configLeaf{text: "{}"}
+		}
+		for k, elem := range value {
+			segment := "." + k
+			if strings.ContainsAny(k, ".[]\"") {
+				segment = fmt.Sprintf("[%q]", k)
+			}
+			flattenPlaintext(prefix+segment, elem, leaves)
+		}
+	case []config.Plaintext:
+		if len(value) == 0 {
+			leaves[prefix] = configLeaf{text: "[]"}
+		}
+		for i, elem := range value {
+			flattenPlaintext(fmt.Sprintf("%s[%d]", prefix, i), elem, leaves)
+		}
+	default:
+		leaves[prefix] = configLeaf{text: fmt.Sprintf("%v", value), secure: v.Secure()}
+	}
+}
+
+func (l configLeaf) String() string {
+	if l.secure {
+		return "[secret]"
+	}
+	return l.text
+}
+
+// renderConfigDiff writes the changes between the old and new values of the given keys, one line per scalar value.
+// Secret values are never shown. It returns false if nothing changed.
+func renderConfigDiff(
+	ctx context.Context, w io.Writer, oldConfig, newConfig config.Map, keys []config.Key, dec config.Decrypter,
+) (bool, error) {
+	oldLeaves, newLeaves := map[string]configLeaf{}, map[string]configLeaf{}
+	for _, key := range keys {
+		name := prettyKey(key)
+		if v, ok := oldConfig[key]; ok {
+			plaintext, err := v.Decrypt(ctx, dec)
+			if err != nil {
+				return false, fmt.Errorf("decrypting configuration key '%s': %w", key, err)
+			}
+			flattenPlaintext(name, plaintext, oldLeaves)
+		}
+		if v, ok := newConfig[key]; ok {
+			plaintext, err := v.Decrypt(ctx, dec)
+			if err != nil {
+				return false, fmt.Errorf("decrypting configuration key '%s': %w", key, err)
+			}
+			flattenPlaintext(name, plaintext, newLeaves)
+		}
+	}
+
+	paths := make([]string, 0, len(oldLeaves)+len(newLeaves))
+	for path := range newLeaves {
+		paths = append(paths, path)
+	}
+	for path := range oldLeaves {
+		if _, ok := newLeaves[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	changed := false
+	for _, path := range paths {
+		oldLeaf, hadOld := oldLeaves[path]
+		newLeaf, hasNew := newLeaves[path]
+		switch {
+		case !hadOld:
+			fmt.Fprintf(w, "  + %s: %s\n", path, newLeaf)
+		case !hasNew:
+			fmt.Fprintf(w, "  - %s: %s\n", path, oldLeaf)
+		case oldLeaf != newLeaf:
+			fmt.Fprintf(w, "  ~ %s: %s => %s\n", path, oldLeaf, newLeaf)
+		default:
+			continue
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// readConfigFile reads a config document from the file at path, or from standard input if path is "-".
+func readConfigFile(projectName, path string) (map[config.Key]config.Plaintext, error) {
+	if path == "-" {
+		return readConfigDocument(projectName, os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values, err := readConfigDocument(projectName, f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return values, nil
+}
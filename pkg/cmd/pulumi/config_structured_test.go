@@ -0,0 +1,149 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+)
+
+func TestReadConfigDocument(t *testing.T) {
+	t.Parallel()
+
+	values, err := readConfigDocument("test", strings.NewReader(`
+name: web
+replicas: 3
+debug: false
+aws:region: us-west-2
+database:
+  host: db.example.com
+  port: 5432
+  password: !secret hunter2
+tokens: !secret
+  - abc
+  - def
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, config.NewPlaintext("web"), values[config.MustMakeKey("test", "name")])
+	assert.Equal(t, config.NewPlaintext(int64(3)), values[config.MustMakeKey("test", "replicas")])
+	assert.Equal(t, config.NewPlaintext(false), values[config.MustMakeKey("test", "debug")])
+	assert.Equal(t, config.NewPlaintext("us-west-2"), values[config.MustMakeKey("aws", "region")])
+	assert.Equal(t, config.NewPlaintext(map[string]config.Plaintext{
+		"host":     config.NewPlaintext("db.example.com"),
+		"port":     config.NewPlaintext(int64(5432)),
+		"password": config.NewSecurePlaintext("hunter2"),
+	}), values[config.MustMakeKey("test", "database")])
+	assert.Equal(t, config.NewPlaintext([]config.Plaintext{
+		config.NewSecurePlaintext("abc"),
+		config.NewSecurePlaintext("def"),
+	}), values[config.MustMakeKey("test", "tokens")])
+
+	_, err = readConfigDocument("test", strings.NewReader("- not\n- a\n- mapping\n"))
+	assert.ErrorContains(t, err, "expected a mapping of configuration keys to values")
+
+	_, err = readConfigDocument("test", strings.NewReader("name: ~\n"))
+	assert.ErrorContains(t, err, "null configuration values are not supported")
+}
+
+func TestReadConfigEnv(t *testing.T) {
+	t.Parallel()
+
+	values, err := readConfigEnv("test", "APP_", []string{
+		"HOME=/root",
+		"APP_=ignored",
+		"APP_name=web",
+		"APP_database__host=db.example.com",
+		"APP_database__password=!secret hunter2",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[config.Key]config.Plaintext{
+		config.MustMakeKey("test", "name"): config.NewPlaintext("web"),
+		config.MustMakeKey("test", "database"): config.NewPlaintext(map[string]config.Plaintext{
+			"host":     config.NewPlaintext("db.example.com"),
+			"password": config.NewSecurePlaintext("hunter2"),
+		}),
+	}, values)
+}
+
+// Test that merging a document into the config merges objects with the existing values, and that the diff shows
+// every changed leaf without revealing secrets.
+//
+//nolint:paralleltest // changes the working directory
+func TestMergeConfigValuesAndDiff(t *testing.T) {
+	ctx := context.Background()
+	chdir(t, t.TempDir())
+	require.NoError(t, os.WriteFile("Pulumi.yaml", []byte("name: test\nruntime: mock\n"), 0o600))
+
+	crypter := config.NewSymmetricCrypter(make([]byte, 32))
+	database := config.MustMakeKey("test", "database")
+	replicas := config.MustMakeKey("test", "replicas")
+	region := config.MustMakeKey("aws", "region")
+
+	oldConfig := config.Map{}
+	require.NoError(t, oldConfig.Set(database, config.NewObjectValue(`{"host":"localhost","port":5432}`), false))
+	require.NoError(t, oldConfig.Set(replicas, config.NewValue("1"), false))
+	newConfig := config.Map{}
+	for k, v := range oldConfig {
+		newConfig[k] = v
+	}
+
+	values, err := readConfigDocument("test", strings.NewReader(`
+database:
+  host: db.example.com
+  password: !secret hunter2
+replicas: 1
+aws:region: us-west-2
+`))
+	require.NoError(t, err)
+	require.NoError(t, mergeConfigValues(ctx, newConfig, values, crypter))
+
+	// The port is kept from the existing value, and the password is encrypted.
+	v, err := newConfig[database].Decrypt(ctx, crypter)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"host":     "db.example.com",
+		"port":     int64(5432),
+		"password": "hunter2",
+	}, v.GoValue())
+	assert.True(t, newConfig[database].Secure())
+	raw, err := newConfig[database].MarshalJSON()
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "hunter2")
+
+	var out bytes.Buffer
+	changed, err := renderConfigDiff(ctx, &out, oldConfig, newConfig,
+		[]config.Key{region, database, replicas}, crypter)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "  + aws:region: us-west-2\n"+
+		"  ~ database.host: localhost => db.example.com\n"+
+		"  + database.password: [secret]\n", out.String())
+
+	out.Reset()
+	changed, err = renderConfigDiff(ctx, &out, newConfig, newConfig, []config.Key{database, replicas}, crypter)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, out.String())
+}
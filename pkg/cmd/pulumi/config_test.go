@@ -295,3 +295,39 @@ func TestOpenStackEnvError(t *testing.T) {
 	_, _, err = openStackEnv(context.Background(), stack, &projectStack)
 	assert.Error(t, err)
 }
+
+func TestValidateConfigKeys(t *testing.T) {
+	t.Parallel()
+
+	objectType, minimum := "object", 1024.0
+	proj := &workspace.Project{
+		Name:    tokens.PackageName("test"),
+		Runtime: workspace.NewProjectRuntimeInfo("nodejs", nil),
+		Config: map[string]workspace.ProjectConfigType{
+			"database": {
+				Type: &objectType,
+				Properties: map[string]*workspace.ProjectConfigItemsType{
+					"port": {Type: "integer", Minimum: &minimum},
+				},
+			},
+		},
+	}
+	stack := &backend.MockStack{
+		RefF: func() backend.StackReference {
+			return &backend.MockStackReference{
+				StringV: "dev",
+				NameV:   tokens.MustParseStackName("dev"),
+			}
+		},
+	}
+	ps := &workspace.ProjectStack{Config: config.Map{}}
+
+	// Setting a path validates the root value it is part of.
+	key := config.MustMakeKey("test", "database.port")
+	require.NoError(t, ps.Config.Set(key, config.NewValue("5432"), true))
+	assert.NoError(t, validateConfigKeys(proj, stack, ps, []config.Key{key}, true))
+
+	require.NoError(t, ps.Config.Set(key, config.NewValue("80"), true))
+	assert.EqualError(t, validateConfigKeys(proj, stack, ps, []config.Key{key}, true),
+		"Stack 'dev' with configuration key 'database.port' must be at least 1024")
+}
@@ -0,0 +1,190 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/esc"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+type configValidateCmd struct {
+	stdout io.Writer
+
+	stack string
+}
+
+func newConfigValidateCmd(stack *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate stack configuration against the project's config schema",
+		Long: "Validate stack configuration against the project's config schema.\n" +
+			"\n" +
+			"Checks every `Pulumi.<stack>.yaml` file of the project against the types and constraints declared in\n" +
+			"the `config` section of `Pulumi.yaml`, the same way `pulumi up` does, and exits with a non-zero status\n" +
+			"if any of them is invalid. This is useful to catch configuration mistakes in CI before deploying.\n" +
+			"Pass `--stack` to validate a single stack.\n" +
+			"\n" +
+			"Stacks whose configuration contains secrets or imports environments are loaded from the backend\n" +
+			"so that their values can be decrypted.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			ctx := commandContext()
+			cvcmd := configValidateCmd{stack: *stack}
+			return cvcmd.Run(ctx)
+		}),
+	}
+
+	return cmd
+}
+
+func (cmd *configValidateCmd) Run(ctx context.Context) error {
+	stdout := cmd.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
+	project, projectPath, err := workspace.DetectProjectAndPath()
+	if err != nil {
+		return err
+	}
+
+	paths, err := cmd.stackConfigPaths(ctx, project, projectPath)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(stdout, "No stack configuration files found")
+		return nil
+	}
+
+	invalid := 0
+	for _, path := range paths {
+		if err := validateStackConfigFile(ctx, project, path); err != nil {
+			invalid++
+			fmt.Fprintf(stdout, "%s: %v\n", filepath.Base(path), err)
+		} else {
+			fmt.Fprintf(stdout, "%s: ok\n", filepath.Base(path))
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d stack configuration file(s) are invalid", invalid, len(paths))
+	}
+	return nil
+}
+
+// stackConfigPaths returns the path of the configuration file of the selected stack, or of every stack of the project
+// if no stack was selected.
+func (cmd *configValidateCmd) stackConfigPaths(
+	ctx context.Context, project *workspace.Project, projectPath string,
+) ([]string, error) {
+	if cmd.stack != "" || stackConfigFile != "" {
+		s, err := requireStack(ctx, cmd.stack, stackLoadOnly, display.Options{
+			Color: cmdutil.GetGlobalColorization(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		path, err := getProjectStackPath(s)
+		if err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	}
+
+	dir := filepath.Dir(projectPath)
+	if project.StackConfigDir != "" {
+		dir = filepath.Join(dir, project.StackConfigDir)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, workspace.ProjectFile+".*"+filepath.Ext(projectPath)))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// validateStackConfigFile validates a single stack configuration file against the project's config schema.
+func validateStackConfigFile(ctx context.Context, project *workspace.Project, path string) error {
+	// The stack name is the part of the file name between the project file name and the extension.
+	stackName := strings.TrimSuffix(
+		strings.TrimPrefix(filepath.Base(path), workspace.ProjectFile+"."), filepath.Ext(path))
+
+	ps, err := workspace.LoadProjectStack(project, path)
+	if err != nil {
+		return err
+	}
+
+	// Validation applies the project's defaults, so work on a copy of the configuration.
+	cfg := make(config.Map, len(ps.Config))
+	for k, v := range ps.Config {
+		cfg[k] = v
+	}
+
+	var env esc.Value
+	var encrypter config.Encrypter = config.NewPanicCrypter()
+	var decrypter config.Decrypter = config.NewPanicCrypter()
+	if ps.Config.HasSecureValue() || len(ps.EnvironmentBytes()) != 0 {
+		s, err := requireStack(ctx, stackName, stackLoadOnly, display.Options{
+			Color: cmdutil.GetGlobalColorization(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(ps.EnvironmentBytes()) != 0 {
+			e, diags, err := openStackEnv(ctx, s, ps)
+			if err != nil {
+				return fmt.Errorf("opening environment: %w", err)
+			}
+			if len(diags) != 0 {
+				printESCDiagnostics(os.Stderr, diags)
+				return errors.New("opening environment: too many errors")
+			}
+			if e != nil {
+				env = e.Properties["pulumiConfig"]
+			}
+		}
+
+		if needsCrypter(cfg, env) {
+			sm, _, err := getStackSecretsManager(s, ps)
+			if err != nil {
+				return err
+			}
+			if encrypter, err = sm.Encrypter(); err != nil {
+				return err
+			}
+			if decrypter, err = sm.Decrypter(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return workspace.ValidateStackConfigAndApplyProjectConfig(stackName, project, env, cfg, encrypter, decrypter)
+}
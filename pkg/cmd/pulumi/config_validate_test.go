@@ -0,0 +1,79 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that `pulumi config validate` checks every stack configuration file of the project and fails if any of them
+// is invalid.
+//
+//nolint:paralleltest // mutates global state
+func TestConfigValidate(t *testing.T) {
+	tmpDir := t.TempDir()
+	chdir(t, tmpDir)
+
+	require.NoError(t, os.WriteFile("Pulumi.yaml", []byte(`
+name: testProject
+runtime: mock
+config:
+  size:
+    type: string
+    enum: [small, large]
+  replicas:
+    type: integer
+    minimum: 1
+    default: 1
+`), 0o600))
+	require.NoError(t, os.WriteFile("Pulumi.dev.yaml", []byte(`
+config:
+  testProject:size: small
+`), 0o600))
+	require.NoError(t, os.WriteFile("Pulumi.prod.yaml", []byte(`
+config:
+  testProject:size: large
+  testProject:replicas: 0
+`), 0o600))
+	require.NoError(t, os.WriteFile("Pulumi.test.yaml", []byte(`
+config:
+  testProject:replicas: 2
+`), 0o600))
+
+	var stdout bytes.Buffer
+	cmd := configValidateCmd{stdout: &stdout}
+	err := cmd.Run(context.Background())
+	assert.EqualError(t, err, "2 of 3 stack configuration file(s) are invalid")
+	assert.Equal(t, "Pulumi.dev.yaml: ok\n"+
+		"Pulumi.prod.yaml: Stack 'prod' with configuration key 'replicas' must be at least 1\n"+
+		"Pulumi.test.yaml: Stack 'test' is missing configuration value 'size'\n", stdout.String())
+
+	require.NoError(t, os.WriteFile("Pulumi.prod.yaml", []byte(`
+config:
+  testProject:size: large
+  testProject:replicas: 3
+`), 0o600))
+	require.NoError(t, os.Remove("Pulumi.test.yaml"))
+
+	stdout.Reset()
+	require.NoError(t, cmd.Run(context.Background()))
+	assert.Equal(t, "Pulumi.dev.yaml: ok\nPulumi.prod.yaml: ok\n", stdout.String())
+}
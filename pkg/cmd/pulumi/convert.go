@@ -29,6 +29,7 @@ import (
 	javagen "github.com/pulumi/pulumi-java/pkg/codegen/java"
 	yamlgen "github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/codegen"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/convert"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/convert/kubernetes"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/dotnet"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
@@ -102,7 +103,10 @@ func newConvertCmd() *cobra.Command {
 			"\n" +
 			"The source program to convert will default to the current working directory.\n" +
 			"\n" +
-			"Valid source languages: yaml, terraform, bicep, arm\n" +
+			"Valid source languages: yaml, terraform, bicep, arm, kubernetes\n" +
+			"\n" +
+			"Kubernetes manifests are converted by a built-in converter, which reads every .yaml and .yml file\n" +
+			"in the source directory, including the output of `helm template`.\n" +
 			"\n" +
 			"Valid target languages: typescript, python, csharp, go, java, yaml" +
 			"\n" +
@@ -260,6 +264,8 @@ func runConvert(
 	switch from {
 	case "tf":
 		from = "terraform"
+	case "k8s":
+		from = "kubernetes"
 	case "":
 		from = "yaml"
 	}
@@ -377,6 +383,15 @@ func runConvert(
 		if err != nil {
 			return fmt.Errorf("write program to intermediate directory: %w", err)
 		}
+	} else if from == "kubernetes" {
+		diagnostics, err := kubernetes.ConvertDirectory(loader, cwd, pclDirectory)
+		printDiagnostics(pCtx.Diag, diagnostics)
+		if err != nil {
+			return fmt.Errorf("convert kubernetes manifests: %w", err)
+		}
+		if diagnostics.HasErrors() {
+			return fmt.Errorf("conversion failed")
+		}
 	} else if from == "pcl" {
 		// The source code is PCL, we don't need to do anything here, just repoint pclDirectory to it, but
 		// remove the temp dir we just created first
@@ -24,11 +24,15 @@ import (
 	"os"
 	"strings"
 
+	survey "github.com/AlecAivazis/survey/v2"
+	surveycore "github.com/AlecAivazis/survey/v2/core"
 	"github.com/blang/semver"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl/v2"
 
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
@@ -42,6 +46,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
 	pkgWorkspace "github.com/pulumi/pulumi/pkg/v3/workspace"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/env"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
@@ -143,6 +148,191 @@ func makeImportFile(
 	}, nil
 }
 
+// parseDiscoveryScope parses the key=value pairs given by --scope into the scope of a call to DiscoverResources.
+func parseDiscoveryScope(scope []string) (resource.PropertyMap, error) {
+	result := resource.PropertyMap{}
+	for _, kv := range scope {
+		equals := strings.Index(kv, "=")
+		if equals <= 0 {
+			return nil, fmt.Errorf("scope must be of the form key=value, got '%s'", kv)
+		}
+		result[resource.PropertyKey(kv[:equals])] = resource.NewStringProperty(kv[equals+1:])
+	}
+	return result, nil
+}
+
+// discoverResources asks a provider for the existing resources of the given type within the given scope.
+func discoverResources(
+	provider plugin.Provider, typ tokens.Type, scope resource.PropertyMap,
+) ([]plugin.DiscoveredResource, error) {
+	resources, err := provider.DiscoverResources(typ, scope)
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, fmt.Errorf("the %s provider does not support discovering resources of type %s",
+				typ.Package(), typ)
+		}
+		return nil, fmt.Errorf("discovering resources of type %s: %w", typ, err)
+	}
+	return resources, nil
+}
+
+// discoverImportFile discovers the existing resources of the given type using the default provider for its package,
+// configured from the stack's configuration, and builds an import file for them.
+func discoverImportFile(
+	ctx *plugin.Context, snap *deploy.Snapshot, stackName tokens.StackName, projectName tokens.PackageName,
+	target *deploy.Target, typ tokens.Type, scope []string, properties []string, parentSpec, providerSpec string,
+) (importFile, error) {
+	discoveryScope, err := parseDiscoveryScope(scope)
+	if err != nil {
+		return importFile{}, err
+	}
+
+	registry := providers.NewRegistry(ctx.Host, false, nil)
+	provider, err := loadDiscoveryProvider(registry, snap, stackName, projectName, target, typ.Package(), providerSpec)
+	if err != nil {
+		return importFile{}, err
+	}
+	defer func() {
+		contract.IgnoreError(ctx.Host.CloseProvider(provider))
+	}()
+
+	resources, err := discoverResources(provider, typ, discoveryScope)
+	if err != nil {
+		return importFile{}, err
+	}
+	return makeImportFileFromDiscovery(typ, resources, properties, parentSpec, providerSpec)
+}
+
+// loadDiscoveryProvider loads and configures the provider that discovers resources in the same way that the import
+// will load the provider that reads them: the provider given by --provider is loaded from the stack's state, as is
+// the default provider for the package if the stack has one. Otherwise the default provider is configured from the
+// stack's configuration.
+func loadDiscoveryProvider(
+	registry *providers.Registry, snap *deploy.Snapshot, stackName tokens.StackName, projectName tokens.PackageName,
+	target *deploy.Target, pkg tokens.Package, providerSpec string,
+) (plugin.Provider, error) {
+	urn := resource.NewURN(stackName.Q(), projectName, "", providers.MakeProviderType(pkg), "default")
+	if providerSpec != "" {
+		_, providerURN, err := parseResourceSpec(providerSpec)
+		if err != nil {
+			providerURN = resource.URN(providerSpec)
+		}
+		if !providerURN.IsValid() || !providers.IsProviderType(providerURN.Type()) ||
+			providers.GetProviderPackage(providerURN.Type()) != pkg {
+			return nil, fmt.Errorf("'%s' is not the URN of a %s provider", providerURN, pkg)
+		}
+		urn = providerURN
+	}
+
+	if snap != nil {
+		for _, res := range snap.Resources {
+			if res.URN != urn || res.Delete {
+				continue
+			}
+			if err := registry.Same(res); err != nil {
+				return nil, err
+			}
+			ref, err := providers.NewReference(res.URN, res.ID)
+			if err != nil {
+				return nil, err
+			}
+			provider, ok := registry.GetProvider(ref)
+			contract.Assertf(ok, "provider %v must be registered", ref)
+			return provider, nil
+		}
+	}
+	if providerSpec != "" {
+		return nil, fmt.Errorf("provider '%s' does not exist in the stack", urn)
+	}
+
+	config, err := target.GetPackageConfig(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("getting the configuration of the %s provider: %w", pkg, err)
+	}
+	inputs, failures, err := registry.Check(urn, nil, config, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("checking the configuration of the %s provider: %w", pkg, err)
+	}
+	if len(failures) != 0 {
+		var errs []error
+		for _, failure := range failures {
+			errs = append(errs, fmt.Errorf("%v: %v", failure.Property, failure.Reason))
+		}
+		return nil, fmt.Errorf("invalid configuration for the %s provider: %w", pkg, errors.Join(errs...))
+	}
+	id, _, _, err := registry.Create(urn, inputs, 0, false)
+	if err != nil {
+		return nil, fmt.Errorf("configuring the %s provider: %w", pkg, err)
+	}
+	ref, err := providers.NewReference(urn, id)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := registry.GetProvider(ref)
+	contract.Assertf(ok, "provider %v must be registered", ref)
+	return provider, nil
+}
+
+// makeImportFileFromDiscovery builds an import file for the resources found by DiscoverResources. Each resource is
+// named by the name that the provider suggests for it or, failing that, by its ID. Clashing names are made unique by
+// parseImportFile.
+func makeImportFileFromDiscovery(
+	typ tokens.Type, resources []plugin.DiscoveredResource,
+	properties []string, parentSpec, providerSpec string,
+) (importFile, error) {
+	// Use makeImportFile to validate the parent and provider specs and to build the name table that they share.
+	f, err := makeImportFile(string(typ), "", "", properties, parentSpec, providerSpec, "")
+	if err != nil {
+		return importFile{}, err
+	}
+	template := f.Resources[0]
+
+	specs := make([]importSpec, len(resources))
+	for i, res := range resources {
+		spec := template
+		spec.Name, spec.ID = res.Name, res.ID
+		if spec.Name == "" {
+			spec.Name = string(res.ID)
+		}
+		specs[i] = spec
+	}
+	f.Resources = specs
+	return f, nil
+}
+
+// pruneImportFile asks the user which of the resources in an import file should be imported, and returns an import
+// file that contains only those resources.
+func pruneImportFile(f importFile, opts display.Options) (importFile, error) {
+	if len(f.Resources) == 0 {
+		return f, nil
+	}
+
+	options := make([]string, len(f.Resources))
+	specs := make(map[string]importSpec, len(f.Resources))
+	for i, spec := range f.Resources {
+		options[i] = fmt.Sprintf("%s (%s)", spec.Name, spec.ID)
+		specs[options[i]] = spec
+	}
+
+	surveycore.DisableColor = true
+	var selected []string
+	if err := survey.AskOne(&survey.MultiSelect{
+		Message:  opts.Color.Colorize(colors.SpecPrompt + "Choose the resources to import:" + colors.Reset),
+		Options:  options,
+		Default:  options,
+		PageSize: optimalPageSize(optimalPageSizeOpts{nopts: len(options)}),
+	}, &selected, surveyIcons(opts.Color)); err != nil {
+		return importFile{}, err
+	}
+
+	pruned := make([]importSpec, len(selected))
+	for i, option := range selected {
+		pruned[i] = specs[option]
+	}
+	f.Resources = pruned
+	return f, nil
+}
+
 type importSpec struct {
 	Type              tokens.Type `json:"type"`
 	Name              string      `json:"name"`
@@ -489,7 +679,7 @@ func generateImportedDefinitions(ctx *plugin.Context,
 	}
 
 	loader := schema.NewPluginLoader(ctx.Host)
-	return true, importer.GenerateLanguageDefinitions(out, loader, func(w io.Writer, p *pcl.Program) error {
+	diags, err := importer.GenerateLanguageDefinitionsWithDiagnostics(out, loader, func(w io.Writer, p *pcl.Program) error {
 		files, _, err := programGenerator(p, loader)
 		if err != nil {
 			return err
@@ -505,6 +695,10 @@ func generateImportedDefinitions(ctx *plugin.Context,
 		}
 		return nil
 	}, resources, names)
+	// The diagnostics describe values that were generated as literals rather than as references to other imported
+	// resources, so they are worth reporting even if code generation failed.
+	printDiagnostics(ctx.Diag, diags)
+	return true, err
 }
 
 func newImportCmd() *cobra.Command {
@@ -533,6 +727,8 @@ func newImportCmd() *cobra.Command {
 	var properties []string
 
 	var from string
+	var discover bool
+	var scope []string
 
 	cmd := &cobra.Command{
 		Use:   "import [type] [name] [id]",
@@ -564,6 +760,13 @@ func newImportCmd() *cobra.Command {
 			"\n" +
 			"     pulumi import -f import.json\n" +
 			"\n" +
+			"If the provider supports it, the existing resources of a type can be discovered\n" +
+			"rather than listed by hand. The provider is configured from the stack's configuration,\n" +
+			"and the --scope flags narrow the search in a provider-specific way. You will be asked\n" +
+			"which of the discovered resources to import, unless --yes is passed:\n" +
+			"\n" +
+			"     pulumi import 'aws:s3/bucket:Bucket' --discover --scope region=us-west-2\n" +
+			"\n" +
 			"Where import.json is a file that matches the following JSON format:\n" +
 			"\n" +
 			"    {\n" +
@@ -619,8 +822,23 @@ func newImportCmd() *cobra.Command {
 				return result.FromError(fmt.Errorf("create plugin context: %w", err))
 			}
 
+			if len(scope) != 0 && !discover {
+				contract.IgnoreError(cmd.Help())
+				return result.Errorf("a discovery scope may only be specified in conjunction with --discover")
+			}
+
 			var importFile importFile
-			if importFilePath != "" {
+			if discover {
+				if importFilePath != "" || from != "" {
+					contract.IgnoreError(cmd.Help())
+					return result.Errorf("--discover may not be specified in conjunction with an import file or converter")
+				}
+				if len(args) != 1 {
+					contract.IgnoreError(cmd.Help())
+					return result.Errorf("--discover expects a single argument, the type of the resources to import")
+				}
+				// The resources are discovered once the stack's configuration is loaded, as the provider needs it.
+			} else if importFilePath != "" {
 				if len(args) != 0 || parentSpec != "" || providerSpec != "" || len(properties) != 0 {
 					contract.IgnoreError(cmd.Help())
 					return result.Errorf("an inline resource may not be specified in conjunction with an import file")
@@ -797,11 +1015,6 @@ func newImportCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
-			imports, nameTable, err := parseImportFile(importFile, s.Ref().Name(), proj.Name, protectResources)
-			if err != nil {
-				return result.FromError(err)
-			}
-
 			wrapper := func(
 				f func(*pcl.Program) (map[string][]byte, hcl.Diagnostics, error),
 			) func(*pcl.Program, schema.ReferenceLoader) (map[string][]byte, hcl.Diagnostics, error) {
@@ -886,6 +1099,34 @@ func newImportCmd() *cobra.Command {
 				return result.FromError(fmt.Errorf("validating stack config: %w", configErr))
 			}
 
+			if discover {
+				snap, err := s.Snapshot(ctx, stack.DefaultSecretsProvider)
+				if err != nil {
+					return result.FromError(fmt.Errorf("getting snapshot: %w", err))
+				}
+				target := &deploy.Target{Config: cfg.Config, Decrypter: decrypter}
+				f, err := discoverImportFile(pCtx, snap, s.Ref().Name(), proj.Name, target, tokens.Type(args[0]),
+					scope, properties, parentSpec, providerSpec)
+				if err != nil {
+					return result.FromError(err)
+				}
+				if interactive && !yes {
+					if f, err = pruneImportFile(f, opts.Display); err != nil {
+						return result.FromError(err)
+					}
+				}
+				if len(f.Resources) == 0 {
+					fmt.Fprintln(os.Stderr, "There are no resources to import.")
+					return nil
+				}
+				importFile = f
+			}
+
+			imports, nameTable, err := parseImportFile(importFile, s.Ref().Name(), proj.Name, protectResources)
+			if err != nil {
+				return result.FromError(err)
+			}
+
 			opts.Engine = engine.UpdateOptions{
 				Parallel:      parallel,
 				Debug:         debug,
@@ -945,9 +1186,9 @@ func newImportCmd() *cobra.Command {
 					return result.FromError(errors.New("import cancelled"))
 				}
 
-				// If we did a conversion import (i.e. from!="") then lets write the file we've built out to the local
+				// If we did a conversion or discovery import then lets write the file we've built out to the local
 				// directory so if there's any issues users can manually edit the file and try again with --file
-				if from != "" {
+				if from != "" || discover {
 					path, err := writeImportFile(importFile)
 					if err != nil {
 						return result.FromError(err)
@@ -1018,6 +1259,12 @@ func newImportCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVar(
 		&from, "from", "",
 		"Invoke a converter to import the resources")
+	cmd.PersistentFlags().BoolVar(
+		&discover, "discover", false,
+		"Ask the provider for the existing resources of the given type, and choose which of them to import")
+	cmd.PersistentFlags().StringArrayVar(
+		&scope, "scope", nil,
+		"A key=value pair that narrows the search for resources to discover. May be specified multiple times")
 
 	if hasDebugCommands() {
 		cmd.PersistentFlags().StringVar(
@@ -3,9 +3,16 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"testing"
 
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -408,3 +415,141 @@ func TestImportFileMarshal(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expected, buffer.String())
 }
+
+func TestParseDiscoveryScope(t *testing.T) {
+	t.Parallel()
+
+	scope, err := parseDiscoveryScope([]string{"region=us-west-2", "filter=a=b", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{
+		"region": resource.NewStringProperty("us-west-2"),
+		"filter": resource.NewStringProperty("a=b"),
+		"empty":  resource.NewStringProperty(""),
+	}, scope)
+
+	for _, kv := range []string{"region", "=us-west-2"} {
+		_, err := parseDiscoveryScope([]string{kv})
+		assert.ErrorContains(t, err, "scope must be of the form key=value", kv)
+	}
+}
+
+func TestDiscoverResources(t *testing.T) {
+	t.Parallel()
+
+	provider := &deploytest.Provider{
+		DiscoverResourcesF: func(typ tokens.Type, scope resource.PropertyMap) ([]plugin.DiscoveredResource, error) {
+			assert.Equal(t, resource.PropertyMap{"region": resource.NewStringProperty("west")}, scope)
+			return []plugin.DiscoveredResource{{ID: "a-1", Name: "a"}, {ID: "b-2"}}, nil
+		},
+	}
+	resources, err := discoverResources(provider, "pkg:index:Thing",
+		resource.PropertyMap{"region": resource.NewStringProperty("west")})
+	require.NoError(t, err)
+	assert.Equal(t, []plugin.DiscoveredResource{{ID: "a-1", Name: "a"}, {ID: "b-2"}}, resources)
+
+	// A provider that does not support discovery is reported as such.
+	_, err = discoverResources(&deploytest.Provider{}, "pkg:index:Thing", nil)
+	assert.EqualError(t, err, "the pkg provider does not support discovering resources of type pkg:index:Thing")
+
+	// Other errors are passed through.
+	provider.DiscoverResourcesF = func(tokens.Type, resource.PropertyMap) ([]plugin.DiscoveredResource, error) {
+		return nil, errors.New("access denied")
+	}
+	_, err = discoverResources(provider, "pkg:index:Thing", nil)
+	assert.EqualError(t, err, "discovering resources of type pkg:index:Thing: access denied")
+}
+
+func TestMakeImportFileFromDiscovery(t *testing.T) {
+	t.Parallel()
+
+	providerURN := "urn:pulumi:stack::proj::pulumi:providers:pkg::prov"
+	f, err := makeImportFileFromDiscovery("pkg:index:Thing", []plugin.DiscoveredResource{
+		{ID: "a-1", Name: "a"},
+		{ID: "b-2"},
+		{ID: "a-3", Name: "a"},
+	}, []string{"size"}, "", "admin="+providerURN)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]resource.URN{"admin": resource.URN(providerURN)}, f.NameTable)
+	assert.Equal(t, []importSpec{
+		{Type: "pkg:index:Thing", Name: "a", ID: "a-1", Provider: "admin", Properties: []string{"size"}},
+		{Type: "pkg:index:Thing", Name: "b-2", ID: "b-2", Provider: "admin", Properties: []string{"size"}},
+		{Type: "pkg:index:Thing", Name: "a", ID: "a-3", Provider: "admin", Properties: []string{"size"}},
+	}, f.Resources)
+
+	// Clashing names are made unique when the import file is parsed.
+	imports, _, err := parseImportFile(f, tokens.MustParseStackName("stack"), "proj", false)
+	require.NoError(t, err)
+	names := make([]string, len(imports))
+	for i, imp := range imports {
+		names[i] = imp.Name
+	}
+	assert.Equal(t, []string{"a", "b-2", "a_1"}, names)
+
+	_, err = makeImportFileFromDiscovery("pkg:index:Thing", nil, nil, "not-a-urn", "")
+	assert.ErrorContains(t, err, "invalid parent URN")
+}
+
+func TestLoadDiscoveryProvider(t *testing.T) {
+	t.Parallel()
+
+	var configured []resource.PropertyMap
+	host := deploytest.NewPluginHost(nil, nil, nil,
+		deploytest.NewProviderLoader("pkg", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				ConfigureF: func(news resource.PropertyMap) error {
+					configured = append(configured, news)
+					return nil
+				},
+			}, nil
+		}),
+		deploytest.NewProviderLoader("pkg", semver.MustParse("2.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				ConfigureF: func(news resource.PropertyMap) error {
+					return errors.New("version 2.0.0 must not be loaded")
+				},
+			}, nil
+		}),
+	)
+
+	stackName := tokens.MustParseStackName("stack")
+	providerURN := resource.URN("urn:pulumi:stack::proj::pulumi:providers:pkg::prov")
+	inputs := resource.PropertyMap{
+		"region":  resource.NewStringProperty("west"),
+		"version": resource.NewStringProperty("1.0.0"),
+	}
+	snap := &deploy.Snapshot{
+		Resources: []*resource.State{{
+			Type:   providerURN.Type(),
+			URN:    providerURN,
+			Custom: true,
+			ID:     "prov-id",
+			Inputs: inputs,
+		}},
+	}
+	target := &deploy.Target{Config: config.Map{}}
+
+	// The provider given by --provider is configured with its inputs in the stack, at its version.
+	_, err := loadDiscoveryProvider(providers.NewRegistry(host, false, nil), snap, stackName, "proj", target, "pkg",
+		"admin="+string(providerURN))
+	require.NoError(t, err)
+	require.Len(t, configured, 1)
+	assert.Equal(t, inputs, configured[0])
+
+	// Providers that are not in the stack, or that are for another package, are reported.
+	_, err = loadDiscoveryProvider(providers.NewRegistry(host, false, nil), snap, stackName, "proj", target, "pkg",
+		"urn:pulumi:stack::proj::pulumi:providers:pkg::missing")
+	assert.EqualError(t, err,
+		"provider 'urn:pulumi:stack::proj::pulumi:providers:pkg::missing' does not exist in the stack")
+	_, err = loadDiscoveryProvider(providers.NewRegistry(host, false, nil), snap, stackName, "proj", target, "other",
+		string(providerURN))
+	assert.EqualError(t, err, "'"+string(providerURN)+"' is not the URN of a other provider")
+
+	// The default provider is configured from the stack's configuration if the stack does not have one.
+	target.Config[config.MustMakeKey("pkg", "region")] = config.NewValue("east")
+	target.Config[config.MustMakeKey("pkg", "version")] = config.NewValue("1.0.0")
+	_, err = loadDiscoveryProvider(providers.NewRegistry(host, false, nil), nil, stackName, "proj", target, "pkg", "")
+	require.NoError(t, err)
+	require.Len(t, configured, 2)
+	assert.Equal(t, resource.NewStringProperty("east"), configured[1]["region"])
+}
@@ -34,6 +34,8 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -51,6 +53,7 @@ Subcommands of this command are useful to package authors during development.`,
 		newExtractSchemaCommand(),
 		newExtractMappingCommand(),
 		newGenSdkCommand(),
+		newGenDocsCommand(),
 		newPackagePublishCmd(),
 		newPackagePackCmd(),
 	)
@@ -96,6 +99,12 @@ func schemaFromSchemaSource(packageSource string) (*schema.Package, error) {
 		return bind(spec)
 	}
 
+	if schemaBytes, ok := cachedPluginSchema(packageSource); ok {
+		if err := json.Unmarshal(schemaBytes, &spec); err == nil {
+			return bind(spec)
+		}
+	}
+
 	p, err := providerFromSource(packageSource)
 	if err != nil {
 		return nil, err
@@ -112,6 +121,60 @@ func schemaFromSchemaSource(packageSource string) (*schema.Package, error) {
 	return bind(spec)
 }
 
+// cachedPluginSchema returns the schema of an installed plugin through the shared schema cache, so that the plugin
+// only runs the first time its schema is requested. It returns false if the source is not the name of an installed
+// plugin, or if the schema cannot be cached.
+func cachedPluginSchema(packageSource string) ([]byte, bool) {
+	if env.DisableSchemaCache.Value() || isPluginPath(packageSource) {
+		return nil, false
+	}
+	pkg, version, err := parsePluginSource(packageSource)
+	if err != nil {
+		return nil, false
+	}
+	cache, err := schema.DefaultSchemaCache()
+	if err != nil {
+		return nil, false
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, false
+	}
+	sink := cmdutil.Diag()
+	pCtx, err := plugin.NewContext(sink, sink, nil, nil, wd, nil, false, nil)
+	if err != nil {
+		return nil, false
+	}
+	defer contract.IgnoreClose(pCtx)
+
+	entry, _, err := cache.Warm(pCtx.Host, pkg, version)
+	if err != nil {
+		logging.V(7).Infof("schema cache: %v", err)
+		return nil, false
+	}
+	return cache.Get(entry.SchemaCacheKey, entry.PluginTime)
+}
+
+// parsePluginSource splits a plugin source of the form PLUGIN[@VERSION] into its name and version.
+func parsePluginSource(packageSource string) (string, *semver.Version, error) {
+	s := strings.SplitN(packageSource, "@", 2)
+	if len(s) != 2 {
+		return packageSource, nil, nil
+	}
+	v, err := semver.ParseTolerant(s[1])
+	if err != nil {
+		return "", nil, fmt.Errorf("VERSION must be valid semver: %w", err)
+	}
+	return s[0], &v, nil
+}
+
+// isPluginPath returns true if a plugin source is the path to a plugin binary rather than the name of a plugin.
+func isPluginPath(packageSource string) bool {
+	// On unix, these checks are identical. On windows, filepath.Separator is '\\'
+	return strings.ContainsRune(packageSource, filepath.Separator) || strings.ContainsRune(packageSource, '/')
+}
+
 // providerFromSource takes a plugin name or path.
 //
 // PLUGIN[@VERSION] | PATH_TO_PLUGIN
@@ -126,15 +189,9 @@ func providerFromSource(packageSource string) (plugin.Provider, error) {
 		return nil, err
 	}
 
-	var version *semver.Version
-	pkg := packageSource
-	if s := strings.SplitN(packageSource, "@", 2); len(s) == 2 {
-		pkg = s[0]
-		v, err := semver.ParseTolerant(s[1])
-		if err != nil {
-			return nil, fmt.Errorf("VERSION must be valid semver: %w", err)
-		}
-		version = &v
+	pkg, version, err := parsePluginSource(packageSource)
+	if err != nil {
+		return nil, err
 	}
 
 	isExecutable := func(info fs.FileInfo) bool {
@@ -146,8 +203,7 @@ func providerFromSource(packageSource string) (plugin.Provider, error) {
 	}
 
 	// No file separators, so we try to look up the schema
-	// On unix, these checks are identical. On windows, filepath.Separator is '\\'
-	if !strings.ContainsRune(pkg, filepath.Separator) && !strings.ContainsRune(pkg, '/') {
+	if !isPluginPath(pkg) {
 		host, err := plugin.NewDefaultHost(pCtx, nil, false, nil, nil)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,64 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/docs"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+func newGenDocsCommand() *cobra.Command {
+	var out string
+	cmd := &cobra.Command{
+		Use:   "gen-docs <schema_source>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Generate Markdown documentation for a package or schema",
+		Long: `Generate Markdown documentation for a package or schema.
+
+Writes a self-contained tree of Markdown pages: an index for the package and for each
+of its modules, a page for each resource and function with examples in every language,
+and a page for each module's types. Pages link to each other with relative links, so the
+tree can be browsed as-is or published with any static site generator.
+
+<schema_source> can be a package name, the path to a plugin binary, or the path to a schema file.`,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			pkg, err := schemaFromSchemaSource(args[0])
+			if err != nil {
+				return err
+			}
+			files, err := docs.GenerateMarkdown("pulumi", pkg)
+			if err != nil {
+				return err
+			}
+			for name, contents := range files {
+				path := filepath.Join(out, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+					return err
+				}
+				if err := os.WriteFile(path, contents, 0o600); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	}
+	cmd.Flags().StringVarP(&out, "out", "o", "./docs", "The directory to write the documentation to")
+	return cmd
+}
@@ -15,16 +15,22 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	javagen "github.com/pulumi/pulumi-java/pkg/codegen/java"
 
 	"github.com/pulumi/pulumi/pkg/v3/codegen/dotnet"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/jsonschema"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
@@ -35,13 +41,18 @@ func newGenSdkCommand() *cobra.Command {
 	var overlays string
 	var language string
 	var out string
+	var check bool
 	cmd := &cobra.Command{
 		Use:   "gen-sdk <schema_source>",
 		Args:  cobra.ExactArgs(1),
 		Short: "Generate SDK(s) from a package or schema",
 		Long: `Generate SDK(s) from a package or schema.
 
-<schema_source> can be a package name, the path to a plugin binary, or the path to a schema file.`,
+<schema_source> can be a package name, the path to a plugin binary, or the path to a schema file.
+
+Only the files whose contents have changed are rewritten, and files that are no longer generated are deleted
+from the output directory. Use --check to verify that the SDK(s) in the output directory are up to date
+without modifying them.`,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			source := args[0]
 
@@ -57,109 +68,106 @@ func newGenSdkCommand() *cobra.Command {
 				language = "nodejs"
 			}
 
+			languages := []string{language}
 			if language == "all" {
-				for _, lang := range []string{"dotnet", "go", "java", "nodejs", "python"} {
-					err := genSDK(lang, out, pkg, overlays)
-					if err != nil {
-						return err
-					}
+				languages = []string{"dotnet", "go", "java", "nodejs", "python"}
+			}
+
+			var outdated []string
+			for _, lang := range languages {
+				changes, err := genSDK(os.Stdout, lang, out, pkg, overlays, check)
+				if err != nil {
+					return err
+				}
+				if !changes.empty() {
+					outdated = append(outdated, lang)
 				}
-				return nil
 			}
-			return genSDK(language, out, pkg, overlays)
+			if check && len(outdated) != 0 {
+				return fmt.Errorf("the %s SDK(s) in %s are out of date; run `pulumi package gen-sdk` to update them",
+					strings.Join(outdated, ", "), out)
+			}
+			return nil
 		}),
 	}
 	cmd.Flags().StringVarP(&language, "language", "", "all",
-		"The SDK language to generate: [nodejs|python|go|dotnet|java|all]")
+		"The SDK language to generate: [nodejs|python|go|dotnet|java|all]. "+
+			"Use jsonschema to generate a JSON Schema and an OpenAPI document that editors can use to complete and "+
+			"validate YAML programs")
 	cmd.Flags().StringVarP(&out, "out", "o", "./sdk",
 		"The directory to write the SDK to")
+	cmd.Flags().BoolVar(&check, "check", false,
+		"Check that the SDK(s) in the output directory are up to date instead of writing them")
 	cmd.Flags().StringVar(&overlays, "overlays", "", "A folder of extra overlay files to copy to the generated SDK")
 	contract.AssertNoErrorf(cmd.Flags().MarkHidden("overlays"), `Could not mark "overlay" as hidden`)
 	return cmd
 }
 
-func genSDK(language, out string, pkg *schema.Package, overlays string) error {
+// genSDK generates the SDK for the given language and compares it to the SDK in the output directory. Unless check
+// is set, the changed files are written and the files that are no longer generated are deleted. A summary of the
+// changes is written to w.
+func genSDK(w io.Writer, language, out string, pkg *schema.Package, overlays string, check bool) (sdkChanges, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("get current working directory: %w", err)
+		return sdkChanges{}, fmt.Errorf("get current working directory: %w", err)
 	}
 
-	writeWrapper := func(
+	generateWrapper := func(
 		generatePackage func(string, *schema.Package, map[string][]byte) (map[string][]byte, error),
-	) func(string, *schema.Package, map[string][]byte) error {
-		return func(directory string, p *schema.Package, extraFiles map[string][]byte) error {
-			m, err := generatePackage("pulumi", p, extraFiles)
-			if err != nil {
-				return err
-			}
-
-			err = os.RemoveAll(directory)
-			if err != nil && !os.IsNotExist(err) {
-				return err
-			}
-			for k, v := range m {
-				path := filepath.Join(directory, k)
-				err := os.MkdirAll(filepath.Dir(path), 0o700)
-				if err != nil {
-					return err
-				}
-				err = os.WriteFile(path, v, 0o600)
-				if err != nil {
-					return err
-				}
-			}
-			return nil
+	) func(*schema.Package, map[string][]byte) (map[string][]byte, error) {
+		return func(p *schema.Package, extraFiles map[string][]byte) (map[string][]byte, error) {
+			return generatePackage("pulumi", p, extraFiles)
 		}
 	}
 
-	var generatePackage func(string, *schema.Package, map[string][]byte) error
+	var generatePackage func(*schema.Package, map[string][]byte) (map[string][]byte, error)
 	switch language {
 	case "dotnet":
-		generatePackage = writeWrapper(dotnet.GeneratePackage)
+		generatePackage = generateWrapper(dotnet.GeneratePackage)
 	case "java":
-		generatePackage = writeWrapper(javagen.GeneratePackage)
+		generatePackage = generateWrapper(javagen.GeneratePackage)
+	case "jsonschema":
+		generatePackage = generateWrapper(jsonschema.GeneratePackage)
 	default:
-		generatePackage = func(directory string, pkg *schema.Package, extraFiles map[string][]byte) error {
-			// Ensure the target directory is clean, but created.
-			err = os.RemoveAll(directory)
-			if err != nil && !os.IsNotExist(err) {
-				return err
-			}
-			err := os.MkdirAll(directory, 0o700)
+		generatePackage = func(pkg *schema.Package, extraFiles map[string][]byte) (map[string][]byte, error) {
+			// The language plugin writes the SDK to a directory, so have it write to a temporary one that the
+			// generated files are then read back from.
+			directory, err := os.MkdirTemp("", "pulumi-gen-sdk")
 			if err != nil {
-				return err
+				return nil, fmt.Errorf("create temporary directory: %w", err)
 			}
+			defer os.RemoveAll(directory)
 
 			jsonBytes, err := pkg.MarshalJSON()
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			pCtx, err := newPluginContext(cwd)
 			if err != nil {
-				return fmt.Errorf("create plugin context: %w", err)
+				return nil, fmt.Errorf("create plugin context: %w", err)
 			}
 			defer contract.IgnoreClose(pCtx.Host)
 
 			languagePlugin, err := pCtx.Host.LanguageRuntime(cwd, cwd, language, nil)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			loader := schema.NewPluginLoader(pCtx.Host)
 			loaderServer := schema.NewLoaderServer(loader)
 			grpcServer, err := plugin.NewServer(pCtx, schema.LoaderRegistration(loaderServer))
 			if err != nil {
-				return err
+				return nil, err
 			}
 			defer contract.IgnoreClose(grpcServer)
 
 			err = languagePlugin.GeneratePackage(directory, string(jsonBytes), extraFiles, grpcServer.Addr())
 			if err != nil {
-				return err
+				return nil, err
 			}
 
-			return nil
+			return readSDK(directory)
 		}
 	}
 
@@ -180,14 +188,178 @@ func genSDK(language, out string, pkg *schema.Package, overlays string) error {
 			return nil
 		})
 		if err != nil {
-			return fmt.Errorf("read overlay directory %q: %w", overlays, err)
+			return sdkChanges{}, fmt.Errorf("read overlay directory %q: %w", overlays, err)
 		}
 	}
 
+	files, err := generatePackage(pkg, extraFiles)
+	if err != nil {
+		return sdkChanges{}, err
+	}
+
 	root := filepath.Join(out, language)
-	err = generatePackage(root, pkg, extraFiles)
+	changes, err := diffSDK(root, files)
+	if err != nil {
+		return sdkChanges{}, err
+	}
+
+	fmt.Fprintf(w, "%s: %v\n", root, changes)
+	if check {
+		changes.print(w)
+		return changes, nil
+	}
+	return changes, writeSDK(root, files, changes)
+}
+
+// sdkChanges describes how a generated SDK differs from the SDK in its output directory. Paths are relative to the
+// output directory and use forward slashes.
+type sdkChanges struct {
+	added     []string
+	updated   []string
+	deleted   []string
+	unchanged int
+}
+
+// empty returns true if the generated SDK is identical to the SDK in the output directory.
+func (c sdkChanges) empty() bool {
+	return len(c.added) == 0 && len(c.updated) == 0 && len(c.deleted) == 0
+}
+
+func (c sdkChanges) String() string {
+	if c.empty() {
+		return fmt.Sprintf("up to date (%d files)", c.unchanged)
+	}
+	return fmt.Sprintf("%d added, %d updated, %d deleted, %d unchanged",
+		len(c.added), len(c.updated), len(c.deleted), c.unchanged)
+}
+
+// print writes the path of each changed file to w.
+func (c sdkChanges) print(w io.Writer) {
+	for _, p := range c.added {
+		fmt.Fprintf(w, "    + %s\n", p)
+	}
+	for _, p := range c.updated {
+		fmt.Fprintf(w, "    ~ %s\n", p)
+	}
+	for _, p := range c.deleted {
+		fmt.Fprintf(w, "    - %s\n", p)
+	}
+}
+
+// readSDK reads the files in directory into a map from their relative paths to their contents.
+func readSDK(directory string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	fsys := os.DirFS(directory)
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		contents, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		files[path] = contents
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("read generated SDK: %w", err)
+	}
+	return files, nil
+}
+
+// diffSDK compares the generated files with the files in directory, which need not exist.
+func diffSDK(directory string, files map[string][]byte) (sdkChanges, error) {
+	generated := make(map[string][]byte, len(files))
+	for k, v := range files {
+		generated[path.Clean(filepath.ToSlash(k))] = v
+	}
+
+	var changes sdkChanges
+	existing := map[string]bool{}
+	err := filepath.WalkDir(directory, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == directory {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(directory, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		existing[rel] = true
+
+		contents, ok := generated[rel]
+		if !ok {
+			changes.deleted = append(changes.deleted, rel)
+			return nil
+		}
+		current, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(current, contents) {
+			changes.unchanged++
+		} else {
+			changes.updated = append(changes.updated, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return sdkChanges{}, fmt.Errorf("read SDK directory: %w", err)
+	}
+
+	for p := range generated {
+		if !existing[p] {
+			changes.added = append(changes.added, p)
+		}
+	}
+	sort.Strings(changes.added)
+	sort.Strings(changes.updated)
+	sort.Strings(changes.deleted)
+	return changes, nil
+}
+
+// writeSDK applies the given changes to the SDK in directory. Directories that are left empty by deleting files are
+// removed.
+func writeSDK(directory string, files map[string][]byte, changes sdkChanges) error {
+	generated := make(map[string][]byte, len(files))
+	for k, v := range files {
+		generated[path.Clean(filepath.ToSlash(k))] = v
+	}
+
+	for _, p := range append(append([]string{}, changes.added...), changes.updated...) {
+		target := filepath.Join(directory, filepath.FromSlash(p))
+		err := os.MkdirAll(filepath.Dir(target), 0o700)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(target, generated[p], 0o600)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range changes.deleted {
+		target := filepath.Join(directory, filepath.FromSlash(p))
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for dir := filepath.Dir(target); dir != filepath.Clean(directory); dir = filepath.Dir(dir) {
+			entries, err := os.ReadDir(dir)
+			if err != nil || len(entries) != 0 {
+				break
+			}
+			if err := os.Remove(dir); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -0,0 +1,162 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/jsonschema"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+func writeFiles(t *testing.T, directory string, files map[string]string) {
+	for name, contents := range files {
+		path := filepath.Join(directory, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	}
+}
+
+func TestDiffSDK(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"index.ts":       "index",
+		"types/input.ts": "old inputs",
+		"old/removed.ts": "removed",
+	})
+
+	changes, err := diffSDK(dir, map[string][]byte{
+		"index.ts":        []byte("index"),
+		"types/input.ts":  []byte("new inputs"),
+		"types/output.ts": []byte("outputs"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sdkChanges{
+		added:     []string{"types/output.ts"},
+		updated:   []string{"types/input.ts"},
+		deleted:   []string{"old/removed.ts"},
+		unchanged: 1,
+	}, changes)
+	assert.Equal(t, "1 added, 1 updated, 1 deleted, 1 unchanged", changes.String())
+
+	var out bytes.Buffer
+	changes.print(&out)
+	assert.Equal(t, "    + types/output.ts\n    ~ types/input.ts\n    - old/removed.ts\n", out.String())
+}
+
+func TestDiffSDKMissingDirectory(t *testing.T) {
+	t.Parallel()
+
+	changes, err := diffSDK(filepath.Join(t.TempDir(), "missing"), map[string][]byte{"a": nil, "./b/c": nil})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b/c"}, changes.added)
+}
+
+func TestWriteSDK(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"index.ts":       "index",
+		"types/input.ts": "old inputs",
+		"old/removed.ts": "removed",
+	})
+	// Unchanged files must not be rewritten.
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "index.ts"), past, past))
+
+	files := map[string][]byte{
+		"index.ts":        []byte("index"),
+		"types/input.ts":  []byte("new inputs"),
+		"types/output.ts": []byte("outputs"),
+	}
+	changes, err := diffSDK(dir, files)
+	require.NoError(t, err)
+	require.NoError(t, writeSDK(dir, files, changes))
+
+	for name, contents := range files {
+		actual, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		require.NoError(t, err)
+		assert.Equal(t, string(contents), string(actual))
+	}
+	info, err := os.Stat(filepath.Join(dir, "index.ts"))
+	require.NoError(t, err)
+	assert.True(t, past.Equal(info.ModTime()))
+	assert.NoDirExists(t, filepath.Join(dir, "old"))
+
+	changes, err = diffSDK(dir, files)
+	require.NoError(t, err)
+	assert.True(t, changes.empty())
+	assert.Equal(t, "up to date (3 files)", changes.String())
+}
+
+func TestGenSDKCheck(t *testing.T) {
+	t.Parallel()
+
+	pkg, err := schema.ImportSpec(schema.PackageSpec{
+		Name:    "test",
+		Version: "1.0.0",
+		Resources: map[string]schema.ResourceSpec{
+			"test:index:Widget": {
+				InputProperties: map[string]schema.PropertySpec{
+					"size": {TypeSpec: schema.TypeSpec{Type: "integer"}},
+				},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	out := t.TempDir()
+	var summary bytes.Buffer
+
+	// Checking an SDK that has not been generated reports every file as added, and writes nothing.
+	changes, err := genSDK(&summary, "jsonschema", out, pkg, "", true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, changes.added)
+	assert.NoDirExists(t, filepath.Join(out, "jsonschema"))
+	assert.Contains(t, summary.String(), "    + "+jsonschema.SchemaFile+"\n")
+
+	changes, err = genSDK(&summary, "jsonschema", out, pkg, "", false)
+	require.NoError(t, err)
+	assert.NotEmpty(t, changes.added)
+	assert.FileExists(t, filepath.Join(out, "jsonschema", jsonschema.SchemaFile))
+
+	summary.Reset()
+	changes, err = genSDK(&summary, "jsonschema", out, pkg, "", true)
+	require.NoError(t, err)
+	assert.True(t, changes.empty())
+	assert.Regexp(t, `jsonschema: up to date \(\d+ files\)\n$`, summary.String())
+
+	// An orphaned file makes the SDK out of date, and is deleted when the SDK is generated again.
+	writeFiles(t, filepath.Join(out, "jsonschema"), map[string]string{"old.json": "{}"})
+	changes, err = genSDK(&summary, "jsonschema", out, pkg, "", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old.json"}, changes.deleted)
+
+	summary.Reset()
+	_, err = genSDK(&summary, "jsonschema", out, pkg, "", false)
+	require.NoError(t, err)
+	assert.Contains(t, summary.String(), "0 added, 0 updated, 1 deleted")
+	assert.NoFileExists(t, filepath.Join(out, "jsonschema", "old.json"))
+}
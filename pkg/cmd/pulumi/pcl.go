@@ -0,0 +1,277 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/syntax"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+func newPclCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pcl",
+		Short: "Work with PCL programs",
+		Long: `Work with PCL programs
+
+PCL is the intermediate language that Pulumi uses to convert programs between languages. Subcommands of this
+command are useful to authors of converters and of PCL test programs.`,
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newPclFmtCmd())
+	cmd.AddCommand(newPclLintCmd())
+	return cmd
+}
+
+type pclFmtCmd struct {
+	stdout io.Writer
+
+	check bool
+}
+
+func newPclFmtCmd() *cobra.Command {
+	var fmtcmd pclFmtCmd
+	cmd := &cobra.Command{
+		Use:   "fmt [<path>...]",
+		Short: "Format PCL files",
+		Long: "Format PCL files.\n" +
+			"\n" +
+			"Rewrites the given .pp files, and the .pp files in the given directories, in the\n" +
+			"canonical format: one tab per level of indentation, single spaces between tokens,\n" +
+			"and no more than one blank line in a row. Formats the current directory if no\n" +
+			"paths are given.\n" +
+			"\n" +
+			"Use --check to list the files that are not formatted without rewriting them.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			return fmtcmd.Run(args)
+		}),
+	}
+
+	cmd.Flags().BoolVar(&fmtcmd.check, "check", false,
+		"List the files that are not formatted, and fail if there are any, instead of rewriting them")
+	return cmd
+}
+
+func (cmd *pclFmtCmd) Run(paths []string) error {
+	if cmd.stdout == nil {
+		cmd.stdout = os.Stdout
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := pclFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	var diagnostics hcl.Diagnostics
+	sources := map[string][]byte{}
+	var unformatted []string
+	for _, path := range files {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sources[path] = src
+
+		formatted, diags := syntax.Format(src, path)
+		if diags.HasErrors() {
+			diagnostics = append(diagnostics, diags...)
+			continue
+		}
+		if bytes.Equal(src, formatted) {
+			continue
+		}
+
+		unformatted = append(unformatted, path)
+		if cmd.check {
+			fmt.Fprintln(cmd.stdout, path)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, formatted, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	if len(diagnostics) != 0 {
+		fileMap := map[string]*hcl.File{}
+		for path, src := range sources {
+			fileMap[path] = &hcl.File{Bytes: src}
+		}
+		diagWriter := hcl.NewDiagnosticTextWriter(os.Stderr, fileMap, 0, true)
+		contract.IgnoreError(diagWriter.WriteDiagnostics(diagnostics))
+		return fmt.Errorf("could not format %d file(s) with errors", countPclFiles(diagnostics))
+	}
+	if cmd.check && len(unformatted) != 0 {
+		return fmt.Errorf("%d file(s) are not formatted", len(unformatted))
+	}
+	return nil
+}
+
+type pclLintCmd struct {
+	stdout io.Writer
+
+	color  bool
+	loader schema.ReferenceLoader
+}
+
+func newPclLintCmd() *cobra.Command {
+	var lintcmd pclLintCmd
+	return &cobra.Command{
+		Use:   "lint [<directory>]",
+		Args:  cmdutil.MaximumNArgs(1),
+		Short: "Check a PCL program for errors",
+		Long: "Check a PCL program for errors.\n" +
+			"\n" +
+			"Binds the PCL program in the given directory, or in the current directory, against\n" +
+			"the schemas of the packages it uses, and reports unknown resources, functions and\n" +
+			"properties, type mismatches, config and local variables that are never used, and\n" +
+			"outputs that depend on resources that are never created. The command fails if any\n" +
+			"problems are found.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			pCtx, err := newPluginContext(cwd)
+			if err != nil {
+				return fmt.Errorf("create plugin host: %w", err)
+			}
+			defer contract.IgnoreClose(pCtx.Host)
+
+			lintcmd.color = cmdutil.GetGlobalColorization() != colors.Never
+			lintcmd.loader = schema.NewPluginLoader(pCtx.Host)
+			return lintcmd.Run(dir)
+		}),
+	}
+}
+
+func (cmd *pclLintCmd) Run(dir string) error {
+	if cmd.stdout == nil {
+		cmd.stdout = os.Stdout
+	}
+
+	files, err := pclFiles([]string{dir})
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no .pp files found in %s", dir)
+	}
+
+	parser := syntax.NewParser()
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		err = parser.ParseFile(f, filepath.Base(path))
+		contract.IgnoreClose(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	diagnostics := parser.Diagnostics
+	if !diagnostics.HasErrors() {
+		program, bindDiags, err := pcl.BindProgram(parser.Files,
+			pcl.Loader(cmd.loader),
+			pcl.DirPath(dir),
+			pcl.ComponentBinder(pcl.ComponentProgramBinderFromFileSystem()))
+		diagnostics = append(diagnostics, bindDiags...)
+		if err != nil && len(bindDiags) == 0 {
+			return err
+		}
+		if program != nil {
+			diagnostics = append(diagnostics, pcl.Lint(program)...)
+		}
+	}
+
+	if len(diagnostics) == 0 {
+		fmt.Fprintln(cmd.stdout, "No problems found.")
+		return nil
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		si, sj := diagnostics[i].Subject, diagnostics[j].Subject
+		if si == nil || sj == nil {
+			return si != nil
+		}
+		if si.Filename != sj.Filename {
+			return si.Filename < sj.Filename
+		}
+		return si.Start.Byte < sj.Start.Byte
+	})
+	diagWriter := parser.NewDiagnosticWriter(cmd.stdout, 0, cmd.color)
+	contract.IgnoreError(diagWriter.WriteDiagnostics(diagnostics))
+	return fmt.Errorf("found %d problem(s)", len(diagnostics))
+}
+
+// pclFiles returns the given .pp files and the .pp files in the given directories, sorted by path.
+func pclFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(path, "*.pp"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func countPclFiles(diagnostics hcl.Diagnostics) int {
+	files := map[string]bool{}
+	for _, d := range diagnostics {
+		if d.Subject != nil {
+			files[d.Subject.Filename] = true
+		}
+	}
+	return len(files)
+}
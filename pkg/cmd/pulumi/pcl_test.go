@@ -0,0 +1,91 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+func TestPclFmt(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	formatted := filepath.Join(dir, "formatted.pp")
+	unformatted := filepath.Join(dir, "unformatted.pp")
+	require.NoError(t, os.WriteFile(formatted, []byte("config name string {}\n"), 0o600))
+	require.NoError(t, os.WriteFile(unformatted, []byte("output   greeting{\n  value=\"hello\"\n}"), 0o600))
+
+	var stdout bytes.Buffer
+	check := pclFmtCmd{stdout: &stdout, check: true}
+	err := check.Run([]string{dir})
+	assert.EqualError(t, err, "1 file(s) are not formatted")
+	assert.Equal(t, unformatted+"\n", stdout.String())
+
+	stdout.Reset()
+	fmtcmd := pclFmtCmd{stdout: &stdout}
+	require.NoError(t, fmtcmd.Run([]string{dir}))
+	src, err := os.ReadFile(unformatted)
+	require.NoError(t, err)
+	assert.Equal(t, "output greeting {\n\tvalue = \"hello\"\n}\n", string(src))
+
+	require.NoError(t, check.Run([]string{dir}))
+	assert.Empty(t, stdout.String())
+}
+
+func TestPclFmtInvalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "main.pp")
+	require.NoError(t, os.WriteFile(path, []byte("output greeting {"), 0o600))
+
+	fmtcmd := pclFmtCmd{stdout: &bytes.Buffer{}}
+	assert.EqualError(t, fmtcmd.Run([]string{path}), "could not format 1 file(s) with errors")
+}
+
+func TestPclLint(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.pp"), []byte(`config name string {}
+config unused string {}
+
+output greeting {
+	value = "hello ${name}"
+}
+`), 0o600))
+
+	var stdout bytes.Buffer
+	lint := pclLintCmd{stdout: &stdout, loader: schema.NewPluginLoader(nil)}
+	assert.EqualError(t, lint.Run(dir), "found 1 problem(s)")
+	assert.Contains(t, stdout.String(), `config variable "unused" is never used`)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.pp"), []byte(`config name string {}
+
+output greeting {
+	value = "hello ${name}"
+}
+`), 0o600))
+	stdout.Reset()
+	require.NoError(t, lint.Run(dir))
+	assert.Equal(t, "No problems found.\n", stdout.String())
+}
@@ -0,0 +1,97 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/filestate"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+const policyGroupStackHelp = "The stack may be a stack name, which refers to the stack of the current project unless " +
+	"it is fully qualified, or a pattern such as `organization/*/prod` that is matched against the fully qualified " +
+	"names of stacks.\n" +
+	"\n" +
+	"This is only supported by self-managed backends; policy groups of the Pulumi Cloud are managed in the " +
+	"Pulumi Cloud console."
+
+func newPolicyGroupAddStackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-stack <group-name> <stack>",
+		Args:  cmdutil.ExactArgs(2),
+		Short: "Apply a Policy Group to a stack",
+		Long: "Apply a Policy Group to a stack.\n" +
+			"\n" +
+			"The Policy Packs enabled in the group are enforced on every update of the stack. The group is " +
+			"created if it does not exist. " + policyGroupStackHelp,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, cliArgs []string) error {
+			ctx := commandContext()
+			b, err := currentFilestateBackend(ctx)
+			if err != nil {
+				return err
+			}
+			return b.AddStackToPolicyGroup(ctx, cliArgs[0], cliArgs[1])
+		}),
+	}
+	return cmd
+}
+
+func newPolicyGroupRemoveStackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove-stack <group-name> <stack>",
+		Args:  cmdutil.ExactArgs(2),
+		Short: "Stop applying a Policy Group to a stack",
+		Long: "Stop applying a Policy Group to a stack.\n" +
+			"\n" +
+			"The stack must be given the same way it was given to `pulumi policy group add-stack`. " +
+			policyGroupStackHelp,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, cliArgs []string) error {
+			ctx := commandContext()
+			b, err := currentFilestateBackend(ctx)
+			if err != nil {
+				return err
+			}
+			return b.RemoveStackFromPolicyGroup(ctx, cliArgs[0], cliArgs[1])
+		}),
+	}
+	return cmd
+}
+
+// currentFilestateBackend returns the current backend, or an error if it is not a self-managed backend.
+func currentFilestateBackend(ctx context.Context) (filestate.Backend, error) {
+	// Try to read the current project, so that unqualified stack names refer to its stacks.
+	project, _, err := readProject()
+	if err != nil && !errors.Is(err, workspace.ErrProjectNotFound) {
+		return nil, err
+	}
+
+	b, err := currentBackend(ctx, project, display.Options{Color: cmdutil.GetGlobalColorization()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current backend: %w", err)
+	}
+	lb, ok := b.(filestate.Backend)
+	if !ok {
+		return nil, errors.New("the stacks of a Policy Group can only be changed in self-managed backends; " +
+			"use the Pulumi Cloud console to manage the Policy Groups of an organization")
+	}
+	return lb, nil
+}
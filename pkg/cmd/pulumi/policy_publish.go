@@ -23,6 +23,7 @@ import (
 
 	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/filestate"
 	"github.com/pulumi/pulumi/pkg/v3/engine"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
@@ -37,6 +38,8 @@ func newPolicyPublishCmd() *cobra.Command {
 		Args:  cmdutil.MaximumNArgs(1),
 		Short: "Publish a Policy Pack to the Pulumi Cloud",
 		Long: "Publish a Policy Pack to the Pulumi Cloud\n" +
+			"\n" +
+			"When logged into a self-managed backend, the Policy Pack is stored alongside the state instead.\n" +
 			"\n" +
 			"If an organization name is not specified, the default org (if set) or the current user account is used.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
@@ -156,14 +159,20 @@ func requirePolicyPack(
 
 	cloudURL, err := workspace.GetCurrentCloudURL(project)
 	if err != nil {
-		return nil, fmt.Errorf("`pulumi policy` command requires the user to be logged into the Pulumi Cloud: %w", err)
+		return nil, fmt.Errorf("`pulumi policy` command requires the user to be logged into a backend: %w", err)
 	}
 
 	displayOptions := display.Options{
 		Color: cmdutil.GetGlobalColorization(),
 	}
 
-	b, err := loginToCloud(ctx, cloudURL, project, workspace.GetCloudInsecure(cloudURL), displayOptions)
+	// Self-managed backends store policy packs alongside the state, so there is no need to log into the cloud.
+	var b backend.Backend
+	if filestate.IsFileStateBackendURL(cloudURL) {
+		b, err = filestate.New(ctx, cmdutil.Diag(), cloudURL, project)
+	} else {
+		b, err = loginToCloud(ctx, cloudURL, project, workspace.GetCloudInsecure(cloudURL), displayOptions)
+	}
 	if err != nil {
 		return nil, err
 	}
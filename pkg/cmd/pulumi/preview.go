@@ -55,6 +55,7 @@ func newPreviewCmd() *cobra.Command {
 	var policyPackPaths []string
 	var policyPackConfigPaths []string
 	var diffDisplay bool
+	var displayFlag string
 	var eventLogPath string
 	var parallel int
 	var refresh string
@@ -94,9 +95,9 @@ func newPreviewCmd() *cobra.Command {
 		Args: cmdArgs,
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
 			ctx := commandContext()
-			displayType := display.DisplayProgress
-			if diffDisplay {
-				displayType = display.DisplayDiff
+			displayType, err := displayTypeFromFlags(displayFlag, diffDisplay, jsonDisplay)
+			if err != nil {
+				return result.FromError(err)
 			}
 
 			displayOpts := display.Options{
@@ -338,6 +339,9 @@ func newPreviewCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&diffDisplay, "diff", false,
 		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().StringVar(
+		&displayFlag, "display", "",
+		"Display the operation as progress, diff or markdown (a summary for pull request comments)")
 	cmd.Flags().BoolVarP(
 		&jsonDisplay, "json", "j", false,
 		"Serialize the preview diffs, operations, and overall output as JSON")
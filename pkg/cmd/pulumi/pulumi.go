@@ -297,6 +297,7 @@ func NewPulumiCmd() *cobra.Command {
 				newImportCmd(),
 				newRefreshCmd(),
 				newStateCmd(),
+				newQueryCmd(),
 				newInstallCmd(),
 			},
 		},
@@ -336,6 +337,7 @@ func NewPulumiCmd() *cobra.Command {
 				newPluginCmd(),
 				newSchemaCmd(),
 				newPackageCmd(),
+				newPclCmd(),
 			},
 		},
 		{
@@ -360,7 +362,6 @@ func NewPulumiCmd() *cobra.Command {
 		{
 			Name: "Experimental Commands",
 			Commands: []*cobra.Command{
-				newQueryCmd(),
 				newConvertCmd(),
 				newWatchCmd(),
 				newLogsCmd(),
@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"errors"
 
 	"github.com/spf13/cobra"
 
@@ -31,24 +32,43 @@ import (
 //
 //nolint:vetshadow
 func newQueryCmd() *cobra.Command {
-	var stackName string
+	var qscmd queryStateCmd
 
 	cmd := &cobra.Command{
-		Use:   "query",
-		Short: "Run query program against cloud resources",
-		Long: "[EXPERIMENTAL] Run query program against cloud resources.\n" +
+		Use:   "query [expression]",
+		Short: "Query the resources in stacks' state, or run a query program",
+		Long: "Query the resources in stacks' state, or run a query program.\n" +
 			"\n" +
-			"This command loads a Pulumi query program and executes it. In \"query mode\", Pulumi provides various\n" +
-			"useful data sources for querying, such as the resource outputs for a stack. Query mode also disallows\n" +
-			"all resource operations, so users cannot declare resource definitions as they would in normal Pulumi\n" +
-			"programs.\n" +
+			"Given an expression, this command evaluates it as a JMESPath query (https://jmespath.org) over the\n" +
+			"resources in the checkpoints of one or more stacks, without running any program. The query runs over\n" +
+			"a list of resources, each an object with the properties `stack`, `urn`, `type`, `name`, `id`,\n" +
+			"`custom`, `delete`, `protect`, `external`, `parent`, `provider`, `dependencies`, `inputs` and\n" +
+			"`outputs`. For example, to list the S3 buckets in every stack:\n" +
 			"\n" +
-			"The program to run is loaded from the project in the current directory by default. Use the `-C` or\n" +
-			"`--cwd` flag to use a different directory.",
-		Args:   cmdutil.NoArgs,
-		Hidden: !hasExperimentalCommands() && !hasDebugCommands(),
+			"    pulumi query --all-stacks \"[?type=='aws:s3/bucket:Bucket'].{stack: stack, urn: urn}\"\n" +
+			"\n" +
+			"The current stack is queried by default; use `--stack` one or more times to choose the stacks, or\n" +
+			"`--all-stacks` to query every stack in the backend. Secret values are shown as `[secret]` unless\n" +
+			"`--show-secrets` is passed. Results are shown as a table by default; use `--output json` or\n" +
+			"`--output csv` for other formats.\n" +
+			"\n" +
+			"[EXPERIMENTAL] Without an expression, this command loads a Pulumi query program and executes it. In\n" +
+			"\"query mode\", Pulumi provides various useful data sources for querying, such as the resource outputs\n" +
+			"for a stack. Query mode also disallows all resource operations, so users cannot declare resource\n" +
+			"definitions as they would in normal Pulumi programs. The program to run is loaded from the project in\n" +
+			"the current directory by default. Use the `-C` or `--cwd` flag to use a different directory.",
+		Args: cmdutil.MaximumNArgs(1),
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
 			ctx := commandContext()
+			if len(args) == 1 {
+				qscmd.expression = args[0]
+				return result.FromError(qscmd.Run(ctx))
+			}
+			if !hasExperimentalCommands() && !hasDebugCommands() {
+				return result.FromError(errors.New("an expression is required; " +
+					"running query programs is only available with PULUMI_EXPERIMENTAL=true"))
+			}
+
 			interactive := cmdutil.Interactive()
 
 			opts := backend.UpdateOptions{}
@@ -91,9 +111,21 @@ func newQueryCmd() *cobra.Command {
 		}),
 	}
 
+	cmd.PersistentFlags().StringArrayVarP(
+		&qscmd.stacks, "stack", "s", nil,
+		"The name of a stack to query. May be repeated. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&qscmd.allStacks, "all-stacks", false,
+		"Query every stack in the backend")
+	cmd.PersistentFlags().StringVar(
+		&qscmd.project, "project", "",
+		"With --all-stacks, only query the stacks of the given project")
 	cmd.PersistentFlags().StringVarP(
-		&stackName, "stack", "s", "",
-		"The name of the stack to operate on. Defaults to the current stack")
+		&qscmd.output, "output", "o", "table",
+		"The output format: table, json or csv")
+	cmd.PersistentFlags().BoolVar(
+		&qscmd.showSecrets, "show-secrets", false,
+		"Decrypt and show secret values instead of [secret]")
 
 	return cmd
 }
@@ -0,0 +1,356 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// queryStateCmd evaluates a JMESPath expression over the resources in the checkpoints of one or more stacks.
+type queryStateCmd struct {
+	stdout io.Writer
+
+	expression  string
+	stacks      []string
+	allStacks   bool
+	project     string
+	output      string
+	showSecrets bool
+}
+
+func (cmd *queryStateCmd) Run(ctx context.Context) error {
+	stdout := cmd.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
+	switch cmd.output {
+	case "", "table", "json", "csv":
+	default:
+		return fmt.Errorf("unknown output format %q; expected table, json or csv", cmd.output)
+	}
+	if cmd.allStacks && len(cmd.stacks) > 0 {
+		return errors.New("--all-stacks cannot be used with --stack")
+	}
+
+	// Compile the expression first so that a typo is reported before any checkpoint is downloaded.
+	jp, err := jmespath.Compile(cmd.expression)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	stacks, err := cmd.selectStacks(ctx)
+	if err != nil {
+		return err
+	}
+
+	resources := []interface{}{}
+	for _, s := range stacks {
+		stackResources, err := queryStackResources(ctx, s, cmd.showSecrets)
+		if err != nil {
+			return fmt.Errorf("reading the checkpoint of stack %s: %w", s.Ref(), err)
+		}
+		resources = append(resources, stackResources...)
+	}
+
+	result, err := jp.Search(resources)
+	if err != nil {
+		return fmt.Errorf("evaluating query: %w", err)
+	}
+
+	switch cmd.output {
+	case "json":
+		return fprintJSON(stdout, result)
+	case "csv":
+		headers, rows := queryResultRows(result)
+		w := csv.NewWriter(stdout)
+		if err := w.Write(headers); err != nil {
+			return err
+		}
+		if err := w.WriteAll(rows); err != nil {
+			return err
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		headers, rows := queryResultRows(result)
+		if len(rows) == 0 {
+			fmt.Fprintln(stdout, "No results")
+			return nil
+		}
+		table := cmdutil.Table{Headers: make([]string, len(headers))}
+		for i, h := range headers {
+			table.Headers[i] = strings.ToUpper(h)
+		}
+		for _, row := range rows {
+			table.Rows = append(table.Rows, cmdutil.TableRow{Columns: row})
+		}
+		fprintTable(stdout, table, nil)
+		return nil
+	}
+}
+
+// selectStacks returns the stacks to query: the stacks passed with --stack, every stack in the backend if
+// --all-stacks was passed, or the current stack.
+func (cmd *queryStateCmd) selectStacks(ctx context.Context) ([]backend.Stack, error) {
+	opts := display.Options{
+		Color: cmdutil.GetGlobalColorization(),
+	}
+
+	if !cmd.allStacks {
+		names := cmd.stacks
+		if len(names) == 0 {
+			names = []string{""}
+		}
+		stacks := make([]backend.Stack, 0, len(names))
+		for _, name := range names {
+			s, err := requireStack(ctx, name, stackLoadOnly, opts)
+			if err != nil {
+				return nil, err
+			}
+			stacks = append(stacks, s)
+		}
+		return stacks, nil
+	}
+
+	project, _, err := readProject()
+	if err != nil && !errors.Is(err, workspace.ErrProjectNotFound) {
+		return nil, err
+	}
+	b, err := currentBackend(ctx, project, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter backend.ListStacksFilter
+	if cmd.project != "" {
+		filter.Project = &cmd.project
+	}
+	var summaries []backend.StackSummary
+	var inContToken backend.ContinuationToken
+	for {
+		page, outContToken, err := b.ListStacks(ctx, filter, inContToken)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, page...)
+		if outContToken == nil {
+			break
+		}
+		inContToken = outContToken
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name().String() < summaries[j].Name().String()
+	})
+
+	stacks := make([]backend.Stack, 0, len(summaries))
+	for _, summary := range summaries {
+		s, err := b.GetStack(ctx, summary.Name())
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			stacks = append(stacks, s)
+		}
+	}
+	return stacks, nil
+}
+
+// queryStackResources exports the checkpoint of a stack and returns its resources as JSON-like values for a query.
+// Secrets are replaced with "[secret]" unless showSecrets is true, in which case they are decrypted.
+func queryStackResources(ctx context.Context, s backend.Stack, showSecrets bool) ([]interface{}, error) {
+	deployment, err := s.ExportDeployment(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var states []*resource.State
+	if showSecrets {
+		snap, err := stack.DeserializeUntypedDeployment(ctx, deployment, stack.DefaultSecretsProvider)
+		if err != nil {
+			return nil, checkDeploymentVersionError(err, s.Ref().Name().String())
+		}
+		states = snap.Resources
+	} else {
+		// Deserialize the resources directly so that secrets are blinded rather than decrypted.
+		v3deployment, err := stack.UnmarshalUntypedDeployment(ctx, deployment)
+		if err != nil {
+			return nil, checkDeploymentVersionError(err, s.Ref().Name().String())
+		}
+		for _, res := range v3deployment.Resources {
+			state, err := stack.DeserializeResource(res, queryBlindingDecrypter{}, config.BlindingCrypter)
+			if err != nil {
+				return nil, err
+			}
+			states = append(states, state)
+		}
+	}
+
+	resources := make([]interface{}, len(states))
+	for i, state := range states {
+		resources[i] = queryResource(s.Ref().String(), state, showSecrets)
+	}
+	return resources, nil
+}
+
+// queryBlindingDecrypter reads a checkpoint without access to the stack's secrets. Checkpoint secrets are encrypted
+// JSON values, so every ciphertext decrypts to `null`; queryProperties shows the resulting secrets as "[secret]".
+type queryBlindingDecrypter struct{}
+
+func (queryBlindingDecrypter) DecryptValue(ctx context.Context, _ string) (string, error) {
+	return "null", nil
+}
+
+func (d queryBlindingDecrypter) BulkDecrypt(ctx context.Context, ciphertexts []string) (map[string]string, error) {
+	return config.DefaultBulkDecrypt(ctx, d, ciphertexts)
+}
+
+// queryResource returns the JSON-like value that represents a resource in a query.
+func queryResource(stackName string, state *resource.State, showSecrets bool) map[string]interface{} {
+	dependencies := make([]interface{}, len(state.Dependencies))
+	for i, dep := range state.Dependencies {
+		dependencies[i] = string(dep)
+	}
+	return map[string]interface{}{
+		"stack":        stackName,
+		"urn":          string(state.URN),
+		"type":         string(state.Type),
+		"name":         state.URN.Name(),
+		"id":           string(state.ID),
+		"custom":       state.Custom,
+		"delete":       state.Delete,
+		"protect":      state.Protect,
+		"external":     state.External,
+		"parent":       string(state.Parent),
+		"provider":     state.Provider,
+		"dependencies": dependencies,
+		"inputs":       queryProperties(state.Inputs, showSecrets),
+		"outputs":      queryProperties(state.Outputs, showSecrets),
+	}
+}
+
+// queryProperties converts a property map to plain JSON-like values.
+func queryProperties(props resource.PropertyMap, showSecrets bool) map[string]interface{} {
+	var replv func(v resource.PropertyValue) (interface{}, bool)
+	replv = func(v resource.PropertyValue) (interface{}, bool) {
+		switch {
+		case v.IsSecret():
+			if !showSecrets {
+				return "[secret]", true
+			}
+			return v.SecretValue().Element.MapRepl(nil, replv), true
+		case v.IsComputed(), v.IsOutput():
+			return nil, true
+		case v.IsAsset():
+			return v.AssetValue().Serialize(), true
+		case v.IsArchive():
+			return v.ArchiveValue().Serialize(), true
+		case v.IsResourceReference():
+			return string(v.ResourceReferenceValue().URN), true
+		}
+		return nil, false
+	}
+	return props.MapRepl(nil, replv)
+}
+
+// queryResultRows flattens the result of a query into rows. A list of objects has one column per property, any other
+// list has a single column, and any other value is a single row.
+func queryResultRows(result interface{}) ([]string, [][]string) {
+	var items []interface{}
+	switch result := result.(type) {
+	case nil:
+		return []string{"value"}, nil
+	case []interface{}:
+		items = result
+	default:
+		items = []interface{}{result}
+	}
+
+	columns := map[string]bool{}
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			columns = nil
+			break
+		}
+		for k := range obj {
+			columns[k] = true
+		}
+	}
+
+	if columns == nil {
+		rows := make([][]string, len(items))
+		for i, item := range items {
+			rows[i] = []string{queryCellText(item)}
+		}
+		return []string{"value"}, rows
+	}
+
+	headers := make([]string, 0, len(columns))
+	for k := range columns {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		obj := item.(map[string]interface{})
+		row := make([]string, len(headers))
+		for j, h := range headers {
+			row[j] = queryCellText(obj[h])
+		}
+		rows[i] = row
+	}
+	return headers, rows
+}
+
+// queryCellText renders a value for a table or CSV cell. Strings and scalars are shown as is, and lists and objects
+// as compact JSON.
+func queryCellText(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(b)
+	}
+}
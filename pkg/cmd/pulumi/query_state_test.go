@@ -0,0 +1,182 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/b64"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+// newQueryTestBackend sets up a backend with a single stack whose checkpoint contains two buckets, one of them with
+// a secret output.
+func newQueryTestBackend(t *testing.T) {
+	sm := b64.NewBase64SecretsManager()
+	snapshot := &deploy.Snapshot{
+		SecretsManager: sm,
+		Resources: []*resource.State{
+			{
+				URN:    "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs",
+				Type:   "aws:s3/bucket:Bucket",
+				Custom: true,
+				ID:     "logs-1234",
+				Outputs: resource.PropertyMap{
+					"acl":     resource.NewStringProperty("private"),
+					"website": resource.MakeSecret(resource.NewStringProperty("logs.example.com")),
+				},
+			},
+			{
+				URN:    "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::site",
+				Type:   "aws:s3/bucket:Bucket",
+				Custom: true,
+				ID:     "site-5678",
+				Outputs: resource.PropertyMap{
+					"acl": resource.NewStringProperty("public-read"),
+				},
+			},
+			{
+				URN:    "urn:pulumi:dev::proj::aws:iam/role:Role::role",
+				Type:   "aws:iam/role:Role",
+				Custom: true,
+				ID:     "role",
+			},
+		},
+	}
+
+	mockStack := &backend.MockStack{
+		RefF: func() backend.StackReference {
+			return &backend.MockStackReference{
+				StringV: "dev",
+				NameV:   tokens.MustParseStackName("dev"),
+			}
+		},
+		ExportDeploymentF: func(ctx context.Context) (*apitype.UntypedDeployment, error) {
+			deployment, err := stack.SerializeDeployment(snapshot, sm, false)
+			if err != nil {
+				return nil, err
+			}
+			bytes, err := json.Marshal(deployment)
+			if err != nil {
+				return nil, err
+			}
+			return &apitype.UntypedDeployment{Version: 3, Deployment: bytes}, nil
+		},
+	}
+	backendInstance = &backend.MockBackend{
+		GetStackF: func(ctx context.Context, stackRef backend.StackReference) (backend.Stack, error) {
+			return mockStack, nil
+		},
+	}
+	t.Cleanup(func() { backendInstance = nil })
+}
+
+//nolint:paralleltest // mutates global state
+func TestQueryState_JSON(t *testing.T) {
+	newQueryTestBackend(t)
+
+	var stdout bytes.Buffer
+	cmd := queryStateCmd{
+		stdout:     &stdout,
+		stacks:     []string{"dev"},
+		expression: "[?type=='aws:s3/bucket:Bucket'].{name: name, acl: outputs.acl, website: outputs.website}",
+		output:     "json",
+	}
+	require.NoError(t, cmd.Run(context.Background()))
+	assert.JSONEq(t, `[
+		{"name": "logs", "acl": "private", "website": "[secret]"},
+		{"name": "site", "acl": "public-read", "website": null}
+	]`, stdout.String())
+
+	stdout.Reset()
+	cmd.showSecrets = true
+	cmd.expression = "[?name=='logs'].outputs.website | [0]"
+	require.NoError(t, cmd.Run(context.Background()))
+	assert.JSONEq(t, `"logs.example.com"`, stdout.String())
+}
+
+//nolint:paralleltest // mutates global state
+func TestQueryState_CSV(t *testing.T) {
+	newQueryTestBackend(t)
+
+	var stdout bytes.Buffer
+	cmd := queryStateCmd{
+		stdout:     &stdout,
+		stacks:     []string{"dev"},
+		expression: "[?outputs.acl].{name: name, acl: outputs.acl}",
+		output:     "csv",
+	}
+	require.NoError(t, cmd.Run(context.Background()))
+	assert.Equal(t, "acl,name\nprivate,logs\npublic-read,site\n", stdout.String())
+}
+
+//nolint:paralleltest // mutates global state
+func TestQueryState_Table(t *testing.T) {
+	newQueryTestBackend(t)
+
+	var stdout bytes.Buffer
+	cmd := queryStateCmd{
+		stdout:     &stdout,
+		stacks:     []string{"dev"},
+		expression: "[].id",
+	}
+	require.NoError(t, cmd.Run(context.Background()))
+	assert.Contains(t, stdout.String(), "VALUE")
+	assert.Contains(t, stdout.String(), "site-5678")
+
+	stdout.Reset()
+	cmd.expression = "[?type=='aws:ec2/instance:Instance']"
+	require.NoError(t, cmd.Run(context.Background()))
+	assert.Equal(t, "No results\n", stdout.String())
+}
+
+func TestQueryState_Errors(t *testing.T) {
+	t.Parallel()
+
+	cmd := queryStateCmd{expression: "[?", output: "table"}
+	assert.ErrorContains(t, cmd.Run(context.Background()), "invalid query")
+
+	cmd = queryStateCmd{expression: "[]", output: "yaml"}
+	assert.ErrorContains(t, cmd.Run(context.Background()), "unknown output format")
+
+	cmd = queryStateCmd{expression: "[]", allStacks: true, stacks: []string{"dev"}}
+	assert.ErrorContains(t, cmd.Run(context.Background()), "--all-stacks cannot be used with --stack")
+}
+
+func TestQueryResultRows(t *testing.T) {
+	t.Parallel()
+
+	headers, rows := queryResultRows([]interface{}{
+		map[string]interface{}{"a": "x", "b": true},
+		map[string]interface{}{"a": float64(2), "c": []interface{}{"y"}},
+	})
+	assert.Equal(t, []string{"a", "b", "c"}, headers)
+	assert.Equal(t, [][]string{{"x", "true", ""}, {"2", "", `["y"]`}}, rows)
+
+	headers, rows = queryResultRows("scalar")
+	assert.Equal(t, []string{"value"}, headers)
+	assert.Equal(t, [][]string{{"scalar"}}, rows)
+}
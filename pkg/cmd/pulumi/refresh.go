@@ -29,6 +29,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/pkg/v3/engine"
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/secretscan"
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
@@ -62,6 +63,9 @@ func newRefreshCmd() *cobra.Command {
 	var suppressPermalink string
 	var yes bool
 	var targets *[]string
+	var scanSecrets bool
+	var promoteSecrets bool
+	var secretScanConfigPath string
 
 	// Flags for handling pending creates
 	var skipPendingCreates bool
@@ -148,6 +152,14 @@ func newRefreshCmd() *cobra.Command {
 				return runDeployment(ctx, opts.Display, apitype.Refresh, stackName, args[0], remoteArgs)
 			}
 
+			var secretScanner *secretscan.Scanner
+			if scanSecrets || promoteSecrets {
+				secretScanner, err = newSecretScanner(secretScanConfigPath)
+				if err != nil {
+					return result.FromError(err)
+				}
+			}
+
 			filestateBackend, err := isFilestateBackend(opts.Display)
 			if err != nil {
 				return result.FromError(err)
@@ -262,6 +274,8 @@ func newRefreshCmd() *cobra.Command {
 				DisableOutputValues:       disableOutputValues(),
 				Targets:                   deploy.NewUrnTargets(targetUrns),
 				Experimental:              hasExperimentalCommands(),
+				SecretScanner:             secretScanner,
+				PromoteDetectedSecrets:    promoteSecrets,
 			}
 
 			changes, res := s.Refresh(ctx, backend.UpdateOperation{
@@ -339,6 +353,17 @@ func newRefreshCmd() *cobra.Command {
 		&yes, "yes", "y", false,
 		"Automatically approve and perform the refresh after previewing it")
 
+	cmd.PersistentFlags().BoolVar(
+		&scanSecrets, "scan-secrets", false,
+		"Warn about refreshed resource outputs that look like secrets but are not marked as secret")
+	cmd.PersistentFlags().BoolVar(
+		&promoteSecrets, "promote-secrets", false,
+		"Mark refreshed resource outputs that look like secrets as secret before they are saved. "+
+			"Implies --scan-secrets")
+	cmd.PersistentFlags().StringVar(
+		&secretScanConfigPath, "secret-scan-config", "",
+		"Path to a YAML or JSON file that configures the detectors used by --scan-secrets")
+
 	// Flags for pending creates
 	cmd.PersistentFlags().BoolVar(
 		&skipPendingCreates, "skip-pending-creates", false,
@@ -25,7 +25,9 @@ import (
 
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
@@ -46,6 +48,13 @@ func newReplayEventsCmd() *cobra.Command {
 	var delay time.Duration
 	var period time.Duration
 
+	var urns []string
+	var ops []string
+	var since string
+	var until string
+	var htmlReport string
+	var junitReport string
+
 	cmd := &cobra.Command{
 		Use:   "replay-events [kind] [events-file]",
 		Short: "Replay events from a prior update, refresh, or destroy",
@@ -55,7 +64,15 @@ func newReplayEventsCmd() *cobra.Command {
 			"invocation of the Pulumi CLI (e.g. `pulumi up --event-log [file]`).\n" +
 			"\n" +
 			"This command loads events from the indicated file and renders them\n" +
-			"using either the progress view or the diff view.\n",
+			"using either the progress view or the diff view.\n" +
+			"\n" +
+			"The events can be filtered by resource with `--urn`, which accepts the same globs as\n" +
+			"`--target`, by step operation with `--op`, and by time with `--since` and `--until`, which\n" +
+			"accept either an RFC 3339 timestamp or a duration from the first event (e.g. `90s`).\n" +
+			"\n" +
+			"Use `--html-report` to also write a static HTML report of the resource steps, and\n" +
+			"`--junit-report` to write a JUnit XML file with one test case per resource step, so that\n" +
+			"CI systems can show the results of a deployment.\n",
 		Args:   cmdutil.ExactArgs(2),
 		Hidden: !hasDebugCommands(),
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
@@ -95,7 +112,38 @@ func newReplayEventsCmd() *cobra.Command {
 				Debug:                debug,
 			}
 
-			events, err := loadEvents(args[1])
+			jsonEvents, err := loadJSONEvents(args[1])
+			if err != nil {
+				return fmt.Errorf("error reading events: %w", err)
+			}
+
+			filter, err := newReplayEventFilter(jsonEvents, urns, ops, since, until)
+			if err != nil {
+				return err
+			}
+			jsonEvents = filter.apply(jsonEvents)
+
+			if htmlReport != "" || junitReport != "" {
+				report := display.NewReport(jsonEvents)
+				if htmlReport != "" {
+					err := writeReplayReport(htmlReport, func(w io.Writer) error {
+						return report.RenderHTML(w, fmt.Sprintf("pulumi %s", args[0]))
+					})
+					if err != nil {
+						return err
+					}
+				}
+				if junitReport != "" {
+					err := writeReplayReport(junitReport, func(w io.Writer) error {
+						return report.RenderJUnit(w, fmt.Sprintf("pulumi %s", args[0]))
+					})
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			events, err := convertJSONEvents(jsonEvents)
 			if err != nil {
 				return fmt.Errorf("error reading events: %w", err)
 			}
@@ -156,17 +204,167 @@ func newReplayEventsCmd() *cobra.Command {
 	cmd.PersistentFlags().DurationVar(&period, "period", time.Duration(0),
 		"Delay each event by the given duration.")
 
+	cmd.PersistentFlags().StringArrayVar(&urns, "urn", nil,
+		"Only replay the events of resources whose URN matches the given URN or glob. May be repeated.")
+	cmd.PersistentFlags().StringArrayVar(&ops, "op", nil,
+		"Only replay the resource steps with the given operation (e.g. create, update, delete). May be repeated.")
+	cmd.PersistentFlags().StringVar(&since, "since", "",
+		"Only replay the events emitted at or after the given timestamp or duration from the first event")
+	cmd.PersistentFlags().StringVar(&until, "until", "",
+		"Only replay the events emitted at or before the given timestamp or duration from the first event")
+	cmd.PersistentFlags().StringVar(&htmlReport, "html-report", "",
+		"Write a static HTML report of the resource steps to the given file")
+	cmd.PersistentFlags().StringVar(&junitReport, "junit-report", "",
+		"Write a JUnit XML report with one test case per resource step to the given file")
+
 	return cmd
 }
 
+// writeReplayReport writes a report to the file at path using the given render function.
+func writeReplayReport(path string, render func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating report: %w", err)
+	}
+	if err := render(f); err != nil {
+		contract.IgnoreClose(f)
+		return fmt.Errorf("writing report '%v': %w", path, err)
+	}
+	return f.Close()
+}
+
+// replayEventFilter selects the events to replay.
+type replayEventFilter struct {
+	urns  deploy.UrnTargets
+	ops   map[apitype.OpType]bool
+	since time.Time
+	until time.Time
+}
+
+func newReplayEventFilter(
+	events []apitype.EngineEvent, urns, ops []string, since, until string,
+) (*replayEventFilter, error) {
+	f := &replayEventFilter{urns: deploy.NewUrnTargets(urns)}
+
+	if len(ops) > 0 {
+		known := map[apitype.OpType]bool{}
+		for _, op := range deploy.StepOps {
+			known[apitype.OpType(op)] = true
+		}
+		f.ops = map[apitype.OpType]bool{}
+		for _, op := range ops {
+			if !known[apitype.OpType(op)] {
+				return nil, fmt.Errorf("unrecognized operation '%v'", op)
+			}
+			f.ops[apitype.OpType(op)] = true
+		}
+	}
+
+	// Durations are relative to the first event with a timestamp.
+	var start time.Time
+	for _, e := range events {
+		if e.Timestamp != 0 {
+			start = time.Unix(int64(e.Timestamp), 0)
+			break
+		}
+	}
+	var err error
+	if f.since, err = parseReplayTime(since, start); err != nil {
+		return nil, fmt.Errorf("invalid --since: %w", err)
+	}
+	if f.until, err = parseReplayTime(until, start); err != nil {
+		return nil, fmt.Errorf("invalid --until: %w", err)
+	}
+
+	return f, nil
+}
+
+// parseReplayTime parses an RFC 3339 timestamp or a duration from start. An empty string is the zero time.
+func parseReplayTime(s string, start time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%v' is neither an RFC 3339 timestamp nor a duration", s)
+	}
+	return start.Add(d), nil
+}
+
+// apply returns the events that pass the filter. The prelude, summary and cancel events are always kept so that the
+// events can still be displayed. Events that do not belong to a resource are only filtered by time, and diagnostics
+// for a resource are only kept if a step of the resource is.
+func (f *replayEventFilter) apply(events []apitype.EngineEvent) []apitype.EngineEvent {
+	included := map[string]bool{}
+	var result []apitype.EngineEvent
+	for _, e := range events {
+		if e.PreludeEvent != nil || e.SummaryEvent != nil || e.CancelEvent != nil {
+			result = append(result, e)
+			continue
+		}
+
+		at := time.Unix(int64(e.Timestamp), 0)
+		if (!f.since.IsZero() && at.Before(f.since)) || (!f.until.IsZero() && at.After(f.until)) {
+			continue
+		}
+
+		var urn string
+		var op apitype.OpType
+		step := false
+		switch {
+		case e.ResourcePreEvent != nil:
+			urn, op, step = e.ResourcePreEvent.Metadata.URN, e.ResourcePreEvent.Metadata.Op, true
+		case e.ResOutputsEvent != nil:
+			urn, op, step = e.ResOutputsEvent.Metadata.URN, e.ResOutputsEvent.Metadata.Op, true
+		case e.ResOpFailedEvent != nil:
+			urn, op, step = e.ResOpFailedEvent.Metadata.URN, e.ResOpFailedEvent.Metadata.Op, true
+		case e.DiagnosticEvent != nil:
+			urn = e.DiagnosticEvent.URN
+		case e.PolicyEvent != nil:
+			urn = e.PolicyEvent.ResourceURN
+		case e.PolicyRemediationEvent != nil:
+			urn = e.PolicyRemediationEvent.ResourceURN
+		}
+
+		if urn != "" {
+			if !f.urns.Contains(resource.URN(urn)) {
+				continue
+			}
+			if step {
+				if f.ops != nil && !f.ops[op] {
+					continue
+				}
+				included[urn] = true
+			} else if f.ops != nil && !included[urn] {
+				continue
+			}
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// loadEvents reads the engine events in the event log at path.
 func loadEvents(path string) ([]engine.Event, error) {
+	jsonEvents, err := loadJSONEvents(path)
+	if err != nil {
+		return nil, err
+	}
+	return convertJSONEvents(jsonEvents)
+}
+
+// loadJSONEvents reads the serialized engine events in the event log at path.
+func loadJSONEvents(path string) ([]apitype.EngineEvent, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("opening '%v': %w", path, err)
 	}
 	defer contract.IgnoreClose(f)
 
-	var events []engine.Event
+	var events []apitype.EngineEvent
 	dec := json.NewDecoder(f)
 	for {
 		var jsonEvent apitype.EngineEvent
@@ -176,7 +374,15 @@ func loadEvents(path string) ([]engine.Event, error) {
 			}
 			return nil, fmt.Errorf("decoding event: %w", err)
 		}
+		events = append(events, jsonEvent)
+	}
+	return events, nil
+}
 
+// convertJSONEvents converts serialized engine events to engine events that can be displayed.
+func convertJSONEvents(jsonEvents []apitype.EngineEvent) ([]engine.Event, error) {
+	events := make([]engine.Event, 0, len(jsonEvents)+1)
+	for _, jsonEvent := range jsonEvents {
 		event, err := display.ConvertJSONEvent(jsonEvent)
 		if err != nil {
 			return nil, fmt.Errorf("decoding event: %w", err)
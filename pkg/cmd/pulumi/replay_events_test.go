@@ -0,0 +1,74 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+)
+
+func replayTestEvents() []apitype.EngineEvent {
+	step := func(urn string, op apitype.OpType) apitype.StepEventMetadata {
+		return apitype.StepEventMetadata{URN: urn, Op: op, Type: "test:index:Resource"}
+	}
+	const (
+		a = "urn:pulumi:dev::proj::test:index:Resource::a"
+		b = "urn:pulumi:dev::proj::test:index:Resource::b"
+	)
+	return []apitype.EngineEvent{
+		{Timestamp: 100, PreludeEvent: &apitype.PreludeEvent{}},
+		{Timestamp: 100, ResourcePreEvent: &apitype.ResourcePreEvent{Metadata: step(a, apitype.OpCreate)}},
+		{Timestamp: 110, ResOutputsEvent: &apitype.ResOutputsEvent{Metadata: step(a, apitype.OpCreate)}},
+		{Timestamp: 120, ResourcePreEvent: &apitype.ResourcePreEvent{Metadata: step(b, apitype.OpUpdate)}},
+		{Timestamp: 130, DiagnosticEvent: &apitype.DiagnosticEvent{URN: b, Severity: "error", Message: "boom"}},
+		{Timestamp: 130, ResOpFailedEvent: &apitype.ResOpFailedEvent{Metadata: step(b, apitype.OpUpdate)}},
+		{Timestamp: 140, DiagnosticEvent: &apitype.DiagnosticEvent{Severity: "error", Message: "update failed"}},
+		{Timestamp: 140, SummaryEvent: &apitype.SummaryEvent{}},
+	}
+}
+
+func TestReplayEventFilter(t *testing.T) {
+	t.Parallel()
+
+	events := replayTestEvents()
+
+	f, err := newReplayEventFilter(events, nil, nil, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, events, f.apply(events))
+
+	// Filtering by URN keeps the events that don't belong to a resource.
+	f, err = newReplayEventFilter(events, []string{"**::a"}, nil, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []apitype.EngineEvent{events[0], events[1], events[2], events[6], events[7]}, f.apply(events))
+
+	// Filtering by operation also drops the diagnostics of resources without a matching step.
+	f, err = newReplayEventFilter(events, nil, []string{"create"}, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []apitype.EngineEvent{events[0], events[1], events[2], events[6], events[7]}, f.apply(events))
+
+	f, err = newReplayEventFilter(events, nil, nil, "15s", "1970-01-01T00:02:10Z")
+	require.NoError(t, err)
+	assert.Equal(t, []apitype.EngineEvent{events[0], events[3], events[4], events[5], events[7]}, f.apply(events))
+
+	_, err = newReplayEventFilter(events, nil, []string{"frobnicate"}, "", "")
+	assert.ErrorContains(t, err, "unrecognized operation 'frobnicate'")
+
+	_, err = newReplayEventFilter(events, nil, nil, "yesterday", "")
+	assert.ErrorContains(t, err, "invalid --since")
+}
@@ -31,5 +31,7 @@ package schemas for errors.`,
 	}
 
 	cmd.AddCommand(newSchemaCheckCommand())
+	cmd.AddCommand(newSchemaDiffCommand())
+	cmd.AddCommand(newSchemaCacheCmd())
 	return cmd
 }
@@ -0,0 +1,252 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/blang/semver"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func newSchemaCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the schema cache",
+		Long: "Manage the schema cache.\n" +
+			"\n" +
+			"Pulumi caches the schemas of installed resource plugins, so that commands such as\n" +
+			"`pulumi convert`, `pulumi import --generate-code` and `pulumi package gen-sdk` do\n" +
+			"not need to run a plugin to get its schema. The cache is shared by all Pulumi\n" +
+			"processes, and a schema is loaded again once its plugin is reinstalled.\n" +
+			"\n" +
+			"Set PULUMI_DISABLE_SCHEMA_CACHE to disable the cache.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newSchemaCacheWarmCmd())
+	cmd.AddCommand(newSchemaCacheLsCmd())
+	cmd.AddCommand(newSchemaCacheClearCmd())
+	return cmd
+}
+
+type schemaCacheWarmCmd struct {
+	stdout io.Writer
+
+	cache *schema.SchemaCache
+	host  plugin.Host
+}
+
+func newSchemaCacheWarmCmd() *cobra.Command {
+	var warmcmd schemaCacheWarmCmd
+	return &cobra.Command{
+		Use:   "warm [<package>[@<version>]...]",
+		Short: "Load schemas into the schema cache",
+		Long: "Load schemas into the schema cache.\n" +
+			"\n" +
+			"Loads the schemas of the given resource plugins into the cache, installing the plugins\n" +
+			"if they are missing. Without arguments, loads the schemas of all installed resource\n" +
+			"plugins. Schemas that are already cached are not loaded again.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			cache, err := schema.DefaultSchemaCache()
+			if err != nil {
+				return err
+			}
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			pCtx, err := newPluginContext(cwd)
+			if err != nil {
+				return err
+			}
+			defer contract.IgnoreClose(pCtx)
+
+			warmcmd.cache, warmcmd.host = cache, pCtx.Host
+			return warmcmd.Run(args)
+		}),
+	}
+}
+
+func (cmd *schemaCacheWarmCmd) Run(args []string) error {
+	if cmd.stdout == nil {
+		cmd.stdout = os.Stdout
+	}
+
+	type pluginRef struct {
+		name    string
+		version *semver.Version
+	}
+	var plugins []pluginRef
+	if len(args) == 0 {
+		installed, err := workspace.GetPlugins()
+		if err != nil {
+			return fmt.Errorf("loading plugins: %w", err)
+		}
+		for _, p := range installed {
+			if p.Kind == workspace.ResourcePlugin && p.Version != nil {
+				plugins = append(plugins, pluginRef{p.Name, p.Version})
+			}
+		}
+	} else {
+		for _, arg := range args {
+			name, version, err := parsePluginSource(arg)
+			if err != nil {
+				return err
+			}
+			plugins = append(plugins, pluginRef{name, version})
+		}
+	}
+
+	var failed int
+	for _, p := range plugins {
+		entry, loaded, err := cmd.cache.Warm(cmd.host, p.name, p.version)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(cmd.stdout, "%s: %v\n", p.name, err)
+		case loaded:
+			fmt.Fprintf(cmd.stdout, "%s: cached (%s)\n", entry.SchemaCacheKey, humanize.Bytes(uint64(entry.Size)))
+		default:
+			fmt.Fprintf(cmd.stdout, "%s: already cached\n", entry.SchemaCacheKey)
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("failed to cache %d schema(s)", failed)
+	}
+	return nil
+}
+
+type schemaCacheLsCmd struct {
+	stdout io.Writer
+
+	jsonOut bool
+	cache   *schema.SchemaCache
+}
+
+func newSchemaCacheLsCmd() *cobra.Command {
+	var lscmd schemaCacheLsCmd
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List the schemas in the schema cache",
+		Args:  cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			cache, err := schema.DefaultSchemaCache()
+			if err != nil {
+				return err
+			}
+			lscmd.cache = cache
+			return lscmd.Run()
+		}),
+	}
+	cmd.Flags().BoolVarP(&lscmd.jsonOut, "json", "j", false, "Emit output as JSON")
+	return cmd
+}
+
+func (cmd *schemaCacheLsCmd) Run() error {
+	if cmd.stdout == nil {
+		cmd.stdout = os.Stdout
+	}
+
+	entries, err := cmd.cache.List()
+	if err != nil {
+		return err
+	}
+
+	if cmd.jsonOut {
+		if entries == nil {
+			entries = []schema.SchemaCacheEntry{}
+		}
+		return fprintJSON(cmd.stdout, entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(cmd.stdout, "The schema cache is empty.")
+		return nil
+	}
+
+	rows := make([]cmdutil.TableRow, 0, len(entries))
+	digests := map[string]bool{}
+	var totalSize uint64
+	for _, e := range entries {
+		rows = append(rows, cmdutil.TableRow{Columns: []string{
+			e.Package, e.Version, humanize.Bytes(uint64(e.Size)), humanize.Time(e.CachedAt),
+		}})
+		// Identical schemas are only stored once.
+		if !digests[e.Digest] {
+			digests[e.Digest] = true
+			totalSize += uint64(e.Size)
+		}
+	}
+	fprintTable(cmd.stdout, cmdutil.Table{
+		Headers: []string{"PACKAGE", "VERSION", "SIZE", "CACHED"},
+		Rows:    rows,
+	}, nil)
+	fmt.Fprintln(cmd.stdout)
+	fmt.Fprintf(cmd.stdout, "TOTAL schema cache size: %s\n", humanize.Bytes(totalSize))
+	return nil
+}
+
+type schemaCacheClearCmd struct {
+	stdout io.Writer
+
+	cache *schema.SchemaCache
+}
+
+func newSchemaCacheClearCmd() *cobra.Command {
+	var clearcmd schemaCacheClearCmd
+	return &cobra.Command{
+		Use:   "clear [<package>...]",
+		Short: "Remove schemas from the schema cache",
+		Long: "Remove schemas from the schema cache.\n" +
+			"\n" +
+			"Removes the schemas of the given packages, or all schemas if no packages are given.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			cache, err := schema.DefaultSchemaCache()
+			if err != nil {
+				return err
+			}
+			clearcmd.cache = cache
+			return clearcmd.Run(args)
+		}),
+	}
+}
+
+func (cmd *schemaCacheClearCmd) Run(packages []string) error {
+	if cmd.stdout == nil {
+		cmd.stdout = os.Stdout
+	}
+
+	removed, err := cmd.cache.Clear(packages...)
+	for _, e := range removed {
+		fmt.Fprintf(cmd.stdout, "removed %s\n", e.SchemaCacheKey)
+	}
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		fmt.Fprintln(cmd.stdout, "No schemas to remove.")
+	}
+	return nil
+}
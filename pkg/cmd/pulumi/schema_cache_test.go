@@ -0,0 +1,71 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+func TestSchemaCacheLsAndClear(t *testing.T) {
+	t.Parallel()
+
+	cache := schema.NewSchemaCache(t.TempDir())
+	for _, key := range []schema.SchemaCacheKey{
+		{Package: "aws", Version: "6.0.0"},
+		{Package: "aws", Version: "6.1.0"},
+		{Package: "random", Version: "4.14.0"},
+	} {
+		_, err := cache.Put(key, time.Now(), []byte(`{"name":"`+key.Package+`"}`))
+		require.NoError(t, err)
+	}
+
+	var stdout bytes.Buffer
+	ls := schemaCacheLsCmd{stdout: &stdout, cache: cache}
+	require.NoError(t, ls.Run())
+	assert.Contains(t, stdout.String(), "PACKAGE")
+	assert.Contains(t, stdout.String(), "6.1.0")
+	// The two aws schemas are identical, so they are only counted once.
+	assert.Contains(t, stdout.String(), "TOTAL schema cache size: 31 B")
+
+	stdout.Reset()
+	ls.jsonOut = true
+	require.NoError(t, ls.Run())
+	var entries []schema.SchemaCacheEntry
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &entries))
+	require.Len(t, entries, 3)
+	assert.Equal(t, "random", entries[2].Package)
+
+	stdout.Reset()
+	clearcmd := schemaCacheClearCmd{stdout: &stdout, cache: cache}
+	require.NoError(t, clearcmd.Run([]string{"aws"}))
+	assert.Equal(t, "removed aws@6.0.0\nremoved aws@6.1.0\n", stdout.String())
+
+	stdout.Reset()
+	require.NoError(t, clearcmd.Run(nil))
+	assert.Equal(t, "removed random@4.14.0\n", stdout.String())
+
+	stdout.Reset()
+	ls.jsonOut = false
+	require.NoError(t, ls.Run())
+	assert.Equal(t, "The schema cache is empty.\n", stdout.String())
+}
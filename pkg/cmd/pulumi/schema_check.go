@@ -43,33 +43,12 @@ func newSchemaCheckCommand() *cobra.Command {
 			"schema spec as well as additional requirements imposed by the supported\n" +
 			"target languages.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
-			file := args[0]
-
-			// Read from stdin or a specified file
-			reader := os.Stdin
-			if file != "-" {
-				f, err := os.Open(file)
-				if err != nil {
-					return fmt.Errorf("could not open file %v: %w", file, err)
-				}
-				reader = f
-			}
-			schemaBytes, err := io.ReadAll(reader)
+			pkgSpec, err := readSchemaSpec(args[0])
 			if err != nil {
-				return fmt.Errorf("failed to read schema: %w", err)
+				return err
 			}
 
-			var pkgSpec schema.PackageSpec
-			if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
-				err = yaml.Unmarshal(schemaBytes, &pkgSpec)
-			} else {
-				err = json.Unmarshal(schemaBytes, &pkgSpec)
-			}
-			if err != nil {
-				return fmt.Errorf("failed to unmarshal schema: %w", err)
-			}
-
-			_, diags, err := schema.BindSpec(pkgSpec, nil)
+			_, diags, err := schema.BindSpec(*pkgSpec, nil)
 			diagWriter := hcl.NewDiagnosticTextWriter(os.Stderr, nil, 0, true)
 			wrErr := diagWriter.WriteDiagnostics(diags)
 			contract.IgnoreError(wrErr)
@@ -82,3 +61,31 @@ func newSchemaCheckCommand() *cobra.Command {
 
 	return cmd
 }
+
+// readSchemaSpec reads a package schema in JSON or YAML from a file, or from stdin if file is "-".
+func readSchemaSpec(file string) (*schema.PackageSpec, error) {
+	reader := os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not open file %v: %w", file, err)
+		}
+		defer contract.IgnoreClose(f)
+		reader = f
+	}
+	schemaBytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	var pkgSpec schema.PackageSpec
+	if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
+		err = yaml.Unmarshal(schemaBytes, &pkgSpec)
+	} else {
+		err = json.Unmarshal(schemaBytes, &pkgSpec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	return &pkgSpec, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schemadiff"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+type schemaDiffCmd struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	jsonOut   bool
+	languages []string
+}
+
+func newSchemaDiffCommand() *cobra.Command {
+	var sdcmd schemaDiffCmd
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Args:  cmdutil.ExactArgs(2),
+		Short: "Find breaking changes between two versions of a Pulumi package schema",
+		Long: "Find breaking changes between two versions of a Pulumi package schema.\n" +
+			"\n" +
+			"Compares the old and new schemas and classifies every change by the languages\n" +
+			"whose generated SDKs it breaks: removed or retyped properties, new required\n" +
+			"inputs, removed or renamed resources, functions, types and enum values, and\n" +
+			"names changed through language overrides such as the Go import path or the\n" +
+			"Python and Node.js package names.\n" +
+			"\n" +
+			"The command exits with a non-zero status if any change is breaking, so it can\n" +
+			"be used in CI. Use --language to only fail on changes that break the SDKs of\n" +
+			"particular languages.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			return sdcmd.Run(args[0], args[1])
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&sdcmd.jsonOut, "json", "j", false, "Emit the changes as JSON")
+	cmd.Flags().StringSliceVar(&sdcmd.languages, "language", nil,
+		"Only treat changes that break the SDKs of these languages as breaking "+
+			"(one or more of csharp, go, nodejs or python)")
+
+	return cmd
+}
+
+func (cmd *schemaDiffCmd) Run(oldPath, newPath string) error {
+	if cmd.stdout == nil {
+		cmd.stdout = os.Stdout
+	}
+	if cmd.stderr == nil {
+		cmd.stderr = os.Stderr
+	}
+
+	for _, l := range cmd.languages {
+		if !schemaDiffLanguage(l) {
+			return fmt.Errorf("unknown language %q; expected one of %s", l, strings.Join(schemadiff.Languages, ", "))
+		}
+	}
+
+	oldPkg, err := cmd.bind(oldPath)
+	if err != nil {
+		return err
+	}
+	newPkg, err := cmd.bind(newPath)
+	if err != nil {
+		return err
+	}
+	changes, err := schemadiff.Diff(oldPkg, newPkg)
+	if err != nil {
+		return err
+	}
+
+	var breaking, nonBreaking []schemadiff.Change
+	for _, c := range changes {
+		if c.IsBreaking(cmd.languages...) {
+			breaking = append(breaking, c)
+		} else {
+			nonBreaking = append(nonBreaking, c)
+		}
+	}
+
+	if cmd.jsonOut {
+		if changes == nil {
+			changes = []schemadiff.Change{}
+		}
+		err = fprintJSON(cmd.stdout, struct {
+			Breaking bool                `json:"breaking"`
+			Changes  []schemadiff.Change `json:"changes"`
+		}{len(breaking) != 0, changes})
+		if err != nil {
+			return err
+		}
+	} else {
+		printSchemaChanges(cmd.stdout, "Breaking changes", breaking)
+		printSchemaChanges(cmd.stdout, "Non-breaking changes", nonBreaking)
+		if len(changes) == 0 {
+			fmt.Fprintln(cmd.stdout, "No changes.")
+		}
+	}
+
+	if len(breaking) != 0 {
+		return fmt.Errorf("found %d breaking change(s)", len(breaking))
+	}
+	return nil
+}
+
+// bind reads and binds the schema at path, printing any diagnostics.
+func (cmd *schemaDiffCmd) bind(path string) (*schema.Package, error) {
+	spec, err := readSchemaSpec(path)
+	if err != nil {
+		return nil, err
+	}
+	pkg, diags, err := schema.BindSpec(*spec, nil)
+	if len(diags) != 0 {
+		diagWriter := hcl.NewDiagnosticTextWriter(cmd.stderr, nil, 0, true)
+		contract.IgnoreError(diagWriter.WriteDiagnostics(diags))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if diags.HasErrors() {
+		return nil, errors.New("schema validation failed for " + path)
+	}
+	return pkg, nil
+}
+
+func printSchemaChanges(w io.Writer, title string, changes []schemadiff.Change) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, c := range changes {
+		if len(c.Breaking) != 0 {
+			fmt.Fprintf(w, "    %s: %s [%s]\n", c.Path, c.Message, strings.Join(c.Breaking, ", "))
+		} else {
+			fmt.Fprintf(w, "    %s: %s\n", c.Path, c.Message)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func schemaDiffLanguage(l string) bool {
+	for _, known := range schemadiff.Languages {
+		if l == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSchemaDiffFiles(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	oldPath, newPath := filepath.Join(dir, "old.json"), filepath.Join(dir, "new.yaml")
+	require.NoError(t, os.WriteFile(oldPath, []byte(`{
+		"name": "test",
+		"language": {"go": {"importBasePath": "example.com/test/sdk/go/test"}},
+		"resources": {"test:index:Bucket": {"inputProperties": {"acl": {"type": "string"}}}}
+	}`), 0o600))
+	require.NoError(t, os.WriteFile(newPath, []byte(`name: test
+language:
+  go:
+    importBasePath: example.com/test/sdk/v2/go/test
+resources:
+  test:index:Bucket:
+    inputProperties:
+      acl: {type: string}
+      policy: {type: string}
+`), 0o600))
+	return oldPath, newPath
+}
+
+func TestSchemaDiff(t *testing.T) {
+	t.Parallel()
+
+	oldPath, newPath := writeSchemaDiffFiles(t)
+
+	var stdout bytes.Buffer
+	cmd := schemaDiffCmd{stdout: &stdout}
+	assert.EqualError(t, cmd.Run(oldPath, newPath), "found 1 breaking change(s)")
+	assert.Equal(t, `Breaking changes:
+    language/go: import base path changed from "example.com/test/sdk/go/test" to "example.com/test/sdk/v2/go/test" [go]
+
+Non-breaking changes:
+    resources/test:index:Bucket/inputs/policy: property added
+
+`, stdout.String())
+
+	// The Go change does not break the Python SDK.
+	stdout.Reset()
+	cmd = schemaDiffCmd{stdout: &stdout, languages: []string{"python"}}
+	require.NoError(t, cmd.Run(oldPath, newPath))
+
+	stdout.Reset()
+	cmd = schemaDiffCmd{stdout: &stdout, jsonOut: true}
+	assert.Error(t, cmd.Run(oldPath, newPath))
+	var out struct {
+		Breaking bool `json:"breaking"`
+		Changes  []struct {
+			Path     string   `json:"path"`
+			Breaking []string `json:"breaking"`
+		} `json:"changes"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &out))
+	assert.True(t, out.Breaking)
+	require.Len(t, out.Changes, 2)
+	assert.Equal(t, []string{"go"}, out.Changes[0].Breaking)
+
+	cmd = schemaDiffCmd{stdout: &stdout, languages: []string{"java"}}
+	assert.ErrorContains(t, cmd.Run(oldPath, newPath), `unknown language "java"`)
+}
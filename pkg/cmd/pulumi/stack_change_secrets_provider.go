@@ -45,7 +45,8 @@ func newStackChangeSecretsProviderCmd() *cobra.Command {
 		Args:  cmdutil.ExactArgs(1),
 		Short: "Change the secrets provider for a stack",
 		Long: "Change the secrets provider for a stack. " +
-			"Valid secret providers types are `default`, `passphrase`, `awskms`, `azurekeyvault`, `gcpkms`, `hashivault`.\n\n" +
+			"Valid secret providers types are `default`, `passphrase`, `awskms`, `azurekeyvault`, `gcpkms`, `hashivault`, " +
+			"`age`, `pgp`.\n\n" +
 			"To change to using the Pulumi Default Secrets Provider, use the following:\n" +
 			"\n" +
 			"pulumi stack change-secrets-provider default" +
@@ -61,7 +62,20 @@ func newStackChangeSecretsProviderCmd() *cobra.Command {
 			"\"azurekeyvault://mykeyvaultname.vault.azure.net/keys/mykeyname\"`\n" +
 			"* `pulumi stack change-secrets-provider " +
 			"\"gcpkms://projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>\"`\n" +
-			"* `pulumi stack change-secrets-provider \"hashivault://mykey\"`",
+			"* `pulumi stack change-secrets-provider \"hashivault://mykey\"`\n" +
+			"\n" +
+			"To encrypt secrets to age recipients or PGP public keys, list all of them in the URL:\n" +
+			"\n" +
+			"* `pulumi stack change-secrets-provider \"age://age1abc...,age1def...\"`\n" +
+			"* `pulumi stack change-secrets-provider \"pgp://keys/alice.asc,keys/bob.asc\"`\n" +
+			"\n" +
+			"Relative paths to PGP public keys are resolved against the project directory.\n" +
+			"\n" +
+			"Decrypting requires an age identity in `PULUMI_AGE_IDENTITY` or `PULUMI_AGE_IDENTITY_FILE`, or a PGP private " +
+			"key in `PULUMI_PGP_PRIVATE_KEY` or `PULUMI_PGP_PRIVATE_KEY_FILE` (with `PULUMI_PGP_PASSPHRASE` if it is " +
+			"protected). Changing the list of recipients re-encrypts the existing data key for the new list, so team " +
+			"members can be added or removed without re-encrypting every secret; passing the same URL again generates " +
+			"a new data key.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			ctx := commandContext()
 			return scspcmd.Run(ctx, args)
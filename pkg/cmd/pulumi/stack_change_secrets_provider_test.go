@@ -48,7 +48,7 @@ func TestChangeSecretsProvider_Invalid(t *testing.T) {
 	err := cmd.Run(context.Background(), []string{"not_a_secret"})
 	require.Error(t, err)
 	assert.ErrorContains(t, err, "unknown secrets provider type 'not_a_secret' "+
-		"(supported values: default,passphrase,awskms,azurekeyvault,gcpkms,hashivault)")
+		"(supported values: default,passphrase,awskms,azurekeyvault,gcpkms,hashivault,age,pgp)")
 }
 
 func mockStdin(t *testing.T, input string) {
@@ -30,7 +30,7 @@ import (
 
 const (
 	possibleSecretsProviderChoices = "The type of the provider that should be used to encrypt and decrypt secrets\n" +
-		"(possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault)"
+		"(possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault, age, pgp)"
 )
 
 func newStackInitCmd() *cobra.Command {
@@ -64,6 +64,13 @@ func newStackInitCmd() *cobra.Command {
 			"* `pulumi stack init --secrets-provider=\"gcpkms://projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>\"`\n" +
 			"* `pulumi stack init --secrets-provider=\"hashivault://mykey\"\n`" +
 			"\n" +
+			"To encrypt secrets to one or more age recipients or PGP public keys, use one of the following:\n" +
+			"\n" +
+			"* `pulumi stack init --secrets-provider=\"age://age1abc...,age1def...\"`\n" +
+			"* `pulumi stack init --secrets-provider=\"pgp://keys/alice.asc,keys/bob.asc\"`\n" +
+			"\n" +
+			"Relative paths to PGP public keys are resolved against the project directory.\n" +
+			"\n" +
 			"A stack can be created based on the configuration of an existing stack by passing the\n" +
 			"`--copy-config-from` flag.\n" +
 			"* `pulumi stack init --copy-config-from dev`",
@@ -200,7 +207,7 @@ func (cmd *stackInitCmd) Run(ctx context.Context, args []string) error {
 		}
 
 		// copy the config from the old to the new
-		return copyEntireConfigMap(copyStack, copyProjectStack, newStack, newProjectStack)
+		return copyEntireConfigMap(proj, copyStack, copyProjectStack, newStack, newProjectStack)
 	}
 
 	return nil
@@ -0,0 +1,307 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/pkg/v3/secrets"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/age"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/cloud"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/passphrase"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/pgp"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/service"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/deepcopy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+type stackRotateSecretsKeyCmd struct {
+	stdout io.Writer
+
+	stack         string
+	dryRun        bool
+	newPassphrase bool
+}
+
+func newStackRotateSecretsKeyCmd() *cobra.Command {
+	var srskcmd stackRotateSecretsKeyCmd
+	cmd := &cobra.Command{
+		Use:   "rotate-secrets-key",
+		Args:  cmdutil.NoArgs,
+		Short: "Rotate the data key used to encrypt a stack's secrets",
+		Long: "Rotate the data key used to encrypt a stack's secrets.\n" +
+			"\n" +
+			"This generates a new data key for the stack's current secrets provider and re-encrypts every secret in\n" +
+			"the stack's configuration and checkpoint with it, for example after someone who had access to the old\n" +
+			"key has left the team. The secrets provider itself does not change.\n" +
+			"\n" +
+			"For the `passphrase` provider a new salt is generated for the current passphrase; pass\n" +
+			"`--new-passphrase` to choose a new passphrase as well. For cloud, `age` and `pgp` providers a new data key\n" +
+			"is generated and encrypted with the configured key. Stacks using the Pulumi Cloud secrets provider have\n" +
+			"their keys managed by the service and cannot be rotated with this command.\n" +
+			"\n" +
+			"Use `--dry-run` to report how many secret values would be re-encrypted without changing anything.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			ctx := commandContext()
+			return srskcmd.Run(ctx)
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&srskcmd.stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&srskcmd.dryRun, "dry-run", false,
+		"Only report the number of secret values that would be re-encrypted")
+	cmd.PersistentFlags().BoolVar(
+		&srskcmd.newPassphrase, "new-passphrase", false,
+		"Prompt for a new passphrase instead of reusing the current one (passphrase provider only)")
+
+	return cmd
+}
+
+func (cmd *stackRotateSecretsKeyCmd) Run(ctx context.Context) error {
+	stdout := cmd.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
+	opts := display.Options{
+		Color: cmdutil.GetGlobalColorization(),
+	}
+
+	project, _, err := readProject()
+	if err != nil {
+		return err
+	}
+	s, err := requireStack(ctx, cmd.stack, stackLoadOnly, opts)
+	if err != nil {
+		return err
+	}
+	ps, err := loadProjectStack(project, s)
+	if err != nil {
+		return err
+	}
+
+	oldSecretsManager, _, err := getStackSecretsManager(s, ps)
+	if err != nil {
+		return err
+	}
+	switch typ := oldSecretsManager.Type(); typ {
+	case passphrase.Type, cloud.Type, age.Type, pgp.Type:
+	case service.Type:
+		return errors.New("the data key of the Pulumi Cloud secrets provider is managed by the service " +
+			"and cannot be rotated with this command")
+	default:
+		return fmt.Errorf("rotating the data key is not supported for the %q secrets provider", typ)
+	}
+	decrypter, err := oldSecretsManager.Decrypter()
+	if err != nil {
+		return err
+	}
+
+	// Decrypt everything up front, so that nothing is written unless every secret can be read with the old key.
+	configCiphertexts, err := collectConfigCiphertexts(ps.Config)
+	if err != nil {
+		return err
+	}
+	configPlaintexts, err := decrypter.BulkDecrypt(ctx, configCiphertexts)
+	if err != nil {
+		return fmt.Errorf("decrypting stack configuration: %w", err)
+	}
+
+	checkpoint, err := s.ExportDeployment(ctx)
+	if err != nil {
+		return err
+	}
+	snap, err := stack.DeserializeUntypedDeployment(ctx, checkpoint, stack.DefaultSecretsProvider)
+	if err != nil {
+		return checkDeploymentVersionError(err, s.Ref().Name().String())
+	}
+	checkpointSecrets := countSnapshotSecrets(snap)
+
+	if cmd.dryRun {
+		fmt.Fprintf(stdout, "Rotating the data key of stack %s would re-encrypt %d secret value(s) in its "+
+			"configuration and %d in its checkpoint\n", s.Ref(), len(configCiphertexts), checkpointSecrets)
+		return nil
+	}
+
+	// Generate the new key on a copy of the stack settings; they are only saved once the checkpoint is written.
+	newProjectStack := deepcopy.Copy(ps).(*workspace.ProjectStack)
+	newSecretsManager, err := cmd.rotateSecretsManager(oldSecretsManager.Type(), newProjectStack)
+	if err != nil {
+		return err
+	}
+	encrypter, err := newSecretsManager.Encrypter()
+	if err != nil {
+		return err
+	}
+
+	newConfig, err := ps.Config.Copy(&preloadedDecrypter{decrypter, configPlaintexts}, encrypter)
+	if err != nil {
+		return fmt.Errorf("re-encrypting stack configuration: %w", err)
+	}
+	newProjectStack.Config = newConfig
+
+	newDeployment, err := stack.SerializeDeployment(snap, newSecretsManager, false /*showSecrets*/)
+	if err != nil {
+		return fmt.Errorf("re-encrypting checkpoint: %w", err)
+	}
+	bytes, err := json.Marshal(newDeployment)
+	if err != nil {
+		return err
+	}
+
+	// Write the checkpoint first, and put the old one back if the configuration can't be saved, so that the
+	// checkpoint and configuration always agree on the data key.
+	if err := s.ImportDeployment(ctx, &apitype.UntypedDeployment{
+		Version:    apitype.DeploymentSchemaVersionCurrent,
+		Deployment: bytes,
+	}); err != nil {
+		return fmt.Errorf("saving checkpoint: %w", err)
+	}
+	if err := saveProjectStack(s, newProjectStack); err != nil {
+		if restoreErr := s.ImportDeployment(ctx, checkpoint); restoreErr != nil {
+			return errors.Join(
+				fmt.Errorf("saving stack config: %w", err),
+				fmt.Errorf("restoring previous checkpoint: %w", restoreErr))
+		}
+		return fmt.Errorf("saving stack config: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "Rotated the data key of stack %s and re-encrypted %d secret value(s) in its "+
+		"configuration and %d in its checkpoint\n", s.Ref(), len(configCiphertexts), checkpointSecrets)
+	return nil
+}
+
+// rotateSecretsManager returns a secrets manager of the given type with a new data key, updating ps to match.
+func (cmd *stackRotateSecretsKeyCmd) rotateSecretsManager(
+	typ string, ps *workspace.ProjectStack,
+) (secrets.Manager, error) {
+	switch typ {
+	case passphrase.Type:
+		if cmd.newPassphrase {
+			return passphrase.NewPromptingPassphraseSecretsManager(ps, true /* rotateSecretsProvider */)
+		}
+		return passphrase.NewPromptingPassphraseSecretsManagerWithNewSalt(ps)
+	case cloud.Type:
+		return cloud.NewCloudSecretsManager(ps, ps.SecretsProvider, true /* rotateSecretsProvider */)
+	case age.Type:
+		return age.NewAgeSecretsManager(ps, ps.SecretsProvider, true /* rotateSecretsProvider */)
+	case pgp.Type:
+		return pgp.NewPGPSecretsManager(ps, ps.SecretsProvider, true /* rotateSecretsProvider */)
+	default:
+		return nil, fmt.Errorf("rotating the data key is not supported for the %q secrets provider", typ)
+	}
+}
+
+// collectConfigCiphertexts returns the ciphertext of every secure value in the given config, including secure
+// values nested in objects, without decrypting them.
+func collectConfigCiphertexts(m config.Map) ([]string, error) {
+	var collector ciphertextCollector
+	for _, v := range m {
+		if _, err := v.SecureValues(&collector); err != nil {
+			return nil, err
+		}
+	}
+	return collector.ciphertexts, nil
+}
+
+// ciphertextCollector is a Decrypter that records the ciphertexts it is asked to decrypt.
+type ciphertextCollector struct {
+	ciphertexts []string
+}
+
+func (c *ciphertextCollector) DecryptValue(_ context.Context, ciphertext string) (string, error) {
+	c.ciphertexts = append(c.ciphertexts, ciphertext)
+	return "", nil
+}
+
+func (c *ciphertextCollector) BulkDecrypt(ctx context.Context, ciphertexts []string) (map[string]string, error) {
+	return config.DefaultBulkDecrypt(ctx, c, ciphertexts)
+}
+
+// preloadedDecrypter is a Decrypter that answers from the results of an earlier BulkDecrypt call.
+type preloadedDecrypter struct {
+	decrypter  config.Decrypter
+	plaintexts map[string]string
+}
+
+func (d *preloadedDecrypter) DecryptValue(ctx context.Context, ciphertext string) (string, error) {
+	if plaintext, ok := d.plaintexts[ciphertext]; ok {
+		return plaintext, nil
+	}
+	return d.decrypter.DecryptValue(ctx, ciphertext)
+}
+
+func (d *preloadedDecrypter) BulkDecrypt(ctx context.Context, ciphertexts []string) (map[string]string, error) {
+	return config.DefaultBulkDecrypt(ctx, d, ciphertexts)
+}
+
+// countSnapshotSecrets returns the number of secret values in the inputs and outputs of a snapshot's resources.
+func countSnapshotSecrets(snap *deploy.Snapshot) int {
+	if snap == nil {
+		return 0
+	}
+	count := 0
+	for _, res := range snap.Resources {
+		count += countSecrets(resource.NewObjectProperty(res.Inputs))
+		count += countSecrets(resource.NewObjectProperty(res.Outputs))
+	}
+	for _, op := range snap.PendingOperations {
+		if op.Resource != nil {
+			count += countSecrets(resource.NewObjectProperty(op.Resource.Inputs))
+			count += countSecrets(resource.NewObjectProperty(op.Resource.Outputs))
+		}
+	}
+	return count
+}
+
+func countSecrets(v resource.PropertyValue) int {
+	switch {
+	case v.IsSecret():
+		return 1
+	case v.IsArray():
+		count := 0
+		for _, e := range v.ArrayValue() {
+			count += countSecrets(e)
+		}
+		return count
+	case v.IsObject():
+		count := 0
+		for _, e := range v.ObjectValue() {
+			count += countSecrets(e)
+		}
+		return count
+	case v.IsOutput():
+		return countSecrets(v.OutputValue().Element)
+	default:
+		return 0
+	}
+}
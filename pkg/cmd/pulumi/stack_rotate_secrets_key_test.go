@@ -0,0 +1,167 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/pkg/v3/secrets"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/passphrase"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/encoding"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// Test that rotating the key of a passphrase stack re-encrypts the secrets in both its config and its checkpoint
+// with a new salt, and that a dry run only counts them.
+//
+//nolint:paralleltest // mutates global state
+func TestRotateSecretsKey_Passphrase(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("PULUMI_CONFIG_PASSPHRASE", "password123")
+	t.Setenv("PULUMI_CONFIG_PASSPHRASE_FILE", "")
+
+	salt, secretsManager, err := passphrase.NewPassphraseSecretsManager("password123")
+	require.NoError(t, err)
+
+	snapshot := &deploy.Snapshot{
+		SecretsManager: secretsManager,
+		Resources: []*resource.State{
+			{
+				URN:  resource.NewURN("testStack", "testProject", "", resource.RootStackType, "testStack"),
+				Type: resource.RootStackType,
+				Outputs: resource.PropertyMap{
+					"foo": resource.MakeSecret(resource.NewStringProperty("bar")),
+					"nested": resource.NewObjectProperty(resource.PropertyMap{
+						"baz": resource.MakeSecret(resource.NewStringProperty("qux")),
+					}),
+				},
+			},
+		},
+	}
+
+	mockStack := &backend.MockStack{
+		RefF: func() backend.StackReference {
+			return &backend.MockStackReference{
+				StringV: "testStack",
+				NameV:   tokens.MustParseStackName("testStack"),
+			}
+		},
+		SnapshotF: func(_ context.Context, _ secrets.Provider) (*deploy.Snapshot, error) {
+			return snapshot, nil
+		},
+		ExportDeploymentF: func(ctx context.Context) (*apitype.UntypedDeployment, error) {
+			chk, err := stack.SerializeDeployment(snapshot, snapshot.SecretsManager, false)
+			if err != nil {
+				return nil, err
+			}
+			data, err := encoding.JSON.Marshal(chk)
+			if err != nil {
+				return nil, err
+			}
+			return &apitype.UntypedDeployment{
+				Version:    3,
+				Deployment: json.RawMessage(data),
+			}, nil
+		},
+		ImportDeploymentF: func(ctx context.Context, deployment *apitype.UntypedDeployment) error {
+			snap, err := stack.DeserializeUntypedDeployment(ctx, deployment, stack.DefaultSecretsProvider)
+			if err != nil {
+				return err
+			}
+			snapshot = snap
+			return nil
+		},
+	}
+
+	backendInstance = &backend.MockBackend{
+		GetStackF: func(ctx context.Context, stackRef backend.StackReference) (backend.Stack, error) {
+			return mockStack, nil
+		},
+	}
+	t.Cleanup(func() { backendInstance = nil })
+
+	tmpDir := t.TempDir()
+	chdir(t, tmpDir)
+
+	err = os.WriteFile("Pulumi.yaml", []byte(`
+name: testProject
+runtime: mock
+`), 0o600)
+	require.NoError(t, err)
+
+	encrypter, err := secretsManager.Encrypter()
+	require.NoError(t, err)
+	secretBar, err := encrypter.EncryptValue(ctx, "bar")
+	require.NoError(t, err)
+	cfgKey := config.MustMakeKey("testProject", "secret")
+	cfg := workspace.ProjectStack{
+		EncryptionSalt: salt,
+		Config: config.Map{
+			cfgKey: config.NewSecureValue(secretBar),
+		},
+	}
+	require.NoError(t, cfg.Save("Pulumi.testStack.yaml"))
+
+	var stdout bytes.Buffer
+	cmd := stackRotateSecretsKeyCmd{stdout: &stdout, stack: "testStack", dryRun: true}
+	require.NoError(t, cmd.Run(ctx))
+	assert.Equal(t, "Rotating the data key of stack testStack would re-encrypt 1 secret value(s) in its "+
+		"configuration and 2 in its checkpoint\n", stdout.String())
+
+	project, err := workspace.LoadProject("Pulumi.yaml")
+	require.NoError(t, err)
+	projectStack, err := workspace.LoadProjectStack(project, "Pulumi.testStack.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, salt, projectStack.EncryptionSalt)
+
+	stdout.Reset()
+	cmd.dryRun = false
+	require.NoError(t, cmd.Run(ctx))
+	assert.Equal(t, "Rotated the data key of stack testStack and re-encrypted 1 secret value(s) in its "+
+		"configuration and 2 in its checkpoint\n", stdout.String())
+
+	// The config now has a new salt, and its secret is encrypted with it.
+	projectStack, err = workspace.LoadProjectStack(project, "Pulumi.testStack.yaml")
+	require.NoError(t, err)
+	assert.NotEqual(t, salt, projectStack.EncryptionSalt)
+	newSecretsManager, err := passphrase.GetPassphraseSecretsManager("password123", projectStack.EncryptionSalt)
+	require.NoError(t, err)
+	decrypter, err := newSecretsManager.Decrypter()
+	require.NoError(t, err)
+	cfgValue := projectStack.Config[cfgKey]
+	val, err := cfgValue.Value(decrypter)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", val)
+
+	// And the checkpoint uses the same key.
+	assert.JSONEq(t, string(newSecretsManager.State()), string(snapshot.SecretsManager.State()))
+	foo := snapshot.Resources[0].Outputs["foo"]
+	assert.True(t, foo.IsSecret())
+	assert.Equal(t, resource.NewStringProperty("bar"), foo.SecretValue().Element)
+}
@@ -0,0 +1,287 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/secretscan"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+type stackScanSecretsCmd struct {
+	stdout io.Writer
+
+	stack      string
+	configPath string
+	eventLogs  []string
+	promote    bool
+	jsonOut    bool
+}
+
+func newStackScanSecretsCmd() *cobra.Command {
+	var ssscmd stackScanSecretsCmd
+	cmd := &cobra.Command{
+		Use:   "scan-secrets",
+		Args:  cmdutil.NoArgs,
+		Short: "Scan a stack's checkpoint or event logs for secrets stored in plaintext",
+		Long: "Scan a stack's checkpoint or event logs for secrets stored in plaintext.\n" +
+			"\n" +
+			"Providers sometimes return values that look like secrets, such as access keys or passwords, in\n" +
+			"outputs that are not marked as secret, so they end up in plaintext in the checkpoint. This command\n" +
+			"reports the URN and property path of every output that looks like a secret but is not marked as one.\n" +
+			"\n" +
+			"Values are checked against well-known token formats, such as cloud provider access keys, API tokens and\n" +
+			"private keys, and against an entropy threshold for random-looking strings. Pass\n" +
+			"`--secret-scan-config` with a YAML or JSON file to add patterns, tune or disable the detectors, or\n" +
+			"allow known values, e.g.:\n" +
+			"\n" +
+			"    patterns:\n" +
+			"      internal-token: ^itk_[a-z0-9]{32}$\n" +
+			"    entropy:\n" +
+			"      threshold: 5\n" +
+			"    allow:\n" +
+			"      - EXAMPLE\n" +
+			"\n" +
+			"The command fails if it finds any outputs that look like secrets, unless `--promote` is passed to mark\n" +
+			"them as secret in the checkpoint, so that they are encrypted from then on. Pass `--event-log` one or\n" +
+			"more times to scan files written by `--event-log` instead of the checkpoint.\n" +
+			"\n" +
+			"`pulumi up --scan-secrets` and `pulumi refresh --scan-secrets` run the same scan after each step. `pulumi\n" +
+			"preview` has no such flag: a preview does not save any outputs, and most of the outputs it reports are\n" +
+			"not known until the resource is created or updated.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			ctx := commandContext()
+			return ssscmd.Run(ctx)
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&ssscmd.stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().StringVar(
+		&ssscmd.configPath, "secret-scan-config", "",
+		"Path to a YAML or JSON file that configures the secret detectors")
+	cmd.PersistentFlags().StringArrayVar(
+		&ssscmd.eventLogs, "event-log", nil,
+		"Scan the given event log instead of the stack's checkpoint. May be repeated")
+	cmd.PersistentFlags().BoolVar(
+		&ssscmd.promote, "promote", false,
+		"Mark the detected outputs as secret in the stack's checkpoint")
+	cmd.PersistentFlags().BoolVarP(
+		&ssscmd.jsonOut, "json", "j", false,
+		"Emit output as JSON")
+
+	return cmd
+}
+
+// newSecretScanner returns a secret scanner configured by the file at configPath, or with the default detectors if
+// configPath is empty.
+func newSecretScanner(configPath string) (*secretscan.Scanner, error) {
+	var cfg secretscan.Config
+	if configPath != "" {
+		c, err := secretscan.LoadConfig(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading secret scan config: %w", err)
+		}
+		cfg = c
+	}
+	return secretscan.NewScannerFromConfig(cfg)
+}
+
+func (cmd *stackScanSecretsCmd) Run(ctx context.Context) error {
+	stdout := cmd.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
+	if cmd.promote && len(cmd.eventLogs) > 0 {
+		return errors.New("--promote cannot be used with --event-log; only the checkpoint can be updated")
+	}
+
+	scanner, err := newSecretScanner(cmd.configPath)
+	if err != nil {
+		return err
+	}
+
+	var findings []secretscan.Finding
+	if len(cmd.eventLogs) > 0 {
+		for _, path := range cmd.eventLogs {
+			events, err := loadEvents(path)
+			if err != nil {
+				return err
+			}
+			findings = append(findings, scanEvents(scanner, events)...)
+		}
+		findings = dedupeFindings(findings)
+	} else {
+		findings, err = cmd.scanCheckpoint(ctx, scanner)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := cmd.printFindings(stdout, findings); err != nil {
+		return err
+	}
+
+	// Fail if plaintext secrets remain, so that the scan can gate a CI pipeline.
+	if len(findings) != 0 && !cmd.promote {
+		return fmt.Errorf("found %d output(s) that look like secrets; use --promote to mark them as secret",
+			len(findings))
+	}
+	return nil
+}
+
+func (cmd *stackScanSecretsCmd) printFindings(stdout io.Writer, findings []secretscan.Finding) error {
+	if cmd.jsonOut {
+		if findings == nil {
+			findings = []secretscan.Finding{}
+		}
+		b, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, string(b))
+		return err
+	}
+
+	if len(findings) == 0 {
+		fmt.Fprintln(stdout, "No plaintext secrets found")
+		return nil
+	}
+	rows := make([]cmdutil.TableRow, len(findings))
+	for i, f := range findings {
+		rows[i] = cmdutil.TableRow{Columns: []string{string(f.URN), f.Path.String(), f.Detector}}
+	}
+	fmt.Fprint(stdout, cmdutil.Table{
+		Headers: []string{"URN", "PROPERTY", "DETECTOR"},
+		Rows:    rows,
+	}.Render(nil))
+	if cmd.promote {
+		fmt.Fprintf(stdout, "\nMarked %d output(s) as secret\n", len(findings))
+	}
+	return nil
+}
+
+// scanCheckpoint scans the outputs of every resource in the stack's checkpoint, and promotes the findings to
+// secrets in the checkpoint if requested.
+func (cmd *stackScanSecretsCmd) scanCheckpoint(
+	ctx context.Context, scanner *secretscan.Scanner,
+) ([]secretscan.Finding, error) {
+	opts := display.Options{
+		Color: cmdutil.GetGlobalColorization(),
+	}
+	s, err := requireStack(ctx, cmd.stack, stackLoadOnly, opts)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := s.Snapshot(ctx, stack.DefaultSecretsProvider)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return nil, nil
+	}
+
+	var findings []secretscan.Finding
+	for _, res := range snap.Resources {
+		resFindings := scanner.Scan(res.URN, res.Outputs)
+		if cmd.promote && len(resFindings) > 0 {
+			res.Outputs = secretscan.Promote(res.Outputs, resFindings)
+		}
+		findings = append(findings, resFindings...)
+	}
+	if !cmd.promote || len(findings) == 0 {
+		return findings, nil
+	}
+
+	if err := importPromotedSnapshot(ctx, s, snap); err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
+
+// importPromotedSnapshot writes a snapshot with promoted secrets back to the stack, encrypting them with the
+// snapshot's own secrets manager.
+func importPromotedSnapshot(ctx context.Context, s backend.Stack, snap *deploy.Snapshot) error {
+	sm := snap.SecretsManager
+	if sm == nil {
+		return errors.New("the stack's checkpoint has no secrets manager to encrypt the promoted outputs with; " +
+			"run `pulumi up` once to configure one")
+	}
+	deployment, err := stack.SerializeDeployment(snap, sm, false /*showSecrets*/)
+	if err != nil {
+		return err
+	}
+	bytes, err := json.Marshal(deployment)
+	if err != nil {
+		return err
+	}
+	return s.ImportDeployment(ctx, &apitype.UntypedDeployment{
+		Version:    apitype.DeploymentSchemaVersionCurrent,
+		Deployment: bytes,
+	})
+}
+
+// scanEvents scans the new outputs recorded in the step and output events of an event log.
+func scanEvents(scanner *secretscan.Scanner, events []engine.Event) []secretscan.Finding {
+	var findings []secretscan.Finding
+	for _, e := range events {
+		var metadata engine.StepEventMetadata
+		switch payload := e.Payload().(type) {
+		case engine.ResourcePreEventPayload:
+			metadata = payload.Metadata
+		case engine.ResourceOutputsEventPayload:
+			metadata = payload.Metadata
+		case engine.ResourceOperationFailedPayload:
+			metadata = payload.Metadata
+		default:
+			continue
+		}
+		if metadata.New != nil {
+			findings = append(findings, scanner.Scan(metadata.URN, metadata.New.Outputs)...)
+		}
+	}
+	return findings
+}
+
+// dedupeFindings removes repeated findings, keeping the first of each, as the same resource usually appears in
+// several events.
+func dedupeFindings(findings []secretscan.Finding) []secretscan.Finding {
+	seen := map[string]bool{}
+	var result []secretscan.Finding
+	for _, f := range findings {
+		key := string(f.URN) + "\x00" + f.Path.String() + "\x00" + f.Detector
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, f)
+		}
+	}
+	return result
+}
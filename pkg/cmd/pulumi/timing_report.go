@@ -0,0 +1,221 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// timingReportSlowest is the number of steps listed as the slowest in a timing report.
+const timingReportSlowest = 10
+
+// writeStepTimings prints a timing report for the steps of an update if requested, and writes a Chrome trace of them
+// to tracePath if it is set.
+func writeStepTimings(w io.Writer, timings *deploy.StepTimings, parallel int, report bool, tracePath string) error {
+	if timings == nil {
+		return nil
+	}
+	steps := timings.Steps()
+	if report {
+		printTimingReport(w, steps, parallel)
+	}
+	if tracePath != "" {
+		f, err := os.Create(tracePath)
+		if err != nil {
+			return fmt.Errorf("could not create timing trace: %w", err)
+		}
+		if err := writeTimingTrace(f, steps); err != nil {
+			contract.IgnoreClose(f)
+			return fmt.Errorf("could not write timing trace: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("could not write timing trace: %w", err)
+		}
+	}
+	return nil
+}
+
+// printTimingReport prints the slowest steps of an update, its critical path, and how much the degree of parallelism
+// constrained it.
+func printTimingReport(w io.Writer, steps []deploy.StepTiming, parallel int) {
+	fmt.Fprintf(w, "\nTiming report:\n")
+	if len(steps) == 0 {
+		fmt.Fprintf(w, "    No resource operations were performed.\n")
+		return
+	}
+
+	slowest := make([]deploy.StepTiming, len(steps))
+	copy(slowest, steps)
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].Duration() > slowest[j].Duration() })
+	if len(slowest) > timingReportSlowest {
+		slowest = slowest[:timingReportSlowest]
+	}
+	fmt.Fprintf(w, "\nSlowest resources:\n")
+	fprintTable(w, timingTable(slowest), nil)
+
+	path := deploy.CriticalPath(steps)
+	var pathLength time.Duration
+	for _, st := range path {
+		pathLength += st.Duration()
+	}
+	fmt.Fprintf(w, "\nCritical path (%s):\n", formatTimingDuration(pathLength))
+	fprintTable(w, timingTable(path), nil)
+
+	var start, end time.Time
+	var work, wait time.Duration
+	for _, st := range steps {
+		if start.IsZero() || st.Queued.Before(start) {
+			start = st.Queued
+		}
+		if st.Finished.After(end) {
+			end = st.Finished
+		}
+		work += st.Duration()
+		wait += st.Wait()
+	}
+	wall := end.Sub(start)
+
+	fmt.Fprintf(w, "\nParallelism:\n")
+	fmt.Fprintf(w, "    Wall time: %s\n", formatTimingDuration(wall))
+	fmt.Fprintf(w, "    Time spent in resource operations: %s\n", formatTimingDuration(work))
+	peak := deploy.MaxConcurrency(steps)
+	bounded := parallel != defaultParallel
+	degree := deploy.Options{Parallel: parallel}.DegreeOfParallelism()
+	limit := "unbounded"
+	if bounded {
+		limit = fmt.Sprintf("%d", degree)
+	}
+	fmt.Fprintf(w, "    Peak concurrent operations: %d (limit %s)\n", peak, limit)
+	fmt.Fprintf(w, "    Time operations waited for a worker: %s\n", formatTimingDuration(wait))
+
+	if bounded && peak >= degree && wait > 0 {
+		fmt.Fprintf(w, "    The --parallel limit was reached; raising it could shorten the update by up to %s.\n",
+			formatTimingDuration(wall-pathLength))
+	} else {
+		fmt.Fprintf(w, "    The update was not constrained by --parallel; it is bounded by its critical path.\n")
+	}
+}
+
+func timingTable(steps []deploy.StepTiming) cmdutil.Table {
+	rows := make([]cmdutil.TableRow, 0, len(steps))
+	for _, st := range steps {
+		status := ""
+		if st.Failed {
+			status = "failed"
+		}
+		rows = append(rows, cmdutil.TableRow{Columns: []string{
+			formatTimingDuration(st.Duration()),
+			formatTimingDuration(st.Wait()),
+			string(st.Op),
+			string(st.Type),
+			st.URN.Name(),
+			status,
+		}})
+	}
+	return cmdutil.Table{
+		Headers: []string{"DURATION", "WAIT", "OP", "TYPE", "NAME", "STATUS"},
+		Rows:    rows,
+		Prefix:  "    ",
+	}
+}
+
+func formatTimingDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(100 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.String()
+	}
+}
+
+// traceEvent is a complete event in the Chrome trace event format, which can be loaded by chrome://tracing and
+// https://ui.perfetto.dev.
+type traceEvent struct {
+	Name     string            `json:"name"`
+	Category string            `json:"cat"`
+	Phase    string            `json:"ph"`
+	Time     int64             `json:"ts"`
+	Duration int64             `json:"dur"`
+	PID      int               `json:"pid"`
+	TID      int               `json:"tid"`
+	Args     map[string]string `json:"args,omitempty"`
+}
+
+// writeTimingTrace writes the steps of an update as a Chrome trace. Each step is placed on the first lane that is free
+// when it starts, so the number of lanes in the trace is the peak number of concurrent operations.
+func writeTimingTrace(w io.Writer, steps []deploy.StepTiming) error {
+	sorted := make([]deploy.StepTiming, 0, len(steps))
+	var origin time.Time
+	for _, st := range steps {
+		if st.Finished.IsZero() {
+			continue
+		}
+		sorted = append(sorted, st)
+		if origin.IsZero() || st.Queued.Before(origin) {
+			origin = st.Queued
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Started.Before(sorted[j].Started) })
+
+	events := make([]traceEvent, 0, len(sorted))
+	var lanes []time.Time
+	for _, st := range sorted {
+		lane := 0
+		for lane < len(lanes) && lanes[lane].After(st.Started) {
+			lane++
+		}
+		if lane == len(lanes) {
+			lanes = append(lanes, time.Time{})
+		}
+		lanes[lane] = st.Finished
+
+		args := map[string]string{
+			"urn":  string(st.URN),
+			"type": string(st.Type),
+			"wait": formatTimingDuration(st.Wait()),
+		}
+		if st.Failed {
+			args["failed"] = "true"
+		}
+		events = append(events, traceEvent{
+			Name:     fmt.Sprintf("%s (%s)", st.URN.Name(), st.Op),
+			Category: string(st.Op),
+			Phase:    "X",
+			Time:     st.Started.Sub(origin).Microseconds(),
+			Duration: st.Duration().Microseconds(),
+			PID:      1,
+			TID:      lane + 1,
+			Args:     args,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		TraceEvents     []traceEvent `json:"traceEvents"`
+		DisplayTimeUnit string       `json:"displayTimeUnit"`
+	}{events, "ms"})
+}
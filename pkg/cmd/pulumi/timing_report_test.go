@@ -0,0 +1,88 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func testTimingSteps() []deploy.StepTiming {
+	origin := time.Unix(1700000000, 0)
+	urn := func(name string) resource.URN { return resource.NewURN("dev", "proj", "", "pkgA:m:typA", name) }
+	at := func(ms int) time.Time { return origin.Add(time.Duration(ms) * time.Millisecond) }
+	return []deploy.StepTiming{
+		{URN: urn("a"), Type: "pkgA:m:typA", Op: deploy.OpCreate, Queued: at(0), Started: at(0), Finished: at(1000)},
+		{URN: urn("b"), Type: "pkgA:m:typA", Op: deploy.OpCreate, Queued: at(0), Started: at(500), Finished: at(1500)},
+		{
+			URN: urn("c"), Type: "pkgA:m:typA", Op: deploy.OpUpdate, Dependencies: []resource.URN{urn("a")},
+			Queued: at(1000), Started: at(1500), Finished: at(4000), Failed: true,
+		},
+	}
+}
+
+func TestPrintTimingReport(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	printTimingReport(&out, testTimingSteps(), 2)
+	report := out.String()
+
+	assert.Contains(t, report, "Critical path (3.5s):")
+	assert.Regexp(t, `2\.5s +500ms +update +pkgA:m:typA +c +failed`, report)
+	assert.Contains(t, report, "Wall time: 4s")
+	assert.Contains(t, report, "Peak concurrent operations: 2 (limit 2)")
+	assert.Contains(t, report, "Time operations waited for a worker: 1s")
+	assert.Contains(t, report, "The --parallel limit was reached; raising it could shorten the update by up to 500ms.")
+
+	out.Reset()
+	printTimingReport(&out, testTimingSteps(), defaultParallel)
+	assert.Contains(t, out.String(), "(limit unbounded)")
+	assert.Contains(t, out.String(), "The update was not constrained by --parallel")
+}
+
+func TestWriteTimingTrace(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, writeTimingTrace(&out, testTimingSteps()))
+
+	var trace struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &trace))
+	require.Len(t, trace.TraceEvents, 3)
+
+	a, b, c := trace.TraceEvents[0], trace.TraceEvents[1], trace.TraceEvents[2]
+	assert.Equal(t, "a (create)", a.Name)
+	assert.Equal(t, "X", a.Phase)
+	assert.Equal(t, int64(0), a.Time)
+	assert.Equal(t, int64(1000000), a.Duration)
+	assert.Equal(t, int64(500000), b.Time)
+
+	// b overlaps a so it gets its own lane, and c reuses the lane that a freed.
+	assert.Equal(t, 1, a.TID)
+	assert.Equal(t, 2, b.TID)
+	assert.Equal(t, 1, c.TID)
+	assert.Equal(t, "true", c.Args["failed"])
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/pkg/v3/engine"
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/secretscan"
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
 	pkgWorkspace "github.com/pulumi/pulumi/pkg/v3/workspace"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
@@ -64,6 +65,7 @@ func newUpCmd() *cobra.Command {
 	var policyPackPaths []string
 	var policyPackConfigPaths []string
 	var diffDisplay bool
+	var displayFlag string
 	var eventLogPath string
 	var parallel int
 	var refresh string
@@ -83,6 +85,13 @@ func newUpCmd() *cobra.Command {
 	var targetReplaces []string
 	var targetDependents bool
 	var planFilePath string
+	var scanSecrets bool
+	var promoteSecrets bool
+	var secretScanConfigPath string
+	var secretScanner *secretscan.Scanner
+	var timingReport bool
+	var timingTracePath string
+	var stepTimings *deploy.StepTimings
 
 	// up implementation used when the source of the Pulumi program is in the current working directory.
 	upWorkingDirectory := func(ctx context.Context, opts backend.UpdateOptions, cmd *cobra.Command) result.Result {
@@ -159,8 +168,11 @@ func newUpCmd() *cobra.Command {
 			TargetDependents:          targetDependents,
 			// Trigger a plan to be generated during the preview phase which can be constrained to during the
 			// update phase.
-			GeneratePlan: true,
-			Experimental: hasExperimentalCommands(),
+			GeneratePlan:           true,
+			Experimental:           hasExperimentalCommands(),
+			SecretScanner:          secretScanner,
+			PromoteDetectedSecrets: promoteSecrets,
+			StepTimings:            stepTimings,
 		}
 
 		if planFilePath != "" {
@@ -189,6 +201,9 @@ func newUpCmd() *cobra.Command {
 			SecretsProvider:    stack.DefaultSecretsProvider,
 			Scopes:             backend.CancellationScopes,
 		})
+		if err := writeStepTimings(os.Stdout, stepTimings, parallel, timingReport, timingTracePath); err != nil {
+			return result.FromError(err)
+		}
 		switch {
 		case res != nil && res.Error() == context.Canceled:
 			return result.FromError(errors.New("update cancelled"))
@@ -377,8 +392,11 @@ func newUpCmd() *cobra.Command {
 			Refresh:          refreshOption,
 			// If we're in experimental mode then we trigger a plan to be generated during the preview phase
 			// which will be constrained to during the update phase.
-			GeneratePlan: hasExperimentalCommands(),
-			Experimental: hasExperimentalCommands(),
+			GeneratePlan:           hasExperimentalCommands(),
+			Experimental:           hasExperimentalCommands(),
+			SecretScanner:          secretScanner,
+			PromoteDetectedSecrets: promoteSecrets,
+			StepTimings:            stepTimings,
 		}
 
 		// TODO for the URL case:
@@ -396,6 +414,9 @@ func newUpCmd() *cobra.Command {
 			SecretsProvider:    stack.DefaultSecretsProvider,
 			Scopes:             backend.CancellationScopes,
 		})
+		if err := writeStepTimings(os.Stdout, stepTimings, parallel, timingReport, timingTracePath); err != nil {
+			return result.FromError(err)
+		}
 		switch {
 		case res != nil && res.Error() == context.Canceled:
 			return result.FromError(errors.New("update cancelled"))
@@ -450,9 +471,9 @@ func newUpCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
-			displayType := display.DisplayProgress
-			if diffDisplay {
-				displayType = display.DisplayDiff
+			displayType, err := displayTypeFromFlags(displayFlag, diffDisplay, jsonDisplay)
+			if err != nil {
+				return result.FromError(err)
 			}
 
 			opts.Display = display.Options{
@@ -495,6 +516,20 @@ func newUpCmd() *cobra.Command {
 				return runDeployment(ctx, opts.Display, apitype.Update, stackName, args[0], remoteArgs)
 			}
 
+			if scanSecrets || promoteSecrets {
+				secretScanner, err = newSecretScanner(secretScanConfigPath)
+				if err != nil {
+					return result.FromError(err)
+				}
+			}
+
+			if timingReport && jsonDisplay {
+				return result.FromError(errors.New("--timing-report cannot be used with --json"))
+			}
+			if timingReport || timingTracePath != "" {
+				stepTimings = deploy.NewStepTimings()
+			}
+
 			filestateBackend, err := isFilestateBackend(opts.Display)
 			if err != nil {
 				return result.FromError(err)
@@ -534,8 +569,8 @@ func newUpCmd() *cobra.Command {
 		"Config keys contain a path to a property in a map or list to set")
 	cmd.PersistentFlags().StringVar(
 		&secretsProvider, "secrets-provider", "default", "The type of the provider that should be used to encrypt and "+
-			"decrypt secrets (possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault). Only "+
-			"used when creating a new stack from an existing template")
+			"decrypt secrets (possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault, age, pgp). "+
+			"Only used when creating a new stack from an existing template")
 
 	cmd.PersistentFlags().StringVar(
 		&client, "client", "", "The address of an existing language runtime host to connect to")
@@ -572,6 +607,9 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&diffDisplay, "diff", false,
 		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().StringVar(
+		&displayFlag, "display", "",
+		"Display the operation as progress, diff or markdown (a summary for pull request comments)")
 	cmd.Flags().BoolVarP(
 		&jsonDisplay, "json", "j", false,
 		"Serialize the update diffs, operations, and overall output as JSON")
@@ -616,6 +654,23 @@ func newUpCmd() *cobra.Command {
 		&yes, "yes", "y", false,
 		"Automatically approve and perform the update after previewing it")
 
+	cmd.PersistentFlags().BoolVar(
+		&scanSecrets, "scan-secrets", false,
+		"Warn about resource outputs that look like secrets but are not marked as secret")
+	cmd.PersistentFlags().BoolVar(
+		&promoteSecrets, "promote-secrets", false,
+		"Mark resource outputs that look like secrets as secret before they are saved. Implies --scan-secrets")
+	cmd.PersistentFlags().StringVar(
+		&secretScanConfigPath, "secret-scan-config", "",
+		"Path to a YAML or JSON file that configures the detectors used by --scan-secrets")
+
+	cmd.PersistentFlags().BoolVar(
+		&timingReport, "timing-report", false,
+		"Print the slowest resources, the critical path and how much --parallel constrained the update after it finishes")
+	cmd.PersistentFlags().StringVar(
+		&timingTracePath, "timing-trace", "",
+		"Write the timings of the update's resource operations to a Chrome trace event file")
+
 	cmd.PersistentFlags().StringVar(
 		&planFilePath, "plan", "",
 		"[EXPERIMENTAL] Path to a plan file to use for the update. The update will not "+
@@ -45,8 +45,10 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/backend/state"
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/age"
 	"github.com/pulumi/pulumi/pkg/v3/secrets/cloud"
 	"github.com/pulumi/pulumi/pkg/v3/secrets/passphrase"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/pgp"
 	"github.com/pulumi/pulumi/pkg/v3/util/tracing"
 	"github.com/pulumi/pulumi/pkg/v3/version"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
@@ -228,6 +230,10 @@ func createSecretsManager(
 		_, err = stack.DefaultSecretManager(ps)
 	} else if secretsProvider == passphrase.Type {
 		_, err = passphrase.NewPromptingPassphraseSecretsManager(ps, rotateSecretsProvider)
+	} else if age.IsAgeSecretsProvider(secretsProvider) {
+		_, err = age.NewAgeSecretsManager(ps, secretsProvider, rotateSecretsProvider)
+	} else if pgp.IsPGPSecretsProvider(secretsProvider) {
+		_, err = pgp.NewPGPSecretsManager(ps, secretsProvider, rotateSecretsProvider)
 	} else {
 		// All other non-default secrets providers are handled by the cloud secrets provider which
 		// uses a URL schema to identify the provider
@@ -947,6 +953,35 @@ func updateFlagsToOptions(interactive, skipPreview, yes bool) (backend.UpdateOpt
 	}, nil
 }
 
+// displayTypeFromFlags returns the display type selected by the `--display` and `--diff` flags, or an error if the
+// combination is invalid.
+func displayTypeFromFlags(displayFlag string, diffDisplay, jsonDisplay bool) (display.Type, error) {
+	var displayType display.Type
+	switch displayFlag {
+	case "":
+		displayType = display.DisplayProgress
+		if diffDisplay {
+			displayType = display.DisplayDiff
+		}
+		return displayType, nil
+	case "progress":
+		displayType = display.DisplayProgress
+	case "diff":
+		displayType = display.DisplayDiff
+	case "markdown":
+		displayType = display.DisplayMarkdown
+		if jsonDisplay {
+			return 0, errors.New("--display=markdown cannot be used with --json")
+		}
+	default:
+		return 0, fmt.Errorf("unknown display '%s'; expected progress, diff or markdown", displayFlag)
+	}
+	if diffDisplay && displayType != display.DisplayDiff {
+		return 0, fmt.Errorf("--diff cannot be used with --display=%s", displayFlag)
+	}
+	return displayType, nil
+}
+
 func checkDeploymentVersionError(err error, stackName string) error {
 	switch err {
 	case stack.ErrDeploymentSchemaVersionTooOld:
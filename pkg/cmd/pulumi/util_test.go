@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	pul_testing "github.com/pulumi/pulumi/sdk/v3/go/common/testing"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/gitutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
@@ -463,3 +464,32 @@ func TestPulumiCLIMetadata(t *testing.T) {
 		"pulumi.env.PULUMI_DEPRECATED_FLAG": "set",
 	}, actualEnv)
 }
+
+func TestDisplayTypeFromFlags(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		display     string
+		diff, json  bool
+		expected    display.Type
+		expectedErr string
+	}{
+		{expected: display.DisplayProgress},
+		{diff: true, expected: display.DisplayDiff},
+		{display: "progress", expected: display.DisplayProgress},
+		{display: "diff", diff: true, expected: display.DisplayDiff},
+		{display: "markdown", expected: display.DisplayMarkdown},
+		{display: "markdown", diff: true, expectedErr: "--diff cannot be used with --display=markdown"},
+		{display: "markdown", json: true, expectedErr: "--display=markdown cannot be used with --json"},
+		{display: "html", expectedErr: "unknown display 'html'"},
+	}
+	for _, c := range cases {
+		actual, err := displayTypeFromFlags(c.display, c.diff, c.json)
+		if c.expectedErr != "" {
+			assert.ErrorContains(t, err, c.expectedErr)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, actual)
+	}
+}
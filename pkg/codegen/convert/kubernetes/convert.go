@@ -0,0 +1,600 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kubernetes converts Kubernetes manifests, such as those applied with kubectl or rendered by
+// `helm template`, into PCL programs that use the kubernetes package.
+package kubernetes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/cgstrings"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/model"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/syntax"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// customResourceToken is the token of the resource that manages objects whose kinds are not part of the
+// kubernetes package, such as instances of custom resource definitions.
+const customResourceToken = "kubernetes:apiextensions.k8s.io:CustomResource"
+
+// legacyGroups are the built-in API groups whose names predate the convention that built-in groups end in ".k8s.io".
+var legacyGroups = map[string]bool{
+	"apps":        true,
+	"autoscaling": true,
+	"batch":       true,
+	"extensions":  true,
+	"policy":      true,
+}
+
+// null represents PCL's `null` variable.
+var null = &model.Variable{
+	Name:         "null",
+	VariableType: model.NoneType,
+}
+
+// An object is a single Kubernetes object read from a manifest.
+type object struct {
+	node *yaml.Node // the mapping node that describes the object
+	file string
+
+	apiVersion string
+	kind       string
+	name       string
+	namespace  string
+
+	resource *schema.Resource
+	variable string // the name of the PCL resource that manages the object
+}
+
+// ConvertDirectory converts the Kubernetes manifests in sourceDirectory and its subdirectories into a PCL program
+// that is written to targetDirectory. Files with a .yaml or .yml extension are read as manifests if they describe at
+// least one Kubernetes object, with the exception of Pulumi project and stack files. This skips other YAML files,
+// such as Helm's values.yaml and Chart.yaml. The templates of Helm charts are skipped too, as they must be rendered
+// with `helm template` before they can be converted.
+func ConvertDirectory(loader schema.ReferenceLoader, sourceDirectory, targetDirectory string) (hcl.Diagnostics, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(sourceDirectory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != sourceDirectory && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if d.Name() == "templates" {
+				_, err := os.Stat(filepath.Join(filepath.Dir(path), "Chart.yaml"))
+				if err == nil {
+					return filepath.SkipDir
+				} else if !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+			}
+			return nil
+		}
+		if !isManifest(d.Name()) {
+			return nil
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !describesObjects(contents) {
+			return nil
+		}
+		rel, err := filepath.Rel(sourceDirectory, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = contents
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading manifests: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Kubernetes manifests found in %s", sourceDirectory)
+	}
+
+	blocks, diags, err := ConvertManifests(loader, files)
+	if err != nil {
+		return diags, err
+	}
+
+	var source bytes.Buffer
+	for i, block := range blocks {
+		if i > 0 {
+			source.WriteString("\n")
+		}
+		fmt.Fprintf(&source, "%v", block)
+	}
+	formatted, formatDiags := syntax.Format(source.Bytes(), "main.pp")
+	diags = append(diags, formatDiags...)
+	if formatDiags.HasErrors() {
+		return diags, errors.New("formatting program")
+	}
+
+	err = os.WriteFile(filepath.Join(targetDirectory, "main.pp"), formatted, 0o600)
+	if err != nil {
+		return diags, fmt.Errorf("writing program: %w", err)
+	}
+	return diags, nil
+}
+
+func isManifest(name string) bool {
+	ext := filepath.Ext(name)
+	if ext != ".yaml" && ext != ".yml" {
+		return false
+	}
+	base := strings.TrimSuffix(name, ext)
+	return base != "Pulumi" && !strings.HasPrefix(base, "Pulumi.")
+}
+
+// describesObjects returns true if any of the documents in a YAML file has both an apiVersion and a kind. Files that
+// cannot be parsed are assumed to describe objects, so that their errors are reported.
+func describesObjects(contents []byte) bool {
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return false
+		}
+		if err != nil {
+			return true
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		node := resolveAlias(doc.Content[0])
+		if scalarField(node, "apiVersion") != "" && scalarField(node, "kind") != "" {
+			return true
+		}
+	}
+}
+
+// ConvertManifests converts the Kubernetes objects described by the given YAML files into PCL resource definitions.
+// The files are keyed by name and may each hold several documents. Definitions are returned in the order that the
+// objects appear in the files, with the files taken in order of their names.
+//
+// References to ConfigMaps, Secrets, ServiceAccounts and Namespaces that are defined by the same manifests are
+// generated as references to the metadata of the resources that manage them.
+func ConvertManifests(loader schema.ReferenceLoader, files map[string][]byte) ([]*model.Block, hcl.Diagnostics, error) {
+	pkg, err := schema.LoadPackageReference(loader, "kubernetes", nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading kubernetes package: %w", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := &converter{pkg: pkg, variables: map[string]bool{}}
+	for _, name := range names {
+		if err := c.readFile(name, files[name]); err != nil {
+			return nil, c.diags, err
+		}
+	}
+
+	blocks := make([]*model.Block, 0, len(c.objects))
+	for _, obj := range c.objects {
+		block, err := c.generateObject(obj)
+		if err != nil {
+			return nil, c.diags, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, c.diags, nil
+}
+
+type converter struct {
+	pkg schema.PackageReference
+
+	objects   []*object
+	variables map[string]bool
+	diags     hcl.Diagnostics
+}
+
+func (c *converter) warnf(file string, node *yaml.Node, format string, args ...interface{}) {
+	c.diags = append(c.diags, &hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  fmt.Sprintf(format, args...),
+		Subject:  nodeRange(file, node),
+	})
+}
+
+func (c *converter) errorf(file string, node *yaml.Node, format string, args ...interface{}) {
+	c.diags = append(c.diags, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  fmt.Sprintf(format, args...),
+		Subject:  nodeRange(file, node),
+	})
+}
+
+func nodeRange(file string, node *yaml.Node) *hcl.Range {
+	pos := hcl.Pos{Line: node.Line, Column: node.Column}
+	return &hcl.Range{Filename: file, Start: pos, End: pos}
+}
+
+// readFile reads the objects in each of the documents in a file. Empty documents, such as those that Helm renders
+// for templates whose conditions are false, are skipped.
+func (c *converter) readFile(file string, contents []byte) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", file, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		node := resolveAlias(doc.Content[0])
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+			continue
+		}
+		c.readObject(file, node)
+	}
+}
+
+func (c *converter) readObject(file string, node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		c.warnf(file, node, "skipping document that does not describe a Kubernetes object")
+		return
+	}
+
+	apiVersion, kind := scalarField(node, "apiVersion"), scalarField(node, "kind")
+	if apiVersion == "" || kind == "" {
+		c.warnf(file, node, "skipping document that does not describe a Kubernetes object")
+		return
+	}
+
+	// Lists are flattened into their items.
+	if items := field(node, "items"); items != nil && strings.HasSuffix(kind, "List") {
+		if items.Kind != yaml.SequenceNode {
+			c.errorf(file, items, "the items of %s must be a list", kind)
+			return
+		}
+		for _, item := range items.Content {
+			c.readObject(file, resolveAlias(item))
+		}
+		return
+	}
+
+	obj := &object{node: node, file: file, apiVersion: apiVersion, kind: kind}
+	if metadata := field(node, "metadata"); metadata != nil {
+		obj.name = scalarField(metadata, "name")
+		if obj.name == "" {
+			obj.name = strings.TrimSuffix(scalarField(metadata, "generateName"), "-")
+		}
+		obj.namespace = scalarField(metadata, "namespace")
+	}
+
+	token := resourceToken(apiVersion, kind)
+	res, ok, err := c.pkg.Resources().Get(token)
+	if err == nil && !ok {
+		// An unknown version of a built-in kind is most likely one that has been removed from Kubernetes, e.g.
+		// extensions/v1beta1 Ingresses, rather than a custom resource.
+		if group := apiGroup(apiVersion); group == "core" || legacyGroups[group] || strings.HasSuffix(group, ".k8s.io") {
+			c.warnf(file, node, "%s is not a known version of the built-in API group %s, converting %s %s to a "+
+				"CustomResource; it may have been removed from Kubernetes", apiVersion, group, kind, obj.name)
+		}
+		res, ok, err = c.pkg.Resources().Get(customResourceToken)
+	}
+	switch {
+	case err != nil:
+		c.errorf(file, node, "loading resource %s: %v", token, err)
+		return
+	case !ok:
+		c.errorf(file, node, "unknown Kubernetes kind %s in %s", kind, apiVersion)
+		return
+	}
+	obj.resource = res
+	obj.variable = c.variableName(obj)
+	c.objects = append(c.objects, obj)
+}
+
+// resourceToken returns the token of the resource in the kubernetes package that manages objects with the given API
+// version and kind. Objects in the core group, whose API versions have no group, are in the "core" module.
+func resourceToken(apiVersion, kind string) string {
+	group, version, ok := strings.Cut(apiVersion, "/")
+	if !ok {
+		group, version = "core", apiVersion
+	}
+	return fmt.Sprintf("kubernetes:%s/%s:%s", group, version, kind)
+}
+
+// apiGroup returns the group of an API version, which is "core" for API versions that have no group.
+func apiGroup(apiVersion string) string {
+	if group, _, ok := strings.Cut(apiVersion, "/"); ok {
+		return group
+	}
+	return "core"
+}
+
+// variableName returns a unique PCL name for the resource that manages the given object. Names are derived from the
+// object's name and kind, e.g. "frontendDeployment" for a Deployment named "frontend". Objects that share a name and
+// kind in different namespaces are told apart by their namespaces.
+func (c *converter) variableName(obj *object) string {
+	name := cgstrings.Camel(strings.NewReplacer(".", "-", ":", "-", "_", "-").Replace(obj.name))
+	if !strings.HasSuffix(strings.ToLower(name), strings.ToLower(obj.kind)) {
+		name += obj.kind
+	}
+	if !hclsyntax.ValidIdentifier(name) {
+		name = cgstrings.Camel(obj.kind) + cgstrings.UppercaseFirst(name)
+	}
+	if !hclsyntax.ValidIdentifier(name) {
+		name = cgstrings.Camel(obj.kind)
+	}
+
+	if c.variables[name] && obj.namespace != "" {
+		name += cgstrings.UppercaseFirst(cgstrings.Camel(strings.ReplaceAll(obj.namespace, ".", "-")))
+	}
+	unique := name
+	for i := 2; c.variables[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	c.variables[unique] = true
+	return unique
+}
+
+func (c *converter) generateObject(obj *object) (*model.Block, error) {
+	custom := obj.resource.Token == customResourceToken
+
+	var items []model.BodyItem
+	for i := 0; i < len(obj.node.Content); i += 2 {
+		key, value := obj.node.Content[i], resolveAlias(obj.node.Content[i+1])
+
+		var typ schema.Type = schema.AnyType
+		if p, ok := propertyByName(obj.resource.InputProperties, key.Value); ok {
+			typ = p.Type
+		} else if key.Value == "status" {
+			// The status of an object is written by its controllers, never by its manifest.
+			continue
+		} else if !custom {
+			c.warnf(obj.file, key, "skipping unknown property %s of %s %s", key.Value, obj.kind, obj.name)
+			continue
+		}
+
+		x, err := c.generateValue(obj, value, typ, key.Value)
+		if err != nil {
+			return nil, err
+		}
+		if isSecretData(obj, key.Value) {
+			if data, ok := x.(*model.ObjectConsExpression); ok {
+				for i := range data.Items {
+					data.Items[i].Value = secretCall(data.Items[i].Value)
+				}
+			}
+		}
+		items = append(items, &model.Attribute{
+			Name:  key.Value,
+			Value: x,
+		})
+	}
+
+	token := obj.resource.Token
+	return &model.Block{
+		Tokens: syntax.NewBlockTokens("resource", obj.variable, token),
+		Type:   "resource",
+		Labels: []string{obj.variable, token},
+		Body: &model.Body{
+			Items: items,
+		},
+	}, nil
+}
+
+// isSecretData returns true if the named property of an object holds the values of a Secret.
+func isSecretData(obj *object, name string) bool {
+	return obj.apiVersion == "v1" && obj.kind == "Secret" && (name == "data" || name == "stringData")
+}
+
+// secretCall wraps an expression in a call to PCL's secret function.
+func secretCall(arg model.Expression) model.Expression {
+	return &model.FunctionCallExpression{
+		Name: "secret",
+		Signature: model.StaticFunctionSignature{
+			Parameters: []model.Parameter{{
+				Name: "value",
+				Type: arg.Type(),
+			}},
+			ReturnType: model.NewOutputType(arg.Type()),
+		},
+		Args: []model.Expression{arg},
+	}
+}
+
+// generateValue generates an expression for a YAML value of the given type. The name of the property or list that
+// holds the value is used to recognize references to other objects.
+func (c *converter) generateValue(obj *object, node *yaml.Node, typ schema.Type, key string) (model.Expression, error) {
+	typ = codegen.UnwrapType(typ)
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		elementType := schema.AnyType
+		if typ, ok := typ.(*schema.ArrayType); ok {
+			elementType = typ.ElementType
+		}
+
+		exprs := make([]model.Expression, len(node.Content))
+		for i, item := range node.Content {
+			x, err := c.generateValue(obj, resolveAlias(item), elementType, key)
+			if err != nil {
+				return nil, err
+			}
+			exprs[i] = x
+		}
+		return &model.TupleConsExpression{
+			Tokens:      syntax.NewTupleConsTokens(len(exprs)),
+			Expressions: exprs,
+		}, nil
+	case yaml.MappingNode:
+		return c.generateMapping(obj, node, typ, key)
+	case yaml.ScalarNode:
+		return c.generateScalar(obj, node, typ)
+	default:
+		return nil, fmt.Errorf("%s:%d: unexpected YAML node", obj.file, node.Line)
+	}
+}
+
+func (c *converter) generateMapping(obj *object, node *yaml.Node, typ schema.Type, key string,
+) (model.Expression, error) {
+	objectType, _ := typ.(*schema.ObjectType)
+	elementType := schema.AnyType
+	if mapType, ok := typ.(*schema.MapType); ok {
+		elementType = mapType.ElementType
+	}
+
+	items := make([]model.ObjectConsItem, 0, len(node.Content)/2)
+	for i := 0; i < len(node.Content); i += 2 {
+		k, v := node.Content[i], resolveAlias(node.Content[i+1])
+
+		var propKey string
+		var propType schema.Type
+		if objectType != nil {
+			p, ok := propertyByName(objectType.Properties, k.Value)
+			if !ok {
+				c.warnf(obj.file, k, "skipping unknown property %s of %s %s", k.Value, obj.kind, obj.name)
+				continue
+			}
+			propKey, propType = p.Name, p.Type
+		} else {
+			// Always quote the key in case it includes invalid identifier characters (like '/' or '.')
+			propKey, propType = fmt.Sprintf("%q", k.Value), elementType
+		}
+
+		var x model.Expression
+		if target := c.reference(obj, node, key, k.Value, v); target != nil {
+			x = target.nameReference()
+		} else {
+			var err error
+			x, err = c.generateValue(obj, v, propType, k.Value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		items = append(items, model.ObjectConsItem{
+			Key: &model.LiteralValueExpression{
+				Value: cty.StringVal(propKey),
+			},
+			Value: x,
+		})
+	}
+	return &model.ObjectConsExpression{
+		Tokens: syntax.NewObjectConsTokens(len(items)),
+		Items:  items,
+	}, nil
+}
+
+func (c *converter) generateScalar(obj *object, node *yaml.Node, typ schema.Type) (model.Expression, error) {
+	// Values that the schema requires to be strings are kept as written, e.g. an annotation of `true`.
+	if typ == schema.StringType && node.Tag != "!!null" {
+		return stringLiteral(node.Value), nil
+	}
+
+	switch node.Tag {
+	case "!!null":
+		return model.VariableReference(null), nil
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", obj.file, node.Line, err)
+		}
+		return &model.LiteralValueExpression{
+			Value: cty.BoolVal(b),
+		}, nil
+	case "!!int", "!!float":
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", obj.file, node.Line, err)
+		}
+		return &model.LiteralValueExpression{
+			Value: cty.NumberFloatVal(f),
+		}, nil
+	default:
+		return stringLiteral(node.Value), nil
+	}
+}
+
+func stringLiteral(s string) model.Expression {
+	return &model.TemplateExpression{
+		Parts: []model.Expression{
+			&model.LiteralValueExpression{
+				Value: cty.StringVal(s),
+			},
+		},
+	}
+}
+
+func propertyByName(properties []*schema.Property, name string) (*schema.Property, bool) {
+	for _, p := range properties {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// resolveAlias returns the node that an alias refers to, or the node itself if it is not an alias.
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// field returns the value of the named field of a mapping node, or nil if there is no such field.
+func field(node *yaml.Node, name string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == name {
+			return resolveAlias(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// scalarField returns the value of the named scalar field of a mapping node, or "" if there is no such field.
+func scalarField(node *yaml.Node, name string) string {
+	if v := field(node, name); v != nil && v.Kind == yaml.ScalarNode && v.Tag != "!!null" {
+		return v.Value
+	}
+	return ""
+}
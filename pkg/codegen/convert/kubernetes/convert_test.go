@@ -0,0 +1,324 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/dotnet"
+	gogen "github.com/pulumi/pulumi/pkg/v3/codegen/go"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/syntax"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/nodejs"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/python"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/testing/utils"
+)
+
+var testdataPath = filepath.Join("..", "..", "testing", "test", "testdata")
+
+func newLoader() schema.ReferenceLoader {
+	return schema.NewPluginLoader(utils.NewHost(testdataPath))
+}
+
+func convertText(t *testing.T, files map[string]string) (string, hcl.Diagnostics) {
+	sources := map[string][]byte{}
+	for name, contents := range files {
+		sources[name] = []byte(contents)
+	}
+	blocks, diags, err := ConvertManifests(newLoader(), sources)
+	require.NoError(t, err)
+
+	text := make([]string, len(blocks))
+	for i, block := range blocks {
+		text[i] = fmt.Sprintf("%v", block)
+	}
+	formatted, formatDiags := syntax.Format([]byte(strings.Join(text, "\n")), "main.pp")
+	require.False(t, formatDiags.HasErrors(), "%v", formatDiags)
+	return string(formatted), diags
+}
+
+func TestResourceToken(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "kubernetes:core/v1:ConfigMap", resourceToken("v1", "ConfigMap"))
+	assert.Equal(t, "kubernetes:apps/v1:Deployment", resourceToken("apps/v1", "Deployment"))
+	assert.Equal(t, "kubernetes:networking.k8s.io/v1:Ingress", resourceToken("networking.k8s.io/v1", "Ingress"))
+}
+
+func TestConvertManifests(t *testing.T) {
+	t.Parallel()
+
+	text, diags := convertText(t, map[string]string{
+		"b.yaml": `apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  serviceAccountName: app
+  containers:
+  - name: app
+    image: nginx
+    envFrom:
+    - configMapRef:
+        name: config
+    env:
+    - name: TOKEN
+      valueFrom:
+        secretKeyRef:
+          name: missing
+          key: token
+status:
+  phase: Running
+`,
+		"a.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  annotations:
+    enabled: true
+data:
+  PORT: "8080"
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: app
+`,
+	})
+	assert.Empty(t, diags)
+	assert.Equal(t, `resource configConfigMap "kubernetes:core/v1:ConfigMap" {
+	apiVersion = "v1"
+	kind = "ConfigMap"
+	metadata = {
+
+		name = "config",
+
+		annotations = {
+			"enabled" = "true" }
+	}
+	data = {
+		"PORT" = "8080" }
+
+}
+
+resource appServiceAccount "kubernetes:core/v1:ServiceAccount" {
+	apiVersion = "v1"
+	kind = "ServiceAccount"
+	metadata = {
+		name = "app" }
+
+}
+
+resource appPod "kubernetes:core/v1:Pod" {
+	apiVersion = "v1"
+	kind = "Pod"
+	metadata = {
+		name = "app" }
+	spec = {
+
+		serviceAccountName = appServiceAccount.metadata.name,
+
+		containers = [{
+
+			name = "app",
+
+			image = "nginx",
+
+			envFrom = [{
+				configMapRef = {
+					name = configConfigMap.metadata.name } }],
+
+			env = [{
+
+				name = "TOKEN",
+
+				valueFrom = {
+					secretKeyRef = {
+
+						name = "missing",
+
+						key = "token"
+					} }
+			}]
+		}]
+	}
+
+}
+`, text)
+}
+
+func TestConvertManifestsNamespaces(t *testing.T) {
+	t.Parallel()
+
+	// References are only resolved within a namespace, and objects with the same name and kind are told apart by
+	// their namespaces.
+	text, diags := convertText(t, map[string]string{
+		"main.yaml": `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: prod
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    name: creds
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    name: creds
+    namespace: prod
+- apiVersion: v1
+  kind: ServiceAccount
+  metadata:
+    name: deployer
+    namespace: prod
+  imagePullSecrets:
+  - name: creds
+`,
+	})
+	assert.Empty(t, diags)
+	assert.Contains(t, text, `resource credsSecret "kubernetes:core/v1:Secret" {`)
+	assert.Contains(t, text, `resource credsSecretProd "kubernetes:core/v1:Secret" {`)
+	assert.Contains(t, text, `namespace = prodNamespace.metadata.name`)
+	assert.Contains(t, text, `imagePullSecrets = [{
+		name = credsSecretProd.metadata.name }]`)
+}
+
+func TestConvertManifestsDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	text, diags := convertText(t, map[string]string{
+		"main.yaml": `replicaCount: 2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  owner: someone
+data:
+  key: value
+`,
+		"crd.yaml": `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+`,
+	})
+	require.Len(t, diags, 3)
+	assert.Equal(t, hcl.DiagError, diags[0].Severity)
+	assert.Equal(t, "unknown Kubernetes kind Widget in example.com/v1", diags[0].Summary)
+	assert.Equal(t, "crd.yaml", diags[0].Subject.Filename)
+	assert.Equal(t, hcl.DiagWarning, diags[1].Severity)
+	assert.Equal(t, "skipping document that does not describe a Kubernetes object", diags[1].Summary)
+	assert.Equal(t, hcl.DiagWarning, diags[2].Severity)
+	assert.Equal(t, "skipping unknown property owner of ConfigMap config", diags[2].Summary)
+	assert.Equal(t, 7, diags[2].Subject.Start.Line)
+
+	assert.NotContains(t, text, "owner")
+	assert.Contains(t, text, `resource configConfigMap "kubernetes:core/v1:ConfigMap" {`)
+}
+
+func TestConvertManifestsRemovedVersion(t *testing.T) {
+	t.Parallel()
+
+	// An unknown version of a built-in group is reported before falling back to CustomResource, which the test
+	// schema does not have.
+	_, diags := convertText(t, map[string]string{
+		"main.yaml": `apiVersion: flowcontrol.apiserver.k8s.io/v1beta3
+kind: FlowSchema
+metadata:
+  name: service-accounts
+`,
+	})
+	require.Len(t, diags, 2)
+	assert.Equal(t, hcl.DiagWarning, diags[0].Severity)
+	assert.Equal(t, "flowcontrol.apiserver.k8s.io/v1beta3 is not a known version of the built-in API group "+
+		"flowcontrol.apiserver.k8s.io, converting FlowSchema service-accounts to a CustomResource; it may have been "+
+		"removed from Kubernetes", diags[0].Summary)
+	assert.Equal(t, "main.yaml", diags[0].Subject.Filename)
+	assert.Equal(t, hcl.DiagError, diags[1].Severity)
+
+	assert.Equal(t, "core", apiGroup("v1"))
+	assert.Equal(t, "apps", apiGroup("apps/v1beta1"))
+}
+
+// A rendered Helm chart must convert into a program that binds and that each language can generate.
+func TestConvertDirectory(t *testing.T) {
+	t.Parallel()
+
+	loader := newLoader()
+	dir := t.TempDir()
+	diags, err := ConvertDirectory(loader, filepath.Join("testdata", "app"), dir)
+	require.NoError(t, err)
+	// values.yaml does not describe any objects, Pulumi.yaml is ignored altogether, and the templates of the chart in
+	// chart/ have not been rendered.
+	assert.Empty(t, diags)
+
+	source, err := os.ReadFile(filepath.Join(dir, "main.pp"))
+	require.NoError(t, err)
+	for _, ref := range []string{
+		"namespace = webNamespace.metadata.name",
+		"serviceAccountName = appServiceAccount.metadata.name",
+		"name = appConfigConfigMap.metadata.name",
+		"name = appSecret.metadata.name",
+		`name = "external-secret"`,
+		`name = "view"`,
+	} {
+		assert.Contains(t, string(source), ref)
+	}
+	assert.Contains(t, string(source), `subjects = [{
+
+		kind = "ServiceAccount",
+
+		name = appServiceAccount.metadata.name,`)
+	assert.NotContains(t, string(source), "status")
+	assert.NotContains(t, string(source), "Release")
+	assert.Contains(t, string(source), `"password" = secret("hunter2")`)
+
+	program, bindDiags, err := pcl.BindDirectory(dir, loader)
+	require.NoError(t, err)
+	require.False(t, bindDiags.HasErrors(), "%v", bindDiags)
+
+	generators := map[string]func(*pcl.Program) (map[string][]byte, hcl.Diagnostics, error){
+		"nodejs": nodejs.GenerateProgram,
+		"python": python.GenerateProgram,
+		"go":     gogen.GenerateProgram,
+		"dotnet": dotnet.GenerateProgram,
+	}
+	for language, generate := range generators {
+		_, diags, err := generate(program)
+		require.NoError(t, err, language)
+		assert.False(t, diags.HasErrors(), "%s: %v", language, diags)
+	}
+}
+
+func TestConvertDirectoryEmpty(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	_, err := ConvertDirectory(newLoader(), dir, dir)
+	assert.ErrorContains(t, err, "no Kubernetes manifests found")
+}
@@ -0,0 +1,97 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/model"
+)
+
+// referencedKind returns the kind of the object that a field refers to by name, or "" if the field is not a
+// reference. The field is identified by its own name and by the name of the property or list that holds the mapping
+// it is part of, e.g. the "name" of a "configMapKeyRef" or the "secretName" of a "secret" volume.
+func referencedKind(parent *yaml.Node, parentKey, key string) string {
+	switch key {
+	case "name":
+		switch parentKey {
+		case "configMap", "configMapRef", "configMapKeyRef":
+			return "ConfigMap"
+		case "secret", "secretRef", "secretKeyRef", "imagePullSecrets":
+			return "Secret"
+		case "subjects":
+			if scalarField(parent, "kind") == "ServiceAccount" {
+				return "ServiceAccount"
+			}
+		}
+	case "secretName":
+		if parentKey == "secret" || parentKey == "tls" {
+			return "Secret"
+		}
+	case "serviceAccountName", "serviceAccount":
+		return "ServiceAccount"
+	case "namespace":
+		return "Namespace"
+	}
+	return ""
+}
+
+// reference returns the object that the given field of a mapping refers to, or nil if the field is not a reference
+// to one of the converted objects.
+func (c *converter) reference(obj *object, parent *yaml.Node, parentKey, key string, value *yaml.Node) *object {
+	if value.Kind != yaml.ScalarNode || value.Tag != "!!str" {
+		return nil
+	}
+	kind := referencedKind(parent, parentKey, key)
+	if kind == "" {
+		return nil
+	}
+
+	// Namespaces are cluster-scoped. Other objects are looked up in the namespace of the referring object, except for
+	// the subjects of role bindings, which name their own.
+	namespace := ""
+	if kind != "Namespace" {
+		namespace = obj.namespace
+		if parentKey == "subjects" {
+			namespace = scalarField(parent, "namespace")
+		}
+	}
+
+	for _, target := range c.objects {
+		if target != obj && target.kind == kind && target.name == value.Value && target.namespace == namespace &&
+			target.apiVersion == "v1" {
+			return target
+		}
+	}
+	return nil
+}
+
+// nameReference returns an expression that refers to the name of the object, as set by the resource that manages it.
+func (obj *object) nameReference() model.Expression {
+	return &model.ScopeTraversalExpression{
+		RootName: obj.variable,
+		Traversal: hcl.Traversal{
+			hcl.TraverseRoot{Name: obj.variable},
+			hcl.TraverseAttr{Name: "metadata"},
+			hcl.TraverseAttr{Name: "name"},
+		},
+		Parts: []model.Traversable{
+			&model.Variable{Name: obj.variable, VariableType: model.DynamicType},
+			model.DynamicType,
+			model.DynamicType,
+		},
+	}
+}
@@ -0,0 +1,573 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docs
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// markdownSnippetLanguages are the languages whose examples are shown on standalone pages, in order, along with
+// their display names.
+var markdownSnippetLanguages = []struct{ name, title string }{
+	{"typescript", "TypeScript"},
+	{"python", "Python"},
+	{"go", "Go"},
+	{"csharp", "C#"},
+	{"java", "Java"},
+	{"yaml", "YAML"},
+}
+
+// markdownModule is a module of a package as laid out in a standalone documentation tree.
+type markdownModule struct {
+	name      string
+	resources []*schema.Resource
+	functions []*schema.Function
+	types     []schema.Type
+	children  []string
+}
+
+type markdownGenerator struct {
+	dctx    *docGenContext
+	pkg     *schema.Package
+	modules map[string]*markdownModule
+
+	// pages maps each module name, resource and function to the path of its page.
+	pages map[interface{}]string
+	// typeLinks maps each type token to the path of its page and its anchor on that page.
+	typeLinks map[string]string
+}
+
+// GenerateMarkdown generates a self-contained tree of Markdown documentation for a package, for hosting outside of
+// pulumi.com. The tree has an index page for the package and for each of its modules, a page for each resource and
+// function with examples in every language, and a page of each module's types. Pages link to each other with relative
+// links. The returned map contains the filename with path as the key and the contents as its value.
+func GenerateMarkdown(tool string, pkg *schema.Package) (map[string][]byte, error) {
+	dctx := newDocGenContext()
+	g := &markdownGenerator{
+		dctx:      dctx,
+		pkg:       pkg,
+		modules:   map[string]*markdownModule{},
+		pages:     map[interface{}]string{},
+		typeLinks: map[string]string{},
+	}
+
+	for name, mod := range dctx.generateModulesFromSchemaPackage(tool, pkg) {
+		m := g.module(name)
+		m.resources = append(m.resources, mod.resources...)
+		m.functions = append(m.functions, mod.functions...)
+	}
+	for _, t := range pkg.Types {
+		switch t := t.(type) {
+		case *schema.ObjectType:
+			if !t.IsInputShape() {
+				m := g.module(pkg.TokenToModule(t.Token))
+				m.types = append(m.types, t)
+			}
+		case *schema.EnumType:
+			m := g.module(pkg.TokenToModule(t.Token))
+			m.types = append(m.types, t)
+		}
+	}
+	g.layout()
+
+	files := map[string][]byte{}
+	for _, name := range g.moduleNames() {
+		m := g.modules[name]
+		files[g.pages[name]] = []byte(g.modulePage(m))
+		for _, r := range m.resources {
+			files[g.pages[r]] = []byte(g.resourcePage(m, r))
+		}
+		for _, f := range m.functions {
+			files[g.pages[f]] = []byte(g.functionPage(m, f))
+		}
+		if len(m.types) != 0 {
+			files[g.typesPage(m.name)] = []byte(g.typesPageContent(m))
+		}
+	}
+	return files, nil
+}
+
+// module returns the module with the given name, creating it and its parents if needed.
+func (g *markdownGenerator) module(name string) *markdownModule {
+	if m, ok := g.modules[name]; ok {
+		return m
+	}
+	m := &markdownModule{name: name}
+	g.modules[name] = m
+	if name != "" {
+		parent := path.Dir(name)
+		if parent == "." {
+			parent = ""
+		}
+		p := g.module(parent)
+		p.children = append(p.children, name)
+	}
+	return m
+}
+
+func (g *markdownGenerator) moduleNames() []string {
+	names := make([]string, 0, len(g.modules))
+	for name := range g.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// layout sorts the members of each module and assigns a page to each of them. Each module is a directory with an
+// index page, a page for each resource and function, and a page for its types.
+func (g *markdownGenerator) layout() {
+	for _, name := range g.moduleNames() {
+		m := g.modules[name]
+		sort.Strings(m.children)
+		sort.Slice(m.resources, func(i, j int) bool {
+			return resourceName(m.resources[i]) < resourceName(m.resources[j])
+		})
+		sort.Slice(m.functions, func(i, j int) bool {
+			return m.functions[i].Token < m.functions[j].Token
+		})
+		sort.Slice(m.types, func(i, j int) bool {
+			return markdownTypeToken(m.types[i]) < markdownTypeToken(m.types[j])
+		})
+
+		g.pages[name] = path.Join(name, "index.md")
+		used := map[string]bool{"index": true, "types": true}
+		page := func(member string) string {
+			file := strings.ToLower(member)
+			for i := 2; used[file]; i++ {
+				file = fmt.Sprintf("%s-%d", strings.ToLower(member), i)
+			}
+			used[file] = true
+			return path.Join(name, file+".md")
+		}
+		for _, r := range m.resources {
+			g.pages[r] = page(resourceName(r))
+		}
+		for _, f := range m.functions {
+			g.pages[f] = page(tokenToName(f.Token))
+		}
+		for _, t := range m.types {
+			tok := markdownTypeToken(t)
+			g.typeLinks[tok] = g.typesPage(name) + "#" + strings.ToLower(tokenToName(tok))
+		}
+	}
+}
+
+func (g *markdownGenerator) typesPage(module string) string {
+	return path.Join(module, "types.md")
+}
+
+// link returns a relative link from the page at from to the page, and optional anchor, at to.
+func (g *markdownGenerator) link(from, to string) string {
+	target, anchor, _ := strings.Cut(to, "#")
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(target))
+	if err != nil {
+		rel = target
+	}
+	rel = filepath.ToSlash(rel)
+	if anchor != "" {
+		rel += "#" + anchor
+	}
+	return rel
+}
+
+func (g *markdownGenerator) title() string {
+	if g.pkg.DisplayName != "" {
+		return g.pkg.DisplayName
+	}
+	return getPackageDisplayName(g.pkg.Name)
+}
+
+// breadcrumbs returns links to the package index and to each module enclosing the page at from.
+func (g *markdownGenerator) breadcrumbs(from, module string, self bool) string {
+	crumbs := []string{fmt.Sprintf("[%s](%s)", g.title(), g.link(from, g.pages[""]))}
+	if module != "" {
+		parts := strings.Split(module, "/")
+		for i := range parts {
+			name := strings.Join(parts[:i+1], "/")
+			crumbs = append(crumbs, fmt.Sprintf("[%s](%s)", parts[i], g.link(from, g.pages[name])))
+		}
+	}
+	if self {
+		crumbs = crumbs[:len(crumbs)-1]
+		if len(crumbs) == 0 {
+			return ""
+		}
+	}
+	return strings.Join(crumbs, " / ") + "\n\n"
+}
+
+func (g *markdownGenerator) modulePage(m *markdownModule) string {
+	from := g.pages[m.name]
+	var b strings.Builder
+	b.WriteString(g.breadcrumbs(from, m.name, true))
+
+	if m.name == "" {
+		fmt.Fprintf(&b, "# %s\n\n", g.title())
+		if g.pkg.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(g.pkg.Description))
+		}
+		var details []string
+		if g.pkg.Version != nil {
+			details = append(details, fmt.Sprintf("- Version: %s", g.pkg.Version))
+		}
+		if g.pkg.Repository != "" {
+			details = append(details, fmt.Sprintf("- Repository: <%s>", g.pkg.Repository))
+		}
+		if g.pkg.License != "" {
+			details = append(details, fmt.Sprintf("- License: %s", g.pkg.License))
+		}
+		if len(details) != 0 {
+			fmt.Fprintf(&b, "%s\n\n", strings.Join(details, "\n"))
+		}
+	} else {
+		fmt.Fprintf(&b, "# Module %s\n\n", m.name)
+	}
+
+	if len(m.children) != 0 {
+		b.WriteString("## Modules\n\n")
+		for _, child := range m.children {
+			fmt.Fprintf(&b, "- [%s](%s)\n", path.Base(child), g.link(from, g.pages[child]))
+		}
+		b.WriteString("\n")
+	}
+	if len(m.resources) != 0 {
+		b.WriteString("## Resources\n\n")
+		for _, r := range m.resources {
+			fmt.Fprintf(&b, "- [%s](%s)%s\n", resourceName(r), g.link(from, g.pages[r]), summary(r.Comment))
+		}
+		b.WriteString("\n")
+	}
+	if len(m.functions) != 0 {
+		b.WriteString("## Functions\n\n")
+		for _, f := range m.functions {
+			fmt.Fprintf(&b, "- [%s](%s)%s\n", tokenToName(f.Token), g.link(from, g.pages[f]), summary(f.Comment))
+		}
+		b.WriteString("\n")
+	}
+	if len(m.types) != 0 {
+		b.WriteString("## Types\n\n")
+		for _, t := range m.types {
+			tok := markdownTypeToken(t)
+			fmt.Fprintf(&b, "- [%s](%s)\n", tokenToName(tok), g.link(from, g.typeLinks[tok]))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (g *markdownGenerator) resourcePage(m *markdownModule, r *schema.Resource) string {
+	from := g.pages[r]
+	info := g.dctx.decomposeDocstring(r.Comment)
+
+	var b strings.Builder
+	b.WriteString(g.breadcrumbs(from, m.name, false))
+	fmt.Fprintf(&b, "# %s\n\n", resourceName(r))
+	writeDeprecation(&b, "", r.DeprecationMessage)
+	if description := strings.TrimSpace(info.description); description != "" {
+		fmt.Fprintf(&b, "%s\n\n", description)
+	}
+	fmt.Fprintf(&b, "Type token: `%s`\n\n", r.Token)
+	writeExamples(&b, info.examples)
+
+	b.WriteString("## Inputs\n\n")
+	g.writeProperties(&b, from, r.InputProperties)
+
+	var outputs []*schema.Property
+	if !r.IsProvider {
+		outputs = filterOutputProperties(r.InputProperties, r.Properties)
+	}
+	if !r.IsComponent {
+		outputs = append(outputs, &schema.Property{
+			Name:    "id",
+			Comment: "The provider-assigned unique ID for this managed resource.",
+			Type:    schema.StringType,
+		})
+	}
+	b.WriteString("## Outputs\n\n")
+	b.WriteString("All input properties are also available as outputs, as are the following properties.\n\n")
+	g.writeProperties(&b, from, outputs)
+
+	if len(r.Methods) != 0 {
+		b.WriteString("## Methods\n\n")
+		for _, method := range r.Methods {
+			fmt.Fprintf(&b, "### %s\n\n", method.Name)
+			g.writeFunctionBody(&b, from, method.Function, "####")
+		}
+	}
+
+	if details := strings.TrimSpace(info.importDetails); details != "" {
+		fmt.Fprintf(&b, "## Import\n\n%s\n", details)
+	}
+	return b.String()
+}
+
+func (g *markdownGenerator) functionPage(m *markdownModule, f *schema.Function) string {
+	from := g.pages[f]
+	var b strings.Builder
+	b.WriteString(g.breadcrumbs(from, m.name, false))
+	fmt.Fprintf(&b, "# %s\n\n", tokenToName(f.Token))
+	fmt.Fprintf(&b, "Type token: `%s`\n\n", f.Token)
+	g.writeFunctionBody(&b, from, f, "##")
+	return b.String()
+}
+
+// writeFunctionBody writes the description, examples, arguments and result of a function or method, with sections
+// headed at the given level.
+func (g *markdownGenerator) writeFunctionBody(b *strings.Builder, from string, f *schema.Function, level string) {
+	info := g.dctx.decomposeDocstring(f.Comment)
+	writeDeprecation(b, "", f.DeprecationMessage)
+	if description := strings.TrimSpace(info.description); description != "" {
+		fmt.Fprintf(b, "%s\n\n", description)
+	}
+	writeExamples(b, info.examples)
+
+	var args []*schema.Property
+	if f.Inputs != nil {
+		for _, p := range f.Inputs.Properties {
+			// Methods take the resource they are called on as their first argument.
+			if f.IsMethod && p.Name == "__self__" {
+				continue
+			}
+			args = append(args, p)
+		}
+	}
+	fmt.Fprintf(b, "%s Arguments\n\n", level)
+	g.writeProperties(b, from, args)
+
+	if f.ReturnType != nil {
+		fmt.Fprintf(b, "%s Result\n\n", level)
+		if o, ok := f.ReturnType.(*schema.ObjectType); ok && f.Outputs == o {
+			g.writeProperties(b, from, o.Properties)
+		} else {
+			fmt.Fprintf(b, "%s\n\n", g.typeString(from, f.ReturnType))
+		}
+	}
+}
+
+func (g *markdownGenerator) typesPageContent(m *markdownModule) string {
+	from := g.typesPage(m.name)
+	var b strings.Builder
+	b.WriteString(g.breadcrumbs(from, m.name, false))
+	if m.name == "" {
+		b.WriteString("# Types\n\n")
+	} else {
+		fmt.Fprintf(&b, "# Types in %s\n\n", m.name)
+	}
+
+	for _, t := range m.types {
+		tok := markdownTypeToken(t)
+		name := tokenToName(tok)
+		fmt.Fprintf(&b, "<a id=\"%s\"></a>\n\n", strings.ToLower(name))
+		switch t := t.(type) {
+		case *schema.ObjectType:
+			fmt.Fprintf(&b, "## %s\n\n", name)
+			if t.Comment != "" {
+				fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(t.Comment))
+			}
+			g.writeProperties(&b, from, t.Properties)
+		case *schema.EnumType:
+			fmt.Fprintf(&b, "## %s (enum of %s)\n\n", name, g.typeString(from, t.ElementType))
+			if t.Comment != "" {
+				fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(t.Comment))
+			}
+			for _, e := range t.Elements {
+				fmt.Fprintf(&b, "- `%#v`", e.Value)
+				if e.Name != "" {
+					fmt.Fprintf(&b, " (%s)", e.Name)
+				}
+				if e.Comment != "" {
+					fmt.Fprintf(&b, ": %s", indent(strings.TrimSpace(e.Comment)))
+				}
+				b.WriteString("\n")
+				if e.DeprecationMessage != "" {
+					fmt.Fprintf(&b, "\n  > **Deprecated:** %s\n\n", e.DeprecationMessage)
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func (g *markdownGenerator) writeProperties(b *strings.Builder, from string, properties []*schema.Property) {
+	if len(properties) == 0 {
+		b.WriteString("None.\n\n")
+		return
+	}
+
+	sorted := make([]*schema.Property, len(properties))
+	copy(sorted, properties)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		// Required properties come first.
+		if sorted[i].IsRequired() != sorted[j].IsRequired() {
+			return sorted[i].IsRequired()
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	for _, p := range sorted {
+		var attrs []string
+		attrs = append(attrs, g.typeString(from, p.Type))
+		if p.IsRequired() {
+			attrs = append(attrs, "required")
+		}
+		if p.Secret {
+			attrs = append(attrs, "secret")
+		}
+		if p.DefaultValue != nil && p.DefaultValue.Value != nil {
+			attrs = append(attrs, fmt.Sprintf("default `%v`", p.DefaultValue.Value))
+		}
+		fmt.Fprintf(b, "- **%s** (%s)\n", p.Name, strings.Join(attrs, ", "))
+		if comment := strings.TrimSpace(p.Comment); comment != "" {
+			fmt.Fprintf(b, "\n  %s\n", indent(comment))
+		}
+		if p.DeprecationMessage != "" {
+			b.WriteString("\n")
+			writeDeprecation(b, "  ", p.DeprecationMessage)
+		}
+	}
+	b.WriteString("\n")
+}
+
+// typeString describes a type, linking to the pages of the package's types and resources.
+func (g *markdownGenerator) typeString(from string, t schema.Type) string {
+	switch t := t.(type) {
+	case *schema.OptionalType:
+		return g.typeString(from, t.ElementType)
+	case *schema.InputType:
+		return g.typeString(from, t.ElementType)
+	case *schema.ArrayType:
+		return "list of " + g.typeString(from, t.ElementType)
+	case *schema.MapType:
+		return "map of " + g.typeString(from, t.ElementType)
+	case *schema.UnionType:
+		elements := make([]string, len(t.ElementTypes))
+		for i, e := range t.ElementTypes {
+			elements[i] = g.typeString(from, e)
+		}
+		return strings.Join(elements, " or ")
+	case *schema.ObjectType:
+		if t.PlainShape != nil {
+			return g.typeString(from, t.PlainShape)
+		}
+		return g.tokenLink(from, t.Token, g.typeLinks[t.Token])
+	case *schema.EnumType:
+		return g.tokenLink(from, t.Token, g.typeLinks[t.Token])
+	case *schema.ResourceType:
+		if t.Resource != nil {
+			if page, ok := g.pages[t.Resource]; ok {
+				return g.tokenLink(from, t.Token, page)
+			}
+		}
+		return g.tokenLink(from, t.Token, "")
+	case *schema.TokenType:
+		if t.UnderlyingType != nil {
+			return g.typeString(from, t.UnderlyingType)
+		}
+		return g.tokenLink(from, t.Token, "")
+	}
+
+	switch t {
+	case schema.BoolType:
+		return "boolean"
+	case schema.IntType:
+		return "integer"
+	case schema.NumberType:
+		return "number"
+	case schema.StringType:
+		return "string"
+	case schema.ArchiveType:
+		return "Archive"
+	case schema.AssetType:
+		return "Asset"
+	case schema.JSONType:
+		return "JSON"
+	default:
+		return "any"
+	}
+}
+
+// tokenLink links to the page of a type or resource, or names its token if it is not part of this package.
+func (g *markdownGenerator) tokenLink(from, token, to string) string {
+	if to == "" {
+		return "`" + token + "`"
+	}
+	return fmt.Sprintf("[%s](%s)", tokenToName(token), g.link(from, to))
+}
+
+func writeExamples(b *strings.Builder, examples []exampleSection) {
+	if len(examples) == 0 {
+		return
+	}
+
+	b.WriteString("## Example Usage\n\n")
+	for _, example := range examples {
+		// Titles taken from headings keep their heading markup.
+		if title := strings.TrimSpace(strings.TrimLeft(example.Title, "#")); title != "" {
+			fmt.Fprintf(b, "### %s\n\n", title)
+		}
+		for _, lang := range markdownSnippetLanguages {
+			snippet, ok := example.Snippets[lang.name]
+			if !ok || snippet == defaultMissingExampleSnippetPlaceholder {
+				continue
+			}
+			fmt.Fprintf(b, "#### %s\n\n%s\n\n", lang.title, strings.TrimSpace(snippet))
+		}
+	}
+}
+
+func writeDeprecation(b *strings.Builder, prefix, message string) {
+	if message != "" {
+		fmt.Fprintf(b, "%s> **Deprecated:** %s\n\n", prefix, strings.TrimSpace(message))
+	}
+}
+
+// summary returns the first sentence of a description, for lists of resources and functions.
+func summary(comment string) string {
+	comment = strings.TrimSpace(comment)
+	if i := strings.Index(comment, "\n"); i != -1 {
+		comment = comment[:i]
+	}
+	if i := strings.Index(comment, ". "); i != -1 {
+		comment = comment[:i+1]
+	}
+	if comment == "" || strings.HasPrefix(comment, "{{") {
+		return ""
+	}
+	return ": " + comment
+}
+
+// indent indents each line after the first so that it continues a list item.
+func indent(s string) string {
+	return strings.ReplaceAll(s, "\n", "\n  ")
+}
+
+func markdownTypeToken(t schema.Type) string {
+	switch t := t.(type) {
+	case *schema.ObjectType:
+		return t.Token
+	case *schema.EnumType:
+		return t.Token
+	default:
+		return t.String()
+	}
+}
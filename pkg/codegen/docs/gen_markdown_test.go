@@ -0,0 +1,134 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docs
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+const markdownTestSchema = `{
+	"name": "acme",
+	"displayName": "Acme",
+	"version": "1.2.3",
+	"description": "Manage Acme resources.",
+	"meta": {"moduleFormat": "(.*)(?:/[^/]*)"},
+	"resources": {
+		"acme:storage/bucket:Bucket": {
+			"description": "A storage bucket. Buckets hold objects.\n\n{{% examples %}}\n## Example Usage\n` +
+	`{{% example %}}\n### Basic bucket\n\n` +
+	"```typescript\\nnew acme.storage.Bucket(\\\"b\\\");\\n```\\n\\n```python\\nacme.storage.Bucket(\\\"b\\\")\\n```" +
+	`\n{{% /example %}}\n{{% /examples %}}\n\n## Import\n\n` +
+	"```sh\\npulumi import acme:storage/bucket:Bucket b b-123\\n```" + `",
+			"inputProperties": {
+				"name": {"type": "string", "description": "The bucket's name."},
+				"tier": {"$ref": "#/types/acme:storage/bucket:Tier"},
+				"rules": {"type": "array", "items": {"$ref": "#/types/acme:storage/bucket:Rule"}},
+				"acl": {"type": "string", "deprecationMessage": "Use policy instead."}
+			},
+			"requiredInputs": ["name"],
+			"properties": {
+				"name": {"type": "string"},
+				"arn": {"type": "string", "description": "The bucket's ARN."}
+			},
+			"required": ["name", "arn"]
+		},
+		"acme:index:Account": {}
+	},
+	"functions": {
+		"acme:storage/getBucket:getBucket": {
+			"description": "Look up a bucket.",
+			"inputs": {"properties": {"name": {"type": "string"}}, "required": ["name"]},
+			"outputs": {"properties": {"bucket": {"$ref": "#/resources/acme:storage/bucket:Bucket"}}}
+		}
+	},
+	"types": {
+		"acme:storage/bucket:Tier": {
+			"type": "string",
+			"enum": [{"name": "Hot", "value": "hot", "description": "Read often."}, {"value": "cold"}]
+		},
+		"acme:storage/bucket:Rule": {
+			"type": "object",
+			"properties": {"days": {"type": "integer", "default": 30}}
+		}
+	}
+}`
+
+func TestGenerateMarkdown(t *testing.T) {
+	t.Parallel()
+
+	var spec schema.PackageSpec
+	require.NoError(t, json.Unmarshal([]byte(markdownTestSchema), &spec))
+	pkg, diags, err := schema.BindSpec(spec, nil)
+	require.NoError(t, err)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	files, err := GenerateMarkdown(unitTestTool, pkg)
+	require.NoError(t, err)
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{
+		"account.md",
+		"index.md",
+		"provider.md",
+		"storage/bucket.md",
+		"storage/getbucket.md",
+		"storage/index.md",
+		"storage/types.md",
+	}, names)
+
+	index := string(files["index.md"])
+	assert.Contains(t, index, "# Acme\n\nManage Acme resources.\n\n- Version: 1.2.3\n")
+	assert.Contains(t, index, "## Modules\n\n- [storage](storage/index.md)\n")
+	assert.Contains(t, index, "- [Account](account.md)\n")
+
+	module := string(files["storage/index.md"])
+	assert.Contains(t, module, "[Acme](../index.md)\n\n# Module storage\n")
+	assert.Contains(t, module, "- [Bucket](bucket.md): A storage bucket.\n")
+	assert.Contains(t, module, "- [getBucket](getbucket.md): Look up a bucket.\n")
+	assert.Contains(t, module, "- [Rule](types.md#rule)\n")
+
+	bucket := string(files["storage/bucket.md"])
+	assert.Contains(t, bucket, "[Acme](../index.md) / [storage](index.md)\n\n# Bucket\n\nA storage bucket.")
+	assert.Contains(t, bucket, "## Example Usage\n\n### Basic bucket\n\n#### TypeScript\n\n```typescript\n")
+	assert.Contains(t, bucket, "#### Python\n\n```python\nacme.storage.Bucket(\"b\")\n```")
+	assert.NotContains(t, bucket, "{{%")
+	assert.NotContains(t, bucket, "#### Go")
+	assert.Contains(t, bucket, "- **name** (string, required)\n\n  The bucket's name.\n")
+	assert.Contains(t, bucket, "- **rules** (list of [Rule](types.md#rule))\n")
+	assert.Contains(t, bucket, "- **tier** ([Tier](types.md#tier))\n")
+	assert.Contains(t, bucket, "- **acl** (string)\n\n  > **Deprecated:** Use policy instead.\n")
+	assert.Contains(t, bucket, "- **arn** (string, required)\n\n  The bucket's ARN.\n")
+	assert.Contains(t, bucket, "- **id** (string, required)\n")
+	assert.Contains(t, bucket, "## Import\n\n```sh\npulumi import acme:storage/bucket:Bucket b b-123\n```")
+
+	getBucket := string(files["storage/getbucket.md"])
+	assert.Contains(t, getBucket, "## Arguments\n\n- **name** (string, required)\n")
+	assert.Contains(t, getBucket, "## Result\n\n- **bucket** ([Bucket](bucket.md))\n")
+
+	types := string(files["storage/types.md"])
+	assert.Contains(t, types, "<a id=\"rule\"></a>\n\n## Rule\n\n- **days** (integer, default `30`)\n")
+	assert.Contains(t, types, "## Tier (enum of string)\n\n- `\"hot\"` (Hot): Read often.\n- `\"cold\"`\n")
+}
@@ -0,0 +1,248 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syntax
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Format returns the canonical formatting of the given HCL2 source file. Lines are indented with one tab per level of
+// nesting, tokens are separated by at most one space, runs of blank lines are collapsed into a single blank line, and
+// the file ends with a newline. Line breaks, comments, string templates and heredocs are otherwise preserved as
+// written.
+//
+// Source that does not parse is not formatted, and the parser's diagnostics are returned instead.
+func Format(src []byte, filename string) ([]byte, hcl.Diagnostics) {
+	if _, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos); diags.HasErrors() {
+		return nil, diags
+	}
+	tokens, diags := hclsyntax.LexConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return formatLines(splitFormatLines(src, tokens)), nil
+}
+
+// A formatItem is a token, or a string template or heredoc that is copied verbatim.
+type formatItem struct {
+	typ   hclsyntax.TokenType
+	bytes []byte
+	// unary is true if this is a unary operator.
+	unary bool
+	// spacedColon is true if this is the colon of a conditional or for expression, rather than of an object item.
+	spacedColon bool
+}
+
+// A formatScope tracks the expressions within a pair of brackets whose colons are surrounded by spaces.
+type formatScope struct {
+	conditionals int  // the number of conditional expressions whose colon has not been seen yet
+	inFor        bool // true if the colon of a for expression has not been seen yet
+}
+
+// splitFormatLines splits a file's tokens into lines of formatting items. Empty lines are represented by nil.
+func splitFormatLines(src []byte, tokens hclsyntax.Tokens) [][]formatItem {
+	var lines [][]formatItem
+	var line []formatItem
+	endLine := func() {
+		lines, line = append(lines, line), nil
+	}
+	scopes := []formatScope{{}}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch tok.Type {
+		case hclsyntax.TokenEOF:
+			if len(line) != 0 {
+				endLine()
+			}
+		case hclsyntax.TokenNewline:
+			endLine()
+		case hclsyntax.TokenComment:
+			comment := bytes.TrimRight(tok.Bytes, "\r\n")
+			line = append(line, formatItem{typ: tok.Type, bytes: comment})
+			// Line comments include their newline.
+			if len(comment) != len(tok.Bytes) {
+				endLine()
+			}
+		case hclsyntax.TokenOQuote, hclsyntax.TokenOHeredoc:
+			// Copy templates as written, including any interpolated expressions, as whitespace in them is significant.
+			open, closer := tok.Type, hclsyntax.TokenCQuote
+			if open == hclsyntax.TokenOHeredoc {
+				closer = hclsyntax.TokenCHeredoc
+			}
+			depth, j := 0, i
+			for ; j < len(tokens)-1; j++ {
+				if tokens[j].Type == open {
+					depth++
+				} else if tokens[j].Type == closer {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			line = append(line, formatItem{typ: open, bytes: src[tok.Range.Start.Byte:tokens[j].Range.End.Byte]})
+			i = j
+		default:
+			item := formatItem{typ: tok.Type, bytes: tok.Bytes}
+			scope := &scopes[len(scopes)-1]
+			switch {
+			case tok.Type == hclsyntax.TokenMinus || tok.Type == hclsyntax.TokenBang:
+				item.unary = len(line) == 0 || precedesOperand(line[len(line)-1])
+			case tok.Type == hclsyntax.TokenQuestion:
+				scope.conditionals++
+			case tok.Type == hclsyntax.TokenColon && scope.conditionals > 0:
+				item.spacedColon, scope.conditionals = true, scope.conditionals-1
+			case tok.Type == hclsyntax.TokenColon && scope.inFor:
+				item.spacedColon, scope.inFor = true, false
+			case tok.Type == hclsyntax.TokenIdent && string(tok.Bytes) == "for" &&
+				i > 0 && (tokens[i-1].Type == hclsyntax.TokenOBrack || tokens[i-1].Type == hclsyntax.TokenOBrace):
+				scope.inFor = true
+			case isOpener(tok.Type):
+				scopes = append(scopes, formatScope{})
+			case isCloser(tok.Type) && len(scopes) > 1:
+				scopes = scopes[:len(scopes)-1]
+			}
+			line = append(line, item)
+		}
+	}
+	return lines
+}
+
+// formatLines indents and prints lines of formatting items. A line within brackets is indented one level deeper than
+// the line that opened the innermost bracket, so that brackets opened together, as in `[{`, only add a single level of
+// indentation. A line that starts by closing a bracket is indented like the line that opened it.
+func formatLines(lines [][]formatItem) []byte {
+	var buf bytes.Buffer
+	indents := make([]int, len(lines))
+	var open []int // the lines on which each open bracket was opened
+	blank := false
+	for lineIndex, line := range lines {
+		if len(line) == 0 {
+			blank = buf.Len() != 0
+			continue
+		}
+		if blank {
+			buf.WriteByte('\n')
+			blank = false
+		}
+
+		if len(open) != 0 {
+			indents[lineIndex] = indents[open[len(open)-1]]
+			if !isCloser(line[0].typ) {
+				indents[lineIndex]++
+			}
+		}
+		for i := 0; i < indents[lineIndex]; i++ {
+			buf.WriteByte('\t')
+		}
+
+		for i, item := range line {
+			if i > 0 && spaceBetween(line[i-1], item) {
+				buf.WriteByte(' ')
+			}
+			buf.Write(item.bytes)
+
+			switch {
+			case isOpener(item.typ):
+				open = append(open, lineIndex)
+			case isCloser(item.typ) && len(open) > 0:
+				open = open[:len(open)-1]
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func isOpener(typ hclsyntax.TokenType) bool {
+	switch typ {
+	case hclsyntax.TokenOBrace, hclsyntax.TokenOBrack, hclsyntax.TokenOParen:
+		return true
+	default:
+		return false
+	}
+}
+
+func isCloser(typ hclsyntax.TokenType) bool {
+	switch typ {
+	case hclsyntax.TokenCBrace, hclsyntax.TokenCBrack, hclsyntax.TokenCParen:
+		return true
+	default:
+		return false
+	}
+}
+
+// precedesOperand returns true if the item is followed by an operand rather than by an operator, so that a following
+// minus or bang is a unary operator.
+func precedesOperand(item formatItem) bool {
+	switch item.typ {
+	case hclsyntax.TokenIdent, hclsyntax.TokenNumberLit, hclsyntax.TokenOQuote, hclsyntax.TokenOHeredoc,
+		hclsyntax.TokenCBrace, hclsyntax.TokenCBrack, hclsyntax.TokenCParen:
+		return isKeyword(item)
+	default:
+		return true
+	}
+}
+
+// isKeyword returns true if the item is a keyword of a for or conditional expression, which is followed by an operand.
+func isKeyword(item formatItem) bool {
+	if item.typ != hclsyntax.TokenIdent {
+		return false
+	}
+	switch string(item.bytes) {
+	case "for", "in", "if":
+		return true
+	default:
+		return false
+	}
+}
+
+// spaceBetween returns true if adjacent items on a line are separated by a space.
+func spaceBetween(prev, next formatItem) bool {
+	switch {
+	case next.typ == hclsyntax.TokenComment || prev.typ == hclsyntax.TokenComment:
+		return true
+	case prev.typ == hclsyntax.TokenOBrace:
+		// Objects and blocks are padded, unless they are empty.
+		return next.typ != hclsyntax.TokenCBrace
+	case next.typ == hclsyntax.TokenCBrace:
+		return true
+	case prev.typ == hclsyntax.TokenOParen || prev.typ == hclsyntax.TokenOBrack || prev.typ == hclsyntax.TokenDot:
+		return false
+	case prev.unary:
+		return false
+	}
+
+	switch next.typ {
+	case hclsyntax.TokenCParen, hclsyntax.TokenCBrack, hclsyntax.TokenComma, hclsyntax.TokenDot,
+		hclsyntax.TokenEllipsis:
+		return false
+	case hclsyntax.TokenColon:
+		return next.spacedColon
+	case hclsyntax.TokenOParen, hclsyntax.TokenOBrack:
+		// Function calls, index expressions and splats follow their operand directly.
+		switch prev.typ {
+		case hclsyntax.TokenIdent:
+			return isKeyword(prev)
+		case hclsyntax.TokenCBrack, hclsyntax.TokenCParen, hclsyntax.TokenOQuote, hclsyntax.TokenStar:
+			return false
+		}
+	}
+	return true
+}
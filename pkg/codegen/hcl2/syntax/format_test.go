@@ -0,0 +1,158 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syntax
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name: "indentation and spacing",
+			src: "resource   bucket \"aws:s3:Bucket\"{\n" +
+				"  website={indexDocument=\"index.html\"}\n" +
+				"    tags   =  [ \"a\",\"b\" ]\n" +
+				"}",
+			expected: "resource bucket \"aws:s3:Bucket\" {\n" +
+				"\twebsite = { indexDocument = \"index.html\" }\n" +
+				"\ttags = [\"a\", \"b\"]\n" +
+				"}\n",
+		},
+		{
+			name:     "brackets opened together",
+			src:      "resource b \"aws:s3:Bucket\" {\nloggings = [{\ntargetBucket = logs.bucket,\n}]\n}\n",
+			expected: "resource b \"aws:s3:Bucket\" {\n\tloggings = [{\n\t\ttargetBucket = logs.bucket,\n\t}]\n}\n",
+		},
+		{
+			name:     "closing brackets return to the opening line",
+			src:      "x = secret(invoke(\"a:b:c\", {\nname = \"n\"\n}).value)\n",
+			expected: "x = secret(invoke(\"a:b:c\", {\n\tname = \"n\"\n}).value)\n",
+		},
+		{
+			name:     "blank lines",
+			src:      "\n\na = 1\n\n\n\nb = 2\n\n",
+			expected: "a = 1\n\nb = 2\n",
+		},
+		{
+			name:     "comments",
+			src:      "// leading\na = 1     # trailing\n/* block */ b = 2\n",
+			expected: "// leading\na = 1 # trailing\n/* block */ b = 2\n",
+		},
+		{
+			name: "operators",
+			src: "a = -1\n" +
+				"b = !c&&d\n" +
+				"c = x>0?x: -x\n" +
+				"d = {for k,v in m: k=>v if v!=null}\n" +
+				"e = foo.*.id\n" +
+				"f = list[*].id\n" +
+				"g = f(x...)\n",
+			expected: "a = -1\n" +
+				"b = !c && d\n" +
+				"c = x > 0 ? x : -x\n" +
+				"d = { for k, v in m : k => v if v != null }\n" +
+				"e = foo.*.id\n" +
+				"f = list[*].id\n" +
+				"g = f(x...)\n",
+		},
+		{
+			name:     "object keys",
+			src:      "a = {\n\"Name\": \"x\", b : 1}\n",
+			expected: "a = {\n\t\"Name\": \"x\", b: 1 }\n",
+		},
+		{
+			name:     "templates and heredocs are preserved",
+			src:      "a = \"${ x }  y\"\nb = <<EOT\n  keep   this\nEOT\n",
+			expected: "a = \"${ x }  y\"\nb = <<EOT\n  keep   this\nEOT\n",
+		},
+		{
+			name:     "empty blocks",
+			src:      "resource a \"b:c:D\" { }\n",
+			expected: "resource a \"b:c:D\" {}\n",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, diags := Format([]byte(c.src), "test.pp")
+			require.Empty(t, diags)
+			assert.Equal(t, c.expected, string(actual))
+
+			again, diags := Format(actual, "test.pp")
+			require.Empty(t, diags)
+			assert.Equal(t, string(actual), string(again), "formatting is not idempotent")
+		})
+	}
+}
+
+func TestFormatInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, diags := Format([]byte("resource a {"), "test.pp")
+	assert.True(t, diags.HasErrors())
+}
+
+// TestFormatTestdata checks that formatting the PCL test programs preserves their meaning, and is idempotent.
+func TestFormatTestdata(t *testing.T) {
+	t.Parallel()
+
+	paths, err := filepath.Glob(filepath.Join("..", "..", "testing", "test", "testdata", "*", "*.pp"))
+	require.NoError(t, err)
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		require.NoError(t, err)
+		formatted, diags := Format(src, path)
+		if diags.HasErrors() {
+			// Some test programs exercise invalid syntax.
+			continue
+		}
+
+		before, _ := hclsyntax.LexConfig(src, path, hcl.InitialPos)
+		after, diags := hclsyntax.LexConfig(formatted, path, hcl.InitialPos)
+		require.Empty(t, diags, path)
+		assert.Equal(t, significantTokens(before), significantTokens(after), path)
+
+		again, _ := Format(formatted, path)
+		assert.Equal(t, string(formatted), string(again), path)
+	}
+}
+
+// significantTokens returns the text of the tokens that are not newlines or comments. The formatter may change the
+// trailing newline of a comment and the number of blank lines, but nothing else.
+func significantTokens(tokens hclsyntax.Tokens) []string {
+	var text []string
+	for _, tok := range tokens {
+		if tok.Type != hclsyntax.TokenNewline && tok.Type != hclsyntax.TokenComment {
+			text = append(text, string(tok.Bytes))
+		}
+	}
+	return text
+}
@@ -170,6 +170,18 @@ func (c Comment) Bytes() []byte {
 
 func (Comment) isTrivia() {}
 
+// NewComment returns a new comment with the given lines, each of which is printed as a "//" line comment. As with
+// parsed line comments, the lines of the result keep the space that follows the comment delimiter.
+func NewComment(lines ...string) Comment {
+	var bytes []byte
+	commentLines := make([]string, len(lines))
+	for i, l := range lines {
+		commentLines[i] = " " + l
+		bytes = append(bytes, "//"+commentLines[i]+"\n"...)
+	}
+	return Comment{Lines: commentLines, bytes: bytes}
+}
+
 // Whitespace is a piece of trivia that represents a sequence of whitespace characters in a source file.
 type Whitespace struct {
 	rng   hcl.Range
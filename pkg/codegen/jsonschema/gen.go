@@ -0,0 +1,447 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonschema generates a JSON Schema document and an OpenAPI document that describe the resources, functions
+// and types of a Pulumi package. Editors can use them to complete and validate Pulumi YAML programs.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+const (
+	// SchemaFile is the name of the generated JSON Schema document.
+	SchemaFile = "schema.json"
+	// OpenAPIFile is the name of the generated OpenAPI document.
+	OpenAPIFile = "openapi.json"
+)
+
+// object is a JSON Schema object. Maps are marshaled with sorted keys, so the generated documents are stable.
+type object = map[string]interface{}
+
+// GeneratePackage generates a JSON Schema document and an OpenAPI document for pkg. The JSON Schema document
+// describes a Pulumi YAML program that declares the package's resources; both documents define a schema for the
+// inputs and outputs of every resource and function, and for every type.
+func GeneratePackage(tool string, pkg *schema.Package, extraFiles map[string][]byte) (map[string][]byte, error) {
+	jsonSchema, err := GenerateJSONSchema(tool, pkg)
+	if err != nil {
+		return nil, err
+	}
+	openAPI, err := GenerateOpenAPI(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{
+		SchemaFile:  jsonSchema,
+		OpenAPIFile: openAPI,
+	}
+	for path, contents := range extraFiles {
+		files[path] = contents
+	}
+	return files, nil
+}
+
+// GenerateJSONSchema generates a draft-07 JSON Schema document for pkg. The document validates the resources of a
+// Pulumi YAML program that belong to the package, including its provider; resources of other packages are accepted as
+// they are. The "resource" definition validates a single resource declaration, and the other definitions describe
+// each resource, function and type of the package. Values that are not strings also accept a "${...}" interpolation,
+// which Pulumi YAML evaluates to a value of any type.
+func GenerateJSONSchema(tool string, pkg *schema.Package) ([]byte, error) {
+	g := &generator{pkg: pkg, refPrefix: "#/definitions/", interpolations: true}
+	definitions := g.definitions()
+
+	definitions["resource"] = g.resource()
+	// Other top-level sections, such as variables and outputs, are not constrained.
+	definitions["program"] = object{
+		"description": fmt.Sprintf("A Pulumi YAML program that declares resources of the %s package.", pkg.Name),
+		"type":        "object",
+		"properties": object{
+			"resources": object{"type": "object", "additionalProperties": g.ref("resource")},
+		},
+	}
+
+	doc := object{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"$comment": fmt.Sprintf("This file was generated by %v. Do not edit by hand unless you're certain you know "+
+			"what you are doing!", tool),
+		"title":       packageTitle(pkg),
+		"$ref":        g.refPrefix + "program",
+		"definitions": definitions,
+	}
+	if pkg.Description != "" {
+		doc["description"] = pkg.Description
+	}
+	return marshal(doc)
+}
+
+// resource returns the schema for a resource declaration. The properties of a resource are validated against the
+// inputs of the resource whose token, alias or shorthand token is the declaration's type. Declarations of any other
+// type are not constrained, as they may belong to other packages.
+func (g *generator) resource() object {
+	resourceTypes := g.pkg.Resources
+	if g.pkg.Provider != nil {
+		resourceTypes = append([]*schema.Resource{g.pkg.Provider}, resourceTypes...)
+	}
+	tokens := resourceTokens(resourceTypes)
+	sorted := make([]string, 0, len(tokens))
+	for token := range tokens {
+		sorted = append(sorted, token)
+	}
+	sort.Strings(sorted)
+
+	declaration := object{
+		"description": fmt.Sprintf("A resource declaration. The properties of resources of the %s package are "+
+			"validated against their inputs.", g.pkg.Name),
+		"type":       "object",
+		"properties": object{"type": object{"type": "string"}},
+		"required":   []string{"type"},
+	}
+	if len(sorted) != 0 {
+		conditions := make([]interface{}, len(sorted))
+		for i, token := range sorted {
+			conditions[i] = object{
+				"if": object{
+					"properties": object{"type": object{"const": token}},
+					"required":   []string{"type"},
+				},
+				"then": object{
+					"properties": object{"properties": g.ref(inputsKey(tokens[token]))},
+				},
+			}
+		}
+		declaration["allOf"] = conditions
+	}
+	return declaration
+}
+
+// resourceTokens maps each type that Pulumi YAML accepts for the given resources to the token of the resource that it
+// declares. Besides its own token, a resource may be declared by the type of one of its aliases, or by a shorthand
+// such as "aws:s3:Bucket" for "aws:s3/bucket:Bucket" or "test:Thing" for "test:index:Thing". Tokens take precedence
+// over aliases, which take precedence over shorthands.
+func resourceTokens(resources []*schema.Resource) map[string]string {
+	tokens := map[string]string{}
+	add := func(typ, token string) {
+		if _, ok := tokens[typ]; !ok {
+			tokens[typ] = token
+		}
+	}
+	for _, r := range resources {
+		add(r.Token, r.Token)
+	}
+	for _, r := range resources {
+		for _, alias := range r.Aliases {
+			if alias.Type != nil {
+				add(*alias.Type, r.Token)
+			}
+		}
+	}
+	for _, r := range resources {
+		for _, shorthand := range shorthandTokens(r.Token) {
+			add(shorthand, r.Token)
+		}
+	}
+	return tokens
+}
+
+// shorthandTokens returns the shorter forms of a resource token that Pulumi YAML accepts. A module whose last
+// component is the camel-cased name of the resource may omit that component, and the index module may be omitted
+// altogether.
+func shorthandTokens(token string) []string {
+	components := strings.Split(token, ":")
+	if len(components) != 3 {
+		return nil
+	}
+	pkg, module, name := components[0], components[1], components[2]
+	if name == "" {
+		return nil
+	}
+
+	var shorthands []string
+	if i := strings.LastIndex(module, "/"); i != -1 && module[i+1:] == strings.ToLower(name[:1])+name[1:] {
+		module = module[:i]
+		shorthands = append(shorthands, pkg+":"+module+":"+name)
+	}
+	if module == "index" {
+		shorthands = append(shorthands, pkg+":"+name)
+	}
+	return shorthands
+}
+
+// GenerateOpenAPI generates an OpenAPI 3.1 document for pkg whose components section describes each resource,
+// function and type of the package.
+func GenerateOpenAPI(pkg *schema.Package) ([]byte, error) {
+	g := &generator{pkg: pkg, refPrefix: "#/components/schemas/"}
+
+	version := "0.0.0"
+	if pkg.Version != nil {
+		version = pkg.Version.String()
+	}
+	info := object{"title": packageTitle(pkg), "version": version}
+	if pkg.Description != "" {
+		info["description"] = pkg.Description
+	}
+	return marshal(object{
+		"openapi":    "3.1.0",
+		"info":       info,
+		"paths":      object{},
+		"components": object{"schemas": g.definitions()},
+	})
+}
+
+type generator struct {
+	pkg       *schema.Package
+	refPrefix string
+	// interpolations is true if values that are not strings may also be given as "${...}" interpolations.
+	interpolations bool
+}
+
+// definitions returns the schemas for the package's types, for the inputs and outputs of its resources, and for the
+// arguments and results of its functions. Types are keyed by their token. Resources and functions are keyed by their
+// token and ":inputs" or ":outputs", which cannot collide with a type token.
+func (g *generator) definitions() object {
+	defs := object{}
+	for _, t := range g.pkg.Types {
+		switch t := t.(type) {
+		case *schema.ObjectType:
+			if !t.IsInputShape() {
+				defs[t.Token] = g.object(t.Comment, t.Properties, true)
+			}
+		case *schema.EnumType:
+			defs[t.Token] = g.enum(t)
+		}
+	}
+
+	resources := g.pkg.Resources
+	if g.pkg.Provider != nil {
+		resources = append([]*schema.Resource{g.pkg.Provider}, resources...)
+	}
+	for _, r := range resources {
+		inputs := g.object(r.Comment, r.InputProperties, true)
+		outputs := g.object(r.Comment, r.Properties, false)
+		deprecate(inputs, r.DeprecationMessage)
+		deprecate(outputs, r.DeprecationMessage)
+		defs[inputsKey(r.Token)], defs[outputsKey(r.Token)] = inputs, outputs
+	}
+
+	for _, f := range g.pkg.Functions {
+		if f.IsMethod {
+			continue
+		}
+
+		var inputProperties []*schema.Property
+		if f.Inputs != nil {
+			inputProperties = f.Inputs.Properties
+		}
+		inputs := g.object(f.Comment, inputProperties, true)
+		deprecate(inputs, f.DeprecationMessage)
+		defs[inputsKey(f.Token)] = inputs
+
+		if f.ReturnType != nil {
+			var outputs object
+			if o, ok := f.ReturnType.(*schema.ObjectType); ok {
+				outputs = g.object(f.Comment, o.Properties, false)
+			} else {
+				outputs = g.typ(f.ReturnType)
+			}
+			deprecate(outputs, f.DeprecationMessage)
+			defs[outputsKey(f.Token)] = outputs
+		}
+	}
+	return defs
+}
+
+// object returns the schema for an object with the given properties. Unknown properties are only rejected in inputs,
+// so that outputs read from newer providers still validate.
+func (g *generator) object(comment string, properties []*schema.Property, input bool) object {
+	props, required := object{}, []string{}
+	for _, p := range properties {
+		props[p.Name] = g.property(p)
+		if p.IsRequired() {
+			required = append(required, p.Name)
+		}
+	}
+	sort.Strings(required)
+
+	o := object{"type": "object", "properties": props}
+	if len(required) != 0 {
+		o["required"] = required
+	}
+	if input {
+		o["additionalProperties"] = false
+	}
+	if comment != "" {
+		o["description"] = comment
+	}
+	return o
+}
+
+func (g *generator) property(p *schema.Property) object {
+	s := g.typ(p.Type)
+	if _, isRef := s["$ref"]; isRef && (p.Comment != "" || p.DeprecationMessage != "") {
+		// Keywords beside a $ref are ignored by draft-07 validators, so wrap the reference.
+		s = object{"allOf": []interface{}{s}}
+	}
+	if p.Comment != "" {
+		s["description"] = p.Comment
+	}
+	if p.ConstValue != nil {
+		s["const"] = p.ConstValue
+	}
+	if p.DefaultValue != nil && p.DefaultValue.Value != nil {
+		s["default"] = p.DefaultValue.Value
+	}
+	if p.Secret {
+		s["x-pulumi-secret"] = true
+	}
+	deprecate(s, p.DeprecationMessage)
+	return s
+}
+
+func (g *generator) enum(t *schema.EnumType) object {
+	values := make([]interface{}, 0, len(t.Elements))
+	var descriptions []string
+	documented := false
+	for _, e := range t.Elements {
+		values = append(values, e.Value)
+		descriptions = append(descriptions, e.Comment)
+		documented = documented || e.Comment != ""
+	}
+
+	s := g.plainType(t.ElementType)
+	s["enum"] = values
+	if t.Comment != "" {
+		s["description"] = t.Comment
+	}
+	if documented {
+		// Editors that understand this keyword show each value's description while completing it.
+		s["enumDescriptions"] = descriptions
+	}
+	return s
+}
+
+// interpolation is the schema of a "${...}" interpolation.
+var interpolation = object{"type": "string", "pattern": `^\$\{.*\}$`}
+
+// typ returns the schema for a value of type t. If the generator allows interpolations, a value that is not a string
+// may also be given as an interpolation.
+func (g *generator) typ(t schema.Type) object {
+	s := g.plainType(t)
+	if !g.interpolations || len(s) == 0 || s["type"] == "string" {
+		// Strings already accept interpolations, and unconstrained values accept anything.
+		return s
+	}
+	return object{"anyOf": []interface{}{s, interpolation}}
+}
+
+// plainType returns the schema for a value of type t, without allowing interpolations in place of the value itself.
+// Elements of arrays, maps and objects still allow them.
+func (g *generator) plainType(t schema.Type) object {
+	switch t := t.(type) {
+	case *schema.OptionalType:
+		return g.plainType(t.ElementType)
+	case *schema.InputType:
+		return g.plainType(t.ElementType)
+	case *schema.ArrayType:
+		return object{"type": "array", "items": g.typ(t.ElementType)}
+	case *schema.MapType:
+		return object{"type": "object", "additionalProperties": g.typ(t.ElementType)}
+	case *schema.UnionType:
+		elements := make([]interface{}, len(t.ElementTypes))
+		for i, e := range t.ElementTypes {
+			elements[i] = g.plainType(e)
+		}
+		return object{"oneOf": elements}
+	case *schema.ObjectType:
+		if t.PlainShape != nil {
+			return g.plainType(t.PlainShape)
+		}
+		if !g.local(t.PackageReference) {
+			return object{"type": "object", "description": fmt.Sprintf("An object of type %s.", t.Token)}
+		}
+		return g.ref(t.Token)
+	case *schema.EnumType:
+		if !g.local(t.PackageReference) {
+			return g.plainType(t.ElementType)
+		}
+		return g.ref(t.Token)
+	case *schema.ResourceType:
+		return object{"type": "string", "description": fmt.Sprintf("A reference to a %s resource.", t.Token)}
+	case *schema.TokenType:
+		if t.UnderlyingType != nil {
+			return g.plainType(t.UnderlyingType)
+		}
+		return object{}
+	}
+
+	switch t {
+	case schema.BoolType:
+		return object{"type": "boolean"}
+	case schema.IntType:
+		return object{"type": "integer"}
+	case schema.NumberType:
+		return object{"type": "number"}
+	case schema.StringType:
+		return object{"type": "string"}
+	default:
+		// Assets, archives, JSON values and values of any type are not constrained.
+		return object{}
+	}
+}
+
+// local returns true if a type belongs to the package being generated, rather than to one of its dependencies.
+func (g *generator) local(ref schema.PackageReference) bool {
+	return ref == nil || ref.Name() == g.pkg.Name
+}
+
+func (g *generator) ref(key string) object {
+	// Escape the key as a JSON pointer segment.
+	return object{"$ref": g.refPrefix + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)}
+}
+
+func deprecate(s object, message string) {
+	if message != "" {
+		s["deprecated"] = true
+		// VS Code shows this message when a deprecated property is used.
+		s["deprecationMessage"] = message
+	}
+}
+
+func inputsKey(token string) string {
+	return token + ":inputs"
+}
+
+func outputsKey(token string) string {
+	return token + ":outputs"
+}
+
+func packageTitle(pkg *schema.Package) string {
+	if pkg.DisplayName != "" {
+		return pkg.DisplayName
+	}
+	return pkg.Name
+}
+
+func marshal(doc object) ([]byte, error) {
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
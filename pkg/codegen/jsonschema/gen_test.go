@@ -0,0 +1,235 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+const testSchema = `{
+	"name": "test",
+	"version": "1.2.3",
+	"description": "A test package.",
+	"resources": {
+		"test:storage/bucket:Bucket": {
+			"description": "A bucket.",
+			"aliases": [{"type": "test:storage:OldBucket"}],
+			"inputProperties": {
+				"name": {"type": "string", "description": "The bucket's name."},
+				"tier": {"$ref": "#/types/test:index:Tier"},
+				"tags": {"type": "object", "additionalProperties": {"type": "string"}},
+				"password": {"type": "string", "secret": true},
+				"acl": {"type": "string", "deprecationMessage": "use policy"},
+				"rules": {"type": "array", "items": {"$ref": "#/types/test:index:Rule"}}
+			},
+			"requiredInputs": ["name"],
+			"properties": {
+				"arn": {"type": "string"},
+				"size": {"type": "integer"}
+			},
+			"required": ["arn"]
+		}
+	},
+	"functions": {
+		"test:index:getBucket": {
+			"inputs": {"properties": {"name": {"type": "string"}}, "required": ["name"]},
+			"outputs": {"properties": {"arn": {"type": "string"}}}
+		}
+	},
+	"types": {
+		"test:index:Tier": {
+			"type": "string",
+			"enum": [{"value": "hot", "description": "Frequently read."}, {"value": "cold"}]
+		},
+		"test:index:Rule": {
+			"type": "object",
+			"properties": {"days": {"type": "integer", "default": 30}}
+		}
+	}
+}`
+
+// interpolated returns the schema s, or a "${...}" interpolation.
+func interpolated(s map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"anyOf": []interface{}{
+		s,
+		map[string]interface{}{"type": "string", "pattern": `^\$\{.*\}$`},
+	}}
+}
+
+func generateTestDocument(t *testing.T, generate func(*schema.Package) ([]byte, error)) map[string]interface{} {
+	var spec schema.PackageSpec
+	require.NoError(t, json.Unmarshal([]byte(testSchema), &spec))
+	pkg, diags, err := schema.BindSpec(spec, nil)
+	require.NoError(t, err)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	b, err := generate(pkg)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &doc))
+	return doc
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	doc := generateTestDocument(t, func(pkg *schema.Package) ([]byte, error) {
+		return GenerateJSONSchema("test", pkg)
+	})
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", doc["$schema"])
+	assert.Equal(t, "#/definitions/program", doc["$ref"])
+	defs := doc["definitions"].(map[string]interface{})
+
+	inputs := defs["test:storage/bucket:Bucket:inputs"].(map[string]interface{})
+	assert.Equal(t, "A bucket.", inputs["description"])
+	assert.Equal(t, []interface{}{"name"}, inputs["required"])
+	assert.Equal(t, false, inputs["additionalProperties"])
+	props := inputs["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "The bucket's name."}, props["name"])
+	assert.Equal(t, interpolated(map[string]interface{}{"$ref": "#/definitions/test:index:Tier"}), props["tier"])
+	assert.Equal(t, interpolated(map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": "string"},
+	}), props["tags"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "x-pulumi-secret": true}, props["password"])
+	assert.Equal(t, map[string]interface{}{
+		"type":               "string",
+		"deprecated":         true,
+		"deprecationMessage": "use policy",
+	}, props["acl"])
+	assert.Equal(t, interpolated(map[string]interface{}{
+		"type":  "array",
+		"items": interpolated(map[string]interface{}{"$ref": "#/definitions/test:index:Rule"}),
+	}), props["rules"])
+
+	outputs := defs["test:storage/bucket:Bucket:outputs"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"arn"}, outputs["required"])
+	assert.NotContains(t, outputs, "additionalProperties")
+
+	assert.Equal(t, map[string]interface{}{
+		"type":             "string",
+		"enum":             []interface{}{"hot", "cold"},
+		"enumDescriptions": []interface{}{"Frequently read.", ""},
+	}, defs["test:index:Tier"])
+	rule := defs["test:index:Rule"].(map[string]interface{})
+	days := interpolated(map[string]interface{}{"type": "integer"})
+	days["default"] = float64(30)
+	assert.Equal(t, days, rule["properties"].(map[string]interface{})["days"])
+
+	assert.Contains(t, defs, "test:index:getBucket:inputs")
+	assert.Contains(t, defs, "test:index:getBucket:outputs")
+	assert.Contains(t, defs, "pulumi:providers:test:inputs")
+
+	// The properties of each resource are validated by the inputs of the resource whose token, alias or shorthand
+	// token is the type of the declaration.
+	resource := defs["resource"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"type"}, resource["required"])
+	condition := func(token, inputs string) interface{} {
+		return map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"type": map[string]interface{}{"const": token}},
+				"required":   []interface{}{"type"},
+			},
+			"then": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"properties": map[string]interface{}{"$ref": "#/definitions/" + inputs},
+				},
+			},
+		}
+	}
+	assert.Equal(t, []interface{}{
+		condition("pulumi:providers:test", "pulumi:providers:test:inputs"),
+		condition("test:storage/bucket:Bucket", "test:storage~1bucket:Bucket:inputs"),
+		condition("test:storage:Bucket", "test:storage~1bucket:Bucket:inputs"),
+		condition("test:storage:OldBucket", "test:storage~1bucket:Bucket:inputs"),
+	}, resource["allOf"])
+
+	program := defs["program"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"resources": map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"$ref": "#/definitions/resource"},
+		},
+	}, program["properties"])
+}
+
+func TestGenerateOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	doc := generateTestDocument(t, GenerateOpenAPI)
+	assert.Equal(t, "3.1.0", doc["openapi"])
+	assert.Equal(t, map[string]interface{}{
+		"title":       "test",
+		"version":     "1.2.3",
+		"description": "A test package.",
+	}, doc["info"])
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	props := schemas["test:storage/bucket:Bucket:inputs"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"$ref": "#/components/schemas/test:index:Tier"}, props["tier"])
+	assert.NotContains(t, schemas, "resource")
+	assert.NotContains(t, schemas, "program")
+}
+
+func TestGenerateJSONSchemaValidation(t *testing.T) {
+	t.Parallel()
+
+	var spec schema.PackageSpec
+	require.NoError(t, json.Unmarshal([]byte(testSchema), &spec))
+	pkg, diags, err := schema.BindSpec(spec, nil)
+	require.NoError(t, err)
+	require.False(t, diags.HasErrors(), diags.Error())
+	b, err := GenerateJSONSchema("test", pkg)
+	require.NoError(t, err)
+
+	compiler := jsonschema.NewCompiler()
+	require.NoError(t, compiler.AddResource("schema.json", bytes.NewReader(b)))
+	compiled, err := compiler.Compile("schema.json")
+	require.NoError(t, err)
+
+	validate := func(program string) error {
+		var v interface{}
+		require.NoError(t, json.Unmarshal([]byte(program), &v))
+		return compiled.Validate(v)
+	}
+
+	// Resources of other packages are not constrained, and shorthand tokens are validated like full tokens.
+	assert.NoError(t, validate(`{"resources": {
+		"bucket": {"type": "test:storage:Bucket", "properties": {"name": "b"}},
+		"other": {"type": "aws:s3:Bucket", "properties": {"anything": 1}}
+	}}`))
+	assert.Error(t, validate(`{"resources": {"bucket": {"type": "test:storage:Bucket", "properties": {}}}}`))
+	assert.Error(t, validate(`{"resources": {"bucket": {"type": "test:storage:OldBucket", "properties": {"x": 1}}}}`))
+	assert.Error(t, validate(`{"resources": {"bucket": {"properties": {}}}}`))
+}
+
+func TestShorthandTokens(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"aws:s3:Bucket"}, shorthandTokens("aws:s3/bucket:Bucket"))
+	assert.Equal(t, []string{"aws:s3:BucketObject"}, shorthandTokens("aws:s3/bucketObject:BucketObject"))
+	assert.Equal(t, []string{"test:index:Thing", "test:Thing"}, shorthandTokens("test:index/thing:Thing"))
+	assert.Equal(t, []string{"test:Thing"}, shorthandTokens("test:index:Thing"))
+	assert.Empty(t, shorthandTokens("aws:s3/bucketPolicy:Bucket"))
+	assert.Empty(t, shorthandTokens("pulumi:providers:aws"))
+}
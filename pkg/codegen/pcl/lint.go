@@ -0,0 +1,99 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcl
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/model"
+)
+
+// Lint reports likely mistakes in a bound program that the binder accepts: config variables and local variables that
+// are never used, and outputs that are never set because their value depends on a resource that is never created.
+// Unknown properties and type mismatches are reported by the binder itself.
+func Lint(program *Program) hcl.Diagnostics {
+	used := map[Node]bool{}
+	for _, n := range program.Nodes {
+		for _, d := range n.getDependencies() {
+			used[d] = true
+		}
+	}
+
+	var diagnostics hcl.Diagnostics
+	for _, n := range program.Nodes {
+		switch n := n.(type) {
+		case *ConfigVariable:
+			if !used[n] {
+				diagnostics = append(diagnostics, diagf(hcl.DiagWarning, n.syntax.DefRange(),
+					"config variable %q is never used", n.Name()))
+			}
+		case *LocalVariable:
+			if !used[n] {
+				diagnostics = append(diagnostics, diagf(hcl.DiagWarning, n.syntax.NameRange,
+					"local variable %q is never used", n.Name()))
+			}
+		case *OutputVariable:
+			if r := uncreatedDependency(n, map[Node]bool{}); r != nil {
+				diagnostics = append(diagnostics, diagf(hcl.DiagWarning, n.syntax.DefRange(),
+					"output %q is never set: it depends on resource %q, which is never created", n.Name(), r.Name()))
+			}
+		}
+	}
+	return diagnostics
+}
+
+// uncreatedDependency returns a resource that is never created that the node depends on, if any.
+func uncreatedDependency(n Node, visited map[Node]bool) *Resource {
+	for _, d := range n.getDependencies() {
+		if visited[d] {
+			continue
+		}
+		visited[d] = true
+
+		if r, ok := d.(*Resource); ok && r.Options != nil && isEmptyRange(r.Options.Range) {
+			return r
+		}
+		if r := uncreatedDependency(d, visited); r != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// isEmptyRange returns true if a resource's range is a literal that creates no instances: false, zero or an empty
+// list or map.
+func isEmptyRange(expr model.Expression) bool {
+	switch expr := expr.(type) {
+	case *model.LiteralValueExpression:
+		v := expr.Value
+		if v.IsNull() || !v.IsKnown() {
+			return false
+		}
+		switch v.Type() {
+		case cty.Bool:
+			return v.False()
+		case cty.Number:
+			return v.Equals(cty.Zero).True()
+		}
+		return false
+	case *model.TupleConsExpression:
+		return len(expr.Expressions) == 0
+	case *model.ObjectConsExpression:
+		return len(expr.Items) == 0
+	default:
+		return false
+	}
+}
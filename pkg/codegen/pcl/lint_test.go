@@ -0,0 +1,90 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcl_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	source := `config usedConfig string {}
+config unusedConfig int {}
+
+usedLocal = "pet-${usedConfig}"
+unusedLocal = 42
+
+resource pet "random:index/randomPet:RandomPet" {
+	prefix = usedLocal
+}
+
+resource neverCreated "random:index/randomPet:RandomPet" {
+	options {
+		range = false
+	}
+}
+
+output petId {
+	value = pet.id
+}
+
+output neverSet {
+	value = [for p in neverCreated : p.id]
+}
+`
+	program, diags, err := ParseAndBindProgram(t, source, "program.pp")
+	require.NoError(t, err)
+	require.Empty(t, diags)
+
+	var messages []string
+	for _, d := range pcl.Lint(program) {
+		assert.Equal(t, hcl.DiagWarning, d.Severity)
+		require.NotNil(t, d.Subject)
+		messages = append(messages, d.Summary)
+	}
+	assert.Equal(t, []string{
+		`config variable "unusedConfig" is never used`,
+		`local variable "unusedLocal" is never used`,
+		`output "neverSet" is never set: it depends on resource "neverCreated", which is never created`,
+	}, messages)
+}
+
+func TestLintClean(t *testing.T) {
+	t.Parallel()
+
+	source := `config count int {}
+
+resource pets "random:index/randomPet:RandomPet" {
+	options {
+		range = count
+	}
+}
+
+output petIds {
+	value = [for p in pets : p.id]
+}
+`
+	program, diags, err := ParseAndBindProgram(t, source, "program.pp")
+	require.NoError(t, err)
+	require.Empty(t, diags)
+	assert.Empty(t, pcl.Lint(program))
+}
@@ -0,0 +1,379 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/natefinch/atomic"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/env"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/fsutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// SchemaCacheDir is the name of the directory under the Pulumi home directory that holds the schema cache.
+const SchemaCacheDir = "schemas"
+
+// SchemaCacheKey identifies a schema in a SchemaCache.
+type SchemaCacheKey struct {
+	// Package is the name of the package.
+	Package string `json:"package"`
+	// Version is the version of the package.
+	Version string `json:"version"`
+}
+
+func (k SchemaCacheKey) String() string {
+	return k.Package + "@" + k.Version
+}
+
+// hash returns the name of the file that holds the entry for the key.
+func (k SchemaCacheKey) hash() string {
+	h := sha256.New()
+	for _, s := range []string{k.Package, k.Version} {
+		// Separate the fields with a byte that cannot appear in them, so that different keys cannot collide.
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SchemaCacheEntry describes a schema in a SchemaCache.
+type SchemaCacheEntry struct {
+	SchemaCacheKey
+
+	// Digest is the SHA-256 digest of the schema, which addresses its contents in the cache.
+	Digest string `json:"digest"`
+	// Size is the size of the schema, in bytes.
+	Size int64 `json:"size"`
+	// PluginTime is the modification time of the plugin that the schema was loaded from. The entry is stale once the
+	// plugin is modified, for example because it was reinstalled.
+	PluginTime time.Time `json:"pluginTime"`
+	// CachedAt is the time the schema was added to the cache.
+	CachedAt time.Time `json:"cachedAt"`
+}
+
+// SchemaCache is an on-disk cache of package schemas that can be shared by concurrent processes.
+//
+// Schemas are stored once per distinct content under their SHA-256 digest, and each entry maps a package and version
+// to a digest. Files are replaced atomically, so readers never observe a partial write and do not need to lock the
+// cache. Writers serialize through a file lock, which keeps removal of schemas that are no longer referenced from
+// racing with a concurrent write.
+type SchemaCache struct {
+	dir  string
+	lock *fsutil.FileMutex
+}
+
+// NewSchemaCache returns a schema cache rooted at dir. The directory is created when the first schema is added.
+func NewSchemaCache(dir string) *SchemaCache {
+	return &SchemaCache{
+		dir:  dir,
+		lock: fsutil.NewFileMutex(filepath.Join(dir, "lock")),
+	}
+}
+
+var defaultSchemaCache struct {
+	once  sync.Once
+	cache *SchemaCache
+	err   error
+}
+
+// DefaultSchemaCache returns the schema cache in the Pulumi home directory. The same cache is returned on each call,
+// so that writers in this process serialize before taking the file lock.
+func DefaultSchemaCache() (*SchemaCache, error) {
+	defaultSchemaCache.once.Do(func() {
+		dir, err := workspace.GetPulumiPath(SchemaCacheDir)
+		if err != nil {
+			defaultSchemaCache.err = err
+			return
+		}
+		defaultSchemaCache.cache = NewSchemaCache(dir)
+	})
+	return defaultSchemaCache.cache, defaultSchemaCache.err
+}
+
+// Dir returns the directory that holds the cache.
+func (c *SchemaCache) Dir() string {
+	return c.dir
+}
+
+func (c *SchemaCache) entryPath(key SchemaCacheKey) string {
+	return filepath.Join(c.dir, "entries", key.hash()+".json")
+}
+
+func (c *SchemaCache) blobPath(digest string) string {
+	return filepath.Join(c.dir, "blobs", digest+".json")
+}
+
+// Entry returns the entry for key, if there is one.
+func (c *SchemaCache) Entry(key SchemaCacheKey) (SchemaCacheEntry, bool, error) {
+	return c.readEntry(c.entryPath(key))
+}
+
+func (c *SchemaCache) readEntry(path string) (SchemaCacheEntry, bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return SchemaCacheEntry{}, false, nil
+		}
+		return SchemaCacheEntry{}, false, err
+	}
+	var entry SchemaCacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return SchemaCacheEntry{}, false, fmt.Errorf("reading schema cache entry %q: %w", path, err)
+	}
+	return entry, true, nil
+}
+
+// Get returns the schema for key. A schema that was loaded from a plugin whose modification time differs from
+// pluginTime is stale, and is not returned.
+func (c *SchemaCache) Get(key SchemaCacheKey, pluginTime time.Time) ([]byte, bool) {
+	entry, ok, err := c.Entry(key)
+	if err != nil {
+		logging.V(7).Infof("schema cache: %v", err)
+		return nil, false
+	}
+	if !ok || !entry.PluginTime.Equal(pluginTime) {
+		return nil, false
+	}
+
+	schemaBytes, err := os.ReadFile(c.blobPath(entry.Digest))
+	if err != nil {
+		// The schema may have been removed by a concurrent clear.
+		logging.V(7).Infof("schema cache: reading schema for %v: %v", key, err)
+		return nil, false
+	}
+	if int64(len(schemaBytes)) != entry.Size {
+		logging.V(7).Infof("schema cache: schema for %v has size %d, expected %d", key, len(schemaBytes), entry.Size)
+		return nil, false
+	}
+	return schemaBytes, true
+}
+
+// Put adds the schema for key, which was loaded from a plugin with the given modification time, to the cache.
+func (c *SchemaCache) Put(key SchemaCacheKey, pluginTime time.Time, schemaBytes []byte) (SchemaCacheEntry, error) {
+	digest := sha256.Sum256(schemaBytes)
+	entry := SchemaCacheEntry{
+		SchemaCacheKey: key,
+		Digest:         hex.EncodeToString(digest[:]),
+		Size:           int64(len(schemaBytes)),
+		PluginTime:     pluginTime,
+		CachedAt:       time.Now(),
+	}
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		return SchemaCacheEntry{}, err
+	}
+
+	for _, dir := range []string{"entries", "blobs"} {
+		if err := os.MkdirAll(filepath.Join(c.dir, dir), 0o700); err != nil {
+			return SchemaCacheEntry{}, err
+		}
+	}
+	if err := c.lock.Lock(); err != nil {
+		return SchemaCacheEntry{}, err
+	}
+	defer func() { contract.IgnoreError(c.lock.Unlock()) }()
+
+	previous, replaced, err := c.Entry(key)
+	if err != nil {
+		return SchemaCacheEntry{}, err
+	}
+
+	blobPath := c.blobPath(entry.Digest)
+	if _, err := os.Stat(blobPath); os.IsNotExist(err) {
+		if err := atomic.WriteFile(blobPath, bytes.NewReader(schemaBytes)); err != nil {
+			return SchemaCacheEntry{}, fmt.Errorf("writing schema for %v to cache: %w", key, err)
+		}
+	}
+	if err := atomic.WriteFile(c.entryPath(key), bytes.NewReader(entryBytes)); err != nil {
+		return SchemaCacheEntry{}, fmt.Errorf("writing schema cache entry for %v: %w", key, err)
+	}
+
+	if replaced && previous.Digest != entry.Digest {
+		if err := c.removeUnreferencedSchemas(); err != nil {
+			return SchemaCacheEntry{}, err
+		}
+	}
+	return entry, nil
+}
+
+// List returns the entries in the cache, sorted by package and version.
+func (c *SchemaCache) List() ([]SchemaCacheEntry, error) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "entries", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []SchemaCacheEntry
+	for _, path := range paths {
+		entry, ok, err := c.readEntry(path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		ei, ej := entries[i], entries[j]
+		if ei.Package != ej.Package {
+			return ei.Package < ej.Package
+		}
+		vi, erri := semver.ParseTolerant(ei.Version)
+		vj, errj := semver.ParseTolerant(ej.Version)
+		if erri == nil && errj == nil {
+			return vi.LT(vj)
+		}
+		return ei.Version < ej.Version
+	})
+	return entries, nil
+}
+
+// Clear removes the schemas of the given packages from the cache, or every schema if no packages are given. It returns
+// the entries that were removed.
+func (c *SchemaCache) Clear(packages ...string) ([]SchemaCacheEntry, error) {
+	if _, err := os.Stat(c.dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err := c.lock.Lock(); err != nil {
+		return nil, err
+	}
+	defer func() { contract.IgnoreError(c.lock.Unlock()) }()
+
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []SchemaCacheEntry
+	for _, entry := range entries {
+		if len(packages) != 0 && !containsString(packages, entry.Package) {
+			continue
+		}
+		if err := os.Remove(c.entryPath(entry.SchemaCacheKey)); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed = append(removed, entry)
+	}
+	return removed, c.removeUnreferencedSchemas()
+}
+
+// removeUnreferencedSchemas removes the schemas that no entry refers to. The caller must hold the lock.
+func (c *SchemaCache) removeUnreferencedSchemas() error {
+	entries, err := c.List()
+	if err != nil {
+		return err
+	}
+	referenced := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		referenced[entry.Digest] = true
+	}
+
+	paths, err := filepath.Glob(filepath.Join(c.dir, "blobs", "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if referenced[strings.TrimSuffix(filepath.Base(path), ".json")] {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Warm makes sure that the schema of the given resource plugin is in the cache, loading it from the plugin if it is
+// missing or stale. It returns the schema's entry and whether the schema had to be loaded.
+func (c *SchemaCache) Warm(host plugin.Host, pkg string, version *semver.Version) (SchemaCacheEntry, bool, error) {
+	// Skip the schema file next to the plugin, so that the schema is always written to this cache.
+	l := &pluginLoader{
+		host:         host,
+		entries:      map[string]PackageReference{},
+		schemaCache:  c,
+		cacheOptions: pluginLoaderCacheOptions{disableFileCache: true},
+	}
+
+	pluginInfo, err := l.resolvePlugin(pkg, version)
+	if err != nil {
+		return SchemaCacheEntry{}, false, err
+	}
+	key, ok := schemaCacheKey(pkg, pluginInfo)
+	if !ok {
+		return SchemaCacheEntry{}, false, fmt.Errorf("the schema of %s cannot be cached: the plugin has no version", pkg)
+	}
+	if _, ok := c.Get(key, pluginInfo.SchemaTime); ok {
+		entry, _, err := c.Entry(key)
+		return entry, false, err
+	}
+
+	if _, _, err := l.loadSchemaBytes(pkg, pluginInfo.Version); err != nil {
+		return SchemaCacheEntry{}, false, err
+	}
+	entry, ok, err := c.Entry(key)
+	if err == nil && !ok {
+		err = errors.New("the schema was not written to the cache")
+	}
+	return entry, true, err
+}
+
+// schemaCacheKey returns the key of the schema of the given resource plugin. Only schemas of installed plugins, whose
+// modification time is known, are cached, so that a cached schema can be invalidated when its plugin changes.
+func schemaCacheKey(pkg string, pluginInfo *workspace.PluginInfo) (SchemaCacheKey, bool) {
+	if pluginInfo.Version == nil || pluginInfo.SchemaTime.IsZero() {
+		return SchemaCacheKey{}, false
+	}
+	return SchemaCacheKey{Package: pkg, Version: pluginInfo.Version.String()}, true
+}
+
+// newDefaultSchemaCache returns the schema cache that plugin loaders use, or nil if the cache is disabled.
+func newDefaultSchemaCache() *SchemaCache {
+	if env.DisableSchemaCache.Value() {
+		return nil
+	}
+	c, err := DefaultSchemaCache()
+	if err != nil {
+		logging.V(7).Infof("schema cache: %v", err)
+		return nil
+	}
+	return c
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
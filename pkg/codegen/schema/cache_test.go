@@ -0,0 +1,144 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaCache(t *testing.T) {
+	t.Parallel()
+
+	c := NewSchemaCache(t.TempDir())
+	pluginTime := time.Date(2023, 12, 7, 0, 0, 0, 0, time.UTC)
+
+	aws := SchemaCacheKey{Package: "aws", Version: "6.0.0"}
+	_, ok := c.Get(aws, pluginTime)
+	assert.False(t, ok)
+
+	entry, err := c.Put(aws, pluginTime, []byte(`{"name":"aws"}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(14), entry.Size)
+
+	schemaBytes, ok := c.Get(aws, pluginTime)
+	require.True(t, ok)
+	assert.Equal(t, `{"name":"aws"}`, string(schemaBytes))
+
+	// Reinstalling the plugin invalidates the schema.
+	_, ok = c.Get(aws, pluginTime.Add(time.Second))
+	assert.False(t, ok)
+
+	// The same schema is only stored once.
+	awsPatch := SchemaCacheKey{Package: "aws", Version: "6.0.1"}
+	_, err = c.Put(awsPatch, pluginTime, []byte(`{"name":"aws"}`))
+	require.NoError(t, err)
+	_, err = c.Put(SchemaCacheKey{Package: "gcp", Version: "7.0.0"}, pluginTime, []byte(`{"name":"gcp"}`))
+	require.NoError(t, err)
+	assert.Len(t, blobs(t, c), 2)
+
+	entries, err := c.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, aws, entries[0].SchemaCacheKey)
+	assert.Equal(t, awsPatch, entries[1].SchemaCacheKey)
+	assert.Equal(t, "gcp", entries[2].Package)
+
+	// Replacing a schema removes the old one once nothing refers to it.
+	_, err = c.Put(SchemaCacheKey{Package: "gcp", Version: "7.0.0"}, pluginTime, []byte(`{"name":"gcp2"}`))
+	require.NoError(t, err)
+	assert.Len(t, blobs(t, c), 2)
+
+	removed, err := c.Clear("aws")
+	require.NoError(t, err)
+	assert.Len(t, removed, 2)
+	assert.Len(t, blobs(t, c), 1)
+
+	removed, err = c.Clear()
+	require.NoError(t, err)
+	assert.Len(t, removed, 1)
+	assert.Empty(t, blobs(t, c))
+	entries, err = c.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSchemaCacheVersionOrder(t *testing.T) {
+	t.Parallel()
+
+	c := NewSchemaCache(t.TempDir())
+	for _, v := range []string{"1.10.0", "1.9.0", "1.2.0"} {
+		_, err := c.Put(SchemaCacheKey{Package: "random", Version: v}, time.Time{}, []byte(v))
+		require.NoError(t, err)
+	}
+
+	entries, err := c.List()
+	require.NoError(t, err)
+	var versions []string
+	for _, e := range entries {
+		versions = append(versions, e.Version)
+	}
+	assert.Equal(t, []string{"1.2.0", "1.9.0", "1.10.0"}, versions)
+}
+
+func TestSchemaCacheConcurrentWriters(t *testing.T) {
+	t.Parallel()
+
+	// Separate caches over the same directory stand in for separate processes.
+	dir := t.TempDir()
+	pluginTime := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := NewSchemaCache(dir)
+			for j := 0; j < 10; j++ {
+				key := SchemaCacheKey{Package: "pkg", Version: fmt.Sprintf("1.0.%d", j%3)}
+				_, err := c.Put(key, pluginTime, []byte(fmt.Sprintf("schema %d", i)))
+				assert.NoError(t, err)
+				if schemaBytes, ok := c.Get(key, pluginTime); ok {
+					assert.Contains(t, string(schemaBytes), "schema ")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	c := NewSchemaCache(dir)
+	entries, err := c.List()
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+	// Only the schemas that the remaining entries refer to are kept.
+	referenced := map[string]bool{}
+	for _, e := range entries {
+		referenced[e.Digest] = true
+	}
+	assert.Len(t, blobs(t, c), len(referenced))
+}
+
+func blobs(t *testing.T, c *SchemaCache) []string {
+	paths, err := filepath.Glob(filepath.Join(c.Dir(), "blobs", "*.json"))
+	require.NoError(t, err)
+	return paths
+}
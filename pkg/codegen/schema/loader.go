@@ -54,6 +54,9 @@ type pluginLoader struct {
 	host    plugin.Host
 	entries map[string]PackageReference
 
+	// schemaCache is the on-disk schema cache shared with other processes, or nil if it is disabled.
+	schemaCache *SchemaCache
+
 	cacheOptions pluginLoaderCacheOptions
 }
 
@@ -63,24 +66,31 @@ type pluginLoaderCacheOptions struct {
 	disableEntryCache bool
 	// useFileCache enables skipping plugin loading when possible and caching JSON schemas to files
 	disableFileCache bool
+	// disableSchemaCache disables the on-disk schema cache shared with other processes
+	disableSchemaCache bool
 	// useMmap enables the use of memory mapped IO to avoid copying the JSON schema
 	disableMmap bool
 }
 
 func NewPluginLoader(host plugin.Host) ReferenceLoader {
 	return &pluginLoader{
-		host:    host,
-		entries: map[string]PackageReference{},
+		host:        host,
+		entries:     map[string]PackageReference{},
+		schemaCache: newDefaultSchemaCache(),
 	}
 }
 
 func newPluginLoaderWithOptions(host plugin.Host, cacheOptions pluginLoaderCacheOptions) ReferenceLoader {
-	return &pluginLoader{
+	l := &pluginLoader{
 		host:    host,
 		entries: map[string]PackageReference{},
 
 		cacheOptions: cacheOptions,
 	}
+	if !cacheOptions.disableSchemaCache {
+		l.schemaCache = newDefaultSchemaCache()
+	}
+	return l
 }
 
 func (l *pluginLoader) getPackage(key string) (PackageReference, bool) {
@@ -214,39 +224,10 @@ func LoadPackageReference(loader Loader, pkg string, version *semver.Version) (P
 }
 
 func (l *pluginLoader) loadSchemaBytes(pkg string, version *semver.Version) ([]byte, *semver.Version, error) {
-	pluginInfo, err := l.host.ResolvePlugin(workspace.ResourcePlugin, pkg, version)
+	pluginInfo, err := l.resolvePlugin(pkg, version)
 	if err != nil {
-		// Try and install the plugin if it was missing and try again, unless auto plugin installs are turned off.
-		if env.DisableAutomaticPluginAcquisition.Value() {
-			return nil, nil, err
-		}
-
-		var missingError *workspace.MissingError
-		if errors.As(err, &missingError) {
-			spec := workspace.PluginSpec{
-				Kind:    workspace.ResourcePlugin,
-				Name:    pkg,
-				Version: version,
-			}
-
-			log := func(sev diag.Severity, msg string) {
-				l.host.Log(sev, "", msg, 0)
-			}
-
-			_, err = pkgWorkspace.InstallPlugin(spec, log)
-			if err != nil {
-				return nil, nil, err
-			}
-
-			pluginInfo, err = l.host.ResolvePlugin(workspace.ResourcePlugin, pkg, version)
-			if err != nil {
-				return nil, version, err
-			}
-		} else {
-			return nil, nil, err
-		}
+		return nil, nil, err
 	}
-	contract.Assertf(pluginInfo != nil, "loading pkg %q: pluginInfo was unexpectedly nil", pkg)
 
 	if version == nil {
 		version = pluginInfo.Version
@@ -259,6 +240,14 @@ func (l *pluginLoader) loadSchemaBytes(pkg string, version *semver.Version) ([]b
 		}
 	}
 
+	cacheKey, cacheable := schemaCacheKey(pkg, pluginInfo)
+	cacheable = cacheable && l.schemaCache != nil
+	if cacheable {
+		if schemaBytes, ok := l.schemaCache.Get(cacheKey, pluginInfo.SchemaTime); ok {
+			return schemaBytes, nil, nil
+		}
+	}
+
 	schemaBytes, provider, err := l.loadPluginSchemaBytes(pkg, version)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Error loading schema from plugin: %w", err)
@@ -270,6 +259,12 @@ func (l *pluginLoader) loadSchemaBytes(pkg string, version *semver.Version) ([]b
 			return nil, nil, fmt.Errorf("Error writing schema from plugin to cache: %w", err)
 		}
 	}
+	if cacheable && !schemaIsEmpty(schemaBytes) {
+		if _, err := l.schemaCache.Put(cacheKey, pluginInfo.SchemaTime, schemaBytes); err != nil {
+			// The shared cache is an optimization, so failing to write to it is not fatal.
+			l.host.Log(diag.Warning, "", fmt.Sprintf("could not cache the schema of %s: %v", cacheKey, err), 0)
+		}
+	}
 
 	if version == nil {
 		info, _ := provider.GetPluginInfo() // nonfatal error
@@ -279,6 +274,44 @@ func (l *pluginLoader) loadSchemaBytes(pkg string, version *semver.Version) ([]b
 	return schemaBytes, version, nil
 }
 
+// resolvePlugin resolves the resource plugin for a package, installing it if it is missing.
+func (l *pluginLoader) resolvePlugin(pkg string, version *semver.Version) (*workspace.PluginInfo, error) {
+	pluginInfo, err := l.host.ResolvePlugin(workspace.ResourcePlugin, pkg, version)
+	if err != nil {
+		// Try and install the plugin if it was missing and try again, unless auto plugin installs are turned off.
+		if env.DisableAutomaticPluginAcquisition.Value() {
+			return nil, err
+		}
+
+		var missingError *workspace.MissingError
+		if errors.As(err, &missingError) {
+			spec := workspace.PluginSpec{
+				Kind:    workspace.ResourcePlugin,
+				Name:    pkg,
+				Version: version,
+			}
+
+			log := func(sev diag.Severity, msg string) {
+				l.host.Log(sev, "", msg, 0)
+			}
+
+			_, err = pkgWorkspace.InstallPlugin(spec, log)
+			if err != nil {
+				return nil, err
+			}
+
+			pluginInfo, err = l.host.ResolvePlugin(workspace.ResourcePlugin, pkg, version)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, err
+		}
+	}
+	contract.Assertf(pluginInfo != nil, "loading pkg %q: pluginInfo was unexpectedly nil", pkg)
+	return pluginInfo, nil
+}
+
 func (l *pluginLoader) loadPluginSchemaBytes(pkg string, version *semver.Version) ([]byte, plugin.Provider, error) {
 	provider, err := l.host.Provider(tokens.Package(pkg), version)
 	if err != nil {
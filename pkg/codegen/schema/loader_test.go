@@ -87,9 +87,10 @@ func BenchmarkLoadPackageReference(b *testing.B) {
 	b.Run("no-cache", func(b *testing.B) {
 		// Disables in-memory cache, mmaping, and using schema files:
 		loader := initLoader(b, pluginLoaderCacheOptions{
-			disableEntryCache: true,
-			disableMmap:       true,
-			disableFileCache:  true,
+			disableEntryCache:  true,
+			disableMmap:        true,
+			disableFileCache:   true,
+			disableSchemaCache: true,
 		})
 
 		b.StopTimer()
@@ -0,0 +1,468 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schemadiff compares two versions of a package schema and classifies each difference by the languages whose
+// generated SDKs it breaks.
+package schemadiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	dotnet "github.com/pulumi/pulumi/pkg/v3/codegen/dotnet"
+	gogen "github.com/pulumi/pulumi/pkg/v3/codegen/go"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/nodejs"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/python"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// Languages are the languages whose SDKs are checked for breaking changes, named as in a schema's language section.
+var Languages = []string{"csharp", "go", "nodejs", "python"}
+
+var importers = map[string]schema.Language{
+	"csharp": dotnet.Importer,
+	"go":     gogen.Importer,
+	"nodejs": nodejs.Importer,
+	"python": python.Importer,
+}
+
+// Change is a difference between two versions of a package schema.
+type Change struct {
+	// Path identifies the part of the schema that changed, e.g. "resources/pkg:index:Res/inputs/name".
+	Path string `json:"path"`
+	// Message describes the change.
+	Message string `json:"message"`
+	// Breaking lists the languages whose generated SDKs the change breaks. It is empty for non-breaking changes.
+	Breaking []string `json:"breaking,omitempty"`
+}
+
+// IsBreaking returns true if the change breaks the SDK of any of the given languages, or of any language if none are
+// given.
+func (c Change) IsBreaking(languages ...string) bool {
+	if len(languages) == 0 {
+		return len(c.Breaking) != 0
+	}
+	for _, l := range c.Breaking {
+		for _, want := range languages {
+			if l == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Diff compares the before and after versions of a package and returns the changes between them, sorted by path. The
+// packages' language-specific metadata is imported so that renames made through language overrides are found.
+func Diff(before, after *schema.Package) ([]Change, error) {
+	if err := before.ImportLanguages(importers); err != nil {
+		return nil, fmt.Errorf("importing old package metadata: %w", err)
+	}
+	if err := after.ImportLanguages(importers); err != nil {
+		return nil, fmt.Errorf("importing new package metadata: %w", err)
+	}
+
+	d := &differ{}
+	d.pkg(before, after)
+	sort.SliceStable(d.changes, func(i, j int) bool { return d.changes[i].Path < d.changes[j].Path })
+	return d.changes, nil
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(path, message string, breaking ...string) {
+	if len(breaking) != 0 {
+		breaking = append([]string(nil), breaking...)
+	}
+	d.changes = append(d.changes, Change{Path: path, Message: message, Breaking: breaking})
+}
+
+func (d *differ) pkg(before, after *schema.Package) {
+	if before.Name != after.Name {
+		d.add("name", fmt.Sprintf("package renamed from %q to %q", before.Name, after.Name), Languages...)
+	}
+
+	d.languageNames(before, after)
+	d.properties("config", before.Config, after.Config, propertyOutput)
+
+	if before.Provider != nil && after.Provider != nil {
+		d.resource("provider", before.Provider, after.Provider)
+	}
+
+	oldResources, newResources := map[string]*schema.Resource{}, map[string]*schema.Resource{}
+	for _, r := range before.Resources {
+		oldResources[r.Token] = r
+	}
+	for _, r := range after.Resources {
+		newResources[r.Token] = r
+	}
+	for _, tok := range sortedKeys(oldResources) {
+		path := "resources/" + tok
+		if r, ok := newResources[tok]; ok {
+			d.resource(path, oldResources[tok], r)
+		} else {
+			d.add(path, "resource removed"+renamedTo(tok, after), Languages...)
+		}
+	}
+	for _, tok := range sortedKeys(newResources) {
+		if _, ok := oldResources[tok]; !ok {
+			d.add("resources/"+tok, "resource added")
+		}
+	}
+
+	oldFunctions, newFunctions := map[string]*schema.Function{}, map[string]*schema.Function{}
+	for _, f := range before.Functions {
+		oldFunctions[f.Token] = f
+	}
+	for _, f := range after.Functions {
+		newFunctions[f.Token] = f
+	}
+	for _, tok := range sortedKeys(oldFunctions) {
+		path := "functions/" + tok
+		if f, ok := newFunctions[tok]; ok {
+			d.function(path, oldFunctions[tok], f)
+		} else {
+			d.add(path, "function removed", Languages...)
+		}
+	}
+	for _, tok := range sortedKeys(newFunctions) {
+		if _, ok := oldFunctions[tok]; !ok {
+			d.add("functions/"+tok, "function added")
+		}
+	}
+
+	oldTypes, newTypes := namedTypes(before), namedTypes(after)
+	for _, tok := range sortedKeys(oldTypes) {
+		path := "types/" + tok
+		t, ok := newTypes[tok]
+		if !ok {
+			d.add(path, "type removed", Languages...)
+			continue
+		}
+		switch oldType := oldTypes[tok].(type) {
+		case *schema.ObjectType:
+			if newType, ok := t.(*schema.ObjectType); ok {
+				d.properties(path+"/properties", oldType.Properties, newType.Properties, propertyInput|propertyOutput)
+			} else {
+				d.add(path, "type changed from an object to an enum", Languages...)
+			}
+		case *schema.EnumType:
+			if newType, ok := t.(*schema.EnumType); ok {
+				d.enum(path, oldType, newType)
+			} else {
+				d.add(path, "type changed from an enum to an object", Languages...)
+			}
+		}
+	}
+	for _, tok := range sortedKeys(newTypes) {
+		if _, ok := oldTypes[tok]; !ok {
+			d.add("types/"+tok, "type added")
+		}
+	}
+}
+
+// languageNames compares the names that language overrides give to the package and its modules.
+func (d *differ) languageNames(before, after *schema.Package) {
+	rename := func(lang, what, oldName, newName string) {
+		if oldName != newName {
+			d.add("language/"+lang, fmt.Sprintf("%s changed from %q to %q", what, oldName, newName), lang)
+		}
+	}
+
+	oldGo, _ := before.Language["go"].(gogen.GoPackageInfo)
+	newGo, _ := after.Language["go"].(gogen.GoPackageInfo)
+	rename("go", "import base path", oldGo.ImportBasePath, newGo.ImportBasePath)
+	rename("go", "root package name", oldGo.RootPackageName, newGo.RootPackageName)
+
+	oldNode, _ := before.Language["nodejs"].(nodejs.NodePackageInfo)
+	newNode, _ := after.Language["nodejs"].(nodejs.NodePackageInfo)
+	rename("nodejs", "package name", oldNode.PackageName, newNode.PackageName)
+
+	oldPython, _ := before.Language["python"].(python.PackageInfo)
+	newPython, _ := after.Language["python"].(python.PackageInfo)
+	rename("python", "package name", oldPython.PackageName, newPython.PackageName)
+
+	oldCSharp, _ := before.Language["csharp"].(dotnet.CSharpPackageInfo)
+	newCSharp, _ := after.Language["csharp"].(dotnet.CSharpPackageInfo)
+	rename("csharp", "root namespace", oldCSharp.GetRootNamespace(), newCSharp.GetRootNamespace())
+
+	// Only modules that still exist can have been renamed; removed modules show up as removed members.
+	modules := map[string]bool{}
+	for _, tok := range memberTokens(before) {
+		modules[before.TokenToModule(tok)] = true
+	}
+	newModules := map[string]bool{}
+	for _, tok := range memberTokens(after) {
+		newModules[after.TokenToModule(tok)] = true
+	}
+	for _, mod := range sortedKeys(modules) {
+		if mod == "" || !newModules[mod] {
+			continue
+		}
+		module := fmt.Sprintf("name of module %q", mod)
+		rename("go", module, moduleName(oldGo.ModuleToPackage, mod), moduleName(newGo.ModuleToPackage, mod))
+		rename("python", module,
+			moduleName(oldPython.ModuleNameOverrides, mod), moduleName(newPython.ModuleNameOverrides, mod))
+		rename("csharp", module, moduleName(oldCSharp.Namespaces, mod), moduleName(newCSharp.Namespaces, mod))
+	}
+}
+
+func (d *differ) resource(path string, before, after *schema.Resource) {
+	oldInfo, _ := before.Language["csharp"].(dotnet.CSharpResourceInfo)
+	newInfo, _ := after.Language["csharp"].(dotnet.CSharpResourceInfo)
+	if oldInfo.Name != newInfo.Name {
+		d.add(path, fmt.Sprintf("C# name changed from %q to %q", oldInfo.Name, newInfo.Name), "csharp")
+	}
+	if before.IsComponent != after.IsComponent {
+		d.add(path, "changed between a component and a custom resource", Languages...)
+	}
+	d.deprecation(path, before.DeprecationMessage, after.DeprecationMessage)
+
+	d.properties(path+"/inputs", before.InputProperties, after.InputProperties, propertyInput)
+	d.properties(path+"/properties", before.Properties, after.Properties, propertyOutput)
+}
+
+func (d *differ) function(path string, before, after *schema.Function) {
+	d.deprecation(path, before.DeprecationMessage, after.DeprecationMessage)
+
+	var oldInputs, newInputs []*schema.Property
+	if before.Inputs != nil {
+		oldInputs = before.Inputs.Properties
+	}
+	if after.Inputs != nil {
+		newInputs = after.Inputs.Properties
+	}
+	d.properties(path+"/inputs", oldInputs, newInputs, propertyInput)
+
+	if before.MultiArgumentInputs != after.MultiArgumentInputs {
+		d.add(path+"/inputs", "changed between a single argument object and multiple arguments", Languages...)
+	}
+
+	oldReturn, newReturn := typeString(before.ReturnType), typeString(after.ReturnType)
+	oldOutputs, oldIsObject := before.ReturnType.(*schema.ObjectType)
+	newOutputs, newIsObject := after.ReturnType.(*schema.ObjectType)
+	switch {
+	case oldIsObject && newIsObject:
+		d.properties(path+"/outputs", oldOutputs.Properties, newOutputs.Properties, propertyOutput)
+	case oldReturn != newReturn:
+		d.add(path+"/outputs", fmt.Sprintf("return type changed from %s to %s", oldReturn, newReturn), Languages...)
+	}
+}
+
+func (d *differ) enum(path string, before, after *schema.EnumType) {
+	if oldType, newType := typeString(before.ElementType), typeString(after.ElementType); oldType != newType {
+		d.add(path, fmt.Sprintf("element type changed from %s to %s", oldType, newType), Languages...)
+	}
+
+	newValues := map[string]*schema.Enum{}
+	for _, e := range after.Elements {
+		newValues[fmt.Sprint(e.Value)] = e
+	}
+	oldValues := map[string]bool{}
+	for _, e := range before.Elements {
+		value := fmt.Sprint(e.Value)
+		oldValues[value] = true
+		path := fmt.Sprintf("%s/values/%v", path, value)
+		n, ok := newValues[value]
+		switch {
+		case !ok:
+			d.add(path, "enum value removed", Languages...)
+		case e.Name != n.Name:
+			d.add(path, fmt.Sprintf("enum value renamed from %q to %q", e.Name, n.Name), Languages...)
+		}
+	}
+	for _, e := range after.Elements {
+		if value := fmt.Sprint(e.Value); !oldValues[value] {
+			d.add(fmt.Sprintf("%s/values/%v", path, value), "enum value added")
+		}
+	}
+}
+
+// propertyUse describes how the properties being compared are used by SDKs: whether users set them, read them, or
+// both.
+type propertyUse int
+
+const (
+	propertyInput propertyUse = 1 << iota
+	propertyOutput
+)
+
+func (d *differ) properties(path string, before, after []*schema.Property, use propertyUse) {
+	newProps := map[string]*schema.Property{}
+	for _, p := range after {
+		newProps[p.Name] = p
+	}
+	oldProps := map[string]bool{}
+	for _, o := range before {
+		oldProps[o.Name] = true
+		path := path + "/" + o.Name
+		if n, ok := newProps[o.Name]; ok {
+			d.property(path, o, n, use)
+		} else {
+			d.add(path, "property removed", Languages...)
+		}
+	}
+	for _, n := range after {
+		if oldProps[n.Name] {
+			continue
+		}
+		path := path + "/" + n.Name
+		if use&propertyInput != 0 && n.IsRequired() {
+			d.add(path, "required property added", Languages...)
+		} else {
+			d.add(path, "property added")
+		}
+	}
+}
+
+func (d *differ) property(path string, before, after *schema.Property, use propertyUse) {
+	if oldType, newType := typeString(before.Type), typeString(after.Type); oldType != newType {
+		d.add(path, fmt.Sprintf("type changed from %s to %s", oldType, newType), Languages...)
+	}
+
+	switch {
+	case !before.IsRequired() && after.IsRequired():
+		if use&propertyInput != 0 {
+			d.add(path, "property became required", Languages...)
+		} else {
+			// Go represents optional outputs as pointers, so making one required changes its type.
+			d.add(path, "property became required", "go")
+		}
+	case before.IsRequired() && !after.IsRequired():
+		if use&propertyOutput != 0 {
+			// Optional outputs are pointers in Go and may be null or undefined in C# and TypeScript.
+			d.add(path, "property became optional", "csharp", "go", "nodejs")
+		} else {
+			d.add(path, "property became optional")
+		}
+	}
+
+	if before.ConstValue != after.ConstValue {
+		d.add(path, fmt.Sprintf("constant value changed from %v to %v", before.ConstValue, after.ConstValue), Languages...)
+	}
+	if before.Secret != after.Secret && use&propertyOutput != 0 {
+		d.add(path, fmt.Sprintf("secret changed from %v to %v", before.Secret, after.Secret))
+	}
+	d.deprecation(path, before.DeprecationMessage, after.DeprecationMessage)
+
+	oldCSharp, _ := before.Language["csharp"].(dotnet.CSharpPropertyInfo)
+	newCSharp, _ := after.Language["csharp"].(dotnet.CSharpPropertyInfo)
+	if oldCSharp.Name != newCSharp.Name {
+		d.add(path, fmt.Sprintf("C# name changed from %q to %q", oldCSharp.Name, newCSharp.Name), "csharp")
+	}
+	oldPython, _ := before.Language["python"].(python.PropertyInfo)
+	newPython, _ := after.Language["python"].(python.PropertyInfo)
+	if oldPython.MapCase != newPython.MapCase {
+		d.add(path, fmt.Sprintf("Python map casing changed from %v to %v", oldPython.MapCase, newPython.MapCase),
+			"python")
+	}
+}
+
+func (d *differ) deprecation(path, before, after string) {
+	if before == "" && after != "" {
+		d.add(path, "deprecated: "+after)
+	}
+}
+
+// typeString returns a description of a type that ignores whether it is optional or accepts outputs, which are
+// compared separately.
+func typeString(t schema.Type) string {
+	switch t := t.(type) {
+	case nil:
+		return "nothing"
+	case *schema.OptionalType:
+		return typeString(t.ElementType)
+	case *schema.InputType:
+		return typeString(t.ElementType)
+	case *schema.ArrayType:
+		return "array<" + typeString(t.ElementType) + ">"
+	case *schema.MapType:
+		return "map<" + typeString(t.ElementType) + ">"
+	case *schema.UnionType:
+		elements := make([]string, len(t.ElementTypes))
+		for i, e := range t.ElementTypes {
+			elements[i] = typeString(e)
+		}
+		return "union<" + strings.Join(elements, ", ") + ">"
+	case *schema.ObjectType:
+		if t.PlainShape != nil {
+			return typeString(t.PlainShape)
+		}
+		return t.Token
+	case *schema.ResourceType:
+		return t.Token
+	default:
+		return t.String()
+	}
+}
+
+// renamedTo describes the resource in pkg that lists tok as an alias, if any.
+func renamedTo(tok string, pkg *schema.Package) string {
+	for _, r := range pkg.Resources {
+		for _, a := range r.Aliases {
+			if a.Type != nil && *a.Type == tok {
+				return fmt.Sprintf(" (renamed to %s)", r.Token)
+			}
+		}
+	}
+	return ""
+}
+
+func namedTypes(pkg *schema.Package) map[string]schema.Type {
+	types := map[string]schema.Type{}
+	for _, t := range pkg.Types {
+		switch t := t.(type) {
+		case *schema.ObjectType:
+			if !t.IsInputShape() {
+				types[t.Token] = t
+			}
+		case *schema.EnumType:
+			types[t.Token] = t
+		}
+	}
+	return types
+}
+
+func memberTokens(pkg *schema.Package) []string {
+	var tokens []string
+	for _, r := range pkg.Resources {
+		tokens = append(tokens, r.Token)
+	}
+	for _, f := range pkg.Functions {
+		tokens = append(tokens, f.Token)
+	}
+	for tok := range namedTypes(pkg) {
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+func moduleName(overrides map[string]string, mod string) string {
+	if name, ok := overrides[mod]; ok {
+		return name
+	}
+	return mod
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,199 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadiff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+const oldSchema = `{
+	"name": "test",
+	"version": "1.0.0",
+	"language": {
+		"go": {"importBasePath": "github.com/example/pulumi-test/sdk/go/test"},
+		"nodejs": {"packageName": "@example/test"}
+	},
+	"resources": {
+		"test:index:Bucket": {
+			"inputProperties": {
+				"acl": {"type": "string"},
+				"name": {"type": "string"},
+				"size": {"type": "integer", "language": {"csharp": {"name": "SizeGb"}}}
+			},
+			"requiredInputs": ["name"],
+			"properties": {
+				"arn": {"type": "string"},
+				"region": {"type": "string"}
+			},
+			"required": ["arn", "region"]
+		},
+		"test:index:Queue": {}
+	},
+	"functions": {
+		"test:index:getBucket": {
+			"inputs": {"properties": {"name": {"type": "string"}}},
+			"outputs": {"properties": {"arn": {"type": "string"}}}
+		}
+	},
+	"types": {
+		"test:index:Tier": {
+			"type": "string",
+			"enum": [{"value": "hot"}, {"value": "cold"}]
+		}
+	}
+}`
+
+const newSchema = `{
+	"name": "test",
+	"version": "1.1.0",
+	"language": {
+		"go": {"importBasePath": "github.com/example/pulumi-test/sdk/v2/go/test"},
+		"nodejs": {"packageName": "@example/test"}
+	},
+	"resources": {
+		"test:index:Bucket": {
+			"inputProperties": {
+				"acl": {"type": "string"},
+				"name": {"type": "string"},
+				"size": {"type": "number", "language": {"csharp": {"name": "Size"}}},
+				"policy": {"type": "string"},
+				"owner": {"type": "string"}
+			},
+			"requiredInputs": ["name", "owner"],
+			"properties": {
+				"arn": {"type": "string"},
+				"region": {"type": "string"},
+				"endpoint": {"type": "string"}
+			},
+			"required": ["arn"]
+		},
+		"test:index:MessageQueue": {
+			"aliases": [{"type": "test:index:Queue"}]
+		}
+	},
+	"functions": {
+		"test:index:getBucket": {
+			"inputs": {"properties": {"name": {"type": "string"}}},
+			"outputs": {"properties": {"arn": {"type": "string"}}},
+			"deprecationMessage": "use getBucketV2"
+		}
+	},
+	"types": {
+		"test:index:Tier": {
+			"type": "string",
+			"enum": [{"value": "hot"}, {"value": "warm"}]
+		}
+	}
+}`
+
+func bindTestSchema(t *testing.T, text string) *schema.Package {
+	var spec schema.PackageSpec
+	require.NoError(t, json.Unmarshal([]byte(text), &spec))
+	pkg, diags, err := schema.BindSpec(spec, nil)
+	require.NoError(t, err)
+	require.False(t, diags.HasErrors(), diags.Error())
+	return pkg
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	changes, err := Diff(bindTestSchema(t, oldSchema), bindTestSchema(t, newSchema))
+	require.NoError(t, err)
+
+	all := []string{"csharp", "go", "nodejs", "python"}
+	assert.Equal(t, []Change{
+		{
+			Path:    "functions/test:index:getBucket",
+			Message: "deprecated: use getBucketV2",
+		},
+		{
+			Path: "language/go",
+			Message: `import base path changed from "github.com/example/pulumi-test/sdk/go/test" ` +
+				`to "github.com/example/pulumi-test/sdk/v2/go/test"`,
+			Breaking: []string{"go"},
+		},
+		{
+			Path:     "resources/test:index:Bucket/inputs/owner",
+			Message:  "required property added",
+			Breaking: all,
+		},
+		{
+			Path:    "resources/test:index:Bucket/inputs/policy",
+			Message: "property added",
+		},
+		{
+			Path:     "resources/test:index:Bucket/inputs/size",
+			Message:  "type changed from integer to number",
+			Breaking: all,
+		},
+		{
+			Path:     "resources/test:index:Bucket/inputs/size",
+			Message:  `C# name changed from "SizeGb" to "Size"`,
+			Breaking: []string{"csharp"},
+		},
+		{
+			Path:    "resources/test:index:Bucket/properties/endpoint",
+			Message: "property added",
+		},
+		{
+			Path:     "resources/test:index:Bucket/properties/region",
+			Message:  "property became optional",
+			Breaking: []string{"csharp", "go", "nodejs"},
+		},
+		{
+			Path:    "resources/test:index:MessageQueue",
+			Message: "resource added",
+		},
+		{
+			Path:     "resources/test:index:Queue",
+			Message:  "resource removed (renamed to test:index:MessageQueue)",
+			Breaking: all,
+		},
+		{
+			Path:     "types/test:index:Tier/values/cold",
+			Message:  "enum value removed",
+			Breaking: all,
+		},
+		{
+			Path:    "types/test:index:Tier/values/warm",
+			Message: "enum value added",
+		},
+	}, changes)
+}
+
+func TestDiffIdentical(t *testing.T) {
+	t.Parallel()
+
+	changes, err := Diff(bindTestSchema(t, oldSchema), bindTestSchema(t, oldSchema))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestChangeIsBreaking(t *testing.T) {
+	t.Parallel()
+
+	c := Change{Breaking: []string{"go"}}
+	assert.True(t, c.IsBreaking())
+	assert.True(t, c.IsBreaking("go", "python"))
+	assert.False(t, c.IsBreaking("python"))
+	assert.False(t, Change{}.IsBreaking())
+}
@@ -0,0 +1,842 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/model"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// ErrUnsupported is returned by InterpretProgram for programs that use features that the interpreter does not support.
+var ErrUnsupported = errors.New("not supported by the PCL interpreter")
+
+// ProgramResult is the observable result of running a program: the resources it registers and the outputs of its
+// stack.
+type ProgramResult struct {
+	Resources []RegisteredResource
+	Outputs   resource.PropertyMap
+}
+
+// InterpretProgram evaluates a bound PCL program against the same mocks as MockMonitor, and returns the resources that
+// the program registers and the outputs of its stack. Programs generated from the PCL program should produce the same
+// result when they are run under a MockMonitor.
+//
+// config supplies the values of the program's config variables by name. Config variables that are not present take
+// their default values.
+func InterpretProgram(program *pcl.Program, project, stack string,
+	config map[string]resource.PropertyValue,
+) (*ProgramResult, error) {
+	i := &interpreter{
+		project:  project,
+		stack:    stack,
+		config:   config,
+		stackURN: mockURN(project, stack, "", stackType, project+"-"+stack),
+		values:   map[model.Traversable]resource.PropertyValue{},
+	}
+
+	outputs := resource.PropertyMap{}
+	for _, n := range pcl.Linearize(program) {
+		switch n := n.(type) {
+		case *pcl.ConfigVariable:
+			v, ok := config[n.LogicalName()]
+			if !ok {
+				if n.DefaultValue == nil {
+					return nil, fmt.Errorf("missing value for config variable %q", n.LogicalName())
+				}
+				var err error
+				if v, err = i.eval(n.DefaultValue); err != nil {
+					return nil, err
+				}
+			}
+			i.values[n] = v
+		case *pcl.LocalVariable:
+			v, err := i.eval(n.Definition.Value)
+			if err != nil {
+				return nil, err
+			}
+			i.values[n] = v
+		case *pcl.Resource:
+			v, err := i.registerResource(n)
+			if err != nil {
+				return nil, fmt.Errorf("resource %q: %w", n.LogicalName(), err)
+			}
+			i.values[n] = v
+		case *pcl.OutputVariable:
+			v, err := i.eval(n.Value)
+			if err != nil {
+				return nil, fmt.Errorf("output %q: %w", n.LogicalName(), err)
+			}
+			if !v.IsNull() {
+				outputs[resource.PropertyKey(n.LogicalName())] = v
+			}
+		default:
+			return nil, fmt.Errorf("%T nodes: %w", n, ErrUnsupported)
+		}
+	}
+	return &ProgramResult{Resources: i.resources, Outputs: outputs}, nil
+}
+
+// MockConfig returns values for the config variables of a program that do not have defaults. String variables are
+// set to their name, numbers to 42 and booleans to true.
+func MockConfig(program *pcl.Program) (map[string]resource.PropertyValue, error) {
+	config := map[string]resource.PropertyValue{}
+	for _, v := range program.ConfigVariables() {
+		if v.DefaultValue != nil {
+			continue
+		}
+		switch model.ResolveOutputs(v.Type()) {
+		case model.StringType:
+			config[v.LogicalName()] = resource.NewStringProperty(v.LogicalName())
+		case model.NumberType, model.IntType:
+			config[v.LogicalName()] = resource.NewNumberProperty(42)
+		case model.BoolType:
+			config[v.LogicalName()] = resource.NewBoolProperty(true)
+		default:
+			return nil, fmt.Errorf("config variables of type %v: %w", v.Type(), ErrUnsupported)
+		}
+	}
+	return config, nil
+}
+
+type interpreter struct {
+	project  string
+	stack    string
+	config   map[string]resource.PropertyValue
+	stackURN resource.URN
+
+	// values holds the values of program nodes and of the variables of for and splat expressions.
+	values map[model.Traversable]resource.PropertyValue
+	// rangeValue is the value of the range variable of the resource that is being registered, if any.
+	rangeValue *resource.PropertyValue
+
+	resources []RegisteredResource
+}
+
+func unsupported(format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), ErrUnsupported)
+}
+
+// registerResource registers each instance of a resource, and returns the value that refers to the resource: an
+// object for a single instance, or a list or map of objects for a resource with a range.
+func (i *interpreter) registerResource(r *pcl.Resource) (resource.PropertyValue, error) {
+	parent, protect := i.stackURN, false
+	if r.Options != nil {
+		if r.Options.Parent != nil {
+			v, err := i.eval(r.Options.Parent)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			urn, err := index(v, resource.NewStringProperty("urn"))
+			if err != nil || !urn.IsString() {
+				return resource.PropertyValue{}, fmt.Errorf("parent is not a resource")
+			}
+			parent = resource.URN(urn.StringValue())
+		}
+		if r.Options.Protect != nil {
+			v, err := i.eval(r.Options.Protect)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			protect = unsecret(v).IsBool() && unsecret(v).BoolValue()
+		}
+	}
+
+	if r.Options == nil || r.Options.Range == nil {
+		return i.registerInstance(r, r.LogicalName(), parent, protect)
+	}
+
+	rng, err := i.eval(r.Options.Range)
+	if err != nil {
+		return resource.PropertyValue{}, err
+	}
+	defer func() { i.rangeValue = nil }()
+
+	rng = unsecret(rng)
+	switch {
+	case rng.IsNull():
+		// This is usually an output of a resource that the mocks do not set.
+		return resource.PropertyValue{}, unsupported("ranges over null values")
+	case rng.IsBool():
+		if !rng.BoolValue() {
+			return resource.NewNullProperty(), nil
+		}
+		return i.registerInstance(r, r.LogicalName(), parent, protect)
+	case rng.IsNumber():
+		var instances []resource.PropertyValue
+		for n := 0; n < int(rng.NumberValue()); n++ {
+			key := resource.NewNumberProperty(float64(n))
+			i.rangeValue = &resource.PropertyValue{V: resource.PropertyMap{"key": key, "value": key}}
+			v, err := i.registerInstance(r, fmt.Sprintf("%s-%d", r.LogicalName(), n), parent, protect)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			instances = append(instances, v)
+		}
+		return resource.NewArrayProperty(instances), nil
+	case rng.IsArray():
+		var instances []resource.PropertyValue
+		for n, e := range rng.ArrayValue() {
+			i.rangeValue = &resource.PropertyValue{V: resource.PropertyMap{
+				"key":   resource.NewNumberProperty(float64(n)),
+				"value": e,
+			}}
+			v, err := i.registerInstance(r, fmt.Sprintf("%s-%d", r.LogicalName(), n), parent, protect)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			instances = append(instances, v)
+		}
+		return resource.NewArrayProperty(instances), nil
+	case rng.IsObject():
+		instances := resource.PropertyMap{}
+		for _, k := range rng.ObjectValue().StableKeys() {
+			i.rangeValue = &resource.PropertyValue{V: resource.PropertyMap{
+				"key":   resource.NewStringProperty(string(k)),
+				"value": rng.ObjectValue()[k],
+			}}
+			v, err := i.registerInstance(r, fmt.Sprintf("%s-%s", r.LogicalName(), k), parent, protect)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			instances[k] = v
+		}
+		return resource.NewObjectProperty(instances), nil
+	default:
+		return resource.PropertyValue{}, fmt.Errorf("cannot range over %v", rng.TypeString())
+	}
+}
+
+func (i *interpreter) registerInstance(r *pcl.Resource, name string, parent resource.URN,
+	protect bool,
+) (resource.PropertyValue, error) {
+	inputs := resource.PropertyMap{}
+	for _, attr := range r.Inputs {
+		v, err := i.eval(attr.Value)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		if !v.IsNull() {
+			inputs[resource.PropertyKey(attr.Name)] = v
+		}
+	}
+
+	// Generated programs register resources with the token from their schema, which may differ from the canonical
+	// token that the binder gives them.
+	typ, custom := r.Token, true
+	if r.Schema != nil {
+		typ, custom = r.Schema.Token, !r.Schema.IsComponent
+	}
+	urn := mockURN(i.project, i.stack, parent, typ, name)
+	i.resources = append(i.resources, RegisteredResource{
+		URN:     urn,
+		Type:    typ,
+		Name:    name,
+		Custom:  custom,
+		Parent:  parent,
+		Protect: protect,
+		Inputs:  inputs,
+	})
+
+	id, outputs := mockOutputs(name, custom, inputs)
+	outputs["urn"] = resource.NewStringProperty(string(urn))
+	if custom {
+		outputs["id"] = resource.NewStringProperty(string(id))
+	}
+	return resource.NewObjectProperty(outputs), nil
+}
+
+func (i *interpreter) eval(expr model.Expression) (resource.PropertyValue, error) {
+	switch expr := expr.(type) {
+	case *model.LiteralValueExpression:
+		return ctyToProperty(expr.Value)
+	case *model.TemplateExpression:
+		var sb strings.Builder
+		secret := false
+		for _, part := range expr.Parts {
+			v, err := i.eval(part)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			secret = secret || v.IsSecret()
+			s, err := toString(unsecret(v))
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			sb.WriteString(s)
+		}
+		return withSecret(resource.NewStringProperty(sb.String()), secret), nil
+	case *model.TupleConsExpression:
+		elements := make([]resource.PropertyValue, len(expr.Expressions))
+		for n, e := range expr.Expressions {
+			v, err := i.eval(e)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			elements[n] = v
+		}
+		return resource.NewArrayProperty(elements), nil
+	case *model.ObjectConsExpression:
+		object := resource.PropertyMap{}
+		for _, item := range expr.Items {
+			k, err := i.eval(item.Key)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			key, err := toString(unsecret(k))
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			v, err := i.eval(item.Value)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			object[resource.PropertyKey(key)] = v
+		}
+		return resource.NewObjectProperty(object), nil
+	case *model.ScopeTraversalExpression:
+		v, err := i.lookup(expr.Parts[0], expr.RootName)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		return traverse(v, expr.Traversal[1:])
+	case *model.RelativeTraversalExpression:
+		v, err := i.eval(expr.Source)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		return traverse(v, expr.Traversal)
+	case *model.IndexExpression:
+		collection, err := i.eval(expr.Collection)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		key, err := i.eval(expr.Key)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		return index(collection, key)
+	case *model.FunctionCallExpression:
+		return i.call(expr)
+	case *model.ConditionalExpression:
+		cond, err := i.eval(expr.Condition)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		if !unsecret(cond).IsBool() {
+			return resource.PropertyValue{}, fmt.Errorf("condition is not a bool")
+		}
+		if unsecret(cond).BoolValue() {
+			return i.eval(expr.TrueResult)
+		}
+		return i.eval(expr.FalseResult)
+	case *model.BinaryOpExpression:
+		return i.evalBinaryOp(expr)
+	case *model.UnaryOpExpression:
+		v, err := i.eval(expr.Operand)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		secret, v := v.IsSecret(), unsecret(v)
+		switch {
+		case expr.Operation == hclsyntax.OpNegate && v.IsNumber():
+			return withSecret(resource.NewNumberProperty(-v.NumberValue()), secret), nil
+		case expr.Operation == hclsyntax.OpLogicalNot && v.IsBool():
+			return withSecret(resource.NewBoolProperty(!v.BoolValue()), secret), nil
+		default:
+			return resource.PropertyValue{}, fmt.Errorf("invalid operand for unary operator")
+		}
+	case *model.ForExpression:
+		return i.evalFor(expr)
+	case *model.SplatExpression:
+		source, err := i.eval(expr.Source)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		secret, source := source.IsSecret(), unsecret(source)
+		var items []resource.PropertyValue
+		switch {
+		case source.IsArray():
+			items = source.ArrayValue()
+		case !source.IsNull():
+			items = []resource.PropertyValue{source}
+		}
+		results := make([]resource.PropertyValue, len(items))
+		for n, item := range items {
+			i.values[expr.Item] = item
+			if results[n], err = i.eval(expr.Each); err != nil {
+				return resource.PropertyValue{}, err
+			}
+		}
+		return withSecret(resource.NewArrayProperty(results), secret), nil
+	default:
+		return resource.PropertyValue{}, unsupported("%T expressions", expr)
+	}
+}
+
+func (i *interpreter) lookup(root model.Traversable, name string) (resource.PropertyValue, error) {
+	if v, ok := i.values[root]; ok {
+		return v, nil
+	}
+	if v, ok := root.(*model.Variable); ok && v.Name == "range" && i.rangeValue != nil {
+		return *i.rangeValue, nil
+	}
+	return resource.PropertyValue{}, unsupported("references to %q", name)
+}
+
+func (i *interpreter) evalBinaryOp(expr *model.BinaryOpExpression) (resource.PropertyValue, error) {
+	left, err := i.eval(expr.LeftOperand)
+	if err != nil {
+		return resource.PropertyValue{}, err
+	}
+	right, err := i.eval(expr.RightOperand)
+	if err != nil {
+		return resource.PropertyValue{}, err
+	}
+	secret := left.IsSecret() || right.IsSecret()
+	left, right = unsecret(left), unsecret(right)
+
+	switch expr.Operation {
+	case hclsyntax.OpEqual:
+		return withSecret(resource.NewBoolProperty(left.DeepEquals(right)), secret), nil
+	case hclsyntax.OpNotEqual:
+		return withSecret(resource.NewBoolProperty(!left.DeepEquals(right)), secret), nil
+	case hclsyntax.OpLogicalAnd, hclsyntax.OpLogicalOr:
+		if !left.IsBool() || !right.IsBool() {
+			return resource.PropertyValue{}, fmt.Errorf("invalid operands for logical operator")
+		}
+		result := left.BoolValue() && right.BoolValue()
+		if expr.Operation == hclsyntax.OpLogicalOr {
+			result = left.BoolValue() || right.BoolValue()
+		}
+		return withSecret(resource.NewBoolProperty(result), secret), nil
+	}
+
+	if !left.IsNumber() || !right.IsNumber() {
+		return resource.PropertyValue{}, fmt.Errorf("invalid operands for arithmetic or comparison operator")
+	}
+	l, r := left.NumberValue(), right.NumberValue()
+	var result resource.PropertyValue
+	switch expr.Operation {
+	case hclsyntax.OpAdd:
+		result = resource.NewNumberProperty(l + r)
+	case hclsyntax.OpSubtract:
+		result = resource.NewNumberProperty(l - r)
+	case hclsyntax.OpMultiply:
+		result = resource.NewNumberProperty(l * r)
+	case hclsyntax.OpDivide:
+		result = resource.NewNumberProperty(l / r)
+	case hclsyntax.OpModulo:
+		result = resource.NewNumberProperty(math.Mod(l, r))
+	case hclsyntax.OpGreaterThan:
+		result = resource.NewBoolProperty(l > r)
+	case hclsyntax.OpGreaterThanOrEqual:
+		result = resource.NewBoolProperty(l >= r)
+	case hclsyntax.OpLessThan:
+		result = resource.NewBoolProperty(l < r)
+	case hclsyntax.OpLessThanOrEqual:
+		result = resource.NewBoolProperty(l <= r)
+	default:
+		return resource.PropertyValue{}, unsupported("binary operator %v", expr.Operation)
+	}
+	return withSecret(result, secret), nil
+}
+
+func (i *interpreter) evalFor(expr *model.ForExpression) (resource.PropertyValue, error) {
+	if expr.Group {
+		return resource.PropertyValue{}, unsupported("grouping for expressions")
+	}
+
+	collection, err := i.eval(expr.Collection)
+	if err != nil {
+		return resource.PropertyValue{}, err
+	}
+	secret, collection := collection.IsSecret(), unsecret(collection)
+
+	var keys, values []resource.PropertyValue
+	switch {
+	case collection.IsArray():
+		for n, v := range collection.ArrayValue() {
+			keys, values = append(keys, resource.NewNumberProperty(float64(n))), append(values, v)
+		}
+	case collection.IsObject():
+		for _, k := range collection.ObjectValue().StableKeys() {
+			keys = append(keys, resource.NewStringProperty(string(k)))
+			values = append(values, collection.ObjectValue()[k])
+		}
+	default:
+		return resource.PropertyValue{}, fmt.Errorf("cannot iterate over %v", collection.TypeString())
+	}
+
+	var elements []resource.PropertyValue
+	object := resource.PropertyMap{}
+	for n := range keys {
+		if expr.KeyVariable != nil {
+			i.values[expr.KeyVariable] = keys[n]
+		}
+		i.values[expr.ValueVariable] = values[n]
+
+		if expr.Condition != nil {
+			cond, err := i.eval(expr.Condition)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			if !unsecret(cond).IsBool() || !unsecret(cond).BoolValue() {
+				continue
+			}
+		}
+
+		v, err := i.eval(expr.Value)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		if expr.Key == nil {
+			elements = append(elements, v)
+			continue
+		}
+		k, err := i.eval(expr.Key)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		key, err := toString(unsecret(k))
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		object[resource.PropertyKey(key)] = v
+	}
+
+	if expr.Key == nil {
+		return withSecret(resource.NewArrayProperty(elements), secret), nil
+	}
+	return withSecret(resource.NewObjectProperty(object), secret), nil
+}
+
+func (i *interpreter) call(expr *model.FunctionCallExpression) (resource.PropertyValue, error) {
+	args := make([]resource.PropertyValue, len(expr.Args))
+	secret := false
+	for n, arg := range expr.Args {
+		v, err := i.eval(arg)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		secret = secret || v.IsSecret()
+		args[n] = unsecret(v)
+	}
+	str := func(n int) (string, error) {
+		if n >= len(args) || !args[n].IsString() {
+			return "", fmt.Errorf("argument %d to %s must be a string", n, expr.Name)
+		}
+		return args[n].StringValue(), nil
+	}
+
+	var result resource.PropertyValue
+	switch expr.Name {
+	case pcl.IntrinsicConvert:
+		return i.eval(expr.Args[0])
+	case "secret":
+		return resource.MakeSecret(args[0]), nil
+	case "unsecret":
+		return args[0], nil
+	case "toJSON":
+		secret = secret || args[0].ContainsSecrets()
+		bytes, err := json.Marshal(unsecretDeep(args[0]).Mappable())
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		result = resource.NewStringProperty(string(bytes))
+	case "fileAsset", "stringAsset", "remoteAsset":
+		s, err := str(0)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		asset := &resource.Asset{Sig: resource.AssetSig}
+		switch expr.Name {
+		case "fileAsset":
+			asset.Path = s
+		case "stringAsset":
+			asset.Text = s
+		default:
+			asset.URI = s
+		}
+		result = resource.NewAssetProperty(asset)
+	case "fileArchive", "remoteArchive":
+		s, err := str(0)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		archive := &resource.Archive{Sig: resource.ArchiveSig}
+		if expr.Name == "fileArchive" {
+			archive.Path = s
+		} else {
+			archive.URI = s
+		}
+		result = resource.NewArchiveProperty(archive)
+	case "join":
+		sep, err := str(0)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		if len(args) < 2 || !args[1].IsArray() {
+			return resource.PropertyValue{}, fmt.Errorf("the second argument to join must be a list")
+		}
+		var parts []string
+		for _, e := range args[1].ArrayValue() {
+			s, err := toString(unsecret(e))
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			secret = secret || e.IsSecret()
+			parts = append(parts, s)
+		}
+		result = resource.NewStringProperty(strings.Join(parts, sep))
+	case "split":
+		sep, err := str(0)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		s, err := str(1)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		var parts []resource.PropertyValue
+		for _, p := range strings.Split(s, sep) {
+			parts = append(parts, resource.NewStringProperty(p))
+		}
+		result = resource.NewArrayProperty(parts)
+	case "length":
+		switch v := args[0]; {
+		case v.IsArray():
+			result = resource.NewNumberProperty(float64(len(v.ArrayValue())))
+		case v.IsObject():
+			result = resource.NewNumberProperty(float64(len(v.ObjectValue())))
+		case v.IsString():
+			result = resource.NewNumberProperty(float64(len([]rune(v.StringValue()))))
+		default:
+			return resource.PropertyValue{}, fmt.Errorf("cannot take the length of %v", v.TypeString())
+		}
+	case "element":
+		if !args[0].IsArray() || len(args[0].ArrayValue()) == 0 || !args[1].IsNumber() {
+			return resource.PropertyValue{}, fmt.Errorf("invalid arguments to element")
+		}
+		elements := args[0].ArrayValue()
+		result = elements[int(args[1].NumberValue())%len(elements)]
+	case "toBase64":
+		s, err := str(0)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		result = resource.NewStringProperty(base64.StdEncoding.EncodeToString([]byte(s)))
+	case "fromBase64":
+		s, err := str(0)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		bytes, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		result = resource.NewStringProperty(string(bytes))
+	case "entries":
+		var entries []resource.PropertyValue
+		switch v := args[0]; {
+		case v.IsArray():
+			for n, e := range v.ArrayValue() {
+				entries = append(entries, resource.NewObjectProperty(resource.PropertyMap{
+					"key":   resource.NewNumberProperty(float64(n)),
+					"value": e,
+				}))
+			}
+		case v.IsObject():
+			for _, k := range v.ObjectValue().StableKeys() {
+				entries = append(entries, resource.NewObjectProperty(resource.PropertyMap{
+					"key":   resource.NewStringProperty(string(k)),
+					"value": v.ObjectValue()[k],
+				}))
+			}
+		default:
+			return resource.PropertyValue{}, fmt.Errorf("cannot take the entries of %v", v.TypeString())
+		}
+		result = resource.NewArrayProperty(entries)
+	case "lookup":
+		key, err := str(1)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		var v resource.PropertyValue
+		ok := args[0].IsObject()
+		if ok {
+			v, ok = args[0].ObjectValue()[resource.PropertyKey(key)]
+		}
+		if !ok {
+			if len(args) < 3 {
+				return resource.PropertyValue{}, fmt.Errorf("missing key %q", key)
+			}
+			v = args[2]
+		}
+		result = v
+	case "singleOrNone":
+		if !args[0].IsArray() || len(args[0].ArrayValue()) > 1 {
+			return resource.PropertyValue{}, fmt.Errorf("the argument to singleOrNone must be a list of at most one element")
+		}
+		result = resource.NewNullProperty()
+		if len(args[0].ArrayValue()) == 1 {
+			result = args[0].ArrayValue()[0]
+		}
+	case "stack":
+		result = resource.NewStringProperty(i.stack)
+	case "project":
+		result = resource.NewStringProperty(i.project)
+	case pcl.Invoke:
+		// The mock monitor returns the arguments of an invoke as its result.
+		result = resource.NewObjectProperty(resource.PropertyMap{})
+		if len(args) > 1 {
+			result = args[1]
+		}
+	default:
+		return resource.PropertyValue{}, unsupported("the %s function", expr.Name)
+	}
+	return withSecret(result, secret), nil
+}
+
+// traverse applies the attribute and index traversers of a traversal to a value. Attributes that are not present
+// evaluate to null, as the outputs of a mock resource that are not among its inputs do.
+func traverse(v resource.PropertyValue, traversal hcl.Traversal) (resource.PropertyValue, error) {
+	for _, traverser := range traversal {
+		var key resource.PropertyValue
+		switch traverser := traverser.(type) {
+		case hcl.TraverseAttr:
+			key = resource.NewStringProperty(traverser.Name)
+		case hcl.TraverseIndex:
+			k, err := ctyToProperty(traverser.Key)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			key = k
+		default:
+			return resource.PropertyValue{}, unsupported("%T traversers", traverser)
+		}
+
+		var err error
+		if v, err = index(v, key); err != nil {
+			return resource.PropertyValue{}, err
+		}
+	}
+	return v, nil
+}
+
+func index(collection, key resource.PropertyValue) (resource.PropertyValue, error) {
+	secret := collection.IsSecret() || key.IsSecret()
+	collection, key = unsecret(collection), unsecret(key)
+
+	var result resource.PropertyValue
+	switch {
+	case collection.IsNull():
+		result = resource.NewNullProperty()
+	case collection.IsArray() && key.IsNumber():
+		elements, n := collection.ArrayValue(), int(key.NumberValue())
+		if n < 0 || n >= len(elements) {
+			return resource.PropertyValue{}, fmt.Errorf("index %d is out of range", n)
+		}
+		result = elements[n]
+	case collection.IsObject() && key.IsString():
+		v, ok := collection.ObjectValue()[resource.PropertyKey(key.StringValue())]
+		if !ok {
+			v = resource.NewNullProperty()
+		}
+		result = v
+	default:
+		return resource.PropertyValue{}, fmt.Errorf("cannot index %v with %v", collection.TypeString(), key.TypeString())
+	}
+	return withSecret(result, secret), nil
+}
+
+func ctyToProperty(v cty.Value) (resource.PropertyValue, error) {
+	switch {
+	case v.IsNull():
+		return resource.NewNullProperty(), nil
+	case !v.IsKnown():
+		return resource.PropertyValue{}, unsupported("unknown values")
+	case v.Type() == cty.String:
+		return resource.NewStringProperty(v.AsString()), nil
+	case v.Type() == cty.Number:
+		f, _ := v.AsBigFloat().Float64()
+		return resource.NewNumberProperty(f), nil
+	case v.Type() == cty.Bool:
+		return resource.NewBoolProperty(v.True()), nil
+	default:
+		return resource.PropertyValue{}, unsupported("literals of type %v", v.Type().FriendlyName())
+	}
+}
+
+func toString(v resource.PropertyValue) (string, error) {
+	switch {
+	case v.IsString():
+		return v.StringValue(), nil
+	case v.IsNumber():
+		return strconv.FormatFloat(v.NumberValue(), 'f', -1, 64), nil
+	case v.IsBool():
+		return strconv.FormatBool(v.BoolValue()), nil
+	default:
+		return "", fmt.Errorf("cannot convert %v to a string", v.TypeString())
+	}
+}
+
+// unsecret returns the value of a secret, or the value itself if it is not a secret.
+func unsecret(v resource.PropertyValue) resource.PropertyValue {
+	for v.IsSecret() {
+		v = v.SecretValue().Element
+	}
+	return v
+}
+
+// unsecretDeep returns a value with all of the secrets it contains replaced by their values.
+func unsecretDeep(v resource.PropertyValue) resource.PropertyValue {
+	v = unsecret(v)
+	switch {
+	case v.IsArray():
+		elements := make([]resource.PropertyValue, len(v.ArrayValue()))
+		for n, e := range v.ArrayValue() {
+			elements[n] = unsecretDeep(e)
+		}
+		return resource.NewArrayProperty(elements)
+	case v.IsObject():
+		object := resource.PropertyMap{}
+		for k, e := range v.ObjectValue() {
+			object[k] = unsecretDeep(e)
+		}
+		return resource.NewObjectProperty(object)
+	default:
+		return v
+	}
+}
+
+func withSecret(v resource.PropertyValue, secret bool) resource.PropertyValue {
+	if secret && !v.IsSecret() {
+		return resource.MakeSecret(v)
+	}
+	return v
+}
@@ -0,0 +1,186 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	pbempty "github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/rpcutil"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+// stackType is the type of the root resource that every program registers.
+const stackType = "pulumi:pulumi:Stack"
+
+// A RegisteredResource records the parts of a call to RegisterResource that a program generator is responsible for.
+type RegisteredResource struct {
+	URN     resource.URN
+	Type    string
+	Name    string
+	Custom  bool
+	Parent  resource.URN
+	Protect bool
+	Inputs  resource.PropertyMap
+}
+
+// MockMonitor is a resource monitor that records the resources that a program registers, without creating them. Each
+// custom resource is given the ID "<name>_id" and outputs that are equal to its inputs, and each invoke returns its
+// arguments, so that the values a program sees are the same as those computed by InterpretProgram.
+type MockMonitor struct {
+	pulumirpc.UnimplementedResourceMonitorServer
+
+	project string
+	stack   string
+
+	m         sync.Mutex
+	resources []RegisteredResource
+	outputs   resource.PropertyMap
+}
+
+// NewMockMonitor creates a mock resource monitor for the given project and stack.
+func NewMockMonitor(project, stack string) *MockMonitor {
+	return &MockMonitor{project: project, stack: stack}
+}
+
+// Serve starts a gRPC server for the monitor. It returns the address of the server and a function that stops it.
+func (m *MockMonitor) Serve() (string, func(), error) {
+	cancel := make(chan bool)
+	handle, err := rpcutil.ServeWithOptions(rpcutil.ServeOptions{
+		Cancel: cancel,
+		Init: func(srv *grpc.Server) error {
+			pulumirpc.RegisterResourceMonitorServer(srv, m)
+			return nil
+		},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	stop := func() {
+		close(cancel)
+		<-handle.Done
+	}
+	return fmt.Sprintf("127.0.0.1:%d", handle.Port), stop, nil
+}
+
+// Resources returns the resources that have been registered, in registration order. The stack resource is not included.
+func (m *MockMonitor) Resources() []RegisteredResource {
+	m.m.Lock()
+	defer m.m.Unlock()
+	return append([]RegisteredResource(nil), m.resources...)
+}
+
+// Outputs returns the outputs of the stack.
+func (m *MockMonitor) Outputs() resource.PropertyMap {
+	m.m.Lock()
+	defer m.m.Unlock()
+	return m.outputs
+}
+
+func (m *MockMonitor) SupportsFeature(ctx context.Context,
+	req *pulumirpc.SupportsFeatureRequest,
+) (*pulumirpc.SupportsFeatureResponse, error) {
+	// Resource references and output values would make the inputs of a resource depend on how the SDK chooses to
+	// serialize them, so only secrets are supported.
+	return &pulumirpc.SupportsFeatureResponse{HasSupport: req.GetId() == "secrets"}, nil
+}
+
+func (m *MockMonitor) RegisterResource(ctx context.Context,
+	req *pulumirpc.RegisterResourceRequest,
+) (*pulumirpc.RegisterResourceResponse, error) {
+	inputs, err := plugin.UnmarshalProperties(req.GetObject(), mockMarshalOptions("inputs"))
+	if err != nil {
+		return nil, err
+	}
+
+	urn := mockURN(m.project, m.stack, resource.URN(req.GetParent()), req.GetType(), req.GetName())
+	if req.GetType() != stackType {
+		m.m.Lock()
+		m.resources = append(m.resources, RegisteredResource{
+			URN:     urn,
+			Type:    req.GetType(),
+			Name:    req.GetName(),
+			Custom:  req.GetCustom(),
+			Parent:  resource.URN(req.GetParent()),
+			Protect: req.GetProtect(),
+			Inputs:  inputs,
+		})
+		m.m.Unlock()
+	}
+
+	id, outputs := mockOutputs(req.GetName(), req.GetCustom(), inputs)
+	object, err := plugin.MarshalProperties(outputs, mockMarshalOptions("outputs"))
+	if err != nil {
+		return nil, err
+	}
+	return &pulumirpc.RegisterResourceResponse{Urn: string(urn), Id: string(id), Object: object}, nil
+}
+
+func (m *MockMonitor) RegisterResourceOutputs(ctx context.Context,
+	req *pulumirpc.RegisterResourceOutputsRequest,
+) (*pbempty.Empty, error) {
+	if resource.URN(req.GetUrn()).Type() != stackType {
+		return &pbempty.Empty{}, nil
+	}
+
+	outputs, err := plugin.UnmarshalProperties(req.GetOutputs(), mockMarshalOptions("outputs"))
+	if err != nil {
+		return nil, err
+	}
+	m.m.Lock()
+	m.outputs = outputs
+	m.m.Unlock()
+	return &pbempty.Empty{}, nil
+}
+
+func (m *MockMonitor) Invoke(ctx context.Context,
+	req *pulumirpc.ResourceInvokeRequest,
+) (*pulumirpc.InvokeResponse, error) {
+	return &pulumirpc.InvokeResponse{Return: req.GetArgs()}, nil
+}
+
+func (m *MockMonitor) ReadResource(ctx context.Context,
+	req *pulumirpc.ReadResourceRequest,
+) (*pulumirpc.ReadResourceResponse, error) {
+	return nil, fmt.Errorf("the mock monitor does not support reading resource %v", req.GetName())
+}
+
+func mockMarshalOptions(label string) plugin.MarshalOptions {
+	return plugin.MarshalOptions{Label: label, KeepUnknowns: true, KeepSecrets: true, SkipNulls: true}
+}
+
+// mockURN returns the URN of a registered resource. The URN of a resource's parent determines its qualified type.
+func mockURN(project, stack string, parent resource.URN, typ, name string) resource.URN {
+	var parentType tokens.Type
+	if parent != "" {
+		parentType = parent.QualifiedType()
+	}
+	return resource.NewURN(tokens.QName(stack), tokens.PackageName(project), parentType, tokens.Type(typ), name)
+}
+
+// mockOutputs returns the ID and outputs of a registered resource.
+func mockOutputs(name string, custom bool, inputs resource.PropertyMap) (resource.ID, resource.PropertyMap) {
+	if !custom {
+		return "", resource.PropertyMap{}
+	}
+	return resource.ID(name + "_id"), inputs.Copy()
+}
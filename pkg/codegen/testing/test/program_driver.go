@@ -20,6 +20,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/syntax"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/testing/utils"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 )
@@ -42,6 +43,8 @@ type ProgramTest struct {
 	SkipCompile        codegen.StringSet
 	BindOptions        []pcl.BindOption
 	MockPluginVersions map[string]string
+	// Languages for which the generated program is not run by round-trip tests.
+	SkipRoundTrip codegen.StringSet
 }
 
 var testdataPath = filepath.Join("..", "testing", "test", "testdata")
@@ -529,6 +532,10 @@ type PkgVersionInfo struct {
 // The PCL file is the only piece that must be manually authored. Once the schema has been written, the expected outputs
 // can be generated by running `PULUMI_ACCEPT=true go test ./..." from the `pkg/codegen` directory.
 //
+// If PULUMI_ROUNDTRIP_TEST is set, each generated program that is compiled is also run under a mock resource monitor,
+// and the resources that it registers are compared with those computed by interpreting the PCL file. This requires the
+// language host for the language to be installed.
+//
 //nolint:revive
 func TestProgramCodegen(
 	t *testing.T,
@@ -541,6 +548,7 @@ func TestProgramCodegen(
 	assert.NotNil(t, testcase.TestCases, "Caller must provide test cases")
 	pulumiAccept := cmdutil.IsTruthy(os.Getenv("PULUMI_ACCEPT"))
 	skipCompile := cmdutil.IsTruthy(os.Getenv("PULUMI_SKIP_COMPILE_TEST"))
+	roundTrip := cmdutil.IsTruthy(os.Getenv("PULUMI_ROUNDTRIP_TEST"))
 
 	for _, tt := range testcase.TestCases {
 		tt := tt // avoid capturing loop variable
@@ -615,6 +623,14 @@ func TestProgramCodegen(
 				}
 				t.Logf("bind diags:\n%s", bindDiags)
 			}
+
+			// The program is interpreted before code generation, which may rewrite it.
+			compile := !skipCompile && testcase.Check != nil && !tt.SkipCompile.Has(testcase.Language)
+			var roundTripConfig map[string]resource.PropertyValue
+			var roundTripExpected *ProgramResult
+			if compile && roundTrip && !tt.SkipRoundTrip.Has(testcase.Language) {
+				roundTripConfig, roundTripExpected = interpretRoundTrip(t, program)
+			}
 			var files map[string][]byte
 			// generate a full project and check expected package versions
 			if testcase.IsGenProject {
@@ -674,11 +690,14 @@ func TestProgramCodegen(
 					}
 				}
 			}
-			if !skipCompile && testcase.Check != nil && !tt.SkipCompile.Has(testcase.Language) {
+			if compile {
 				extraPulumiPackages := codegen.NewStringSet()
 				collectExtraPulumiPackages(program, extraPulumiPackages)
 				testcase.Check(t, expectedFile, extraPulumiPackages)
 			}
+			if roundTripExpected != nil {
+				checkRoundTrip(t, testcase.Language, testDir, roundTripConfig, roundTripExpected)
+			}
 		})
 	}
 }
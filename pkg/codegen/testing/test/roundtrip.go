@@ -0,0 +1,236 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+const (
+	roundTripProject = "test"
+	roundTripStack   = "dev"
+)
+
+// RunProgram runs the program in the given directory with the language host for the given language, under a
+// MockMonitor, and returns the resources that it registers and the outputs of its stack. The program's dependencies
+// must already be installed.
+func RunProgram(language, dir, project, stack string,
+	configValues map[string]resource.PropertyValue,
+) (*ProgramResult, error) {
+	monitor := NewMockMonitor(project, stack)
+	addr, stop, err := monitor.Serve()
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	sink := diag.DefaultSink(io.Discard, io.Discard, diag.FormatOptions{Color: colors.Never})
+	ctx, err := plugin.NewContext(sink, sink, nil, nil, dir, nil, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(ctx)
+
+	runtime, err := ctx.Host.LanguageRuntime(dir, dir, language, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := map[config.Key]string{}
+	for name, v := range configValues {
+		s, err := toString(v)
+		if err != nil {
+			return nil, err
+		}
+		cfg[config.MustMakeKey(project, name)] = s
+	}
+
+	progerr, _, err := runtime.Run(plugin.RunInfo{
+		MonitorAddress: addr,
+		Project:        project,
+		Stack:          stack,
+		Pwd:            dir,
+		Program:        ".",
+		Config:         cfg,
+		Parallel:       1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if progerr != "" {
+		return nil, errors.New(progerr)
+	}
+	return &ProgramResult{Resources: monitor.Resources(), Outputs: monitor.Outputs()}, nil
+}
+
+// DiffProgramResults compares the results of two runs of a program, and returns a description of each difference.
+// Resources are matched by URN, and are compared without regard to the order in which they were registered. Null
+// properties are ignored, and strings that hold JSON are compared as JSON.
+func DiffProgramResults(expected, actual *ProgramResult) []string {
+	var diffs []string
+
+	actualResources := map[resource.URN]RegisteredResource{}
+	for _, r := range actual.Resources {
+		actualResources[r.URN] = r
+	}
+	expectedURNs := map[resource.URN]bool{}
+	for _, e := range expected.Resources {
+		expectedURNs[e.URN] = true
+		a, ok := actualResources[e.URN]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("resource %v was not registered", e.URN))
+			continue
+		}
+		if e.Custom != a.Custom {
+			diffs = append(diffs, fmt.Sprintf("resource %v: expected custom=%v, got %v", e.URN, e.Custom, a.Custom))
+		}
+		if e.Parent != a.Parent {
+			diffs = append(diffs, fmt.Sprintf("resource %v: expected parent %v, got %v", e.URN, e.Parent, a.Parent))
+		}
+		if e.Protect != a.Protect {
+			diffs = append(diffs, fmt.Sprintf("resource %v: expected protect=%v, got %v", e.URN, e.Protect, a.Protect))
+		}
+		diffs = append(diffs, diffProperties(fmt.Sprintf("resource %v: input", e.URN), e.Inputs, a.Inputs)...)
+	}
+	for _, a := range actual.Resources {
+		if !expectedURNs[a.URN] {
+			diffs = append(diffs, fmt.Sprintf("unexpected resource %v", a.URN))
+		}
+	}
+
+	return append(diffs, diffProperties("stack output", expected.Outputs, actual.Outputs)...)
+}
+
+func diffProperties(what string, expected, actual resource.PropertyMap) []string {
+	expected = normalizeValue(resource.NewObjectProperty(expected)).ObjectValue()
+	actual = normalizeValue(resource.NewObjectProperty(actual)).ObjectValue()
+
+	keys := map[resource.PropertyKey]bool{}
+	for k := range expected {
+		keys[k] = true
+	}
+	for k := range actual {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, string(k))
+	}
+	sort.Strings(sorted)
+
+	var diffs []string
+	for _, k := range sorted {
+		e, hasExpected := expected[resource.PropertyKey(k)]
+		a, hasActual := actual[resource.PropertyKey(k)]
+		switch {
+		case !hasActual:
+			diffs = append(diffs, fmt.Sprintf("%s %q is missing", what, k))
+		case !hasExpected:
+			diffs = append(diffs, fmt.Sprintf("%s %q is unexpected: %v", what, k, a))
+		case !e.DeepEquals(a):
+			diffs = append(diffs, fmt.Sprintf("%s %q: expected %v, got %v", what, k, e, a))
+		}
+	}
+	return diffs
+}
+
+// normalizeValue removes the differences between property values that do not depend on the program that produced
+// them: null object properties, the formatting of JSON strings, and the hashes of assets and archives.
+func normalizeValue(v resource.PropertyValue) resource.PropertyValue {
+	switch {
+	case v.IsSecret():
+		return resource.MakeSecret(normalizeValue(v.SecretValue().Element))
+	case v.IsArray():
+		elements := make([]resource.PropertyValue, len(v.ArrayValue()))
+		for n, e := range v.ArrayValue() {
+			elements[n] = normalizeValue(e)
+		}
+		return resource.NewArrayProperty(elements)
+	case v.IsObject():
+		object := resource.PropertyMap{}
+		for k, e := range v.ObjectValue() {
+			if !e.IsNull() {
+				object[k] = normalizeValue(e)
+			}
+		}
+		return resource.NewObjectProperty(object)
+	case v.IsString():
+		s := strings.TrimSpace(v.StringValue())
+		if strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[") {
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(s), &parsed); err == nil {
+				// Marshaling sorts the keys of objects.
+				canonical, err := json.Marshal(parsed)
+				contract.AssertNoErrorf(err, "marshaling parsed JSON")
+				return resource.NewStringProperty(string(canonical))
+			}
+		}
+		return v
+	case v.IsAsset():
+		a := v.AssetValue()
+		return resource.NewAssetProperty(&resource.Asset{Sig: a.Sig, Text: a.Text, Path: a.Path, URI: a.URI})
+	case v.IsArchive():
+		a := v.ArchiveValue()
+		return resource.NewArchiveProperty(&resource.Archive{Sig: a.Sig, Assets: a.Assets, Path: a.Path, URI: a.URI})
+	default:
+		return v
+	}
+}
+
+// interpretRoundTrip computes the expected result of a round-trip test of a program, and the config to run it with.
+// It returns nil if the program uses features that the interpreter does not support.
+func interpretRoundTrip(t *testing.T, program *pcl.Program) (map[string]resource.PropertyValue, *ProgramResult) {
+	cfg, err := MockConfig(program)
+	if err == nil {
+		var expected *ProgramResult
+		if expected, err = InterpretProgram(program, roundTripProject, roundTripStack, cfg); err == nil {
+			return cfg, expected
+		}
+	}
+	if errors.Is(err, ErrUnsupported) {
+		t.Logf("skipping round-trip test: %v", err)
+		return nil, nil
+	}
+	require.NoError(t, err, "interpreting program")
+	return nil, nil
+}
+
+// checkRoundTrip runs a generated program under a mock resource monitor, and checks that its result matches the
+// result of interpreting the PCL program it was generated from.
+func checkRoundTrip(t *testing.T, language, dir string, cfg map[string]resource.PropertyValue,
+	expected *ProgramResult,
+) {
+	actual, err := RunProgram(language, dir, roundTripProject, roundTripStack, cfg)
+	require.NoError(t, err, "running generated program")
+	assert.Empty(t, DiffProgramResults(expected, actual),
+		"the generated program does not register the same resources as the PCL program")
+}
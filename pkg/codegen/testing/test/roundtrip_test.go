@@ -0,0 +1,213 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/syntax"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/testing/utils"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/rpcutil"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+const roundTripSource = `config prefix string {}
+config count int {
+	default = 2
+}
+
+resource pets "random:index/randomPet:RandomPet" {
+	options {
+		range = count
+	}
+	prefix = "${prefix}-${range.value}"
+	length = range.value + 1
+}
+
+resource password "random:index/randomPassword:RandomPassword" {
+	length = 16
+	special = length([for p in pets : p.id if p.prefix != "none"]) > 1
+	overrideSpecial = secret("!@")
+}
+
+output names {
+	value = pets[*].id
+}
+output special {
+	value = password.special
+}
+output json {
+	value = toJSON({ b = [prefix], a = password.length })
+}
+`
+
+func bindRoundTripProgram(t *testing.T, source string) *pcl.Program {
+	parser := syntax.NewParser()
+	require.NoError(t, parser.ParseFile(strings.NewReader(source), "main.pp"))
+	require.False(t, parser.Diagnostics.HasErrors(), "%v", parser.Diagnostics)
+
+	program, diags, err := pcl.BindProgram(parser.Files, pcl.PluginHost(utils.NewHost("testdata")))
+	require.NoError(t, err)
+	require.False(t, diags.HasErrors(), "%v", diags)
+	return program
+}
+
+func TestInterpretProgram(t *testing.T) {
+	t.Parallel()
+
+	program := bindRoundTripProgram(t, roundTripSource)
+	cfg, err := MockConfig(program)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]resource.PropertyValue{"prefix": resource.NewStringProperty("prefix")}, cfg)
+
+	result, err := InterpretProgram(program, "proj", "dev", cfg)
+	require.NoError(t, err)
+
+	stack := mockURN("proj", "dev", "", stackType, "proj-dev")
+	require.Len(t, result.Resources, 3)
+	assert.Equal(t, RegisteredResource{
+		URN:    mockURN("proj", "dev", stack, "random:index/randomPet:RandomPet", "pets-1"),
+		Type:   "random:index/randomPet:RandomPet",
+		Name:   "pets-1",
+		Custom: true,
+		Parent: stack,
+		Inputs: resource.PropertyMap{
+			"prefix": resource.NewStringProperty("prefix-1"),
+			"length": resource.NewNumberProperty(2),
+		},
+	}, result.Resources[1])
+	assert.Equal(t, resource.PropertyMap{
+		"length":          resource.NewNumberProperty(16),
+		"special":         resource.NewBoolProperty(true),
+		"overrideSpecial": resource.MakeSecret(resource.NewStringProperty("!@")),
+	}, result.Resources[2].Inputs)
+
+	assert.Equal(t, resource.PropertyMap{
+		"names": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewStringProperty("pets-0_id"),
+			resource.NewStringProperty("pets-1_id"),
+		}),
+		"special": resource.NewBoolProperty(true),
+		"json":    resource.NewStringProperty(`{"a":16,"b":["prefix"]}`),
+	}, result.Outputs)
+}
+
+func TestInterpretProgramUnsupported(t *testing.T) {
+	t.Parallel()
+
+	program := bindRoundTripProgram(t, `output dir {
+	value = cwd()
+}
+`)
+	_, err := InterpretProgram(program, "proj", "dev", nil)
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+// TestMockMonitorRoundTrip replays the registrations of an interpreted program through the mock monitor, as a
+// generated program would, and checks that the monitor records the same result.
+func TestMockMonitorRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	program := bindRoundTripProgram(t, roundTripSource)
+	cfg, err := MockConfig(program)
+	require.NoError(t, err)
+	expected, err := InterpretProgram(program, "proj", "dev", cfg)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	opts := mockMarshalOptions("test")
+	monitor := NewMockMonitor("proj", "dev")
+	stack, err := monitor.RegisterResource(ctx, &pulumirpc.RegisterResourceRequest{Type: stackType, Name: "proj-dev"})
+	require.NoError(t, err)
+
+	for _, r := range expected.Resources {
+		// SDKs serialize JSON without sorting its keys, and send null for unset properties.
+		inputs := r.Inputs.Copy()
+		inputs["unset"] = resource.NewNullProperty()
+		object, err := plugin.MarshalProperties(inputs, plugin.MarshalOptions{KeepSecrets: true})
+		require.NoError(t, err)
+
+		resp, err := monitor.RegisterResource(ctx, &pulumirpc.RegisterResourceRequest{
+			Type:   r.Type,
+			Name:   r.Name,
+			Custom: r.Custom,
+			Parent: stack.Urn,
+			Object: object,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, string(r.URN), resp.Urn)
+		assert.Equal(t, r.Name+"_id", resp.Id)
+	}
+
+	outputs := expected.Outputs.Copy()
+	outputs["json"] = resource.NewStringProperty(`{"b": ["prefix"], "a": 16}`)
+	object, err := plugin.MarshalProperties(outputs, opts)
+	require.NoError(t, err)
+	_, err = monitor.RegisterResourceOutputs(ctx, &pulumirpc.RegisterResourceOutputsRequest{
+		Urn:     stack.Urn,
+		Outputs: object,
+	})
+	require.NoError(t, err)
+
+	actual := &ProgramResult{Resources: monitor.Resources(), Outputs: monitor.Outputs()}
+	assert.Empty(t, DiffProgramResults(expected, actual))
+
+	// Differences are reported.
+	actual.Resources[0].Inputs = resource.PropertyMap{"length": resource.NewNumberProperty(3)}
+	actual.Resources = actual.Resources[:2]
+	assert.Equal(t, []string{
+		`resource urn:pulumi:dev::proj::random:index/randomPet:RandomPet::pets-0: input "length": expected {1}, got {3}`,
+		`resource urn:pulumi:dev::proj::random:index/randomPet:RandomPet::pets-0: input "prefix" is missing`,
+		`resource urn:pulumi:dev::proj::random:index/randomPassword:RandomPassword::password was not registered`,
+	}, DiffProgramResults(expected, actual))
+}
+
+func TestMockMonitorServe(t *testing.T) {
+	t.Parallel()
+
+	monitor := NewMockMonitor("proj", "dev")
+	addr, stop, err := monitor.Serve()
+	require.NoError(t, err)
+	defer stop()
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), rpcutil.GrpcChannelOptions())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pulumirpc.NewResourceMonitorClient(conn)
+
+	ctx := context.Background()
+	for feature, supported := range map[string]bool{"secrets": true, "resourceReferences": false} {
+		resp, err := client.SupportsFeature(ctx, &pulumirpc.SupportsFeatureRequest{Id: feature})
+		require.NoError(t, err)
+		assert.Equal(t, supported, resp.HasSupport, feature)
+	}
+
+	args, err := plugin.MarshalProperties(resource.PropertyMap{"name": resource.NewStringProperty("a")},
+		plugin.MarshalOptions{})
+	require.NoError(t, err)
+	resp, err := client.Invoke(ctx, &pulumirpc.ResourceInvokeRequest{Tok: "random:index:getThing", Args: args})
+	require.NoError(t, err)
+	assert.Equal(t, "a", resp.Return.Fields["name"].GetStringValue())
+}
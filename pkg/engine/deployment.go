@@ -319,6 +319,7 @@ func (deployment *deployment) run(cancelCtx *Context, actions runActions,
 			DisableResourceReferences: deployment.Options.DisableResourceReferences,
 			DisableOutputValues:       deployment.Options.DisableOutputValues,
 			GeneratePlan:              deployment.Options.UpdateOptions.GeneratePlan,
+			StepTimings:               deployment.Options.UpdateOptions.StepTimings,
 		}
 		newPlan, walkError = deployment.Deployment.Execute(ctx, opts, preview)
 		close(done)
@@ -27,6 +27,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/display"
 	resourceanalyzer "github.com/pulumi/pulumi/pkg/v3/resource/analyzer"
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/secretscan"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
@@ -164,6 +165,17 @@ type UpdateOptions struct {
 
 	// Experimental is true if the engine is in experimental mode (i.e. PULUMI_EXPERIMENTAL was set)
 	Experimental bool
+
+	// SecretScanner, if set, scans the outputs of each resource after its step completes and warns about values
+	// that look like secrets but are not marked secret.
+	SecretScanner *secretscan.Scanner
+
+	// PromoteDetectedSecrets marks the values found by SecretScanner as secret before the resource's state is
+	// written to the snapshot.
+	PromoteDetectedSecrets bool
+
+	// StepTimings, if set, records when each step of the update was queued, started and finished.
+	StepTimings *deploy.StepTimings
 }
 
 // HasChanges returns true if there are any non-same changes in the resulting summary.
@@ -610,6 +622,12 @@ func (acts *updateActions) OnResourceStepPost(
 			acts.MapLock.Unlock()
 		}
 
+		// Scan the new outputs before they are reported below, so that promoted secrets are never reported in
+		// plaintext. The outputs of a same step were scanned by the update that produced them.
+		if !isInternalStep && step.Op() != deploy.OpDelete && step.Op() != deploy.OpSame {
+			acts.scanOutputsForSecrets(step)
+		}
+
 		// Also show outputs here for custom resources, since there might be some from the initial registration. We do
 		// not show outputs for component resources at this point: any that exist must be from a previous execution of
 		// the Pulumi program, as component resources only report outputs via calls to RegisterResourceOutputs.
@@ -655,6 +673,10 @@ func (acts *updateActions) OnResourceOutputs(step deploy.Step) error {
 	assertSeen(acts.Seen, step)
 	acts.MapLock.Unlock()
 
+	if !isInternalStep(step) {
+		acts.scanOutputsForSecrets(step)
+	}
+
 	acts.Opts.Events.resourceOutputsEvent(step.Op(), step, false /*planning*/, acts.Opts.Debug, isInternalStep(step))
 
 	// There's a chance there are new outputs that weren't written out last time.
@@ -662,6 +684,34 @@ func (acts *updateActions) OnResourceOutputs(step deploy.Step) error {
 	return acts.Context.SnapshotManager.RegisterResourceOutputs(step)
 }
 
+// scanOutputsForSecrets runs the configured secret scanner over the new outputs of a step, warning about each value
+// that looks like a secret and, if requested, marking it secret. This runs before the outputs are reported or written
+// to the snapshot, so promoted values never reach either in plaintext.
+func (acts *updateActions) scanOutputsForSecrets(step deploy.Step) {
+	scanner := acts.Opts.SecretScanner
+	new := step.New()
+	if scanner == nil || new == nil {
+		return
+	}
+
+	findings := scanner.Scan(new.URN, new.Outputs)
+	if len(findings) == 0 {
+		return
+	}
+	for _, f := range findings {
+		if acts.Opts.PromoteDetectedSecrets {
+			acts.Opts.Diag.Warningf(diag.RawMessage(new.URN, fmt.Sprintf(
+				"output %q looks like a secret (%s); marking it as secret", f.Path.String(), f.Detector)))
+		} else {
+			acts.Opts.Diag.Warningf(diag.RawMessage(new.URN, fmt.Sprintf(
+				"output %q looks like a secret (%s) but is not marked as secret", f.Path.String(), f.Detector)))
+		}
+	}
+	if acts.Opts.PromoteDetectedSecrets {
+		new.Outputs = secretscan.Promote(new.Outputs, findings)
+	}
+}
+
 func (acts *updateActions) OnPolicyViolation(urn resource.URN, d plugin.AnalyzeDiagnostic) {
 	acts.Opts.Events.policyViolationEvent(urn, d)
 }
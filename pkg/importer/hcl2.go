@@ -19,6 +19,7 @@ import (
 	"math"
 	"strings"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/pulumi/pulumi/pkg/v3/codegen"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/model"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/syntax"
@@ -37,8 +38,53 @@ var Null = &model.Variable{
 	VariableType: model.NoneType,
 }
 
-// GenerateHCL2Definition generates a Pulumi HCL2 definition for a given resource.
+// GenerateHCL2Definition generates a Pulumi HCL2 definition for a given resource. Property values are generated as
+// literals; use GenerateHCL2Definitions to generate references between a set of imported resources.
 func GenerateHCL2Definition(loader schema.Loader, state *resource.State, names NameTable) (*model.Block, error) {
+	return generateHCL2Definition(loader, state, names, nil)
+}
+
+// GenerateHCL2Definitions generates Pulumi HCL2 definitions for a set of imported resources. A property value that
+// matches the ID or a computed output of exactly one other imported resource is generated as a reference to that
+// resource, and the definitions are ordered so that each follows the definitions that it refers to. Values that match
+// more than one resource, or that would create a circular reference, are left as literals; each is reported by a
+// warning and by a comment on the definition. Values that are too common to identify a resource, such as short
+// strings, numbers and region names, are always left as literals.
+func GenerateHCL2Definitions(loader schema.Loader, states []*resource.State,
+	names NameTable,
+) ([]*model.Block, hcl.Diagnostics, error) {
+	table := newReferenceTable(states, names)
+
+	blocks := map[resource.URN]*model.Block{}
+	var diags hcl.Diagnostics
+	for _, state := range states {
+		refs := newReferenceResolver(table, state, resourceName(state, names))
+		block, err := generateHCL2Definition(loader, state, names, refs)
+		if err != nil {
+			return nil, diags, err
+		}
+		if len(refs.notes) != 0 {
+			lines := []string{"The following values were not replaced with references to other imported resources:"}
+			for _, note := range refs.notes {
+				lines = append(lines, "  "+note)
+			}
+			block.Tokens.Type.LeadingTrivia = syntax.TriviaList{syntax.NewComment(lines...)}
+		}
+		blocks[state.URN] = block
+		diags = append(diags, refs.diags...)
+	}
+
+	sorted := table.sort(states)
+	result := make([]*model.Block, len(sorted))
+	for i, state := range sorted {
+		result[i] = blocks[state.URN]
+	}
+	return result, diags, nil
+}
+
+func generateHCL2Definition(loader schema.Loader, state *resource.State, names NameTable,
+	refs *referenceResolver,
+) (*model.Block, error) {
 	// TODO: pull the package version from the resource's provider
 	pkg, err := schema.LoadPackageReference(loader, string(state.Type.Package()), nil)
 	if err != nil {
@@ -72,7 +118,7 @@ func GenerateHCL2Definition(loader schema.Loader, state *resource.State, names N
 	}
 
 	for _, p := range r.InputProperties {
-		x, err := generatePropertyValue(p, state.Inputs[resource.PropertyKey(p.Name)])
+		x, err := generatePropertyValue(p, state.Inputs[resource.PropertyKey(p.Name)], refs, p.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -84,7 +130,12 @@ func GenerateHCL2Definition(loader schema.Loader, state *resource.State, names N
 		}
 	}
 
-	resourceOptions, err := makeResourceOptions(state, names)
+	// The imported resources may refer to each other through their options, so use the names of all of them.
+	optionNames, referenced := names, map[resource.URN]bool(nil)
+	if refs != nil {
+		optionNames, referenced = refs.table.names, refs.referenced
+	}
+	resourceOptions, err := makeResourceOptions(state, optionNames, referenced)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +177,11 @@ func appendResourceOption(block *model.Block, name string, value model.Expressio
 	return block
 }
 
-func makeResourceOptions(state *resource.State, names NameTable) (*model.Block, error) {
+// makeResourceOptions generates the options block for a resource. Dependencies on the resources in referenced are
+// omitted, as they are implied by the references in the resource's properties.
+func makeResourceOptions(state *resource.State, names NameTable,
+	referenced map[resource.URN]bool,
+) (*model.Block, error) {
 	var resourceOptions *model.Block
 	if state.Parent != "" && state.Parent.Type() != resource.RootStackType {
 		name, ok := names[state.Parent]
@@ -148,15 +203,18 @@ func makeResourceOptions(state *resource.State, names NameTable) (*model.Block,
 			resourceOptions = appendResourceOption(resourceOptions, "provider", newVariableReference(name))
 		}
 	}
-	if len(state.Dependencies) != 0 {
-		deps := make([]model.Expression, len(state.Dependencies))
-		for i, d := range state.Dependencies {
-			name, ok := names[d]
-			if !ok {
-				return nil, fmt.Errorf("no name for resource %v", d)
-			}
-			deps[i] = newVariableReference(name)
+	var deps []model.Expression
+	for _, d := range state.Dependencies {
+		if referenced[d] {
+			continue
+		}
+		name, ok := names[d]
+		if !ok {
+			return nil, fmt.Errorf("no name for resource %v", d)
 		}
+		deps = append(deps, newVariableReference(name))
+	}
+	if len(deps) != 0 {
 		resourceOptions = appendResourceOption(resourceOptions, "dependsOn", &model.TupleConsExpression{
 			Tokens:      syntax.NewTupleConsTokens(len(deps)),
 			Expressions: deps,
@@ -349,15 +407,15 @@ func zeroValue(t schema.Type) model.Expression {
 	}
 	switch t {
 	case schema.BoolType:
-		x, err := generateValue(t, resource.NewBoolProperty(false))
+		x, err := generateValue(t, resource.NewBoolProperty(false), nil, "")
 		contract.IgnoreError(err)
 		return x
 	case schema.IntType, schema.NumberType:
-		x, err := generateValue(t, resource.NewNumberProperty(0))
+		x, err := generateValue(t, resource.NewNumberProperty(0), nil, "")
 		contract.IgnoreError(err)
 		return x
 	case schema.StringType:
-		x, err := generateValue(t, resource.NewStringProperty(""))
+		x, err := generateValue(t, resource.NewStringProperty(""), nil, "")
 		contract.IgnoreError(err)
 		return x
 	case schema.ArchiveType, schema.AssetType:
@@ -372,8 +430,11 @@ func zeroValue(t schema.Type) model.Expression {
 
 // generatePropertyValue generates the value for the given property. If the value is absent and the property is
 // required, a zero value for the property's type is generated. If the value is absent and the property is not
-// required, no value is generated (i.e. this function returns nil).
-func generatePropertyValue(property *schema.Property, value resource.PropertyValue) (model.Expression, error) {
+// required, no value is generated (i.e. this function returns nil). The path of the value is used to describe values
+// that refs could not resolve.
+func generatePropertyValue(property *schema.Property, value resource.PropertyValue, refs *referenceResolver,
+	path string,
+) (model.Expression, error) {
 	if !value.HasValue() {
 		if !property.IsRequired() {
 			return nil, nil
@@ -381,7 +442,7 @@ func generatePropertyValue(property *schema.Property, value resource.PropertyVal
 		return zeroValue(property.Type), nil
 	}
 
-	return generateValue(property.Type, value)
+	return generateValue(property.Type, value, refs, path)
 }
 
 // valueStructurallyTypedAs returns true if the given value is structurally typed as the given schema type.
@@ -592,8 +653,10 @@ func reduceUnionType(schemaUnion *schema.UnionType, value resource.PropertyValue
 }
 
 // generateValue generates a value from the given property value. The given type may or may not match the shape of the
-// given value.
-func generateValue(typ schema.Type, value resource.PropertyValue) (model.Expression, error) {
+// given value. Strings that refs resolves to references to other imported resources are generated as references.
+func generateValue(typ schema.Type, value resource.PropertyValue, refs *referenceResolver,
+	path string,
+) (model.Expression, error) {
 	typ = codegen.UnwrapType(typ)
 
 	if unionType, ok := typ.(*schema.UnionType); ok {
@@ -612,7 +675,7 @@ func generateValue(typ schema.Type, value resource.PropertyValue) (model.Express
 		arr := value.ArrayValue()
 		exprs := make([]model.Expression, len(arr))
 		for i, v := range arr {
-			x, err := generateValue(elementType, v)
+			x, err := generateValue(elementType, v, refs, fmt.Sprintf("%s[%d]", path, i))
 			if err != nil {
 				return nil, err
 			}
@@ -643,7 +706,7 @@ func generateValue(typ schema.Type, value resource.PropertyValue) (model.Express
 		switch arg := typ.(type) {
 		case *schema.ObjectType:
 			for _, p := range arg.Properties {
-				x, err := generatePropertyValue(p, obj[resource.PropertyKey(p.Name)])
+				x, err := generatePropertyValue(p, obj[resource.PropertyKey(p.Name)], refs, path+"."+p.Name)
 				if err != nil {
 					return nil, err
 				}
@@ -669,7 +732,7 @@ func generateValue(typ schema.Type, value resource.PropertyValue) (model.Express
 					continue
 				}
 
-				x, err := generateValue(elementType, obj[k])
+				x, err := generateValue(elementType, obj[k], refs, fmt.Sprintf("%s[%q]", path, k))
 				if err != nil {
 					return nil, err
 				}
@@ -690,7 +753,7 @@ func generateValue(typ schema.Type, value resource.PropertyValue) (model.Express
 			Items:  items,
 		}, nil
 	case value.IsSecret():
-		arg, err := generateValue(typ, value.SecretValue().Element)
+		arg, err := generateValue(typ, value.SecretValue().Element, refs, path)
 		if err != nil {
 			return nil, err
 		}
@@ -706,6 +769,12 @@ func generateValue(typ schema.Type, value resource.PropertyValue) (model.Express
 			Args: []model.Expression{arg},
 		}, nil
 	case value.IsString():
+		if typ != schema.ArchiveType && typ != schema.AssetType {
+			if ref := refs.resolve(path, value.StringValue()); ref != nil {
+				return ref, nil
+			}
+		}
+
 		x := &model.TemplateExpression{
 			Parts: []model.Expression{
 				&model.LiteralValueExpression{
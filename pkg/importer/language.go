@@ -60,49 +60,60 @@ func (e *DiagnosticsError) String() string {
 	return e.Error()
 }
 
-// GenerateLanguageDefintions generates a list of resource definitions from the given resource states.
+// GenerateLanguageDefintions generates a list of resource definitions from the given resource states. Property values
+// that were copied from one of the given resources to another are generated as references; see
+// GenerateHCL2Definitions.
 func GenerateLanguageDefinitions(w io.Writer, loader schema.Loader, gen LanguageGenerator, states []*resource.State,
 	names NameTable,
 ) error {
-	var hcl2Text bytes.Buffer
-	for i, state := range states {
-		hcl2Def, err := GenerateHCL2Definition(loader, state, names)
-		if err != nil {
-			return err
-		}
+	_, err := GenerateLanguageDefinitionsWithDiagnostics(w, loader, gen, states, names)
+	return err
+}
 
+// GenerateLanguageDefinitionsWithDiagnostics is like GenerateLanguageDefinitions, but also returns diagnostics that
+// describe the values that could not be generated as references.
+func GenerateLanguageDefinitionsWithDiagnostics(w io.Writer, loader schema.Loader, gen LanguageGenerator,
+	states []*resource.State, names NameTable,
+) (hcl.Diagnostics, error) {
+	hcl2Defs, diags, err := GenerateHCL2Definitions(loader, states, names)
+	if err != nil {
+		return diags, err
+	}
+
+	var hcl2Text bytes.Buffer
+	for i, hcl2Def := range hcl2Defs {
 		pre := ""
 		if i > 0 {
 			pre = "\n"
 		}
-		_, err = fmt.Fprintf(&hcl2Text, "%s%v", pre, hcl2Def)
+		_, err := fmt.Fprintf(&hcl2Text, "%s%v", pre, hcl2Def)
 		contract.IgnoreError(err)
 	}
 
 	parser := syntax.NewParser()
 	if err := parser.ParseFile(&hcl2Text, "anonymous.pp"); err != nil {
-		return err
+		return diags, err
 	}
 	if parser.Diagnostics.HasErrors() {
 		// HCL2 text generation should always generate proper code.
-		return fmt.Errorf("internal error: %w", &DiagnosticsError{
+		return diags, fmt.Errorf("internal error: %w", &DiagnosticsError{
 			diagnostics:         parser.Diagnostics,
 			newDiagnosticWriter: parser.NewDiagnosticWriter,
 		})
 	}
 
-	program, diags, err := pcl.BindProgram(parser.Files, pcl.Loader(loader), pcl.AllowMissingVariables)
+	program, bindDiags, err := pcl.BindProgram(parser.Files, pcl.Loader(loader), pcl.AllowMissingVariables)
 	if err != nil {
-		return err
+		return diags, err
 	}
-	if diags.HasErrors() {
+	if bindDiags.HasErrors() {
 		// It is possible that the provided states do not contain appropriately-shaped inputs, so this may be user
 		// error.
-		return &DiagnosticsError{
-			diagnostics:         diags,
+		return diags, &DiagnosticsError{
+			diagnostics:         bindDiags,
 			newDiagnosticWriter: program.NewDiagnosticWriter,
 		}
 	}
 
-	return gen(w, program)
+	return diags, gen(w, program)
 }
@@ -0,0 +1,328 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/model"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// A reference names a property of an imported resource that holds a particular value.
+type reference struct {
+	urn      resource.URN
+	name     string // the variable name of the resource
+	property string // "id" or the name of one of the resource's outputs
+}
+
+func (r reference) String() string {
+	return r.name + "." + r.property
+}
+
+func (r reference) expression() model.Expression {
+	return &model.ScopeTraversalExpression{
+		RootName:  r.name,
+		Traversal: hcl.Traversal{hcl.TraverseRoot{Name: r.name}, hcl.TraverseAttr{Name: r.property}},
+		Parts: []model.Traversable{
+			&model.Variable{Name: r.name, VariableType: model.DynamicType},
+			model.DynamicType,
+		},
+	}
+}
+
+// A referenceTable maps the IDs and computed outputs of a set of imported resources to the properties that hold them,
+// so that property values that were copied from one imported resource to another can be generated as references.
+type referenceTable struct {
+	values map[string][]reference
+	// names maps the URN of each imported resource, parent and provider to its variable name.
+	names NameTable
+
+	// dependencies records the imported resources that each imported resource depends on, both through its
+	// resource options and through the references that have been resolved so far.
+	dependencies map[resource.URN]map[resource.URN]bool
+}
+
+// resourceName returns the variable name of the definition that is generated for the given resource.
+func resourceName(state *resource.State, names NameTable) string {
+	if name, ok := names[state.URN]; ok {
+		return name
+	}
+	return state.URN.Name()
+}
+
+// minReferenceLength is the length of the shortest value that is generated as a reference. Shorter values, such as
+// "default" or "gp2", are too likely to be set independently on several resources.
+const minReferenceLength = 8
+
+// commonValues are values that are likely to be set independently on several resources, even though they are long
+// enough to be referenced.
+var commonValues = map[string]bool{
+	"disabled":  true,
+	"external":  true,
+	"inactive":  true,
+	"internal":  true,
+	"standard":  true,
+	"unlimited": true,
+}
+
+// regionPattern matches the names of cloud regions and zones, such as "us-west-2", "us-east-1a" or "europe-west1-b",
+// which many resources in an account share.
+var regionPattern = regexp.MustCompile(`^(af|ap|ca|cn|eu|il|me|mx|sa|us|africa|asia|australia|europe|` +
+	`northamerica|southamerica)(-gov)?-[a-z]+-?[0-9]+(-?[a-z])?$`)
+
+// referenceable returns true if a resource's ID or output is distinctive enough that another imported resource with
+// the same value is likely to have taken it from that resource. Short values, numbers, region names and other common
+// values are often set independently on several resources, so they are left as literals.
+func referenceable(value string) bool {
+	if len(value) < minReferenceLength || commonValues[strings.ToLower(value)] || regionPattern.MatchString(value) {
+		return false
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return false
+	}
+	return true
+}
+
+func newReferenceTable(states []*resource.State, names NameTable) *referenceTable {
+	table := &referenceTable{
+		values:       map[string][]reference{},
+		dependencies: map[resource.URN]map[resource.URN]bool{},
+		names:        NameTable{},
+	}
+
+	imported := map[resource.URN]bool{}
+	for _, state := range states {
+		imported[state.URN] = true
+		table.names[state.URN] = resourceName(state, names)
+	}
+	for urn, name := range names {
+		table.names[urn] = name
+	}
+
+	for _, state := range states {
+		deps := map[resource.URN]bool{}
+		for _, d := range state.Dependencies {
+			deps[d] = true
+		}
+		if state.Parent != "" {
+			deps[state.Parent] = true
+		}
+		if state.Provider != "" {
+			if ref, err := providers.ParseReference(state.Provider); err == nil {
+				deps[ref.URN()] = true
+			}
+		}
+		for d := range deps {
+			if !imported[d] {
+				delete(deps, d)
+			}
+		}
+		table.dependencies[state.URN] = deps
+
+		name := table.names[state.URN]
+		if !hclsyntax.ValidIdentifier(name) {
+			continue
+		}
+
+		if state.ID != "" {
+			table.add(string(state.ID), reference{urn: state.URN, name: name, property: "id"})
+		}
+		for _, k := range state.Outputs.StableKeys() {
+			v := state.Outputs[k]
+			if k == "id" || strings.HasPrefix(string(k), "__") || !v.IsString() || v.StringValue() == "" {
+				continue
+			}
+			// Outputs that are copies of the resource's own inputs were chosen by the user rather than by the
+			// provider, so another resource that has the same value is unlikely to have taken it from this one.
+			if input, ok := state.Inputs[k]; ok && input.DeepEquals(v) {
+				continue
+			}
+			table.add(v.StringValue(), reference{urn: state.URN, name: name, property: string(k)})
+		}
+	}
+	return table
+}
+
+func (t *referenceTable) add(value string, ref reference) {
+	if referenceable(value) {
+		t.values[value] = append(t.values[value], ref)
+	}
+}
+
+// dependsOn returns true if the resource with URN from depends on the resource with URN to, directly or indirectly.
+func (t *referenceTable) dependsOn(from, to resource.URN) bool {
+	visited := map[resource.URN]bool{}
+	var visit func(urn resource.URN) bool
+	visit = func(urn resource.URN) bool {
+		if urn == to {
+			return true
+		}
+		if visited[urn] {
+			return false
+		}
+		visited[urn] = true
+		for d := range t.dependencies[urn] {
+			if visit(d) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(from)
+}
+
+// sort orders the given resources so that each follows the imported resources that it depends on. Resources that do
+// not depend on each other keep their relative order.
+func (t *referenceTable) sort(states []*resource.State) []*resource.State {
+	sorted := make([]*resource.State, 0, len(states))
+	done := map[resource.URN]bool{}
+	for len(sorted) < len(states) {
+		progress := false
+		for _, state := range states {
+			if done[state.URN] {
+				continue
+			}
+			ready := true
+			for d := range t.dependencies[state.URN] {
+				if !done[d] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				sorted, done[state.URN], progress = append(sorted, state), true, true
+				break
+			}
+		}
+		if !progress {
+			// The dependencies are cyclic. This can't happen for states that come from a snapshot, but leave the
+			// remaining resources in their original order rather than looping forever.
+			for _, state := range states {
+				if !done[state.URN] {
+					sorted, done[state.URN] = append(sorted, state), true
+				}
+			}
+		}
+	}
+	return sorted
+}
+
+// A referenceResolver resolves the property values of a single imported resource into references to the other
+// imported resources. A nil resolver resolves nothing.
+type referenceResolver struct {
+	table *referenceTable
+	state *resource.State
+	name  string
+
+	// referenced records the resources that the resolved references refer to.
+	referenced map[resource.URN]bool
+	// notes describes the values that were not resolved because they were ambiguous or would create a cycle.
+	notes []string
+	diags hcl.Diagnostics
+}
+
+func newReferenceResolver(table *referenceTable, state *resource.State, name string) *referenceResolver {
+	return &referenceResolver{table: table, state: state, name: name, referenced: map[resource.URN]bool{}}
+}
+
+// resolve returns a reference to the imported resource property that holds the given value, or nil if there is no
+// such property or if there is more than one.
+func (r *referenceResolver) resolve(path, value string) model.Expression {
+	if r == nil {
+		return nil
+	}
+
+	var candidates []reference
+	for _, ref := range r.table.values[value] {
+		if ref.urn != r.state.URN {
+			candidates = append(candidates, ref)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// A resource's ID is a better match than any of its outputs, and an ID match on one resource is a better match
+	// than an output match on another.
+	var ids []reference
+	for _, ref := range candidates {
+		if ref.property == "id" {
+			ids = append(ids, ref)
+		}
+	}
+	if len(ids) != 0 {
+		candidates = ids
+	}
+
+	// Several outputs of one resource may hold the same value. Any of them will do, but prefer one with the same name
+	// as the property that is being generated.
+	var matches []reference
+	byURN := map[resource.URN]int{}
+	for _, ref := range candidates {
+		i, ok := byURN[ref.urn]
+		if !ok {
+			byURN[ref.urn] = len(matches)
+			matches = append(matches, ref)
+		} else if ref.property == propertyName(path) {
+			matches[i] = ref
+		}
+	}
+
+	if len(matches) > 1 {
+		names := make([]string, len(matches))
+		for i, m := range matches {
+			names[i] = m.String()
+		}
+		sort.Strings(names)
+		r.report(path, fmt.Sprintf("%s matches %s", path, strings.Join(names, ", ")))
+		return nil
+	}
+
+	match := matches[0]
+	if r.table.dependsOn(match.urn, r.state.URN) {
+		r.report(path, fmt.Sprintf("%s matches %s, which would create a circular reference", path, match))
+		return nil
+	}
+
+	r.table.dependencies[r.state.URN][match.urn] = true
+	r.referenced[match.urn] = true
+	return match.expression()
+}
+
+func (r *referenceResolver) report(path, note string) {
+	r.notes = append(r.notes, note)
+	r.diags = append(r.diags, &hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  fmt.Sprintf("%s: could not resolve the value of %s into a reference", r.name, path),
+		Detail:   note,
+	})
+}
+
+// propertyName returns the name of the innermost property in the given path, ignoring any indices that follow it.
+func propertyName(path string) string {
+	for strings.HasSuffix(path, "]") {
+		path = path[:strings.LastIndex(path, "[")]
+	}
+	return path[strings.LastIndex(path, ".")+1:]
+}
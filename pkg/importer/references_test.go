@@ -0,0 +1,306 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/dotnet"
+	gogen "github.com/pulumi/pulumi/pkg/v3/codegen/go"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/syntax"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/nodejs"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/python"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/testing/utils"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+func makeImportedState(typ tokens.Type, name string, id resource.ID, inputs, outputs resource.PropertyMap,
+	dependencies ...resource.URN,
+) *resource.State {
+	return &resource.State{
+		Type:         typ,
+		URN:          resource.NewURN("stack", "project", "", typ, name),
+		Custom:       true,
+		ID:           id,
+		Inputs:       inputs,
+		Outputs:      outputs,
+		Dependencies: dependencies,
+	}
+}
+
+// makeCertificateStates returns the states of a CA and of a certificate that it signed. The certificate is listed
+// first, so that its definition must be moved after the definitions that it refers to.
+func makeCertificateStates() []*resource.State {
+	uses := resource.NewPropertyValue([]interface{}{"cert_signing"})
+
+	caKey := makeImportedState("tls:index/privateKey:PrivateKey", "caKey", "ca-key-id",
+		resource.PropertyMap{"algorithm": resource.NewStringProperty("RSA")},
+		resource.PropertyMap{
+			"algorithm":     resource.NewStringProperty("RSA"),
+			"privateKeyPem": resource.NewStringProperty("CA PRIVATE KEY"),
+			"publicKeyPem":  resource.NewStringProperty("CA PUBLIC KEY"),
+		})
+	ca := makeImportedState("tls:index/selfSignedCert:SelfSignedCert", "ca", "ca-id",
+		resource.PropertyMap{
+			"allowedUses":         uses,
+			"privateKeyPem":       resource.NewStringProperty("CA PRIVATE KEY"),
+			"validityPeriodHours": resource.NewNumberProperty(24),
+		},
+		resource.PropertyMap{"certPem": resource.NewStringProperty("CA CERTIFICATE")},
+		caKey.URN)
+	key := makeImportedState("tls:index/privateKey:PrivateKey", "key", "key-id",
+		resource.PropertyMap{"algorithm": resource.NewStringProperty("RSA")},
+		resource.PropertyMap{"privateKeyPem": resource.NewStringProperty("PRIVATE KEY")})
+	request := makeImportedState("tls:index/certRequest:CertRequest", "request", "request-id",
+		resource.PropertyMap{"privateKeyPem": resource.NewStringProperty("PRIVATE KEY")},
+		resource.PropertyMap{"certRequestPem": resource.NewStringProperty("CERTIFICATE REQUEST")})
+	cert := makeImportedState("tls:index/locallySignedCert:LocallySignedCert", "cert", "cert-id",
+		resource.PropertyMap{
+			"allowedUses":         uses,
+			"caCertPem":           resource.NewStringProperty("CA CERTIFICATE"),
+			"caPrivateKeyPem":     resource.NewStringProperty("CA PRIVATE KEY"),
+			"certRequestPem":      resource.NewStringProperty("CERTIFICATE REQUEST"),
+			"validityPeriodHours": resource.NewNumberProperty(24),
+		},
+		nil)
+	return []*resource.State{cert, caKey, ca, key, request}
+}
+
+func generateDefinitionsText(t *testing.T, states []*resource.State) (string, hcl.Diagnostics) {
+	loader := schema.NewPluginLoader(utils.NewHost(testdataPath))
+	blocks, diags, err := GenerateHCL2Definitions(loader, states, nil)
+	require.NoError(t, err)
+
+	text := make([]string, len(blocks))
+	for i, block := range blocks {
+		text[i] = fmt.Sprintf("%v", block)
+	}
+	formatted, formatDiags := syntax.Format([]byte(strings.Join(text, "\n")), "definitions.pp")
+	require.False(t, formatDiags.HasErrors(), "%v", formatDiags)
+	return string(formatted), diags
+}
+
+func TestGenerateHCL2DefinitionsReferences(t *testing.T) {
+	t.Parallel()
+
+	text, diags := generateDefinitionsText(t, makeCertificateStates())
+	assert.Empty(t, diags)
+	assert.Equal(t, `resource caKey "tls:index/privateKey:PrivateKey" {
+	algorithm = "RSA"
+
+}
+
+resource ca "tls:index/selfSignedCert:SelfSignedCert" {
+	allowedUses = [
+		"cert_signing"]
+	privateKeyPem = caKey.privateKeyPem
+	validityPeriodHours = 24
+
+}
+
+resource key "tls:index/privateKey:PrivateKey" {
+	algorithm = "RSA"
+
+}
+
+resource request "tls:index/certRequest:CertRequest" {
+	privateKeyPem = key.privateKeyPem
+
+}
+
+resource cert "tls:index/locallySignedCert:LocallySignedCert" {
+	allowedUses = [
+		"cert_signing"]
+	caCertPem = ca.certPem
+	caPrivateKeyPem = caKey.privateKeyPem
+	certRequestPem = request.certRequestPem
+	validityPeriodHours = 24
+
+}
+`, text)
+}
+
+func TestGenerateHCL2DefinitionsAmbiguousReferences(t *testing.T) {
+	t.Parallel()
+
+	// A second key with the same private key as the CA makes the CA's private key ambiguous. The CA keeps its
+	// explicit dependency on its key, as the dependency is no longer implied by a reference.
+	states := append(makeCertificateStates(),
+		makeImportedState("tls:index/privateKey:PrivateKey", "copy", "copy-id",
+			resource.PropertyMap{"algorithm": resource.NewStringProperty("RSA")},
+			resource.PropertyMap{"privateKeyPem": resource.NewStringProperty("CA PRIVATE KEY")}))
+
+	text, diags := generateDefinitionsText(t, states)
+	assert.Contains(t, text, `// The following values were not replaced with references to other imported resources:
+//   privateKeyPem matches caKey.privateKeyPem, copy.privateKeyPem
+resource ca "tls:index/selfSignedCert:SelfSignedCert" {
+	allowedUses = [
+		"cert_signing"]
+	privateKeyPem = "CA PRIVATE KEY"
+	validityPeriodHours = 24
+	options {
+		dependsOn = [
+			caKey]
+
+	}
+
+}
+`)
+	assert.Contains(t, text, `// The following values were not replaced with references to other imported resources:
+//   caPrivateKeyPem matches caKey.privateKeyPem, copy.privateKeyPem
+resource cert "tls:index/locallySignedCert:LocallySignedCert" {
+	allowedUses = [
+		"cert_signing"]
+	caCertPem = ca.certPem
+	caPrivateKeyPem = "CA PRIVATE KEY"
+`)
+	require.Len(t, diags, 2)
+	assert.Equal(t, hcl.DiagWarning, diags[0].Severity)
+	assert.Equal(t, "cert: could not resolve the value of caPrivateKeyPem into a reference", diags[0].Summary)
+	assert.Equal(t, "privateKeyPem matches caKey.privateKeyPem, copy.privateKeyPem", diags[1].Detail)
+}
+
+func TestGenerateHCL2DefinitionsCircularReferences(t *testing.T) {
+	t.Parallel()
+
+	// Each request's private key matches the ID of the other request.
+	a := makeImportedState("tls:index/certRequest:CertRequest", "a", "request-a-id",
+		resource.PropertyMap{"privateKeyPem": resource.NewStringProperty("request-b-id")}, nil)
+	b := makeImportedState("tls:index/certRequest:CertRequest", "b", "request-b-id",
+		resource.PropertyMap{"privateKeyPem": resource.NewStringProperty("request-a-id")}, nil)
+
+	text, diags := generateDefinitionsText(t, []*resource.State{a, b})
+	assert.Equal(t, `// The following values were not replaced with references to other imported resources:
+//   privateKeyPem matches a.id, which would create a circular reference
+resource b "tls:index/certRequest:CertRequest" {
+	privateKeyPem = "request-a-id"
+
+}
+
+resource a "tls:index/certRequest:CertRequest" {
+	privateKeyPem = b.id
+
+}
+`, text)
+	assert.Len(t, diags, 1)
+}
+
+func TestReferenceable(t *testing.T) {
+	t.Parallel()
+
+	for value, expected := range map[string]bool{
+		"default":          false,
+		"disabled":         false,
+		"Standard":         false,
+		"123456789":        false,
+		"3.14159265":       false,
+		"us-west-2":        false,
+		"us-gov-west-1":    false,
+		"ap-southeast-2a":  false,
+		"us-central1":      false,
+		"europe-west1-b":   false,
+		"vpc-0a1b2c3d4e5f": true,
+		"my-bucket-1":      true,
+		"CA PRIVATE KEY":   true,
+	} {
+		assert.Equal(t, expected, referenceable(value), value)
+	}
+}
+
+func TestGenerateHCL2DefinitionsCommonValues(t *testing.T) {
+	t.Parallel()
+
+	// Values that many resources share, such as "default", region names and numeric IDs, are left as literals even
+	// when they match exactly one other imported resource.
+	var states []*resource.State
+	for i, value := range []string{"default", "us-west-2", "123456789"} {
+		key := makeImportedState("tls:index/privateKey:PrivateKey", fmt.Sprintf("key%d", i), resource.ID(value),
+			resource.PropertyMap{"algorithm": resource.NewStringProperty("RSA")},
+			resource.PropertyMap{"privateKeyPem": resource.NewStringProperty(value)})
+		request := makeImportedState("tls:index/certRequest:CertRequest", fmt.Sprintf("request%d", i),
+			resource.ID(fmt.Sprintf("request-%d-id", i)),
+			resource.PropertyMap{"privateKeyPem": resource.NewStringProperty(value)}, nil)
+		states = append(states, key, request)
+	}
+
+	text, diags := generateDefinitionsText(t, states)
+	assert.Empty(t, diags)
+	assert.Contains(t, text, `privateKeyPem = "default"`)
+	assert.Contains(t, text, `privateKeyPem = "us-west-2"`)
+	assert.Contains(t, text, `privateKeyPem = "123456789"`)
+	assert.NotRegexp(t, `privateKeyPem = key\d`, text)
+}
+
+// The references and the order of the definitions must survive binding and code generation in each language.
+func TestGenerateLanguageDefinitionsReferences(t *testing.T) {
+	t.Parallel()
+
+	loader := schema.NewPluginLoader(utils.NewHost(testdataPath))
+
+	generators := map[string]func(*pcl.Program) (map[string][]byte, hcl.Diagnostics, error){
+		"nodejs": nodejs.GenerateProgram,
+		"python": python.GenerateProgram,
+		"go":     gogen.GenerateProgram,
+		"dotnet": dotnet.GenerateProgram,
+	}
+	expected := map[string][]string{
+		"nodejs": {"caKey.privateKeyPem", "ca.certPem", "request.certRequestPem"},
+		"python": {"ca_key.private_key_pem", "ca.cert_pem", "request.cert_request_pem"},
+		"go":     {"caKey.PrivateKeyPem", "ca.CertPem", "request.CertRequestPem"},
+		"dotnet": {"caKey.PrivateKeyPem", "ca.CertPem", "request.CertRequestPem"},
+	}
+
+	for language, generate := range generators {
+		language, generate := language, generate
+		t.Run(language, func(t *testing.T) {
+			t.Parallel()
+
+			var code string
+			diags, err := GenerateLanguageDefinitionsWithDiagnostics(io.Discard, loader, func(_ io.Writer, p *pcl.Program) error {
+				var names []string
+				for _, n := range pcl.Linearize(p) {
+					names = append(names, n.Name())
+				}
+				assert.Equal(t, []string{"caKey", "ca", "key", "request", "cert"}, names)
+
+				files, diags, err := generate(p)
+				if err != nil {
+					return err
+				}
+				assert.False(t, diags.HasErrors(), "%v", diags)
+				for _, contents := range files {
+					code += string(contents)
+				}
+				return nil
+			}, makeCertificateStates(), nil)
+			require.NoError(t, err)
+			assert.Empty(t, diags)
+
+			for _, ref := range expected[language] {
+				assert.Contains(t, code, ref)
+			}
+		})
+	}
+}
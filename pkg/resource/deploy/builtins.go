@@ -7,6 +7,8 @@ import (
 	"sort"
 
 	uuid "github.com/gofrs/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
@@ -57,6 +59,12 @@ func (p *builtinProvider) GetMappings(key string) ([]string, error) {
 	return []string{}, nil
 }
 
+func (p *builtinProvider) DiscoverResources(typ tokens.Type,
+	scope resource.PropertyMap,
+) ([]plugin.DiscoveredResource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "the builtin provider cannot discover resources of type %v", typ)
+}
+
 // CheckConfig validates the configuration for this resource provider.
 func (p *builtinProvider) CheckConfig(urn resource.URN, olds,
 	news resource.PropertyMap, allowUnknowns bool,
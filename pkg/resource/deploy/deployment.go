@@ -51,18 +51,19 @@ type BackendClient interface {
 
 // Options controls the deployment process.
 type Options struct {
-	Events                    Events     // an optional events callback interface.
-	Parallel                  int        // the degree of parallelism for resource operations (<=1 for serial).
-	Refresh                   bool       // whether or not to refresh before executing the deployment.
-	RefreshOnly               bool       // whether or not to exit after refreshing.
-	Targets                   UrnTargets // If specified, only operate on specified resources.
-	ReplaceTargets            UrnTargets // If specified, mark the specified resources for replacement.
-	TargetDependents          bool       // true if we're allowing things to proceed, even with unspecified targets
-	TrustDependencies         bool       // whether or not to trust the resource dependency graph.
-	UseLegacyDiff             bool       // whether or not to use legacy diffing behavior.
-	DisableResourceReferences bool       // true to disable resource reference support.
-	DisableOutputValues       bool       // true to disable output value support.
-	GeneratePlan              bool       // true to enable plan generation.
+	Events                    Events       // an optional events callback interface.
+	Parallel                  int          // the degree of parallelism for resource operations (<=1 for serial).
+	Refresh                   bool         // whether or not to refresh before executing the deployment.
+	RefreshOnly               bool         // whether or not to exit after refreshing.
+	Targets                   UrnTargets   // If specified, only operate on specified resources.
+	ReplaceTargets            UrnTargets   // If specified, mark the specified resources for replacement.
+	TargetDependents          bool         // true if we're allowing things to proceed, even with unspecified targets
+	TrustDependencies         bool         // whether or not to trust the resource dependency graph.
+	UseLegacyDiff             bool         // whether or not to use legacy diffing behavior.
+	DisableResourceReferences bool         // true to disable resource reference support.
+	DisableOutputValues       bool         // true to disable output value support.
+	GeneratePlan              bool         // true to enable plan generation.
+	StepTimings               *StepTimings // if set, records the timings of the deployment's steps.
 }
 
 // DegreeOfParallelism returns the degree of parallelism that should be used during the
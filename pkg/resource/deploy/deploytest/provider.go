@@ -20,6 +20,8 @@ import (
 
 	"github.com/blang/semver"
 	uuid "github.com/gofrs/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
@@ -70,6 +72,8 @@ type Provider struct {
 
 	GetMappingF  func(key, provider string) ([]byte, string, error)
 	GetMappingsF func(key string) ([]string, error)
+
+	DiscoverResourcesF func(typ tokens.Type, scope resource.PropertyMap) ([]plugin.DiscoveredResource, error)
 }
 
 func (prov *Provider) SignalCancellation() error {
@@ -250,3 +254,12 @@ func (prov *Provider) GetMappings(key string) ([]string, error) {
 	}
 	return prov.GetMappingsF(key)
 }
+
+func (prov *Provider) DiscoverResources(typ tokens.Type,
+	scope resource.PropertyMap,
+) ([]plugin.DiscoveredResource, error) {
+	if prov.DiscoverResourcesF == nil {
+		return nil, status.Error(codes.Unimplemented, "DiscoverResources is not implemented")
+	}
+	return prov.DiscoverResourcesF(typ, scope)
+}
@@ -271,6 +271,12 @@ func (r *Registry) GetMappings(key string) ([]string, error) {
 	return nil, errors.New("the provider registry has no mappings")
 }
 
+func (r *Registry) DiscoverResources(typ tokens.Type, scope resource.PropertyMap) ([]plugin.DiscoveredResource, error) {
+	contract.Failf("DiscoverResources must not be called on the provider registry")
+
+	return nil, errors.New("the provider registry cannot discover resources")
+}
+
 // CheckConfig validates the configuration for this resource provider.
 func (r *Registry) CheckConfig(urn resource.URN, olds,
 	news resource.PropertyMap, allowUnknowns bool,
@@ -25,6 +25,8 @@ import (
 	"github.com/blang/semver"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
@@ -163,6 +165,12 @@ func (prov *testProvider) GetMappings(key string) ([]string, error) {
 	return []string{}, nil
 }
 
+func (prov *testProvider) DiscoverResources(typ tokens.Type,
+	scope resource.PropertyMap,
+) ([]plugin.DiscoveredResource, error) {
+	return nil, status.Error(codes.Unimplemented, "DiscoverResources is not implemented")
+}
+
 type providerLoader struct {
 	pkg     tokens.Package
 	version semver.Version
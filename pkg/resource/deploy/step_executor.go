@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/promise"
@@ -125,6 +126,10 @@ func (se *stepExecutor) ExecuteSerial(chain chain) completionToken {
 	// The select here is to avoid blocking on a send to se.incomingChains if a cancellation is pending.
 	// If one is pending, we should exit early - we will shortly be tearing down the engine and exiting.
 
+	if timings := se.timings(); timings != nil && len(chain) > 0 {
+		timings.queued(chain[0], time.Now())
+	}
+
 	completion := make(chan bool)
 	select {
 	case se.incomingChains <- incomingChain{Chain: chain, CompletionChan: completion}:
@@ -270,7 +275,8 @@ func (se *stepExecutor) WaitForCompletion() {
 // executeChain executes a chain, one step at a time. If any step in the chain fails to execute, or if the
 // context is canceled, the chain stops execution.
 func (se *stepExecutor) executeChain(workerID int, chain chain) {
-	for _, step := range chain {
+	timings := se.timings()
+	for i, step := range chain {
 		select {
 		case <-se.ctx.Done():
 			se.log(workerID, "step %v on %v canceled", step.Op(), step.URN())
@@ -278,6 +284,14 @@ func (se *stepExecutor) executeChain(workerID int, chain chain) {
 		default:
 		}
 
+		if timings != nil {
+			// Steps after the first are ready to run as soon as the step before them finishes.
+			if i > 0 {
+				timings.queued(step, time.Now())
+			}
+			timings.started(step, time.Now())
+		}
+
 		// Take the work lock before executing the step, this uses the "read" side of the lock because we're ok with as
 		// many workers as possible executing steps in parallel.
 		se.workerLock.RLock()
@@ -285,6 +299,10 @@ func (se *stepExecutor) executeChain(workerID int, chain chain) {
 		// Regardless of error we need to release the lock here.
 		se.workerLock.RUnlock()
 
+		if timings != nil {
+			timings.finished(step, time.Now(), err != nil)
+		}
+
 		if err != nil {
 			se.log(workerID, "step %v on %v failed, signalling cancellation", step.Op(), step.URN())
 			se.cancelDueToError(err)
@@ -304,6 +322,14 @@ func (se *stepExecutor) executeChain(workerID int, chain chain) {
 	}
 }
 
+// timings returns the recorder for step timings, or nil if timings are not being recorded.
+func (se *stepExecutor) timings() *StepTimings {
+	if se.preview {
+		return nil
+	}
+	return se.opts.StepTimings
+}
+
 func (se *stepExecutor) cancelDueToError(err error) {
 	set := se.sawError.Reject(err)
 	if !set {
@@ -0,0 +1,213 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+// StepTiming records when a step was ready to run, when a worker started running it, and when it finished.
+type StepTiming struct {
+	URN  resource.URN
+	Type tokens.Type
+	Op   display.StepOp
+
+	// Dependencies are the resources that the step's resource depends on, including its parent.
+	Dependencies []resource.URN
+
+	// Queued is when the step was ready to run. The first step of a chain is ready when the chain is submitted to
+	// the step executor; every other step is ready as soon as the step before it finishes.
+	Queued time.Time
+	// Started is when a worker started running the step.
+	Started time.Time
+	// Finished is when the step finished, or the zero time if it never did.
+	Finished time.Time
+
+	Failed bool
+}
+
+// Wait returns how long the step waited for a worker.
+func (t StepTiming) Wait() time.Duration {
+	if t.Started.IsZero() || t.Started.Before(t.Queued) {
+		return 0
+	}
+	return t.Started.Sub(t.Queued)
+}
+
+// Duration returns how long the step ran for.
+func (t StepTiming) Duration() time.Duration {
+	if t.Finished.IsZero() || t.Finished.Before(t.Started) {
+		return 0
+	}
+	return t.Finished.Sub(t.Started)
+}
+
+// StepTimings records the timings of the steps of a deployment. It is safe for concurrent use. Timings are not recorded
+// during previews, so that the preview that precedes an update is not mixed with it.
+type StepTimings struct {
+	m     sync.Mutex
+	steps map[Step]*StepTiming
+	order []*StepTiming
+}
+
+// NewStepTimings creates an empty set of step timings.
+func NewStepTimings() *StepTimings {
+	return &StepTimings{steps: map[Step]*StepTiming{}}
+}
+
+func (t *StepTimings) timing(step Step) *StepTiming {
+	if st, ok := t.steps[step]; ok {
+		return st
+	}
+
+	st := &StepTiming{URN: step.URN(), Type: step.Type(), Op: step.Op()}
+	state := step.New()
+	if state == nil {
+		state = step.Old()
+	}
+	if state != nil {
+		st.Dependencies = append(st.Dependencies, state.Dependencies...)
+		if state.Parent != "" {
+			st.Dependencies = append(st.Dependencies, state.Parent)
+		}
+	}
+	t.steps[step] = st
+	t.order = append(t.order, st)
+	return st
+}
+
+func (t *StepTimings) queued(step Step, at time.Time) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.timing(step).Queued = at
+}
+
+func (t *StepTimings) started(step Step, at time.Time) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.timing(step).Started = at
+}
+
+func (t *StepTimings) finished(step Step, at time.Time, failed bool) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	st := t.timing(step)
+	st.Finished, st.Failed = at, failed
+}
+
+// Steps returns the timings of the steps that started, in the order they were first seen.
+func (t *StepTimings) Steps() []StepTiming {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	steps := make([]StepTiming, 0, len(t.order))
+	for _, st := range t.order {
+		if !st.Started.IsZero() {
+			steps = append(steps, *st)
+		}
+	}
+	return steps
+}
+
+// CriticalPath returns the longest chain of steps through the dependency graph, measured by the time the steps ran
+// for. A step follows another on the path if their resources depend on each other, in either direction so that
+// deletions are covered, and the other step finished before it started. The path is returned in execution order.
+func CriticalPath(steps []StepTiming) []StepTiming {
+	// Visit the steps in the order they started; a step can only follow steps that started before it.
+	order := make([]int, len(steps))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return steps[order[i]].Started.Before(steps[order[j]].Started) })
+
+	related := map[resource.URN]map[resource.URN]bool{}
+	relate := func(a, b resource.URN) {
+		if related[a] == nil {
+			related[a] = map[resource.URN]bool{}
+		}
+		related[a][b] = true
+	}
+	for _, st := range steps {
+		for _, dep := range st.Dependencies {
+			relate(st.URN, dep)
+			relate(dep, st.URN)
+		}
+	}
+
+	length := make([]time.Duration, len(steps))
+	prev := make([]int, len(steps))
+	best := -1
+	for n, i := range order {
+		length[i], prev[i] = steps[i].Duration(), -1
+		for _, j := range order[:n] {
+			if !related[steps[i].URN][steps[j].URN] || steps[j].Finished.IsZero() ||
+				steps[j].Finished.After(steps[i].Started) {
+				continue
+			}
+			if l := length[j] + steps[i].Duration(); l > length[i] {
+				length[i], prev[i] = l, j
+			}
+		}
+		if best == -1 || length[i] > length[best] {
+			best = i
+		}
+	}
+
+	var path []StepTiming
+	for i := best; i != -1; i = prev[i] {
+		path = append(path, steps[i])
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// MaxConcurrency returns the largest number of steps that were running at the same time.
+func MaxConcurrency(steps []StepTiming) int {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+	edges := make([]edge, 0, 2*len(steps))
+	for _, st := range steps {
+		if st.Finished.IsZero() {
+			continue
+		}
+		edges = append(edges, edge{st.Started, 1}, edge{st.Finished, -1})
+	}
+	// Process finishes before starts at the same instant, so that back-to-back steps do not count as concurrent.
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	running, peak := 0, 0
+	for _, e := range edges {
+		running += e.delta
+		if running > peak {
+			peak = running
+		}
+	}
+	return peak
+}
@@ -0,0 +1,87 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func testStepTiming(name string, start, end int, deps ...string) StepTiming {
+	origin := time.Unix(0, 0)
+	st := StepTiming{
+		URN:      resource.NewURN("dev", "proj", "", "pkgA:m:typA", name),
+		Op:       OpCreate,
+		Queued:   origin,
+		Started:  origin.Add(time.Duration(start) * time.Second),
+		Finished: origin.Add(time.Duration(end) * time.Second),
+	}
+	for _, dep := range deps {
+		st.Dependencies = append(st.Dependencies, resource.NewURN("dev", "proj", "", "pkgA:m:typA", dep))
+	}
+	return st
+}
+
+func TestCriticalPath(t *testing.T) {
+	t.Parallel()
+
+	// a -> b -> d is 1+5+1 seconds, a -> c -> d is 1+2+1 seconds, and e is unrelated to the rest.
+	steps := []StepTiming{
+		testStepTiming("a", 0, 1),
+		testStepTiming("b", 1, 6, "a"),
+		testStepTiming("c", 1, 3, "a"),
+		testStepTiming("d", 6, 7, "b", "c"),
+		testStepTiming("e", 0, 4),
+	}
+
+	var names []string
+	for _, st := range CriticalPath(steps) {
+		names = append(names, st.URN.Name())
+	}
+	assert.Equal(t, []string{"a", "b", "d"}, names)
+
+	assert.Empty(t, CriticalPath(nil))
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	steps := []StepTiming{
+		testStepTiming("a", 0, 1),
+		testStepTiming("b", 1, 6),
+		testStepTiming("c", 1, 3),
+		testStepTiming("d", 2, 4),
+		testStepTiming("e", 6, 7),
+	}
+	assert.Equal(t, 3, MaxConcurrency(steps))
+
+	// Steps that run back to back are not concurrent.
+	assert.Equal(t, 1, MaxConcurrency([]StepTiming{testStepTiming("a", 0, 1), testStepTiming("b", 1, 2)}))
+}
+
+func TestStepTimingWait(t *testing.T) {
+	t.Parallel()
+
+	st := testStepTiming("a", 2, 3)
+	assert.Equal(t, 2*time.Second, st.Wait())
+	assert.Equal(t, time.Second, st.Duration())
+
+	st.Finished = time.Time{}
+	assert.Equal(t, time.Duration(0), st.Duration())
+}
@@ -0,0 +1,342 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secretscan detects values in resource properties that look like secrets but are not marked as secret, so
+// that they can be reported or promoted to secrets before they are written to a checkpoint in plaintext.
+package secretscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"gopkg.in/yaml.v3"
+)
+
+// Detector decides whether a single string value looks like a secret.
+type Detector interface {
+	// Name is a short identifier for the detector that is included in findings, e.g. "aws-access-key-id".
+	Name() string
+	// Detect returns true if the value looks like a secret.
+	Detect(value string) bool
+}
+
+// RegexDetector detects values that match a regular expression.
+type RegexDetector struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// NewRegexDetector returns a detector with the given name that detects values matching pattern.
+func NewRegexDetector(name, pattern string) (*RegexDetector, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern for detector %q: %w", name, err)
+	}
+	return &RegexDetector{name: name, pattern: re}, nil
+}
+
+func (d *RegexDetector) Name() string             { return d.name }
+func (d *RegexDetector) Detect(value string) bool { return d.pattern.MatchString(value) }
+
+// EntropyDetector detects long, token-like values with a high Shannon entropy, which is typical of randomly
+// generated keys and passwords. Values containing characters that do not usually appear in tokens, such as
+// whitespace or ':', are ignored, which rules out most prose, URLs and identifiers such as ARNs.
+type EntropyDetector struct {
+	// MinLength is the minimum length of a value to be considered.
+	MinLength int
+	// Threshold is the minimum entropy, in bits per character, of a value to be considered a secret.
+	Threshold float64
+}
+
+const (
+	// DefaultEntropyMinLength is the default EntropyDetector.MinLength.
+	DefaultEntropyMinLength = 24
+	// DefaultEntropyThreshold is the default EntropyDetector.Threshold. It is above the 4 bits per character that
+	// hexadecimal strings such as hashes can reach, so those are not reported.
+	DefaultEntropyThreshold = 4.5
+)
+
+var tokenPattern = regexp.MustCompile(`^[A-Za-z0-9+/=_\-.]+$`)
+
+func (d *EntropyDetector) Name() string { return "high-entropy-string" }
+
+func (d *EntropyDetector) Detect(value string) bool {
+	if len(value) < d.MinLength || !tokenPattern.MatchString(value) {
+		return false
+	}
+	return ShannonEntropy(value) >= d.Threshold
+}
+
+// ShannonEntropy returns the Shannon entropy of s in bits per character.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	entropy := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// builtinPatterns are the well-known token formats detected by default.
+var builtinPatterns = []struct{ name, pattern string }{
+	{"aws-access-key-id", `\b(AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{"github-token", `\b(gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{82})\b`},
+	{"slack-token", `\bxox[baprs]-[A-Za-z0-9-]{10,}`},
+	{"google-api-key", `\bAIza[0-9A-Za-z_\-]{35}\b`},
+	{"stripe-secret-key", `\b[rs]k_live_[0-9A-Za-z]{24,}\b`},
+	{"pulumi-access-token", `\bpul-[0-9a-f]{40}\b`},
+	{"private-key", `-----BEGIN ([A-Z]+ )*PRIVATE KEY( BLOCK)?-----`},
+	{"json-web-token", `\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`},
+	{"url-credentials", `\b[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]+:[^/\s:@]+@`},
+}
+
+// BuiltinDetectors returns detectors for well-known token formats, such as cloud provider access keys, API tokens,
+// private keys and URLs with embedded credentials.
+func BuiltinDetectors() []Detector {
+	detectors := make([]Detector, len(builtinPatterns))
+	for i, p := range builtinPatterns {
+		d, err := NewRegexDetector(p.name, p.pattern)
+		if err != nil {
+			panic(err)
+		}
+		detectors[i] = d
+	}
+	return detectors
+}
+
+// Config configures the detectors used by a Scanner. The zero value enables the builtin detectors and the entropy
+// detector with its default settings.
+type Config struct {
+	// DisableBuiltins disables the detectors for well-known token formats.
+	DisableBuiltins bool `json:"disableBuiltins,omitempty" yaml:"disableBuiltins,omitempty"`
+	// Patterns are additional regular expressions to detect, keyed by detector name.
+	Patterns map[string]string `json:"patterns,omitempty" yaml:"patterns,omitempty"`
+	// Entropy configures the entropy detector.
+	Entropy EntropyConfig `json:"entropy,omitempty" yaml:"entropy,omitempty"`
+	// Allow lists regular expressions for values that should never be reported, e.g. known public keys.
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+}
+
+// EntropyConfig configures the entropy detector.
+type EntropyConfig struct {
+	// Disable disables the entropy detector.
+	Disable bool `json:"disable,omitempty" yaml:"disable,omitempty"`
+	// MinLength overrides DefaultEntropyMinLength if non-zero.
+	MinLength int `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	// Threshold overrides DefaultEntropyThreshold if non-zero.
+	Threshold float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+}
+
+// LoadConfig reads a scanner configuration from a YAML or JSON file.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	// YAML is a superset of JSON, so this handles both.
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Finding is a property value that looks like a secret.
+type Finding struct {
+	// URN is the URN of the resource that the property belongs to.
+	URN resource.URN
+	// Path is the path of the property within the resource's properties.
+	Path resource.PropertyPath
+	// Detector is the name of the detector that matched the value.
+	Detector string
+}
+
+// MarshalJSON renders the path of a finding as a string, e.g. "tags.token" or "keys[0]".
+func (f Finding) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		URN      resource.URN `json:"urn"`
+		Path     string       `json:"path"`
+		Detector string       `json:"detector"`
+	}{f.URN, f.Path.String(), f.Detector})
+}
+
+// Scanner runs a set of detectors over resource properties.
+type Scanner struct {
+	detectors []Detector
+	allow     []*regexp.Regexp
+}
+
+// NewScanner returns a scanner that uses the given detectors.
+func NewScanner(detectors ...Detector) *Scanner {
+	return &Scanner{detectors: detectors}
+}
+
+// NewScannerFromConfig returns a scanner configured by cfg.
+func NewScannerFromConfig(cfg Config) (*Scanner, error) {
+	var detectors []Detector
+	if !cfg.DisableBuiltins {
+		detectors = append(detectors, BuiltinDetectors()...)
+	}
+
+	names := make([]string, 0, len(cfg.Patterns))
+	for name := range cfg.Patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d, err := NewRegexDetector(name, cfg.Patterns[name])
+		if err != nil {
+			return nil, err
+		}
+		detectors = append(detectors, d)
+	}
+
+	if !cfg.Entropy.Disable {
+		entropy := &EntropyDetector{MinLength: DefaultEntropyMinLength, Threshold: DefaultEntropyThreshold}
+		if cfg.Entropy.MinLength != 0 {
+			entropy.MinLength = cfg.Entropy.MinLength
+		}
+		if cfg.Entropy.Threshold != 0 {
+			entropy.Threshold = cfg.Entropy.Threshold
+		}
+		detectors = append(detectors, entropy)
+	}
+
+	s := NewScanner(detectors...)
+	for _, pattern := range cfg.Allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allow pattern %q: %w", pattern, err)
+		}
+		s.allow = append(s.allow, re)
+	}
+	return s, nil
+}
+
+// detect returns the name of the first detector that matches value, if any.
+func (s *Scanner) detect(value string) (string, bool) {
+	for _, re := range s.allow {
+		if re.MatchString(value) {
+			return "", false
+		}
+	}
+	for _, d := range s.detectors {
+		if d.Detect(value) {
+			return d.Name(), true
+		}
+	}
+	return "", false
+}
+
+// Scan returns a finding for each string in props that looks like a secret and is not already marked secret.
+// Findings are sorted by path.
+func (s *Scanner) Scan(urn resource.URN, props resource.PropertyMap) []Finding {
+	var findings []Finding
+	for _, k := range props.StableKeys() {
+		s.scanValue(urn, resource.PropertyPath{string(k)}, props[k], &findings)
+	}
+	return findings
+}
+
+func (s *Scanner) scanValue(urn resource.URN, path resource.PropertyPath, v resource.PropertyValue,
+	findings *[]Finding,
+) {
+	switch {
+	case v.IsSecret():
+		// Already protected.
+	case v.IsString():
+		if name, ok := s.detect(v.StringValue()); ok {
+			*findings = append(*findings, Finding{URN: urn, Path: copyPath(path), Detector: name})
+		}
+	case v.IsArray():
+		for i, e := range v.ArrayValue() {
+			s.scanValue(urn, append(path, i), e, findings)
+		}
+	case v.IsObject():
+		obj := v.ObjectValue()
+		for _, k := range obj.StableKeys() {
+			s.scanValue(urn, append(path, string(k)), obj[k], findings)
+		}
+	case v.IsOutput():
+		if out := v.OutputValue(); out.Known && !out.Secret {
+			s.scanValue(urn, path, out.Element, findings)
+		}
+	}
+}
+
+func copyPath(path resource.PropertyPath) resource.PropertyPath {
+	return append(resource.PropertyPath{}, path...)
+}
+
+// Promote returns a copy of props in which the values at the paths of the given findings are marked secret. The
+// input map is not modified. Findings are matched by path only, so they should come from scanning props.
+func Promote(props resource.PropertyMap, findings []Finding) resource.PropertyMap {
+	if len(findings) == 0 {
+		return props
+	}
+	paths := make(map[string]bool, len(findings))
+	for _, f := range findings {
+		paths[f.Path.String()] = true
+	}
+	return promoteValue(resource.NewObjectProperty(props), nil, paths).ObjectValue()
+}
+
+func promoteValue(v resource.PropertyValue, path resource.PropertyPath, paths map[string]bool,
+) resource.PropertyValue {
+	if len(path) > 0 && paths[path.String()] && !v.IsSecret() && !v.IsOutput() {
+		return resource.MakeSecret(v)
+	}
+	switch {
+	case v.IsArray():
+		arr := v.ArrayValue()
+		promoted := make([]resource.PropertyValue, len(arr))
+		for i, e := range arr {
+			promoted[i] = promoteValue(e, append(copyPath(path), i), paths)
+		}
+		return resource.NewArrayProperty(promoted)
+	case v.IsObject():
+		obj := v.ObjectValue()
+		promoted := make(resource.PropertyMap, len(obj))
+		for k, e := range obj {
+			promoted[k] = promoteValue(e, append(copyPath(path), string(k)), paths)
+		}
+		return resource.NewObjectProperty(promoted)
+	case v.IsOutput():
+		out := v.OutputValue()
+		if out.Known && !out.Secret {
+			out.Element = promoteValue(out.Element, path, paths)
+			if out.Element.IsSecret() {
+				out.Element, out.Secret = out.Element.SecretValue().Element, true
+			}
+			return resource.NewOutputProperty(out)
+		}
+		return v
+	default:
+		return v
+	}
+}
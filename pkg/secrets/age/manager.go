@@ -0,0 +1,260 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package age implements support for a secrets manager whose data key is encrypted to one or more age
+// (https://age-encryption.org) recipients.
+package age
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+
+	"github.com/pulumi/pulumi/pkg/v3/secrets"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// Type is the type of secrets managed by this secrets provider
+const Type = "age"
+
+// URLPrefix is the prefix of age secrets provider URLs. The rest of the URL is a comma-separated list of age
+// recipients, e.g. "age://age1abc...,age1def...".
+const URLPrefix = "age://"
+
+const (
+	identityEnvVar     = "PULUMI_AGE_IDENTITY"
+	identityFileEnvVar = "PULUMI_AGE_IDENTITY_FILE"
+)
+
+type ageSecretsManagerState struct {
+	URL          string `json:"url"`
+	EncryptedKey []byte `json:"encryptedkey"`
+}
+
+// IsAgeSecretsProvider returns true if the given secrets provider URL refers to the age secrets provider.
+func IsAgeSecretsProvider(secretsProvider string) bool {
+	return strings.HasPrefix(secretsProvider, URLPrefix)
+}
+
+// parseRecipients returns the X25519 recipients listed in an age secrets provider URL.
+func parseRecipients(url string) ([]age.Recipient, error) {
+	if !IsAgeSecretsProvider(url) {
+		return nil, fmt.Errorf("age secrets provider URL %q must start with %q", url, URLPrefix)
+	}
+
+	var recipients []age.Recipient
+	for _, s := range strings.Split(strings.TrimPrefix(url, URLPrefix), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		recipient, err := age.ParseX25519Recipient(s)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("age secrets provider URL %q does not list any recipients", url)
+	}
+	return recipients, nil
+}
+
+// loadIdentities loads the identities used to decrypt the data key from PULUMI_AGE_IDENTITY, which contains the
+// identities themselves, or PULUMI_AGE_IDENTITY_FILE, which is the path to an age identity file.
+func loadIdentities() ([]age.Identity, error) {
+	if identity, ok := os.LookupEnv(identityEnvVar); ok && identity != "" {
+		identities, err := age.ParseIdentities(strings.NewReader(identity))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", identityEnvVar, err)
+		}
+		return identities, nil
+	}
+	if identityFile, ok := os.LookupEnv(identityFileEnvVar); ok && identityFile != "" {
+		contents, err := os.ReadFile(identityFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", identityFileEnvVar, err)
+		}
+		identities, err := age.ParseIdentities(bytes.NewReader(contents))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", identityFileEnvVar, err)
+		}
+		return identities, nil
+	}
+	return nil, fmt.Errorf("an age identity must be set with %s or %s", identityEnvVar, identityFileEnvVar)
+}
+
+// unwrapDataKey decrypts an encrypted data key using the identities from the environment.
+func unwrapDataKey(encryptedDataKey []byte) ([]byte, error) {
+	identities, err := loadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(bytes.NewReader(encryptedDataKey), identities...)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, errors.New("none of the available age identities is a recipient of the data key")
+	} else if err != nil {
+		return nil, fmt.Errorf("decrypting data key: %w", err)
+	}
+	dataKey, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// wrapDataKey encrypts a data key to every recipient listed in the given URL.
+func wrapDataKey(url string, dataKey []byte) ([]byte, error) {
+	recipients, err := parseRecipients(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var encrypted bytes.Buffer
+	w, err := age.Encrypt(&encrypted, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting data key: %w", err)
+	}
+	if _, err := w.Write(dataKey); err != nil {
+		return nil, fmt.Errorf("encrypting data key: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("encrypting data key: %w", err)
+	}
+	return encrypted.Bytes(), nil
+}
+
+// generateNewDataKey generates a new DataKey seeded by a fresh random 32-byte key and encrypted to the recipients
+// listed in the given URL.
+func generateNewDataKey(url string) ([]byte, error) {
+	plaintextDataKey := make([]byte, 32)
+	if _, err := rand.Read(plaintextDataKey); err != nil {
+		return nil, err
+	}
+	return wrapDataKey(url, plaintextDataKey)
+}
+
+// newAgeSecretsManager returns a secrets manager that uses an age-encrypted data key for envelope encryption of
+// secrets values.
+func newAgeSecretsManager(url string, encryptedDataKey []byte) (*Manager, error) {
+	plaintextDataKey, err := unwrapDataKey(encryptedDataKey)
+	if err != nil {
+		return nil, err
+	}
+	state, err := json.Marshal(ageSecretsManagerState{
+		URL:          url,
+		EncryptedKey: encryptedDataKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling state: %w", err)
+	}
+	return &Manager{
+		crypter: config.NewSymmetricCrypter(plaintextDataKey),
+		state:   state,
+	}, nil
+}
+
+// Manager is the secrets.Manager implementation for age
+type Manager struct {
+	state   json.RawMessage
+	crypter config.Crypter
+}
+
+func (m *Manager) Type() string                         { return Type }
+func (m *Manager) State() json.RawMessage               { return m.state }
+func (m *Manager) Encrypter() (config.Encrypter, error) { return m.crypter, nil }
+func (m *Manager) Decrypter() (config.Decrypter, error) { return m.crypter, nil }
+
+func EditProjectStack(info *workspace.ProjectStack, state json.RawMessage) error {
+	info.EncryptionSalt = ""
+
+	var s ageSecretsManagerState
+	err := json.Unmarshal(state, &s)
+	if err != nil {
+		return fmt.Errorf("unmarshalling age state: %w", err)
+	}
+
+	info.SecretsProvider = s.URL
+	info.EncryptedKey = base64.StdEncoding.EncodeToString(s.EncryptedKey)
+	return nil
+}
+
+// NewAgeSecretsManagerFromState deserialize configuration from state and returns a secrets manager that uses an
+// age-encrypted data key for envelope encryption of secrets values.
+func NewAgeSecretsManagerFromState(state json.RawMessage) (secrets.Manager, error) {
+	var s ageSecretsManagerState
+	if err := json.Unmarshal(state, &s); err != nil {
+		return nil, fmt.Errorf("unmarshalling state: %w", err)
+	}
+
+	return newAgeSecretsManager(s.URL, s.EncryptedKey)
+}
+
+// NewAgeSecretsManager returns a secrets manager for the age recipients listed in secretsProvider, updating the
+// stack's encryption information as needed.
+//
+// If the stack already uses age with a different set of recipients, its data key is decrypted and re-encrypted
+// to the new recipients, so that existing secrets remain readable. This is how team members are added or removed.
+// A new data key is only generated for new stacks, when switching from another secrets provider, or when
+// rotateSecretsProvider is set.
+func NewAgeSecretsManager(info *workspace.ProjectStack,
+	secretsProvider string, rotateSecretsProvider bool,
+) (secrets.Manager, error) {
+	// Only a passphrase provider has an encryption salt.
+	info.EncryptionSalt = ""
+
+	if rotateSecretsProvider {
+		info.EncryptedKey = ""
+	}
+
+	switch {
+	case info.EncryptedKey == "" || !IsAgeSecretsProvider(info.SecretsProvider):
+		dataKey, err := generateNewDataKey(secretsProvider)
+		if err != nil {
+			return nil, err
+		}
+		info.EncryptedKey = base64.StdEncoding.EncodeToString(dataKey)
+	case info.SecretsProvider != secretsProvider:
+		oldKey, err := base64.StdEncoding.DecodeString(info.EncryptedKey)
+		if err != nil {
+			return nil, err
+		}
+		plaintextDataKey, err := unwrapDataKey(oldKey)
+		if err != nil {
+			return nil, errors.Join(
+				errors.New("changing age recipients requires an identity for one of the current recipients"), err)
+		}
+		dataKey, err := wrapDataKey(secretsProvider, plaintextDataKey)
+		if err != nil {
+			return nil, err
+		}
+		info.EncryptedKey = base64.StdEncoding.EncodeToString(dataKey)
+	}
+	info.SecretsProvider = secretsProvider
+
+	dataKey, err := base64.StdEncoding.DecodeString(info.EncryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	return newAgeSecretsManager(secretsProvider, dataKey)
+}
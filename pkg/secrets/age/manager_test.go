@@ -0,0 +1,182 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package age
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func newIdentity(t *testing.T) *age.X25519Identity {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return identity
+}
+
+func providerURL(identities ...*age.X25519Identity) string {
+	recipients := make([]string, len(identities))
+	for i, identity := range identities {
+		recipients[i] = identity.Recipient().String()
+	}
+	return URLPrefix + strings.Join(recipients, ",")
+}
+
+func resetAgeTestEnvVars(t *testing.T) {
+	oldIdentity, hasIdentity := os.LookupEnv(identityEnvVar)
+	oldIdentityFile, hasIdentityFile := os.LookupEnv(identityFileEnvVar)
+	os.Unsetenv(identityEnvVar)
+	os.Unsetenv(identityFileEnvVar)
+	t.Cleanup(func() {
+		os.Unsetenv(identityEnvVar)
+		os.Unsetenv(identityFileEnvVar)
+		if hasIdentity {
+			os.Setenv(identityEnvVar, oldIdentity)
+		}
+		if hasIdentityFile {
+			os.Setenv(identityFileEnvVar, oldIdentityFile)
+		}
+	})
+}
+
+//nolint:paralleltest // mutates environment variables
+func TestAgeManagerNoIdentityReturnsError(t *testing.T) {
+	resetAgeTestEnvVars(t)
+
+	alice := newIdentity(t)
+	info := &workspace.ProjectStack{}
+	_, err := NewAgeSecretsManager(info, providerURL(alice), false /* rotateSecretsProvider */)
+	assert.ErrorContains(t, err, "PULUMI_AGE_IDENTITY")
+}
+
+//nolint:paralleltest // mutates environment variables
+func TestAgeManagerInvalidRecipientReturnsError(t *testing.T) {
+	resetAgeTestEnvVars(t)
+
+	_, err := NewAgeSecretsManager(&workspace.ProjectStack{}, URLPrefix+"age1notakey", false)
+	assert.Error(t, err)
+	_, err = NewAgeSecretsManager(&workspace.ProjectStack{}, URLPrefix, false)
+	assert.ErrorContains(t, err, "does not list any recipients")
+}
+
+//nolint:paralleltest // mutates environment variables
+func TestAgeManagerRoundTrip(t *testing.T) {
+	resetAgeTestEnvVars(t)
+	ctx := context.Background()
+
+	alice := newIdentity(t)
+	os.Setenv(identityEnvVar, alice.String())
+
+	info := &workspace.ProjectStack{EncryptionSalt: "v1:stale"}
+	sm, err := NewAgeSecretsManager(info, providerURL(alice), false /* rotateSecretsProvider */)
+	require.NoError(t, err)
+	assert.Equal(t, Type, sm.Type())
+	assert.Equal(t, providerURL(alice), info.SecretsProvider)
+	assert.NotEmpty(t, info.EncryptedKey)
+	assert.Empty(t, info.EncryptionSalt)
+
+	enc, err := sm.Encrypter()
+	require.NoError(t, err)
+	ciphertext, err := enc.EncryptValue(ctx, "hunter2")
+	require.NoError(t, err)
+
+	// The manager can be reconstructed from its state, e.g. when loading a checkpoint.
+	fromState, err := NewAgeSecretsManagerFromState(sm.State())
+	require.NoError(t, err)
+	dec, err := fromState.Decrypter()
+	require.NoError(t, err)
+	plaintext, err := dec.DecryptValue(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+
+	// And the stack config can be restored from the state.
+	restored := &workspace.ProjectStack{}
+	require.NoError(t, EditProjectStack(restored, sm.State()))
+	assert.Equal(t, info.SecretsProvider, restored.SecretsProvider)
+	assert.Equal(t, info.EncryptedKey, restored.EncryptedKey)
+}
+
+//nolint:paralleltest // mutates environment variables
+func TestAgeManagerIdentityFile(t *testing.T) {
+	resetAgeTestEnvVars(t)
+
+	alice := newIdentity(t)
+	identityFile := filepath.Join(t.TempDir(), "keys.txt")
+	require.NoError(t, os.WriteFile(identityFile, []byte("# alice\n"+alice.String()+"\n"), 0o600))
+	os.Setenv(identityFileEnvVar, identityFile)
+
+	sm, err := NewAgeSecretsManager(&workspace.ProjectStack{}, providerURL(alice), false)
+	require.NoError(t, err)
+	assert.NotNil(t, sm)
+
+	os.Setenv(identityFileEnvVar, filepath.Join(t.TempDir(), "missing.txt"))
+	_, err = NewAgeSecretsManagerFromState(sm.State())
+	assert.ErrorContains(t, err, "unable to read PULUMI_AGE_IDENTITY_FILE")
+}
+
+//nolint:paralleltest // mutates environment variables
+func TestAgeManagerChangeRecipientsRewrapsDataKey(t *testing.T) {
+	resetAgeTestEnvVars(t)
+	ctx := context.Background()
+
+	alice, bob := newIdentity(t), newIdentity(t)
+	os.Setenv(identityEnvVar, alice.String())
+
+	info := &workspace.ProjectStack{}
+	sm, err := NewAgeSecretsManager(info, providerURL(alice), false)
+	require.NoError(t, err)
+	enc, err := sm.Encrypter()
+	require.NoError(t, err)
+	ciphertext, err := enc.EncryptValue(ctx, "hunter2")
+	require.NoError(t, err)
+
+	// Adding bob re-wraps the existing data key, so he can read secrets encrypted before he was added.
+	_, err = NewAgeSecretsManager(info, providerURL(alice, bob), false)
+	require.NoError(t, err)
+	assert.Equal(t, providerURL(alice, bob), info.SecretsProvider)
+
+	os.Setenv(identityEnvVar, bob.String())
+	sm, err = NewAgeSecretsManager(info, info.SecretsProvider, false)
+	require.NoError(t, err)
+	dec, err := sm.Decrypter()
+	require.NoError(t, err)
+	plaintext, err := dec.DecryptValue(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+
+	// Bob removes alice, who can then no longer decrypt the data key.
+	_, err = NewAgeSecretsManager(info, providerURL(bob), false)
+	require.NoError(t, err)
+	os.Setenv(identityEnvVar, alice.String())
+	_, err = NewAgeSecretsManager(info, info.SecretsProvider, false)
+	assert.ErrorContains(t, err, "none of the available age identities")
+
+	// Rotating generates a new data key, so old secrets can no longer be decrypted with it.
+	os.Setenv(identityEnvVar, bob.String())
+	sm, err = NewAgeSecretsManager(info, info.SecretsProvider, true /* rotateSecretsProvider */)
+	require.NoError(t, err)
+	dec, err = sm.Decrypter()
+	require.NoError(t, err)
+	_, err = dec.DecryptValue(ctx, ciphertext)
+	assert.Error(t, err)
+}
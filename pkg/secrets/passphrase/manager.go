@@ -296,6 +296,45 @@ func NewPromptingPassphraseSecretsManager(info *workspace.ProjectStack,
 	return sm, nil
 }
 
+// NewPromptingPassphraseSecretsManagerWithNewSalt returns a passphrase-based secrets manager that uses the stack's
+// current passphrase with a freshly generated salt, which changes the key used to encrypt secrets without changing
+// the passphrase itself. The current passphrase is read from PULUMI_CONFIG_PASSPHRASE, the file specified by
+// PULUMI_CONFIG_PASSPHRASE_FILE, or otherwise prompted for if interactive, and must match the stack's current salt.
+func NewPromptingPassphraseSecretsManagerWithNewSalt(info *workspace.ProjectStack) (secrets.Manager, error) {
+	if info.EncryptionSalt == "" {
+		return nil, errors.New("the stack does not have an encryption salt")
+	}
+
+	const prompt = "Enter your passphrase to unlock config/secrets\n" +
+		"    (set PULUMI_CONFIG_PASSPHRASE or PULUMI_CONFIG_PASSPHRASE_FILE to remember)"
+	for {
+		phrase, interactive, phraseErr := readPassphrase(prompt, true /*useEnv*/)
+		if phraseErr != nil {
+			return nil, phraseErr
+		}
+
+		_, err := symmetricCrypterFromPhraseAndState(phrase, info.EncryptionSalt)
+		switch {
+		case interactive && err == ErrIncorrectPassphrase:
+			cmdutil.Diag().Errorf(diag.Message("", "incorrect passphrase"))
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		state, sm, err := NewPassphraseSecretsManager(phrase)
+		if err != nil {
+			return nil, err
+		}
+		setCachedSecretsManager(state, sm)
+
+		info.EncryptionSalt = state
+		info.EncryptedKey = ""
+		info.SecretsProvider = ""
+		return sm, nil
+	}
+}
+
 // promptForNewPassphrase prompts for a new passphrase, and returns the state and the secrets manager.
 func promptForNewPassphrase(rotate bool) (string, secrets.Manager, error) {
 	var phrase string
@@ -1,6 +1,7 @@
 package passphrase
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"strings"
@@ -8,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 )
 
 const (
@@ -130,3 +133,37 @@ func TestPassphraseManagerEmptyPassfileReturnsError(t *testing.T) {
 	assert.Error(t, err, strings.Contains(err.Error(), "unable to find either `PULUMI_CONFIG_PASSPHRASE` nor "+
 		"`PULUMI_CONFIG_PASSPHRASE_FILE`"))
 }
+
+//nolint:paralleltest // mutates environment variables
+func TestPassphraseManagerWithNewSalt(t *testing.T) {
+	resetEnv := resetPassphraseTestEnvVars()
+	defer resetEnv()
+
+	os.Setenv("PULUMI_CONFIG_PASSPHRASE", "password")
+	os.Unsetenv("PULUMI_CONFIG_PASSPHRASE_FILE")
+
+	const oldSalt = "v1:fozI5u6B030=:v1:F+6ZduKKd8G0/V7L:PGMFeIzwobWRKmEAzUdaQHqC5mMRIQ=="
+	info := &workspace.ProjectStack{EncryptionSalt: oldSalt}
+	sm, err := NewPromptingPassphraseSecretsManagerWithNewSalt(info)
+	require.NoError(t, err)
+	assert.NotEqual(t, oldSalt, info.EncryptionSalt)
+
+	// The same passphrase unlocks the new salt.
+	clearCachedSecretsManagers()
+	enc, err := sm.Encrypter()
+	require.NoError(t, err)
+	ciphertext, err := enc.EncryptValue(context.Background(), "hunter2")
+	require.NoError(t, err)
+	fromState, err := NewPromptingPassphraseSecretsManagerFromState(sm.State())
+	require.NoError(t, err)
+	dec, err := fromState.Decrypter()
+	require.NoError(t, err)
+	plaintext, err := dec.DecryptValue(context.Background(), ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+
+	// The current passphrase must be correct.
+	os.Setenv("PULUMI_CONFIG_PASSPHRASE", "wrong")
+	_, err = NewPromptingPassphraseSecretsManagerWithNewSalt(&workspace.ProjectStack{EncryptionSalt: oldSalt})
+	assert.ErrorIs(t, err, ErrIncorrectPassphrase)
+}
@@ -0,0 +1,314 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pgp implements support for a secrets manager whose data key is encrypted to one or more OpenPGP keys.
+package pgp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+
+	"github.com/pulumi/pulumi/pkg/v3/secrets"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// Type is the type of secrets managed by this secrets provider
+const Type = "pgp"
+
+// URLPrefix is the prefix of PGP secrets provider URLs. The rest of the URL is a comma-separated list of paths to
+// ASCII-armored public key files, e.g. "pgp://keys/alice.asc,keys/bob.asc". Relative paths are resolved against
+// the directory of the Pulumi project, so that the URL saved in the stack's configuration can be used from any
+// directory of the project. Outside of a project, they are resolved against the current working directory.
+const URLPrefix = "pgp://"
+
+const (
+	privateKeyEnvVar     = "PULUMI_PGP_PRIVATE_KEY"
+	privateKeyFileEnvVar = "PULUMI_PGP_PRIVATE_KEY_FILE"
+	passphraseEnvVar     = "PULUMI_PGP_PASSPHRASE"
+)
+
+type pgpSecretsManagerState struct {
+	URL          string `json:"url"`
+	EncryptedKey []byte `json:"encryptedkey"`
+}
+
+// IsPGPSecretsProvider returns true if the given secrets provider URL refers to the PGP secrets provider.
+func IsPGPSecretsProvider(secretsProvider string) bool {
+	return strings.HasPrefix(secretsProvider, URLPrefix)
+}
+
+// loadRecipients loads the public keys listed in a PGP secrets provider URL.
+func loadRecipients(url string) (openpgp.EntityList, error) {
+	if !IsPGPSecretsProvider(url) {
+		return nil, fmt.Errorf("PGP secrets provider URL %q must start with %q", url, URLPrefix)
+	}
+
+	var recipients openpgp.EntityList
+	for _, path := range strings.Split(strings.TrimPrefix(url, URLPrefix), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		path, err := resolveKeyPath(path)
+		if err != nil {
+			return nil, err
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading PGP public key: %w", err)
+		}
+		keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(contents))
+		if err != nil {
+			return nil, fmt.Errorf("reading PGP public key %s: %w", path, err)
+		}
+		recipients = append(recipients, keys...)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("PGP secrets provider URL %q does not list any public keys", url)
+	}
+	return recipients, nil
+}
+
+// resolveKeyPath resolves a public key path from a PGP secrets provider URL against the directory of the Pulumi
+// project, if there is one.
+func resolveKeyPath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	projectPath, err := workspace.DetectProjectPath()
+	if err != nil {
+		if errors.Is(err, workspace.ErrProjectNotFound) {
+			return path, nil
+		}
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(projectPath), path), nil
+}
+
+// loadPrivateKeys loads the private keys used to decrypt the data key from PULUMI_PGP_PRIVATE_KEY, which contains
+// an ASCII-armored key ring, or PULUMI_PGP_PRIVATE_KEY_FILE, which is the path to one. Keys protected by a
+// passphrase are decrypted with PULUMI_PGP_PASSPHRASE.
+func loadPrivateKeys() (openpgp.EntityList, error) {
+	var armored io.Reader
+	if key, ok := os.LookupEnv(privateKeyEnvVar); ok && key != "" {
+		armored = strings.NewReader(key)
+	} else if keyFile, ok := os.LookupEnv(privateKeyFileEnvVar); ok && keyFile != "" {
+		contents, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", privateKeyFileEnvVar, err)
+		}
+		armored = bytes.NewReader(contents)
+	} else {
+		return nil, fmt.Errorf("a PGP private key must be set with %s or %s", privateKeyEnvVar, privateKeyFileEnvVar)
+	}
+
+	keys, err := openpgp.ReadArmoredKeyRing(armored)
+	if err != nil {
+		return nil, fmt.Errorf("reading PGP private key: %w", err)
+	}
+
+	passphrase, hasPassphrase := os.LookupEnv(passphraseEnvVar)
+	for _, key := range keys {
+		if key.PrivateKey == nil {
+			return nil, errors.New("the PGP key is not a private key")
+		}
+		if !key.PrivateKey.Encrypted && !hasEncryptedSubkeys(key) {
+			continue
+		}
+		if !hasPassphrase {
+			return nil, fmt.Errorf("the PGP private key is protected by a passphrase; set it with %s", passphraseEnvVar)
+		}
+		if err := key.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("decrypting PGP private key: %w", err)
+		}
+	}
+	return keys, nil
+}
+
+func hasEncryptedSubkeys(key *openpgp.Entity) bool {
+	for _, subkey := range key.Subkeys {
+		if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+			return true
+		}
+	}
+	return false
+}
+
+// unwrapDataKey decrypts an encrypted data key using the private keys from the environment.
+func unwrapDataKey(encryptedDataKey []byte) ([]byte, error) {
+	keys, err := loadPrivateKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	// The keys have already been decrypted, so there is nothing to prompt for.
+	prompt := func([]openpgp.Key, bool) ([]byte, error) {
+		return nil, errors.New("the PGP private key is still encrypted")
+	}
+	md, err := openpgp.ReadMessage(bytes.NewReader(encryptedDataKey), keys, prompt, nil /* config */)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting data key: %w", err)
+	}
+	dataKey, err := io.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// wrapDataKey encrypts a data key to every public key listed in the given URL.
+func wrapDataKey(url string, dataKey []byte) ([]byte, error) {
+	recipients, err := loadRecipients(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w, err := openpgp.Encrypt(&buf, recipients, nil /* signed */, nil /* hints */, nil /* config */)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting data key: %w", err)
+	}
+	if _, err := w.Write(dataKey); err != nil {
+		return nil, fmt.Errorf("encrypting data key: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("encrypting data key: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// generateNewDataKey generates a new DataKey seeded by a fresh random 32-byte key and encrypted to the public keys
+// listed in the given URL.
+func generateNewDataKey(url string) ([]byte, error) {
+	plaintextDataKey := make([]byte, 32)
+	if _, err := rand.Read(plaintextDataKey); err != nil {
+		return nil, err
+	}
+	return wrapDataKey(url, plaintextDataKey)
+}
+
+// newPGPSecretsManager returns a secrets manager that uses a PGP-encrypted data key for envelope encryption of
+// secrets values.
+func newPGPSecretsManager(url string, encryptedDataKey []byte) (*Manager, error) {
+	plaintextDataKey, err := unwrapDataKey(encryptedDataKey)
+	if err != nil {
+		return nil, err
+	}
+	state, err := json.Marshal(pgpSecretsManagerState{
+		URL:          url,
+		EncryptedKey: encryptedDataKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling state: %w", err)
+	}
+	return &Manager{
+		crypter: config.NewSymmetricCrypter(plaintextDataKey),
+		state:   state,
+	}, nil
+}
+
+// Manager is the secrets.Manager implementation for PGP
+type Manager struct {
+	state   json.RawMessage
+	crypter config.Crypter
+}
+
+func (m *Manager) Type() string                         { return Type }
+func (m *Manager) State() json.RawMessage               { return m.state }
+func (m *Manager) Encrypter() (config.Encrypter, error) { return m.crypter, nil }
+func (m *Manager) Decrypter() (config.Decrypter, error) { return m.crypter, nil }
+
+func EditProjectStack(info *workspace.ProjectStack, state json.RawMessage) error {
+	info.EncryptionSalt = ""
+
+	var s pgpSecretsManagerState
+	err := json.Unmarshal(state, &s)
+	if err != nil {
+		return fmt.Errorf("unmarshalling pgp state: %w", err)
+	}
+
+	info.SecretsProvider = s.URL
+	info.EncryptedKey = base64.StdEncoding.EncodeToString(s.EncryptedKey)
+	return nil
+}
+
+// NewPGPSecretsManagerFromState deserialize configuration from state and returns a secrets manager that uses a
+// PGP-encrypted data key for envelope encryption of secrets values.
+func NewPGPSecretsManagerFromState(state json.RawMessage) (secrets.Manager, error) {
+	var s pgpSecretsManagerState
+	if err := json.Unmarshal(state, &s); err != nil {
+		return nil, fmt.Errorf("unmarshalling state: %w", err)
+	}
+
+	return newPGPSecretsManager(s.URL, s.EncryptedKey)
+}
+
+// NewPGPSecretsManager returns a secrets manager for the public keys listed in secretsProvider, updating the
+// stack's encryption information as needed.
+//
+// If the stack already uses PGP with a different set of public keys, its data key is decrypted and re-encrypted
+// to the new keys, so that existing secrets remain readable. This is how team members are added or removed.
+// A new data key is only generated for new stacks, when switching from another secrets provider, or when
+// rotateSecretsProvider is set.
+func NewPGPSecretsManager(info *workspace.ProjectStack,
+	secretsProvider string, rotateSecretsProvider bool,
+) (secrets.Manager, error) {
+	// Only a passphrase provider has an encryption salt.
+	info.EncryptionSalt = ""
+
+	if rotateSecretsProvider {
+		info.EncryptedKey = ""
+	}
+
+	switch {
+	case info.EncryptedKey == "" || !IsPGPSecretsProvider(info.SecretsProvider):
+		dataKey, err := generateNewDataKey(secretsProvider)
+		if err != nil {
+			return nil, err
+		}
+		info.EncryptedKey = base64.StdEncoding.EncodeToString(dataKey)
+	case info.SecretsProvider != secretsProvider:
+		oldKey, err := base64.StdEncoding.DecodeString(info.EncryptedKey)
+		if err != nil {
+			return nil, err
+		}
+		plaintextDataKey, err := unwrapDataKey(oldKey)
+		if err != nil {
+			return nil, errors.Join(
+				errors.New("changing PGP recipients requires the private key of one of the current recipients"), err)
+		}
+		dataKey, err := wrapDataKey(secretsProvider, plaintextDataKey)
+		if err != nil {
+			return nil, err
+		}
+		info.EncryptedKey = base64.StdEncoding.EncodeToString(dataKey)
+	}
+	info.SecretsProvider = secretsProvider
+
+	dataKey, err := base64.StdEncoding.DecodeString(info.EncryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	return newPGPSecretsManager(secretsProvider, dataKey)
+}
@@ -0,0 +1,220 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgp
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+type testKey struct {
+	publicKeyFile string
+	privateKey    string
+}
+
+// newTestKey generates an EdDSA key pair, writes its public key to a file and returns the armored private key,
+// optionally protected by a passphrase.
+func newTestKey(t *testing.T, name, passphrase string) testKey {
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", &packet.Config{
+		Algorithm: packet.PubKeyAlgoEdDSA,
+	})
+	require.NoError(t, err)
+
+	var public bytes.Buffer
+	w, err := armor.Encode(&public, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+	publicKeyFile := filepath.Join(t.TempDir(), name+".asc")
+	require.NoError(t, os.WriteFile(publicKeyFile, public.Bytes(), 0o600))
+
+	if passphrase != "" {
+		require.NoError(t, entity.EncryptPrivateKeys([]byte(passphrase), nil))
+	}
+	var private bytes.Buffer
+	w, err = armor.Encode(&private, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivateWithoutSigning(w, nil))
+	require.NoError(t, w.Close())
+
+	return testKey{publicKeyFile: publicKeyFile, privateKey: private.String()}
+}
+
+func providerURL(keys ...testKey) string {
+	files := make([]string, len(keys))
+	for i, key := range keys {
+		files[i] = key.publicKeyFile
+	}
+	return URLPrefix + strings.Join(files, ",")
+}
+
+func resetPGPTestEnvVars(t *testing.T) {
+	vars := []string{privateKeyEnvVar, privateKeyFileEnvVar, passphraseEnvVar}
+	old := map[string]string{}
+	for _, v := range vars {
+		if value, ok := os.LookupEnv(v); ok {
+			old[v] = value
+		}
+		os.Unsetenv(v)
+	}
+	t.Cleanup(func() {
+		for _, v := range vars {
+			os.Unsetenv(v)
+			if value, ok := old[v]; ok {
+				os.Setenv(v, value)
+			}
+		}
+	})
+}
+
+//nolint:paralleltest // mutates environment variables
+func TestPGPManagerNoPrivateKeyReturnsError(t *testing.T) {
+	resetPGPTestEnvVars(t)
+
+	alice := newTestKey(t, "alice", "")
+	_, err := NewPGPSecretsManager(&workspace.ProjectStack{}, providerURL(alice), false /* rotateSecretsProvider */)
+	assert.ErrorContains(t, err, "PULUMI_PGP_PRIVATE_KEY")
+
+	_, err = NewPGPSecretsManager(&workspace.ProjectStack{}, URLPrefix, false)
+	assert.ErrorContains(t, err, "does not list any public keys")
+}
+
+//nolint:paralleltest // mutates environment variables
+func TestPGPManagerRoundTrip(t *testing.T) {
+	resetPGPTestEnvVars(t)
+	ctx := context.Background()
+
+	alice := newTestKey(t, "alice", "")
+	os.Setenv(privateKeyEnvVar, alice.privateKey)
+
+	info := &workspace.ProjectStack{EncryptionSalt: "v1:stale"}
+	sm, err := NewPGPSecretsManager(info, providerURL(alice), false /* rotateSecretsProvider */)
+	require.NoError(t, err)
+	assert.Equal(t, Type, sm.Type())
+	assert.Equal(t, providerURL(alice), info.SecretsProvider)
+	assert.NotEmpty(t, info.EncryptedKey)
+	assert.Empty(t, info.EncryptionSalt)
+
+	enc, err := sm.Encrypter()
+	require.NoError(t, err)
+	ciphertext, err := enc.EncryptValue(ctx, "hunter2")
+	require.NoError(t, err)
+
+	fromState, err := NewPGPSecretsManagerFromState(sm.State())
+	require.NoError(t, err)
+	dec, err := fromState.Decrypter()
+	require.NoError(t, err)
+	plaintext, err := dec.DecryptValue(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+
+	restored := &workspace.ProjectStack{}
+	require.NoError(t, EditProjectStack(restored, sm.State()))
+	assert.Equal(t, info.SecretsProvider, restored.SecretsProvider)
+	assert.Equal(t, info.EncryptedKey, restored.EncryptedKey)
+}
+
+//nolint:paralleltest // mutates environment variables
+func TestPGPManagerPassphraseProtectedKey(t *testing.T) {
+	resetPGPTestEnvVars(t)
+
+	alice := newTestKey(t, "alice", "correct horse")
+	keyFile := filepath.Join(t.TempDir(), "alice-private.asc")
+	require.NoError(t, os.WriteFile(keyFile, []byte(alice.privateKey), 0o600))
+	os.Setenv(privateKeyFileEnvVar, keyFile)
+
+	_, err := NewPGPSecretsManager(&workspace.ProjectStack{}, providerURL(alice), false)
+	assert.ErrorContains(t, err, "PULUMI_PGP_PASSPHRASE")
+
+	os.Setenv(passphraseEnvVar, "wrong")
+	_, err = NewPGPSecretsManager(&workspace.ProjectStack{}, providerURL(alice), false)
+	assert.ErrorContains(t, err, "decrypting PGP private key")
+
+	os.Setenv(passphraseEnvVar, "correct horse")
+	_, err = NewPGPSecretsManager(&workspace.ProjectStack{}, providerURL(alice), false)
+	assert.NoError(t, err)
+}
+
+//nolint:paralleltest // mutates environment variables
+func TestPGPManagerChangeRecipientsRewrapsDataKey(t *testing.T) {
+	resetPGPTestEnvVars(t)
+	ctx := context.Background()
+
+	alice, bob := newTestKey(t, "alice", ""), newTestKey(t, "bob", "")
+	os.Setenv(privateKeyEnvVar, alice.privateKey)
+
+	info := &workspace.ProjectStack{}
+	sm, err := NewPGPSecretsManager(info, providerURL(alice), false)
+	require.NoError(t, err)
+	enc, err := sm.Encrypter()
+	require.NoError(t, err)
+	ciphertext, err := enc.EncryptValue(ctx, "hunter2")
+	require.NoError(t, err)
+
+	// Adding bob re-wraps the existing data key, so he can read secrets encrypted before he was added.
+	_, err = NewPGPSecretsManager(info, providerURL(alice, bob), false)
+	require.NoError(t, err)
+
+	os.Setenv(privateKeyEnvVar, bob.privateKey)
+	sm, err = NewPGPSecretsManager(info, info.SecretsProvider, false)
+	require.NoError(t, err)
+	dec, err := sm.Decrypter()
+	require.NoError(t, err)
+	plaintext, err := dec.DecryptValue(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+
+	// Bob removes alice, who can then no longer decrypt the data key.
+	_, err = NewPGPSecretsManager(info, providerURL(bob), false)
+	require.NoError(t, err)
+	os.Setenv(privateKeyEnvVar, alice.privateKey)
+	_, err = NewPGPSecretsManager(info, info.SecretsProvider, false)
+	assert.ErrorContains(t, err, "decrypting data key")
+}
+
+func TestPGPManagerResolvesKeysAgainstProject(t *testing.T) {
+	resetPGPTestEnvVars(t)
+
+	alice := newTestKey(t, "alice", "")
+	os.Setenv(privateKeyEnvVar, alice.privateKey)
+
+	project := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(project, "Pulumi.yaml"), []byte("name: test\nruntime: yaml\n"), 0o600))
+	public, err := os.ReadFile(alice.publicKeyFile)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "keys", "nested"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "keys", "alice.asc"), public, 0o600))
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(filepath.Join(project, "keys", "nested")))
+	t.Cleanup(func() { require.NoError(t, os.Chdir(cwd)) })
+
+	// The path is relative to the project, not to the working directory.
+	_, err = NewPGPSecretsManager(&workspace.ProjectStack{}, URLPrefix+"keys/alice.asc", false)
+	require.NoError(t, err)
+}
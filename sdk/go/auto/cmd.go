@@ -26,7 +26,28 @@ import (
 
 const unknownErrorCode = -2
 
-func runPulumiCommandSync(
+// PulumiCommand runs Pulumi CLI commands on behalf of a Workspace. Every Workspace and Stack operation is expressed
+// as a set of CLI arguments, e.g. ["stack", "ls", "--json"], that is passed to Run. The default implementation
+// executes the `pulumi` binary found on $PATH; alternative implementations, such as ones that drive the engine
+// in-process, can be supplied with the Pulumi LocalWorkspaceOption.
+type PulumiCommand interface {
+	// Run executes the command described by args in workdir, with additionalEnv appended to the environment.
+	// Output is written to additionalOutput and additionalErrorOutput as it is produced, and the complete stdout,
+	// stderr, and exit code are returned once the command finishes. A non-zero exit code must be accompanied by a
+	// non-nil error.
+	Run(ctx context.Context,
+		workdir string,
+		additionalOutput []io.Writer,
+		additionalErrorOutput []io.Writer,
+		additionalEnv []string,
+		args ...string,
+	) (string, string, int, error)
+}
+
+// pulumiCommand is the default PulumiCommand, which executes the pulumi binary found on $PATH.
+type pulumiCommand struct{}
+
+func (pulumiCommand) Run(
 	ctx context.Context,
 	workdir string,
 	additionalOutput []io.Writer,
@@ -59,6 +80,14 @@ func runPulumiCommandSync(
 	return stdout.String(), stderr.String(), code, err
 }
 
+// pulumiCommandFor returns the PulumiCommand used to run commands for the given workspace.
+func pulumiCommandFor(ws Workspace) PulumiCommand {
+	if lws, isLocalWorkspace := ws.(*LocalWorkspace); isLocalWorkspace && lws.pulumiCommand != nil {
+		return lws.pulumiCommand
+	}
+	return pulumiCommand{}
+}
+
 func withNonInteractiveArg(args []string) []string {
 	out := slice.Prealloc[string](len(args))
 	seen := false
@@ -19,13 +19,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/blang/semver"
 
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/debug"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optchangesecretsprovider"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/opthistory"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optimport"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optremove"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstackrename"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstate"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstaterename"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstateunprotect"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
@@ -55,6 +64,7 @@ type LocalWorkspace struct {
 	remoteEnvVars                 map[string]EnvVarValue
 	preRunCommands                []string
 	remoteSkipInstallDependencies bool
+	pulumiCommand                 PulumiCommand
 }
 
 var settingsExtensions = []string{".yaml", ".yml", ".json"}
@@ -515,6 +525,51 @@ func (l *LocalWorkspace) RemoveStack(ctx context.Context, stackName string, opts
 	return nil
 }
 
+// RenameStack renames the stack matching the stack name to the new stack name, moving its configuration.
+func (l *LocalWorkspace) RenameStack(
+	ctx context.Context,
+	stackName string,
+	newStackName string,
+	opts ...optstackrename.Option,
+) error {
+	renameOpts := &optstackrename.Options{}
+	for _, o := range opts {
+		o.ApplyOption(renameOpts)
+	}
+
+	stdout, stderr, errCode, err := l.runPulumiCmdSyncWithOutput(ctx,
+		renameOpts.ProgressStreams, renameOpts.ErrorProgressStreams,
+		"stack", "rename", newStackName, "--stack", stackName,
+	)
+	if err != nil {
+		return newAutoError(fmt.Errorf("failed to rename stack: %w", err), stdout, stderr, errCode)
+	}
+	return nil
+}
+
+// ChangeSecretsProvider changes the secrets provider of the stack matching the stack name,
+// re-encrypting its configuration and state with the new provider.
+func (l *LocalWorkspace) ChangeSecretsProvider(
+	ctx context.Context,
+	stackName string,
+	secretsProvider string,
+	opts ...optchangesecretsprovider.Option,
+) error {
+	changeOpts := &optchangesecretsprovider.Options{}
+	for _, o := range opts {
+		o.ApplyOption(changeOpts)
+	}
+
+	stdout, stderr, errCode, err := l.runPulumiCmdSyncWithOutput(ctx,
+		changeOpts.ProgressStreams, changeOpts.ErrorProgressStreams,
+		"stack", "change-secrets-provider", secretsProvider, "--stack", stackName,
+	)
+	if err != nil {
+		return newAutoError(fmt.Errorf("failed to change secrets provider: %w", err), stdout, stderr, errCode)
+	}
+	return nil
+}
+
 // ListStacks returns all Stacks created under the current Project.
 // This queries underlying backend and may return stacks not present in the Workspace (as Pulumi.<stack>.yaml files).
 func (l *LocalWorkspace) ListStacks(ctx context.Context) ([]StackSummary, error) {
@@ -679,6 +734,209 @@ func (l *LocalWorkspace) StackOutputs(ctx context.Context, stackName string) (Ou
 	return res, nil
 }
 
+// ImportResources imports existing resources into the stack matching the given name,
+// returning the resource declaration code generated for them.
+func (l *LocalWorkspace) ImportResources(
+	ctx context.Context,
+	stackName string,
+	opts ...optimport.Option,
+) (ImportResult, error) {
+	var res ImportResult
+
+	importOpts := &optimport.Options{}
+	for _, o := range opts {
+		o.ApplyOption(importOpts)
+	}
+
+	tempDir, err := os.MkdirTemp("", "pulumi-import-")
+	if err != nil {
+		return res, fmt.Errorf("could not import resources, failed to allocate temp directory: %w", err)
+	}
+	defer func() { contract.IgnoreError(os.RemoveAll(tempDir)) }()
+
+	importFile := struct {
+		NameTable map[string]string           `json:"nameTable,omitempty"`
+		Resources []*optimport.ImportResource `json:"resources"`
+	}{
+		NameTable: importOpts.NameTable,
+		Resources: importOpts.Resources,
+	}
+	bytes, err := json.Marshal(importFile)
+	if err != nil {
+		return res, fmt.Errorf("could not import resources, failed to marshal import file: %w", err)
+	}
+	importFilePath := filepath.Join(tempDir, "import.json")
+	if err = os.WriteFile(importFilePath, bytes, 0o600); err != nil {
+		return res, fmt.Errorf("could not import resources, failed to write import file: %w", err)
+	}
+
+	args := debug.AddArgs(&importOpts.DebugLogOpts, nil)
+	args = append(args, "import", "--yes", "--skip-preview", "--file", importFilePath)
+
+	generateCode := importOpts.GenerateCode == nil || *importOpts.GenerateCode
+	generatedCodePath := filepath.Join(tempDir, "generated_code.txt")
+	if generateCode {
+		args = append(args, "--out", generatedCodePath)
+	} else {
+		args = append(args, "--generate-code=false")
+	}
+	if importOpts.Protect != nil {
+		args = append(args, fmt.Sprintf("--protect=%t", *importOpts.Protect))
+	}
+	if importOpts.Message != "" {
+		args = append(args, fmt.Sprintf("--message=%q", importOpts.Message))
+	}
+	if importOpts.Parallel > 0 {
+		args = append(args, fmt.Sprintf("--parallel=%d", importOpts.Parallel))
+	}
+	if importOpts.UserAgent != "" {
+		args = append(args, fmt.Sprintf("--exec-agent=%s", importOpts.UserAgent))
+	}
+	if importOpts.Color != "" {
+		args = append(args, fmt.Sprintf("--color=%s", importOpts.Color))
+	}
+
+	if len(importOpts.EventStreams) > 0 {
+		t, err := tailLogs("import", importOpts.EventStreams)
+		if err != nil {
+			return res, fmt.Errorf("failed to tail logs: %w", err)
+		}
+		defer t.Close()
+		args = append(args, "--event-log", t.Filename)
+	}
+
+	args = append(args, "--stack", stackName)
+
+	stdout, stderr, errCode, err := l.runPulumiCmdSyncWithOutput(
+		ctx,
+		importOpts.ProgressStreams,      /* additionalOutputs */
+		importOpts.ErrorProgressStreams, /* additionalErrorOutputs */
+		args...,
+	)
+	if err != nil {
+		return res, newAutoError(fmt.Errorf("failed to import resources: %w", err), stdout, stderr, errCode)
+	}
+
+	if generateCode {
+		code, err := os.ReadFile(generatedCodePath)
+		if err != nil {
+			return res, fmt.Errorf("could not read generated code: %w", err)
+		}
+		res.GeneratedCode = string(code)
+	}
+
+	historyOpts := []opthistory.Option{}
+	if showSecrets := importOpts.ShowSecrets; showSecrets != nil {
+		historyOpts = append(historyOpts, opthistory.ShowSecrets(*showSecrets))
+	}
+	s := Stack{workspace: l, stackName: stackName}
+	history, err := s.History(ctx, 1 /*pageSize*/, 1 /*page*/, historyOpts...)
+	if err != nil {
+		return res, fmt.Errorf("failed to import resources: %w", err)
+	}
+	if len(history) > 0 {
+		res.Summary = history[0]
+	}
+
+	res.StdOut = stdout
+	res.StdErr = stderr
+	return res, nil
+}
+
+// StateDelete deletes the resource with the given URN from the state of the stack matching the given name.
+func (l *LocalWorkspace) StateDelete(
+	ctx context.Context,
+	stackName string,
+	urn string,
+	opts ...optstate.Option,
+) error {
+	stateOpts := &optstate.Options{}
+	for _, o := range opts {
+		o.ApplyOption(stateOpts)
+	}
+
+	args := []string{"state", "delete", urn, "--yes", "--stack", stackName}
+	if stateOpts.Force {
+		args = append(args, "--force")
+	}
+	if stateOpts.TargetDependents {
+		args = append(args, "--target-dependents")
+	}
+
+	stdout, stderr, errCode, err := l.runPulumiCmdSync(ctx, args...)
+	if err != nil {
+		return newAutoError(fmt.Errorf("failed to delete resource from state: %w", err), stdout, stderr, errCode)
+	}
+	return nil
+}
+
+// StateUnprotect removes deletion protection from the resource with the given URN
+// in the stack matching the given name.
+func (l *LocalWorkspace) StateUnprotect(
+	ctx context.Context,
+	stackName string,
+	urn string,
+	opts ...optstateunprotect.Option,
+) error {
+	unprotectOpts := &optstateunprotect.Options{}
+	for _, o := range opts {
+		o.ApplyOption(unprotectOpts)
+	}
+
+	stdout, stderr, errCode, err := l.runPulumiCmdSyncWithOutput(ctx,
+		unprotectOpts.ProgressStreams, unprotectOpts.ErrorProgressStreams,
+		"state", "unprotect", urn, "--yes", "--stack", stackName,
+	)
+	if err != nil {
+		return newAutoError(fmt.Errorf("failed to unprotect resource: %w", err), stdout, stderr, errCode)
+	}
+	return nil
+}
+
+// StateUnprotectAll removes deletion protection from every resource in the stack matching the given name.
+func (l *LocalWorkspace) StateUnprotectAll(
+	ctx context.Context,
+	stackName string,
+	opts ...optstateunprotect.Option,
+) error {
+	unprotectOpts := &optstateunprotect.Options{}
+	for _, o := range opts {
+		o.ApplyOption(unprotectOpts)
+	}
+
+	stdout, stderr, errCode, err := l.runPulumiCmdSyncWithOutput(ctx,
+		unprotectOpts.ProgressStreams, unprotectOpts.ErrorProgressStreams,
+		"state", "unprotect", "--all", "--yes", "--stack", stackName,
+	)
+	if err != nil {
+		return newAutoError(fmt.Errorf("failed to unprotect resources: %w", err), stdout, stderr, errCode)
+	}
+	return nil
+}
+
+// StateRename renames the resource with the given URN in the stack matching the given name.
+func (l *LocalWorkspace) StateRename(
+	ctx context.Context,
+	stackName string,
+	urn string,
+	newName string,
+	opts ...optstaterename.Option,
+) error {
+	renameOpts := &optstaterename.Options{}
+	for _, o := range opts {
+		o.ApplyOption(renameOpts)
+	}
+
+	stdout, stderr, errCode, err := l.runPulumiCmdSyncWithOutput(ctx,
+		renameOpts.ProgressStreams, renameOpts.ErrorProgressStreams,
+		"state", "rename", urn, newName, "--yes", "--stack", stackName,
+	)
+	if err != nil {
+		return newAutoError(fmt.Errorf("failed to rename resource: %w", err), stdout, stderr, errCode)
+	}
+	return nil
+}
+
 func (l *LocalWorkspace) getPulumiVersion(ctx context.Context) (string, error) {
 	stdout, stderr, errCode, err := l.runPulumiCmdSync(ctx, "version")
 	if err != nil {
@@ -708,6 +966,15 @@ func parseAndValidatePulumiVersion(minVersion semver.Version, currentVersion str
 func (l *LocalWorkspace) runPulumiCmdSync(
 	ctx context.Context,
 	args ...string,
+) (string, string, int, error) {
+	return l.runPulumiCmdSyncWithOutput(ctx, nil /* additionalOutputs */, nil /* additionalErrorOutputs */, args...)
+}
+
+func (l *LocalWorkspace) runPulumiCmdSyncWithOutput(
+	ctx context.Context,
+	additionalOutput []io.Writer,
+	additionalErrorOutput []io.Writer,
+	args ...string,
 ) (string, string, int, error) {
 	var env []string
 	if l.PulumiHome() != "" {
@@ -720,10 +987,10 @@ func (l *LocalWorkspace) runPulumiCmdSync(
 			env = append(env, strings.Join(e, "="))
 		}
 	}
-	return runPulumiCommandSync(ctx,
+	return pulumiCommandFor(l).Run(ctx,
 		l.WorkDir(),
-		nil, /* additionalOutputs */
-		nil, /* additionalErrorOutputs */
+		additionalOutput,
+		additionalErrorOutput,
 		env,
 		args...,
 	)
@@ -738,7 +1005,7 @@ func (l *LocalWorkspace) supportsPulumiCmdFlag(ctx context.Context, flag string,
 	}
 
 	// Run the command with `--help`, and then we'll look for the flag in the output.
-	stdout, _, _, err := runPulumiCommandSync(ctx, l.WorkDir(), nil, nil, env, append(args, "--help")...)
+	stdout, _, _, err := pulumiCommandFor(l).Run(ctx, l.WorkDir(), nil, nil, env, append(args, "--help")...)
 	if err != nil {
 		return false, err
 	}
@@ -796,6 +1063,7 @@ func NewLocalWorkspace(ctx context.Context, opts ...LocalWorkspaceOption) (Works
 		remoteEnvVars:                 lwOpts.RemoteEnvVars,
 		remoteSkipInstallDependencies: lwOpts.RemoteSkipInstallDependencies,
 		repo:                          lwOpts.Repo,
+		pulumiCommand:                 lwOpts.PulumiCommand,
 	}
 
 	// optOut indicates we should skip the version check.
@@ -897,6 +1165,8 @@ type localWorkspaceOptions struct {
 	PreRunCommands []string
 	// RemoteSkipInstallDependencies sets whether to skip the default dependency installation step
 	RemoteSkipInstallDependencies bool
+	// PulumiCommand runs Pulumi CLI commands for the workspace. Defaults to executing the pulumi binary.
+	PulumiCommand PulumiCommand
 }
 
 // LocalWorkspaceOption is used to customize and configure a LocalWorkspace at initialization time.
@@ -966,6 +1236,14 @@ func WorkDir(workDir string) LocalWorkspaceOption {
 	})
 }
 
+// Pulumi sets the PulumiCommand used to run Pulumi CLI commands for the workspace and its stacks,
+// in place of executing the pulumi binary found on $PATH.
+func Pulumi(cmd PulumiCommand) LocalWorkspaceOption {
+	return localWorkspaceOption(func(lo *localWorkspaceOptions) {
+		lo.PulumiCommand = cmd
+	})
+}
+
 // Program is the Pulumi Program to execute. If none is supplied,
 // the program identified in $WORKDIR/Pulumi.yaml will be used instead.
 func Program(program pulumi.RunFunc) LocalWorkspaceOption {
@@ -19,7 +19,9 @@ import (
 	"context"
 	cryptorand "crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -36,12 +38,18 @@ import (
 
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/debug"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optchangesecretsprovider"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optdestroy"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optimport"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optpreview"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optrefresh"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optremove"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstate"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstaterename"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstateunprotect"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optup"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	resourceConfig "github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
@@ -1739,6 +1747,294 @@ func TestTagFunctions(t *testing.T) {
 	assert.Nil(t, err, "failed to remove stack. Resources have leaked.")
 }
 
+func TestStateAndRenameFunctions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sName := randomStackName()
+	stackName := FullyQualifiedStackName(pulumiOrg, pName, sName)
+
+	type comp struct {
+		pulumi.ResourceState
+	}
+
+	// initialize
+	s, err := NewStackInlineSource(ctx, stackName, pName, func(ctx *pulumi.Context) error {
+		var a, b comp
+		if err := ctx.RegisterComponentResource("test:index:Comp", "a", &a, pulumi.Protect(true)); err != nil {
+			return err
+		}
+		return ctx.RegisterComponentResource("test:index:Comp", "b", &b, pulumi.Parent(&a))
+	})
+	if err != nil {
+		t.Errorf("failed to initialize stack, err: %v", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		// -- pulumi stack rm --
+		err = s.Workspace().RemoveStack(ctx, s.Name(), optremove.Force())
+		assert.Nil(t, err, "failed to remove stack. Resources have leaked.")
+	}()
+
+	// -- pulumi up --
+	_, err = s.Up(ctx)
+	if err != nil {
+		t.Errorf("up failed, err: %v", err)
+		t.FailNow()
+	}
+
+	project := tokens.PackageName(pName)
+	stack := tokens.QName(sName)
+	aURN := resource.NewURN(stack, project, "", "test:index:Comp", "a")
+	bURN := resource.NewURN(stack, project, "test:index:Comp", "test:index:Comp", "b")
+
+	// -- pulumi state unprotect --
+	var unprotectOut bytes.Buffer
+	err = s.StateUnprotect(ctx, string(aURN), optstateunprotect.ProgressStreams(&unprotectOut))
+	if err != nil {
+		t.Errorf("state unprotect failed, err: %v", err)
+		t.FailNow()
+	}
+	assert.Contains(t, unprotectOut.String(), "Resource unprotected")
+
+	// -- pulumi state rename --
+	var renameOut bytes.Buffer
+	err = s.StateRename(ctx, string(bURN), "c", optstaterename.ProgressStreams(&renameOut))
+	if err != nil {
+		t.Errorf("state rename failed, err: %v", err)
+		t.FailNow()
+	}
+	assert.Contains(t, renameOut.String(), "Resource renamed")
+
+	// -- pulumi state delete --
+	err = s.StateDelete(ctx, string(aURN), optstate.TargetDependents())
+	if err != nil {
+		t.Errorf("state delete failed, err: %v", err)
+		t.FailNow()
+	}
+
+	state, err := s.Export(ctx)
+	if err != nil {
+		t.Errorf("export failed, err: %v", err)
+		t.FailNow()
+	}
+	var deployment apitype.DeploymentV3
+	err = json.Unmarshal(state.Deployment, &deployment)
+	if err != nil {
+		t.Errorf("failed to unmarshal deployment, err: %v", err)
+		t.FailNow()
+	}
+	for _, res := range deployment.Resources {
+		assert.NotEqual(t, "test:index:Comp", string(res.Type), "expected components to be deleted")
+	}
+
+	// -- pulumi stack rename --
+	newStackName := FullyQualifiedStackName(pulumiOrg, pName, randomStackName())
+	err = s.Rename(ctx, newStackName)
+	if err != nil {
+		t.Errorf("stack rename failed, err: %v", err)
+		t.FailNow()
+	}
+	assert.Equal(t, newStackName, s.Name())
+
+	stacks, err := s.Workspace().ListStacks(ctx)
+	if err != nil {
+		t.Errorf("list stacks failed, err: %v", err)
+		t.FailNow()
+	}
+	var found bool
+	for _, summary := range stacks {
+		if strings.HasSuffix(newStackName, summary.Name) {
+			found = true
+		}
+	}
+	assert.True(t, found, "expected renamed stack to be listed")
+}
+
+func TestImportResources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sName := randomStackName()
+	stackName := FullyQualifiedStackName(pulumiOrg, pName, sName)
+
+	// initialize
+	s, err := NewStackInlineSource(ctx, stackName, pName, func(ctx *pulumi.Context) error {
+		return nil
+	})
+	if err != nil {
+		t.Errorf("failed to initialize stack, err: %v", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		// -- pulumi stack rm --
+		err = s.Workspace().RemoveStack(ctx, s.Name(), optremove.Force())
+		assert.Nil(t, err, "failed to remove stack. Resources have leaked.")
+	}()
+
+	// -- pulumi import --
+	// There is no schema for the test package, so there is no code to generate.
+	res, err := s.ImportResources(ctx,
+		optimport.Resources([]*optimport.ImportResource{
+			{Type: "test:index:Comp", Name: "imported", Component: true},
+		}),
+		optimport.Protect(false),
+		optimport.GenerateCode(false),
+		optimport.Color("never"),
+		optimport.UserAgent(agent),
+	)
+	if err != nil {
+		t.Errorf("import failed, err: %v", err)
+		t.FailNow()
+	}
+	assert.Equal(t, "resource-import", res.Summary.Kind)
+	assert.Equal(t, "succeeded", res.Summary.Result)
+	assert.Empty(t, res.GeneratedCode)
+
+	state, err := s.Export(ctx)
+	if err != nil {
+		t.Errorf("export failed, err: %v", err)
+		t.FailNow()
+	}
+	var deployment apitype.DeploymentV3
+	err = json.Unmarshal(state.Deployment, &deployment)
+	if err != nil {
+		t.Errorf("failed to unmarshal deployment, err: %v", err)
+		t.FailNow()
+	}
+	var found bool
+	for _, r := range deployment.Resources {
+		if r.Type == "test:index:Comp" && r.URN.Name() == "imported" {
+			found = true
+		}
+	}
+	assert.True(t, found, "expected the imported resource to be in the stack's state")
+}
+
+func TestChangeSecretsProvider(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sName := randomStackName()
+	stackName := FullyQualifiedStackName(pulumiOrg, pName, sName)
+
+	// initialize
+	s, err := NewStackInlineSource(ctx, stackName, pName, func(ctx *pulumi.Context) error {
+		return nil
+	}, EnvVars(map[string]string{
+		"PULUMI_CONFIG_PASSPHRASE": "password",
+	}))
+	if err != nil {
+		t.Errorf("failed to initialize stack, err: %v", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		// -- pulumi stack rm --
+		err = s.Workspace().RemoveStack(ctx, s.Name())
+		assert.Nil(t, err, "failed to remove stack. Resources have leaked.")
+	}()
+
+	passwordVal := "Password1234!"
+	err = s.SetConfig(ctx, "MySecretDatabasePassword", ConfigValue{Value: passwordVal, Secret: true})
+	if err != nil {
+		t.Errorf("setConfig failed, err: %v", err)
+		t.FailNow()
+	}
+
+	settings, err := s.Workspace().StackSettings(ctx, stackName)
+	if err != nil {
+		t.Errorf("failed to load stack settings, err: %v", err)
+		t.FailNow()
+	}
+	oldSalt := settings.EncryptionSalt
+
+	// -- pulumi stack change-secrets-provider --
+	// Changing to the passphrase provider gives the stack a new key, whichever provider it used before.
+	var stdout bytes.Buffer
+	err = s.ChangeSecretsProvider(ctx, "passphrase", optchangesecretsprovider.ProgressStreams(&stdout))
+	if err != nil {
+		t.Errorf("change secrets provider failed, err: %v", err)
+		t.FailNow()
+	}
+	assert.Contains(t, stdout.String(), "Migrating old configuration and state to new secrets provider")
+
+	settings, err = s.Workspace().StackSettings(ctx, stackName)
+	if err != nil {
+		t.Errorf("failed to load stack settings, err: %v", err)
+		t.FailNow()
+	}
+	assert.NotEmpty(t, settings.EncryptionSalt)
+	assert.NotEqual(t, oldSalt, settings.EncryptionSalt)
+}
+
+// Checks that changing to the age secrets provider re-encrypts the stack's secrets with the new provider.
+func TestChangeSecretsProviderAge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sName := randomStackName()
+	stackName := FullyQualifiedStackName(pulumiOrg, pName, sName)
+
+	// A test-only age key pair, so that the new secrets provider works with any backend.
+	const (
+		ageRecipient = "age1wm2dsrmdupkcce47fcyfw8rsscspduggul5reck26sqv6nme3p6srhwwwx"
+		ageIdentity  = "AGE-SECRET-KEY-1X95846QVHXVXJQYU02RU2GX0HNTASAPG4UT3MLY03CL96G6V83KQNRYKN2"
+	)
+
+	// initialize
+	s, err := NewStackInlineSource(ctx, stackName, pName, func(ctx *pulumi.Context) error {
+		return nil
+	}, EnvVars(map[string]string{
+		"PULUMI_CONFIG_PASSPHRASE": "password",
+		"PULUMI_AGE_IDENTITY":      ageIdentity,
+	}))
+	if err != nil {
+		t.Errorf("failed to initialize stack, err: %v", err)
+		t.FailNow()
+	}
+
+	defer func() {
+		// -- pulumi stack rm --
+		err = s.Workspace().RemoveStack(ctx, s.Name())
+		assert.Nil(t, err, "failed to remove stack. Resources have leaked.")
+	}()
+
+	passwordVal := "Password1234!"
+	err = s.SetConfig(ctx, "MySecretDatabasePassword", ConfigValue{Value: passwordVal, Secret: true})
+	if err != nil {
+		t.Errorf("setConfig failed, err: %v", err)
+		t.FailNow()
+	}
+
+	// -- pulumi stack change-secrets-provider --
+	secretsProvider := "age://" + ageRecipient
+	err = s.ChangeSecretsProvider(ctx, secretsProvider)
+	if err != nil {
+		t.Errorf("change secrets provider failed, err: %v", err)
+		t.FailNow()
+	}
+
+	settings, err := s.Workspace().StackSettings(ctx, stackName)
+	if err != nil {
+		t.Errorf("failed to load stack settings, err: %v", err)
+		t.FailNow()
+	}
+	assert.Equal(t, secretsProvider, settings.SecretsProvider)
+	assert.NotEmpty(t, settings.EncryptedKey)
+
+	// The secret must have been re-encrypted with the new provider.
+	cfg, err := s.GetConfig(ctx, "MySecretDatabasePassword")
+	if err != nil {
+		t.Errorf("getConfig failed, err: %v", err)
+		t.FailNow()
+	}
+	assert.Equal(t, passwordVal, cfg.Value)
+	assert.True(t, cfg.Secret)
+}
+
 //nolint:paralleltest // mutates environment variables
 func TestStructuredOutput(t *testing.T) {
 	ctx := context.Background()
@@ -2146,6 +2442,76 @@ func TestMinimumVersion(t *testing.T) {
 	}
 }
 
+// fakePulumiCommand is a PulumiCommand that answers a fixed set of commands without running a CLI.
+type fakePulumiCommand struct {
+	m    sync.Mutex
+	runs [][]string
+}
+
+func (c *fakePulumiCommand) Run(
+	ctx context.Context,
+	workdir string,
+	additionalOutput []io.Writer,
+	additionalErrorOutput []io.Writer,
+	additionalEnv []string,
+	args ...string,
+) (string, string, int, error) {
+	c.m.Lock()
+	c.runs = append(c.runs, args)
+	c.m.Unlock()
+
+	var stdout string
+	switch strings.Join(args, " ") {
+	case "version":
+		stdout = "v3.99.0\n"
+	case "stack ls --json":
+		stdout = `[{"name": "dev", "current": true}]`
+	case "stack tag set foo bar --stack dev", "stack select --stack dev", "cancel --yes --stack dev":
+	default:
+		return "", "unknown command", 1, fmt.Errorf("unknown command %v", args)
+	}
+	for _, w := range additionalOutput {
+		_, err := w.Write([]byte(stdout))
+		contract.IgnoreError(err)
+	}
+	return stdout, "", 0, nil
+}
+
+func TestPulumiCommand(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cmd := &fakePulumiCommand{}
+	ws, err := NewLocalWorkspace(ctx, WorkDir(t.TempDir()), Pulumi(cmd))
+	require.NoError(t, err)
+	assert.Equal(t, "3.99.0", ws.PulumiVersion())
+
+	stacks, err := ws.ListStacks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []StackSummary{{Name: "dev", Current: true}}, stacks)
+
+	err = ws.SetTag(ctx, "dev", "foo", "bar")
+	require.NoError(t, err)
+
+	err = ws.RemoveTag(ctx, "dev", "foo")
+	assert.Error(t, err)
+
+	// Stack operations are run by the workspace's command too.
+	stack, err := SelectStack(ctx, "dev", ws)
+	require.NoError(t, err)
+	err = stack.Cancel(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"version"},
+		{"stack", "ls", "--json"},
+		{"stack", "tag", "set", "foo", "bar", "--stack", "dev"},
+		{"stack", "tag", "rm", "foo", "--stack", "dev"},
+		{"stack", "select", "--stack", "dev"},
+		{"cancel", "--yes", "--stack", "dev"},
+	}, cmd.runs)
+}
+
 func TestProjectSettingsRespected(t *testing.T) {
 	t.Parallel()
 
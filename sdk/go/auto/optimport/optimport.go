@@ -0,0 +1,185 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package optimport contains functional options to be used with stack import operations
+// github.com/sdk/v2/go/x/auto Stack.ImportResources(...optimport.Option)
+package optimport
+
+import (
+	"io"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/debug"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+)
+
+// ImportResource describes a single resource to import into the stack.
+type ImportResource struct {
+	// Type is the type token of the resource, e.g. "aws:s3/bucket:Bucket".
+	Type string `json:"type"`
+	// Name is the name of the resource in the stack.
+	Name string `json:"name"`
+	// ID is the provider ID of the resource to import.
+	ID string `json:"id,omitempty"`
+	// Parent (optional) is the name of the parent resource as listed in the name table.
+	Parent string `json:"parent,omitempty"`
+	// Provider (optional) is the name of the provider resource as listed in the name table.
+	Provider string `json:"provider,omitempty"`
+	// Version (optional) is the version of the provider plugin to use.
+	Version string `json:"version,omitempty"`
+	// PluginDownloadURL (optional) is the URL to download the provider plugin from.
+	PluginDownloadURL string `json:"pluginDownloadUrl,omitempty"`
+	// Properties (optional) is the list of property names to use for the import.
+	Properties []string `json:"properties,omitempty"`
+	// Component marks the resource as a component resource that has no ID.
+	Component bool `json:"component,omitempty"`
+	// Remote marks a component resource as a remote (multi-language) component.
+	Remote bool `json:"remote,omitempty"`
+}
+
+// Resources specifies the list of resources to import
+func Resources(resources []*ImportResource) Option {
+	return optionFunc(func(opts *Options) {
+		opts.Resources = resources
+	})
+}
+
+// NameTable maps names used as parent and provider references in Resources to the URNs of existing resources
+func NameTable(nameTable map[string]string) Option {
+	return optionFunc(func(opts *Options) {
+		opts.NameTable = nameTable
+	})
+}
+
+// Protect sets whether the imported resources are protected from deletion. Defaults to true.
+func Protect(protect bool) Option {
+	return optionFunc(func(opts *Options) {
+		opts.Protect = &protect
+	})
+}
+
+// GenerateCode sets whether resource declaration code is generated for the imported resources. Defaults to true.
+func GenerateCode(generateCode bool) Option {
+	return optionFunc(func(opts *Options) {
+		opts.GenerateCode = &generateCode
+	})
+}
+
+// Parallel is the number of resource operations to run in parallel at once during the import
+// (1 for no parallelism). Defaults to unbounded. (default 2147483647)
+func Parallel(n int) Option {
+	return optionFunc(func(opts *Options) {
+		opts.Parallel = n
+	})
+}
+
+// Message (optional) to associate with the import operation
+func Message(message string) Option {
+	return optionFunc(func(opts *Options) {
+		opts.Message = message
+	})
+}
+
+// ProgressStreams allows specifying one or more io.Writers to redirect incremental import stdout
+func ProgressStreams(writers ...io.Writer) Option {
+	return optionFunc(func(opts *Options) {
+		opts.ProgressStreams = writers
+	})
+}
+
+// ErrorProgressStreams allows specifying one or more io.Writers to redirect incremental import stderr
+func ErrorProgressStreams(writers ...io.Writer) Option {
+	return optionFunc(func(opts *Options) {
+		opts.ErrorProgressStreams = writers
+	})
+}
+
+// EventStreams allows specifying one or more channels to receive the Pulumi event stream
+func EventStreams(channels ...chan<- events.EngineEvent) Option {
+	return optionFunc(func(opts *Options) {
+		opts.EventStreams = channels
+	})
+}
+
+// DebugLogging provides options for verbose logging to standard error, and enabling plugin logs.
+func DebugLogging(debugOpts debug.LoggingOptions) Option {
+	return optionFunc(func(opts *Options) {
+		opts.DebugLogOpts = debugOpts
+	})
+}
+
+// UserAgent specifies the agent responsible for the update, stored in backends as "environment.exec.agent"
+func UserAgent(agent string) Option {
+	return optionFunc(func(opts *Options) {
+		opts.UserAgent = agent
+	})
+}
+
+// Color allows specifying whether to colorize output. Choices are: always, never, raw, auto (default "auto")
+func Color(color string) Option {
+	return optionFunc(func(opts *Options) {
+		opts.Color = color
+	})
+}
+
+// ShowSecrets configures whether to show config secrets when they appear in the config.
+func ShowSecrets(show bool) Option {
+	return optionFunc(func(opts *Options) {
+		opts.ShowSecrets = &show
+	})
+}
+
+// Option is a parameter to be applied to a Stack.ImportResources() operation
+type Option interface {
+	ApplyOption(*Options)
+}
+
+// ---------------------------------- implementation details ----------------------------------
+
+// Options is an implementation detail
+type Options struct {
+	// Resources is the list of resources to import
+	Resources []*ImportResource
+	// NameTable maps names used in Resources to the URNs of existing resources
+	NameTable map[string]string
+	// Protect the imported resources from deletion (default true)
+	Protect *bool
+	// GenerateCode for the imported resources (default true)
+	GenerateCode *bool
+	// Parallel is the number of resource operations to run in parallel at once
+	// (1 for no parallelism). Defaults to unbounded. (default 2147483647)
+	Parallel int
+	// Message (optional) to associate with the import operation
+	Message string
+	// ProgressStreams allows specifying one or more io.Writers to redirect incremental import stdout
+	ProgressStreams []io.Writer
+	// ErrorProgressStreams allows specifying one or more io.Writers to redirect incremental import stderr
+	ErrorProgressStreams []io.Writer
+	// EventStreams allows specifying one or more channels to receive the Pulumi event stream
+	EventStreams []chan<- events.EngineEvent
+	// DebugLogOpts specifies additional settings for debug logging
+	DebugLogOpts debug.LoggingOptions
+	// UserAgent specifies the agent responsible for the update, stored in backends as "environment.exec.agent"
+	UserAgent string
+	// Colorize output. Choices are: always, never, raw, auto (default "auto")
+	Color string
+	// Show config secrets when they appear.
+	ShowSecrets *bool
+}
+
+type optionFunc func(*Options)
+
+// ApplyOption is an implementation detail
+func (o optionFunc) ApplyOption(opts *Options) {
+	o(opts)
+}
@@ -0,0 +1,56 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package optsession contains functional options to be used with preview sessions
+// github.com/sdk/v2/go/x/auto Stack.NewPreviewSession(...optsession.Option)
+package optsession
+
+// Provider identifies a resource provider plugin that is kept running for the lifetime of a preview session.
+type Provider struct {
+	// Name is the name of the provider package, e.g. "aws".
+	Name string
+	// Version (optional) is the version of the installed plugin to use. Defaults to the latest installed version.
+	Version string
+	// Path (optional) is the path to the plugin binary. Overrides Version if set.
+	Path string
+}
+
+// Providers specifies resource provider plugins to start with the session and keep running between previews.
+// Providers that are not listed are discovered from the first preview of the session, which loads them as usual,
+// and are kept running for the previews that follow.
+func Providers(providers ...Provider) Option {
+	return optionFunc(func(opts *Options) {
+		opts.Providers = append(opts.Providers, providers...)
+	})
+}
+
+// Option is a parameter to be applied to a Stack.NewPreviewSession() operation
+type Option interface {
+	ApplyOption(*Options)
+}
+
+// ---------------------------------- implementation details ----------------------------------
+
+// Options is an implementation detail
+type Options struct {
+	// Providers to keep running between previews
+	Providers []Provider
+}
+
+type optionFunc func(*Options)
+
+// ApplyOption is an implementation detail
+func (o optionFunc) ApplyOption(opts *Options) {
+	o(opts)
+}
@@ -0,0 +1,96 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package optstackgraph contains functional options to be used with multi-stack operations
+// github.com/sdk/v2/go/x/auto StackGraph.Up(...optstackgraph.Option)
+package optstackgraph
+
+import (
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optpreview"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optup"
+)
+
+// FailurePolicy determines how the remaining stacks are handled when an operation on a stack fails.
+type FailurePolicy int
+
+const (
+	// StopOnFailure stops starting new stacks as soon as any stack fails. Stacks that are already running
+	// are allowed to finish; all others are skipped. This is the default.
+	StopOnFailure FailurePolicy = iota
+	// SkipDependents skips the stacks that transitively depend on a failed stack, but keeps running
+	// stacks that do not.
+	SkipDependents
+	// ContinueOnFailure runs every stack regardless of failures, including dependents of failed stacks.
+	ContinueOnFailure
+)
+
+// Parallel is the number of stacks to operate on in parallel at once (1 for no parallelism).
+// Defaults to unbounded.
+func Parallel(n int) Option {
+	return optionFunc(func(opts *Options) {
+		opts.Parallel = n
+	})
+}
+
+// OnFailure sets the policy applied to the remaining stacks when an operation on a stack fails.
+func OnFailure(policy FailurePolicy) Option {
+	return optionFunc(func(opts *Options) {
+		opts.FailurePolicy = policy
+	})
+}
+
+// UpOptions specifies a function that returns the options passed to the Stack.Up() operation on the named stack.
+// The function is called once for each stack, never concurrently, so that every stack can be given its own
+// optup.EventStreams channels, which are closed when the operation on the stack completes, and its own
+// optup.ProgressStreams writers, which may be written to while other stacks are running.
+func UpOptions(upOpts func(stackName string) []optup.Option) Option {
+	return optionFunc(func(opts *Options) {
+		opts.UpOptions = upOpts
+	})
+}
+
+// PreviewOptions specifies a function that returns the options passed to the Stack.Preview() operation on the named
+// stack. Like UpOptions, the function is called once for each stack.
+func PreviewOptions(previewOpts func(stackName string) []optpreview.Option) Option {
+	return optionFunc(func(opts *Options) {
+		opts.PreviewOptions = previewOpts
+	})
+}
+
+// Option is a parameter to be applied to a StackGraph operation
+type Option interface {
+	ApplyOption(*Options)
+}
+
+// ---------------------------------- implementation details ----------------------------------
+
+// Options is an implementation detail
+type Options struct {
+	// Parallel is the number of stacks to operate on in parallel at once
+	// (1 for no parallelism). Defaults to unbounded.
+	Parallel int
+	// FailurePolicy is applied to the remaining stacks when an operation on a stack fails
+	FailurePolicy FailurePolicy
+	// UpOptions returns the options passed to the Stack.Up() operation on the named stack
+	UpOptions func(stackName string) []optup.Option
+	// PreviewOptions returns the options passed to the Stack.Preview() operation on the named stack
+	PreviewOptions func(stackName string) []optpreview.Option
+}
+
+type optionFunc func(*Options)
+
+// ApplyOption is an implementation detail
+func (o optionFunc) ApplyOption(opts *Options) {
+	o(opts)
+}
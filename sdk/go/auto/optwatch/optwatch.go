@@ -0,0 +1,103 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package optwatch contains functional options to be used with stack watch operations
+// github.com/sdk/v2/go/x/auto Stack.Watch(...optwatch.Option)
+package optwatch
+
+import (
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optpreview"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optsession"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optup"
+)
+
+// Paths specifies the files and directories to watch for changes. Relative paths are resolved against the
+// workspace's working directory. Defaults to the working directory. Stack settings files and directories such as
+// node_modules and .pulumi are ignored within a watched directory, but not when they are listed here themselves.
+func Paths(paths ...string) Option {
+	return optionFunc(func(opts *Options) {
+		opts.Paths = paths
+	})
+}
+
+// Interval is how often the watched paths are checked for changes. Defaults to one second.
+func Interval(interval time.Duration) Option {
+	return optionFunc(func(opts *Options) {
+		opts.Interval = interval
+	})
+}
+
+// PreviewOnly runs a preview instead of an update on each change. Previews share a single preview session,
+// so plugins stay loaded between iterations.
+func PreviewOnly() Option {
+	return optionFunc(func(opts *Options) {
+		opts.PreviewOnly = true
+	})
+}
+
+// UpOptions specifies a function that returns the options passed to the Stack.Up() operation of an iteration,
+// numbered from 1. The function is called once for each iteration, so that every update can be given its own
+// optup.EventStreams channels, which are closed when the update completes.
+func UpOptions(upOpts func(iteration int) []optup.Option) Option {
+	return optionFunc(func(opts *Options) {
+		opts.UpOptions = upOpts
+	})
+}
+
+// PreviewOptions specifies a function that returns the options passed to the preview of an iteration when
+// PreviewOnly is set. Like UpOptions, the function is called once for each iteration.
+func PreviewOptions(previewOpts func(iteration int) []optpreview.Option) Option {
+	return optionFunc(func(opts *Options) {
+		opts.PreviewOptions = previewOpts
+	})
+}
+
+// SessionOptions specifies the options for the preview session used when PreviewOnly is set
+func SessionOptions(sessionOpts ...optsession.Option) Option {
+	return optionFunc(func(opts *Options) {
+		opts.SessionOptions = sessionOpts
+	})
+}
+
+// Option is a parameter to be applied to a Stack.Watch() operation
+type Option interface {
+	ApplyOption(*Options)
+}
+
+// ---------------------------------- implementation details ----------------------------------
+
+// Options is an implementation detail
+type Options struct {
+	// Paths to watch for changes, defaults to the working directory
+	Paths []string
+	// Interval at which paths are checked for changes, defaults to one second
+	Interval time.Duration
+	// PreviewOnly runs previews instead of updates
+	PreviewOnly bool
+	// UpOptions returns the options passed to the Stack.Up() operation of an iteration
+	UpOptions func(iteration int) []optup.Option
+	// PreviewOptions returns the options passed to the preview of an iteration
+	PreviewOptions func(iteration int) []optpreview.Option
+	// SessionOptions configure the preview session used for previews
+	SessionOptions []optsession.Option
+}
+
+type optionFunc func(*Options)
+
+// ApplyOption is an implementation detail
+func (o optionFunc) ApplyOption(opts *Options) {
+	o(opts)
+}
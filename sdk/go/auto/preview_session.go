@@ -0,0 +1,330 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optpreview"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optsession"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// debugProvidersEnv is the environment variable that tells the engine to attach to already running providers
+// rather than starting them, as a comma-separated list of name:port pairs.
+const debugProvidersEnv = "PULUMI_DEBUG_PROVIDERS"
+
+// PreviewSession runs successive previews of a stack while keeping the processes they depend on running in
+// between: the language runtime for inline programs, and the provider plugins the stack uses. This avoids paying
+// the cost of starting them on every preview, e.g. when previewing after each save in an editor. A PreviewSession
+// must be closed when it is no longer needed.
+//
+// Providers listed with optsession.Providers are started with the session. Other providers are discovered from the
+// resources of the first successful preview, which loads them as usual, and are kept running for the previews
+// that follow. A provider that is used at more than one version is never kept running.
+type PreviewSession struct {
+	stack *Stack
+
+	m          sync.Mutex
+	server     *languageRuntimeServer
+	providers  []*sessionProvider
+	env        []string
+	discovered bool // true once providers have been discovered from a preview
+	closed     bool
+}
+
+// sessionProvider is a provider plugin process started in attach mode for a PreviewSession.
+type sessionProvider struct {
+	name string
+	port int
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// NewPreviewSession starts a PreviewSession for the stack.
+func (s *Stack) NewPreviewSession(ctx context.Context, opts ...optsession.Option) (*PreviewSession, error) {
+	sessionOpts := &optsession.Options{}
+	for _, o := range opts {
+		o.ApplyOption(sessionOpts)
+	}
+
+	ps := &PreviewSession{stack: s}
+	if program := s.Workspace().Program(); program != nil {
+		server, err := startLanguageRuntimeServer(program)
+		if err != nil {
+			return nil, err
+		}
+		ps.server = server
+	}
+
+	for _, p := range sessionOpts.Providers {
+		provider, err := startSessionProvider(ctx, s.Workspace(), p)
+		if err != nil {
+			contract.IgnoreError(ps.Close())
+			return nil, fmt.Errorf("failed to start provider %q: %w", p.Name, err)
+		}
+		ps.providers = append(ps.providers, provider)
+	}
+	ps.updateEnv()
+
+	return ps, nil
+}
+
+// updateEnv sets the environment that tells the engine to attach to the session's providers.
+func (ps *PreviewSession) updateEnv() {
+	var attach []string
+	for _, p := range ps.providers {
+		attach = append(attach, fmt.Sprintf("%s:%d", p.name, p.port))
+	}
+	ps.env = nil
+	if len(attach) > 0 {
+		ps.env = []string{fmt.Sprintf("%s=%s", debugProvidersEnv, strings.Join(attach, ","))}
+	}
+}
+
+// Preview performs a dry-run update to the stack, returning pending changes. Previews within a session are
+// run one at a time.
+func (ps *PreviewSession) Preview(ctx context.Context, opts ...optpreview.Option) (PreviewResult, error) {
+	ps.m.Lock()
+	defer ps.m.Unlock()
+
+	if ps.closed {
+		return PreviewResult{}, errors.New("preview session is closed")
+	}
+	for _, p := range ps.providers {
+		select {
+		case <-p.done:
+			return PreviewResult{}, fmt.Errorf("provider %q exited unexpectedly", p.name)
+		default:
+		}
+	}
+
+	preOpts := &optpreview.Options{}
+	for _, o := range opts {
+		o.ApplyOption(preOpts)
+	}
+
+	var discovery chan events.EngineEvent
+	var preEvents []events.EngineEvent
+	discoveryDone := make(chan struct{})
+	if !ps.discovered {
+		discovery = make(chan events.EngineEvent)
+		go func() {
+			defer close(discoveryDone)
+			for e := range discovery {
+				if e.ResourcePreEvent != nil {
+					preEvents = append(preEvents, e)
+				}
+			}
+		}()
+		// Copy the caller's streams rather than appending to them, so that the slice they passed in is not shared.
+		streams := make([]chan<- events.EngineEvent, 0, len(preOpts.EventStreams)+1)
+		streams = append(streams, preOpts.EventStreams...)
+		preOpts.EventStreams = append(streams, discovery)
+	}
+
+	res, err := ps.stack.preview(ctx, preOpts, ps.server, ps.env)
+	if discovery != nil && err == nil {
+		// The event streams are closed once the preview has returned successfully.
+		<-discoveryDone
+		ps.discovered = true
+		ps.startDiscoveredProviders(ctx, discoverProviders(preEvents))
+	}
+	return res, err
+}
+
+// startDiscoveredProviders starts the given providers unless they are already running. Providers that fail to start
+// are left to be loaded by each preview as usual.
+func (ps *PreviewSession) startDiscoveredProviders(ctx context.Context, providers []optsession.Provider) {
+	running := map[string]bool{}
+	for _, p := range ps.providers {
+		running[p.name] = true
+	}
+	for _, p := range providers {
+		if running[p.Name] {
+			continue
+		}
+		provider, err := startSessionProvider(ctx, ps.stack.Workspace(), p)
+		if err != nil {
+			continue
+		}
+		ps.providers = append(ps.providers, provider)
+	}
+	ps.updateEnv()
+}
+
+// discoverProviders returns the provider plugins used by the resources of a preview, found from the provider
+// resources in its events. Only packages with a single provider resource are returned: the engine attaches every
+// provider of a package to the same process, so providers with different configuration, such as one per region,
+// must each be loaded by the preview as usual.
+func discoverProviders(preEvents []events.EngineEvent) []optsession.Provider {
+	instances := map[string]map[string]string{}
+	var names []string
+	for _, e := range preEvents {
+		if e.ResourcePreEvent == nil {
+			continue
+		}
+		metadata := e.ResourcePreEvent.Metadata
+		if !strings.HasPrefix(metadata.Type, "pulumi:providers:") || metadata.New == nil {
+			continue
+		}
+		name := strings.TrimPrefix(metadata.Type, "pulumi:providers:")
+		version, _ := metadata.New.Inputs["version"].(string)
+		if instances[name] == nil {
+			instances[name] = map[string]string{}
+			names = append(names, name)
+		}
+		instances[name][metadata.URN] = version
+	}
+
+	var providers []optsession.Provider
+	for _, name := range names {
+		if len(instances[name]) != 1 {
+			continue
+		}
+		for _, version := range instances[name] {
+			providers = append(providers, optsession.Provider{Name: name, Version: version})
+		}
+	}
+	return providers
+}
+
+// Close stops the processes kept running by the session.
+func (ps *PreviewSession) Close() error {
+	ps.m.Lock()
+	defer ps.m.Unlock()
+
+	if ps.closed {
+		return nil
+	}
+	ps.closed = true
+
+	var errs []string
+	if ps.server != nil {
+		if err := ps.server.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	for _, p := range ps.providers {
+		p.stop()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close preview session: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// startSessionProvider starts the given provider plugin in attach mode, i.e. without an engine address, and waits
+// for it to report the port it is listening on.
+func startSessionProvider(
+	ctx context.Context,
+	ws Workspace,
+	p optsession.Provider,
+) (*sessionProvider, error) {
+	path := p.Path
+	if path == "" {
+		var version *semver.Version
+		if p.Version != "" {
+			v, err := semver.ParseTolerant(p.Version)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version %q: %w", p.Version, err)
+			}
+			version = &v
+		}
+
+		sink := diag.DefaultSink(io.Discard, io.Discard, diag.FormatOptions{Color: colors.Never})
+		var err error
+		path, err = workspace.GetPluginPath(sink, workspace.ResourcePlugin, p.Name, version, nil /* projectPlugins */)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The provider outlives ctx, which only bounds how long we wait for it to start.
+	cmd := exec.Command(path) //nolint:gosec // the path comes from the plugin cache or the caller
+	cmd.Dir = ws.WorkDir()
+	cmd.Env = os.Environ()
+	if ws.PulumiHome() != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", pulumiHomeEnv, ws.PulumiHome()))
+	}
+	for k, v := range ws.GetEnvVars() {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	cmd.Stderr = io.Discard
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	provider := &sessionProvider{name: p.Name, cmd: cmd, done: make(chan struct{})}
+	ports := make(chan string, 1)
+	go func() {
+		reader := bufio.NewReader(stdout)
+		line, err := reader.ReadString('\n')
+		if err == nil {
+			ports <- strings.TrimSpace(line)
+		}
+		close(ports)
+		// Keep draining stdout so the provider never blocks writing to it.
+		_, err = io.Copy(io.Discard, reader)
+		contract.IgnoreError(err)
+		contract.IgnoreError(cmd.Wait())
+		close(provider.done)
+	}()
+
+	select {
+	case line, ok := <-ports:
+		if !ok {
+			provider.stop()
+			return nil, errors.New("provider exited before reporting its port")
+		}
+		port, err := strconv.Atoi(line)
+		if err != nil {
+			provider.stop()
+			return nil, fmt.Errorf("expected a numeric port from the provider, got %q", line)
+		}
+		provider.port = port
+		return provider, nil
+	case <-ctx.Done():
+		provider.stop()
+		return nil, ctx.Err()
+	}
+}
+
+// stop kills the provider process and waits for it to exit.
+func (p *sessionProvider) stop() {
+	if p.cmd.Process != nil {
+		contract.IgnoreError(p.cmd.Process.Kill())
+	}
+	<-p.done
+}
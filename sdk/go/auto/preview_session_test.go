@@ -0,0 +1,54 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optsession"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+)
+
+func TestDiscoverProviders(t *testing.T) {
+	t.Parallel()
+
+	pre := func(name, typ string, inputs map[string]interface{}) events.EngineEvent {
+		return events.EngineEvent{EngineEvent: apitype.EngineEvent{
+			ResourcePreEvent: &apitype.ResourcePreEvent{Metadata: apitype.StepEventMetadata{
+				URN:  "urn:pulumi:dev::proj::" + typ + "::" + name,
+				Type: typ,
+				New:  &apitype.StepEventStateMetadata{Type: typ, Inputs: inputs},
+			}},
+		}}
+	}
+
+	providers := discoverProviders([]events.EngineEvent{
+		pre("default", "pulumi:providers:random", map[string]interface{}{"version": "4.0.0"}),
+		pre("bucket", "aws:s3/bucket:Bucket", nil),
+		pre("default", "pulumi:providers:kubernetes", nil),
+		// Providers of the same package with different configuration can't share a process, even at one version.
+		pre("west", "pulumi:providers:aws", map[string]interface{}{"version": "6.0.0", "region": "us-west-2"}),
+		pre("east", "pulumi:providers:aws", map[string]interface{}{"version": "6.0.0", "region": "us-east-1"}),
+		pre("v6", "pulumi:providers:gcp", map[string]interface{}{"version": "6.0.0"}),
+		pre("v7", "pulumi:providers:gcp", map[string]interface{}{"version": "7.0.0"}),
+	})
+	assert.Equal(t, []optsession.Provider{
+		{Name: "random", Version: "4.0.0"},
+		{Name: "kubernetes"},
+	}, providers)
+}
@@ -0,0 +1,311 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+)
+
+// ResourceStatus is the status of a single resource within a stack operation.
+type ResourceStatus string
+
+const (
+	// ResourcePending indicates that the resource's step has been planned but not started, as steps are during a
+	// preview, or that the resource has only been referenced by a diagnostic.
+	ResourcePending ResourceStatus = "pending"
+	// ResourceInProgress indicates that the resource's step has started but not yet finished.
+	ResourceInProgress ResourceStatus = "in-progress"
+	// ResourceDone indicates that the resource's step finished successfully.
+	ResourceDone ResourceStatus = "done"
+	// ResourceFailed indicates that the resource's step failed.
+	ResourceFailed ResourceStatus = "failed"
+)
+
+// ResourceProgress is the state of a single step on a resource within a stack operation, folded from the engine event
+// stream. A resource may have several steps, e.g. the create-replacement and delete-replaced steps of a replacement,
+// each of which has its own progress.
+type ResourceProgress struct {
+	// URN is the URN of the resource.
+	URN string
+	// Type is the type token of the resource.
+	Type string
+	// Op is the operation being performed on the resource. It is empty if the resource has only been referenced by a
+	// diagnostic.
+	Op apitype.OpType
+	// Status is the current status of the resource.
+	Status ResourceStatus
+	// Planning is true if the step is only being planned, as it is during a preview.
+	Planning bool
+	// Metadata is the metadata of the most recent step event for the resource.
+	Metadata *apitype.StepEventMetadata
+	// Diagnostics are the diagnostics reported for the resource, in the order they were received.
+	Diagnostics []apitype.DiagnosticEvent
+	// StartTime is the time at which the tracker received the event that started or planned the resource's step. It
+	// is zero until then. Engine event timestamps only have a resolution of one second, so the time of receipt is
+	// used instead.
+	StartTime time.Time
+	// EndTime is the time at which the tracker received the event that finished the resource's step. It is zero until
+	// the step finishes or fails.
+	EndTime time.Time
+}
+
+// Elapsed returns the time spent on the resource's step. If the step is still in progress, the time
+// elapsed up to now is returned.
+func (rp ResourceProgress) Elapsed() time.Duration {
+	if rp.StartTime.IsZero() {
+		return 0
+	}
+	if rp.EndTime.IsZero() {
+		return time.Since(rp.StartTime)
+	}
+	return rp.EndTime.Sub(rp.StartTime)
+}
+
+// Progress is a point-in-time snapshot of the overall progress of a stack operation.
+type Progress struct {
+	// Resources is the progress of every step seen so far, in the order in which they were first seen.
+	Resources []ResourceProgress
+	// Pending is the number of steps that have been planned but not started.
+	Pending int
+	// InProgress is the number of steps that have started but not yet finished.
+	InProgress int
+	// Done is the number of steps that finished successfully.
+	Done int
+	// Failed is the number of steps that failed.
+	Failed int
+	// Canceled is true if the operation has been canceled.
+	Canceled bool
+	// Summary is the summary of the operation. It is nil until the operation completes.
+	Summary *apitype.SummaryEvent
+}
+
+// ProgressCallbacks are invoked by a ProgressTracker as it folds engine events. Any callback may be nil.
+// Callbacks are invoked sequentially from a single goroutine.
+type ProgressCallbacks struct {
+	// OnResourceStart is called when a resource's step starts or, during a preview, is planned.
+	OnResourceStart func(ResourceProgress)
+	// OnResourceDone is called when a resource's step finishes, successfully or not.
+	// The resource's Status distinguishes ResourceDone from ResourceFailed.
+	OnResourceDone func(ResourceProgress)
+	// OnDiagnostic is called for every diagnostic message, whether or not it is associated with a resource.
+	OnDiagnostic func(apitype.DiagnosticEvent)
+	// OnSummary is called with the summary of the operation once it completes.
+	OnSummary func(apitype.SummaryEvent)
+	// OnError is called when the event stream reports an error, for example if an event could not be decoded.
+	OnError func(error)
+}
+
+// ProgressTracker folds a stream of engine events into per-resource progress. Pass the channel returned by
+// Events to an operation's EventStreams option (e.g. optup.EventStreams) to track that operation.
+// A ProgressTracker tracks a single operation; the event channel is closed when the operation completes.
+type ProgressTracker struct {
+	callbacks ProgressCallbacks
+	now       func() time.Time // the clock used to time steps, overridden in tests
+
+	m         sync.Mutex
+	resources map[progressKey]*ResourceProgress
+	latest    map[string]progressKey // the most recent step of each resource, which receives its diagnostics
+	order     []progressKey
+	canceled  bool
+	summary   *apitype.SummaryEvent
+
+	events chan events.EngineEvent
+	done   chan struct{}
+}
+
+// progressKey identifies a step on a resource.
+type progressKey struct {
+	urn string
+	op  apitype.OpType
+}
+
+// NewProgressTracker creates a ProgressTracker that invokes the given callbacks as events arrive.
+func NewProgressTracker(callbacks ProgressCallbacks) *ProgressTracker {
+	t := &ProgressTracker{
+		callbacks: callbacks,
+		now:       time.Now,
+		resources: make(map[progressKey]*ResourceProgress),
+		latest:    make(map[string]progressKey),
+		events:    make(chan events.EngineEvent),
+		done:      make(chan struct{}),
+	}
+	go func() {
+		for e := range t.events {
+			t.process(e)
+		}
+		close(t.done)
+	}()
+	return t
+}
+
+// Events returns the channel on which the tracker receives engine events.
+func (t *ProgressTracker) Events() chan<- events.EngineEvent {
+	return t.events
+}
+
+// Done returns a channel that is closed once the event channel has been closed and every event processed.
+func (t *ProgressTracker) Done() <-chan struct{} {
+	return t.done
+}
+
+// Snapshot returns the current progress of the operation.
+func (t *ProgressTracker) Snapshot() Progress {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	p := Progress{
+		Resources: make([]ResourceProgress, 0, len(t.order)),
+		Canceled:  t.canceled,
+	}
+	if t.summary != nil {
+		summary := *t.summary
+		p.Summary = &summary
+	}
+	for _, key := range t.order {
+		rp := t.resources[key].copy()
+		p.Resources = append(p.Resources, rp)
+		switch rp.Status {
+		case ResourcePending:
+			p.Pending++
+		case ResourceInProgress:
+			p.InProgress++
+		case ResourceDone:
+			p.Done++
+		case ResourceFailed:
+			p.Failed++
+		}
+	}
+	return p
+}
+
+func (rp *ResourceProgress) copy() ResourceProgress {
+	c := *rp
+	c.Diagnostics = append([]apitype.DiagnosticEvent(nil), rp.Diagnostics...)
+	return c
+}
+
+// step returns the progress entry for the given step, creating a pending entry if none exists. An entry that was
+// created for the resource's diagnostics before any of its steps were seen becomes the entry of the step.
+// The caller must hold the lock.
+func (t *ProgressTracker) step(urn string, op apitype.OpType) *ResourceProgress {
+	key := progressKey{urn: urn, op: op}
+	rp, ok := t.resources[key]
+	if !ok {
+		unknown := progressKey{urn: urn}
+		if rp, ok = t.resources[unknown]; ok && op != "" {
+			delete(t.resources, unknown)
+			for i, k := range t.order {
+				if k == unknown {
+					t.order[i] = key
+				}
+			}
+		} else {
+			rp = &ResourceProgress{URN: urn, Status: ResourcePending}
+			t.order = append(t.order, key)
+		}
+		t.resources[key] = rp
+	}
+	t.latest[urn] = key
+	return rp
+}
+
+// latestStep returns the progress entry for the most recent step of the given resource, creating a pending entry if
+// no step has been seen. The caller must hold the lock.
+func (t *ProgressTracker) latestStep(urn string) *ResourceProgress {
+	if key, ok := t.latest[urn]; ok {
+		return t.resources[key]
+	}
+	return t.step(urn, "")
+}
+
+func (t *ProgressTracker) process(e events.EngineEvent) {
+	if e.Error != nil {
+		if t.callbacks.OnError != nil {
+			t.callbacks.OnError(e.Error)
+		}
+		return
+	}
+
+	t.m.Lock()
+	var (
+		started, finished *ResourceProgress
+		diagnostic        *apitype.DiagnosticEvent
+		summary           *apitype.SummaryEvent
+	)
+	switch {
+	case e.CancelEvent != nil:
+		t.canceled = true
+	case e.ResourcePreEvent != nil:
+		md := e.ResourcePreEvent.Metadata
+		rp := t.step(md.URN, md.Op)
+		rp.Type, rp.Op, rp.Metadata = md.Type, md.Op, &md
+		rp.Planning = e.ResourcePreEvent.Planning
+		rp.Status, rp.StartTime = ResourceInProgress, t.now()
+		if rp.Planning {
+			// A planned step is not performed by this operation.
+			rp.Status = ResourcePending
+		}
+		started = rp
+	case e.ResOutputsEvent != nil:
+		md := e.ResOutputsEvent.Metadata
+		rp := t.step(md.URN, md.Op)
+		rp.Type, rp.Op, rp.Metadata = md.Type, md.Op, &md
+		rp.Planning = e.ResOutputsEvent.Planning
+		rp.Status, rp.EndTime = ResourceDone, t.now()
+		finished = rp
+	case e.ResOpFailedEvent != nil:
+		md := e.ResOpFailedEvent.Metadata
+		rp := t.step(md.URN, md.Op)
+		rp.Type, rp.Op, rp.Metadata = md.Type, md.Op, &md
+		rp.Status, rp.EndTime = ResourceFailed, t.now()
+		finished = rp
+	case e.DiagnosticEvent != nil:
+		diag := *e.DiagnosticEvent
+		if diag.URN != "" {
+			rp := t.latestStep(diag.URN)
+			rp.Diagnostics = append(rp.Diagnostics, diag)
+		}
+		diagnostic = &diag
+	case e.SummaryEvent != nil:
+		s := *e.SummaryEvent
+		t.summary = &s
+		summary = &s
+	}
+
+	// Copy out what the callbacks need so that they run without holding the lock and may call Snapshot.
+	var startedCopy, finishedCopy ResourceProgress
+	if started != nil {
+		startedCopy = started.copy()
+	}
+	if finished != nil {
+		finishedCopy = finished.copy()
+	}
+	t.m.Unlock()
+
+	switch {
+	case started != nil && t.callbacks.OnResourceStart != nil:
+		t.callbacks.OnResourceStart(startedCopy)
+	case finished != nil && t.callbacks.OnResourceDone != nil:
+		t.callbacks.OnResourceDone(finishedCopy)
+	case diagnostic != nil && t.callbacks.OnDiagnostic != nil:
+		t.callbacks.OnDiagnostic(*diagnostic)
+	case summary != nil && t.callbacks.OnSummary != nil:
+		t.callbacks.OnSummary(*summary)
+	}
+}
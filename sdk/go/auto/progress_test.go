@@ -0,0 +1,184 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+)
+
+func stepEvent(urn string, op apitype.OpType) apitype.StepEventMetadata {
+	return apitype.StepEventMetadata{Op: op, URN: urn, Type: "test:index:Resource"}
+}
+
+func TestProgressTracker(t *testing.T) {
+	t.Parallel()
+
+	const (
+		urnA = "urn:pulumi:dev::proj::test:index:Resource::a"
+		urnB = "urn:pulumi:dev::proj::test:index:Resource::b"
+		urnC = "urn:pulumi:dev::proj::test:index:Resource::c"
+	)
+
+	var started, done []string
+	var diagnostics []string
+	var summary *apitype.SummaryEvent
+	var errs []error
+	tracker := NewProgressTracker(ProgressCallbacks{
+		OnResourceStart: func(rp ResourceProgress) { started = append(started, rp.URN) },
+		OnResourceDone:  func(rp ResourceProgress) { done = append(done, rp.URN+"="+string(rp.Status)) },
+		OnDiagnostic:    func(d apitype.DiagnosticEvent) { diagnostics = append(diagnostics, d.Message) },
+		OnSummary:       func(s apitype.SummaryEvent) { summary = &s },
+		OnError:         func(err error) { errs = append(errs, err) },
+	})
+
+	// Steps are timed by when their events are received, not by the events' timestamps. The clock is only read when a
+	// step starts or finishes, so it returns the times at which A starts, B starts, A finishes and B fails.
+	clock := []int64{100, 101, 105, 107}
+	tracker.now = func() time.Time {
+		now := time.Unix(clock[0], 0)
+		clock = clock[1:]
+		return now
+	}
+
+	send := func(e apitype.EngineEvent) {
+		tracker.Events() <- events.EngineEvent{EngineEvent: e}
+	}
+
+	send(apitype.EngineEvent{Timestamp: 1, ResourcePreEvent: &apitype.ResourcePreEvent{
+		Metadata: stepEvent(urnA, apitype.OpCreate),
+	}})
+	send(apitype.EngineEvent{Timestamp: 1, ResourcePreEvent: &apitype.ResourcePreEvent{
+		Metadata: stepEvent(urnB, apitype.OpUpdate),
+	}})
+	send(apitype.EngineEvent{Timestamp: 1, DiagnosticEvent: &apitype.DiagnosticEvent{
+		URN: urnC, Message: "warning about c", Severity: "warning",
+	}})
+
+	// Wait for the events to be processed by sending an event that is always dropped.
+	tracker.Events() <- events.EngineEvent{}
+	snap := tracker.Snapshot()
+	require.Len(t, snap.Resources, 3)
+	assert.Equal(t, 2, snap.InProgress)
+	assert.Equal(t, 1, snap.Pending)
+	assert.Equal(t, ResourcePending, snap.Resources[2].Status)
+	assert.Len(t, snap.Resources[2].Diagnostics, 1)
+	assert.Nil(t, snap.Summary)
+
+	send(apitype.EngineEvent{Timestamp: 1, ResOutputsEvent: &apitype.ResOutputsEvent{
+		Metadata: stepEvent(urnA, apitype.OpCreate),
+	}})
+	send(apitype.EngineEvent{Timestamp: 1, ResOpFailedEvent: &apitype.ResOpFailedEvent{
+		Metadata: stepEvent(urnB, apitype.OpUpdate),
+	}})
+	tracker.Events() <- events.EngineEvent{Error: errors.New("bad event")}
+	send(apitype.EngineEvent{Timestamp: 1, SummaryEvent: &apitype.SummaryEvent{
+		DurationSeconds: 8,
+		ResourceChanges: map[apitype.OpType]int{apitype.OpCreate: 1},
+	}})
+	close(tracker.Events())
+	<-tracker.Done()
+
+	assert.Equal(t, []string{urnA, urnB}, started)
+	assert.Equal(t, []string{urnA + "=done", urnB + "=failed"}, done)
+	assert.Equal(t, []string{"warning about c"}, diagnostics)
+	require.NotNil(t, summary)
+	assert.Equal(t, 8, summary.DurationSeconds)
+	require.Len(t, errs, 1)
+
+	snap = tracker.Snapshot()
+	assert.Equal(t, 1, snap.Done)
+	assert.Equal(t, 1, snap.Failed)
+	assert.Equal(t, 1, snap.Pending)
+	assert.Equal(t, 0, snap.InProgress)
+	require.NotNil(t, snap.Summary)
+
+	a := snap.Resources[0]
+	assert.Equal(t, apitype.OpCreate, a.Op)
+	assert.Equal(t, "test:index:Resource", a.Type)
+	assert.Equal(t, 5*time.Second, a.Elapsed())
+	assert.Equal(t, 6*time.Second, snap.Resources[1].Elapsed())
+	assert.Equal(t, time.Duration(0), snap.Resources[2].Elapsed())
+}
+
+func TestProgressTrackerSteps(t *testing.T) {
+	t.Parallel()
+
+	const (
+		urnA = "urn:pulumi:dev::proj::test:index:Resource::a"
+		urnB = "urn:pulumi:dev::proj::test:index:Resource::b"
+	)
+
+	tracker := NewProgressTracker(ProgressCallbacks{})
+	send := func(e apitype.EngineEvent) {
+		tracker.Events() <- events.EngineEvent{EngineEvent: e}
+	}
+
+	// The steps of a replacement are tracked separately, and diagnostics go to the most recent step.
+	send(apitype.EngineEvent{ResourcePreEvent: &apitype.ResourcePreEvent{
+		Metadata: stepEvent(urnA, apitype.OpCreateReplacement),
+	}})
+	send(apitype.EngineEvent{ResOutputsEvent: &apitype.ResOutputsEvent{
+		Metadata: stepEvent(urnA, apitype.OpCreateReplacement),
+	}})
+	send(apitype.EngineEvent{ResourcePreEvent: &apitype.ResourcePreEvent{
+		Metadata: stepEvent(urnA, apitype.OpDeleteReplaced),
+	}})
+	send(apitype.EngineEvent{DiagnosticEvent: &apitype.DiagnosticEvent{
+		URN: urnA, Message: "deleting a", Severity: "info",
+	}})
+
+	// A diagnostic that arrives before any of a resource's steps is kept by its first step, and planned steps are
+	// pending.
+	send(apitype.EngineEvent{DiagnosticEvent: &apitype.DiagnosticEvent{
+		URN: urnB, Message: "checking b", Severity: "info",
+	}})
+	send(apitype.EngineEvent{ResourcePreEvent: &apitype.ResourcePreEvent{
+		Metadata: stepEvent(urnB, apitype.OpUpdate),
+		Planning: true,
+	}})
+	close(tracker.Events())
+	<-tracker.Done()
+
+	snap := tracker.Snapshot()
+	require.Len(t, snap.Resources, 3)
+	assert.Equal(t, 1, snap.Done)
+	assert.Equal(t, 1, snap.InProgress)
+	assert.Equal(t, 1, snap.Pending)
+
+	assert.Equal(t, apitype.OpCreateReplacement, snap.Resources[0].Op)
+	assert.Equal(t, ResourceDone, snap.Resources[0].Status)
+	assert.Empty(t, snap.Resources[0].Diagnostics)
+
+	assert.Equal(t, apitype.OpDeleteReplaced, snap.Resources[1].Op)
+	assert.Equal(t, ResourceInProgress, snap.Resources[1].Status)
+	require.Len(t, snap.Resources[1].Diagnostics, 1)
+	assert.Equal(t, "deleting a", snap.Resources[1].Diagnostics[0].Message)
+
+	b := snap.Resources[2]
+	assert.Equal(t, urnB, b.URN)
+	assert.Equal(t, apitype.OpUpdate, b.Op)
+	assert.Equal(t, ResourcePending, b.Status)
+	assert.True(t, b.Planning)
+	require.Len(t, b.Diagnostics, 1)
+	assert.Equal(t, "checking b", b.Diagnostics[0].Message)
+}
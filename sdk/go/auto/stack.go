@@ -16,7 +16,8 @@
 // without the CLI.
 // Generally this can be thought of as encapsulating the functionality of the CLI (`pulumi up`, `pulumi preview`,
 // pulumi destroy`, `pulumi stack init`, etc.) but with more flexibility. This still requires a
-// CLI binary to be installed and available on your $PATH.
+// CLI binary to be installed and available on your $PATH, unless an alternative PulumiCommand
+// is supplied to the LocalWorkspace with the Pulumi option.
 //
 // In addition to fine-grained building blocks, Automation API provides three out of the box ways to work with Stacks:
 //
@@ -114,10 +115,16 @@ import (
 
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/debug"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optchangesecretsprovider"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optdestroy"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/opthistory"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optimport"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optpreview"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optrefresh"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstackrename"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstate"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstaterename"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstateunprotect"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optup"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/constant"
@@ -207,13 +214,24 @@ func (s *Stack) Workspace() Workspace {
 // Preview preforms a dry-run update to a stack, returning pending changes.
 // https://www.pulumi.com/docs/cli/commands/pulumi_preview/
 func (s *Stack) Preview(ctx context.Context, opts ...optpreview.Option) (PreviewResult, error) {
-	var res PreviewResult
-
 	preOpts := &optpreview.Options{}
 	for _, o := range opts {
 		o.ApplyOption(preOpts)
 	}
 
+	return s.preview(ctx, preOpts, nil /* server */, nil /* additionalEnv */)
+}
+
+// preview runs a preview with the given options. If the workspace has an inline program and server is non-nil,
+// the program is run by server rather than a language runtime server started for this preview.
+func (s *Stack) preview(
+	ctx context.Context,
+	preOpts *optpreview.Options,
+	server *languageRuntimeServer,
+	additionalEnv []string,
+) (PreviewResult, error) {
+	var res PreviewResult
+
 	bufferSizeHint := len(preOpts.Replace) + len(preOpts.Target) +
 		len(preOpts.PolicyPacks) + len(preOpts.PolicyPackConfigs)
 	sharedArgs := slice.Prealloc[string](bufferSizeHint)
@@ -261,11 +279,14 @@ func (s *Stack) Preview(ctx context.Context, opts ...optpreview.Option) (Preview
 
 	kind, args := constant.ExecKindAutoLocal, []string{"preview"}
 	if program := s.Workspace().Program(); program != nil {
-		server, err := startLanguageRuntimeServer(program)
-		if err != nil {
-			return res, err
+		if server == nil {
+			var err error
+			server, err = startLanguageRuntimeServer(program)
+			if err != nil {
+				return res, err
+			}
+			defer contract.IgnoreClose(server)
 		}
-		defer contract.IgnoreClose(server)
 
 		kind, args = constant.ExecKindAutoInline, append(args, "--client="+server.address)
 	}
@@ -299,8 +320,9 @@ func (s *Stack) Preview(ctx context.Context, opts ...optpreview.Option) (Preview
 	defer t.Close()
 	args = append(args, "--event-log", t.Filename)
 
-	stdout, stderr, code, err := s.runPulumiCmdSync(
+	stdout, stderr, code, err := s.runPulumiCmdSyncWithEnv(
 		ctx,
+		additionalEnv,
 		preOpts.ProgressStreams,      /* additionalOutput */
 		preOpts.ErrorProgressStreams, /* additionalErrorOutput */
 		args...,
@@ -796,6 +818,54 @@ func (s *Stack) Import(ctx context.Context, state apitype.UntypedDeployment) err
 	return s.Workspace().ImportStack(ctx, s.Name(), state)
 }
 
+// ImportResources imports existing resources into the stack, returning the resource declaration
+// code generated for them. Resources to import are specified with optimport.Resources.
+// https://www.pulumi.com/docs/cli/commands/pulumi_import/
+func (s *Stack) ImportResources(ctx context.Context, opts ...optimport.Option) (ImportResult, error) {
+	return s.Workspace().ImportResources(ctx, s.Name(), opts...)
+}
+
+// StateDelete deletes the resource with the given URN from the stack's state.
+// The resource itself is not deleted from the cloud provider.
+func (s *Stack) StateDelete(ctx context.Context, urn string, opts ...optstate.Option) error {
+	return s.Workspace().StateDelete(ctx, s.Name(), urn, opts...)
+}
+
+// StateUnprotect removes deletion protection from the resource with the given URN.
+func (s *Stack) StateUnprotect(ctx context.Context, urn string, opts ...optstateunprotect.Option) error {
+	return s.Workspace().StateUnprotect(ctx, s.Name(), urn, opts...)
+}
+
+// StateUnprotectAll removes deletion protection from every resource in the stack.
+func (s *Stack) StateUnprotectAll(ctx context.Context, opts ...optstateunprotect.Option) error {
+	return s.Workspace().StateUnprotectAll(ctx, s.Name(), opts...)
+}
+
+// StateRename renames the resource with the given URN in the stack's state.
+func (s *Stack) StateRename(ctx context.Context, urn string, newName string, opts ...optstaterename.Option) error {
+	return s.Workspace().StateRename(ctx, s.Name(), urn, newName, opts...)
+}
+
+// Rename renames the stack, moving its configuration. Subsequent operations on the Stack use the new name.
+// https://www.pulumi.com/docs/cli/commands/pulumi_stack_rename/
+func (s *Stack) Rename(ctx context.Context, newStackName string, opts ...optstackrename.Option) error {
+	if err := s.Workspace().RenameStack(ctx, s.Name(), newStackName, opts...); err != nil {
+		return err
+	}
+	s.stackName = newStackName
+	return nil
+}
+
+// ChangeSecretsProvider changes the secrets provider of the stack, re-encrypting its configuration and state.
+// https://www.pulumi.com/docs/cli/commands/pulumi_stack_change-secrets-provider/
+func (s *Stack) ChangeSecretsProvider(
+	ctx context.Context,
+	secretsProvider string,
+	opts ...optchangesecretsprovider.Option,
+) error {
+	return s.Workspace().ChangeSecretsProvider(ctx, s.Name(), secretsProvider, opts...)
+}
+
 // UpdateSummary provides a summary of a Stack lifecycle operation (up/preview/refresh/destroy).
 type UpdateSummary struct {
 	Version     int               `json:"version"`
@@ -924,6 +994,19 @@ func (dr *DestroyResult) GetPermalink() (string, error) {
 	return GetPermalink(dr.StdOut)
 }
 
+// ImportResult is the output of a successful Stack.ImportResources operation
+type ImportResult struct {
+	StdOut        string
+	StdErr        string
+	GeneratedCode string
+	Summary       UpdateSummary
+}
+
+// GetPermalink returns the permalink URL in the Pulumi Console for the import operation.
+func (ir *ImportResult) GetPermalink() (string, error) {
+	return GetPermalink(ir.StdOut)
+}
+
 // secretSentinel represents the CLI response for an output marked as "secret"
 const secretSentinel = "[secret]"
 
@@ -932,6 +1015,16 @@ func (s *Stack) runPulumiCmdSync(
 	additionalOutput []io.Writer,
 	additionalErrorOutput []io.Writer,
 	args ...string,
+) (string, string, int, error) {
+	return s.runPulumiCmdSyncWithEnv(ctx, nil /* additionalEnv */, additionalOutput, additionalErrorOutput, args...)
+}
+
+func (s *Stack) runPulumiCmdSyncWithEnv(
+	ctx context.Context,
+	additionalEnv []string,
+	additionalOutput []io.Writer,
+	additionalErrorOutput []io.Writer,
+	args ...string,
 ) (string, string, int, error) {
 	var env []string
 	debugEnv := fmt.Sprintf("%s=%s", "PULUMI_DEBUG_COMMANDS", "true")
@@ -956,6 +1049,7 @@ func (s *Stack) runPulumiCmdSync(
 			env = append(env, strings.Join(e, "="))
 		}
 	}
+	env = append(env, additionalEnv...)
 	additionalArgs, err := s.Workspace().SerializeArgsForOp(ctx, s.Name())
 	if err != nil {
 		return "", "", -1, fmt.Errorf("failed to exec command, error getting additional args: %w", err)
@@ -963,7 +1057,7 @@ func (s *Stack) runPulumiCmdSync(
 	args = append(args, additionalArgs...)
 	args = append(args, "--stack", s.Name())
 
-	stdout, stderr, errCode, err := runPulumiCommandSync(
+	stdout, stderr, errCode, err := pulumiCommandFor(s.Workspace()).Run(
 		ctx,
 		s.Workspace().WorkDir(),
 		additionalOutput,
@@ -0,0 +1,436 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optpreview"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstackgraph"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optup"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// stackReferenceType is the type token of StackReference resources.
+const stackReferenceType = "pulumi:pulumi:StackReference"
+
+// StackGraph is a set of stacks together with the dependencies between them, typically the result of one
+// stack consuming another's outputs through a StackReference. Operations on a StackGraph run against every
+// stack in dependency order: a stack is only operated on once all of the stacks it depends on are done.
+type StackGraph struct {
+	stacks map[string]*Stack
+	deps   map[string]map[string]bool
+}
+
+// StackGraphStatus is the outcome of an operation on a single stack within a StackGraph.
+type StackGraphStatus string
+
+const (
+	// StackSucceeded indicates that the operation on the stack succeeded.
+	StackSucceeded StackGraphStatus = "succeeded"
+	// StackFailed indicates that the operation on the stack failed.
+	StackFailed StackGraphStatus = "failed"
+	// StackSkipped indicates that the stack was not operated on due to the failure policy.
+	StackSkipped StackGraphStatus = "skipped"
+)
+
+// StackGraphStackResult is the result of an operation on a single stack within a StackGraph.
+type StackGraphStackResult struct {
+	// Name is the name of the stack.
+	Name string
+	// Status is the outcome of the operation on the stack.
+	Status StackGraphStatus
+	// Err is the error returned by the operation, if it failed.
+	Err error
+	// Up is the result of the operation, for StackGraph.Up operations that succeeded.
+	Up *UpResult
+	// Preview is the result of the operation, for StackGraph.Preview operations that succeeded.
+	Preview *PreviewResult
+}
+
+// StackGraphResult is the combined result of an operation on every stack in a StackGraph.
+type StackGraphResult struct {
+	// Stacks contains the result for every stack in the graph, in the order in which they were scheduled.
+	Stacks []StackGraphStackResult
+}
+
+// Get returns the result for the stack with the given name.
+func (r StackGraphResult) Get(stackName string) (StackGraphStackResult, bool) {
+	for _, res := range r.Stacks {
+		if res.Name == stackName {
+			return res, true
+		}
+	}
+	return StackGraphStackResult{}, false
+}
+
+// NewStackGraph creates a StackGraph containing the given stacks and no dependencies. Dependencies may be added
+// explicitly with AddDependency or inferred from the stacks' state with InferDependencies.
+func NewStackGraph(stacks ...Stack) (*StackGraph, error) {
+	g := &StackGraph{
+		stacks: make(map[string]*Stack, len(stacks)),
+		deps:   make(map[string]map[string]bool, len(stacks)),
+	}
+	for i := range stacks {
+		s := stacks[i]
+		if _, has := g.stacks[s.Name()]; has {
+			return nil, fmt.Errorf("stack %q was specified more than once", s.Name())
+		}
+		g.stacks[s.Name()] = &s
+		g.deps[s.Name()] = make(map[string]bool)
+	}
+	return g, nil
+}
+
+// AddDependency records that the stack named stackName depends on the stack named dependsOn.
+func (g *StackGraph) AddDependency(stackName, dependsOn string) error {
+	if _, has := g.stacks[stackName]; !has {
+		return fmt.Errorf("unknown stack %q", stackName)
+	}
+	if _, has := g.stacks[dependsOn]; !has {
+		return fmt.Errorf("unknown stack %q", dependsOn)
+	}
+	if stackName == dependsOn {
+		return fmt.Errorf("stack %q cannot depend on itself", stackName)
+	}
+	g.deps[stackName][dependsOn] = true
+	return nil
+}
+
+// Dependencies returns the names of the stacks that the stack named stackName directly depends on, sorted by name.
+func (g *StackGraph) Dependencies(stackName string) []string {
+	deps := make([]string, 0, len(g.deps[stackName]))
+	for dep := range g.deps[stackName] {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// InferDependencies adds a dependency for every StackReference in a stack's current state that refers to
+// another stack in the graph. Stacks that have not been deployed yet contribute no dependencies.
+func (g *StackGraph) InferDependencies(ctx context.Context) error {
+	for _, name := range g.names() {
+		state, err := g.stacks[name].Export(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to infer dependencies of stack %q: %w", name, err)
+		}
+		refs, err := stackReferences(state)
+		if err != nil {
+			return fmt.Errorf("failed to infer dependencies of stack %q: %w", name, err)
+		}
+		for _, ref := range refs {
+			dep, err := g.resolveStackReference(ref)
+			if err != nil {
+				return fmt.Errorf("failed to infer dependencies of stack %q: %w", name, err)
+			}
+			if dep != "" && dep != name {
+				g.deps[name][dep] = true
+			}
+		}
+	}
+	return nil
+}
+
+// Order returns the names of the stacks in the graph in dependency order, with every stack following the stacks
+// it depends on. Ties are broken by name. It returns an error if the dependencies contain a cycle.
+func (g *StackGraph) Order() ([]string, error) {
+	var sorted []string               // will hold the sorted stack names.
+	visiting := make(map[string]bool) // temporary entries to detect cycles.
+	visited := make(map[string]bool)  // entries to avoid visiting the same stack twice.
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visiting[name] {
+			return fmt.Errorf("stack dependencies contain a cycle through %q", name)
+		}
+		if !visited[name] {
+			visiting[name] = true
+			for _, dep := range g.Dependencies(name) {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+			visited[name] = true
+			visiting[name] = false
+			sorted = append(sorted, name)
+		}
+		return nil
+	}
+
+	for _, name := range g.names() {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// Up runs Stack.Up on every stack in the graph in dependency order.
+// An error is returned if the graph contains a cycle or if the operation failed on any stack;
+// the result reports the outcome for every stack either way.
+func (g *StackGraph) Up(ctx context.Context, opts ...optstackgraph.Option) (StackGraphResult, error) {
+	graphOpts := &optstackgraph.Options{}
+	for _, o := range opts {
+		o.ApplyOption(graphOpts)
+	}
+
+	// Stacks run in parallel, but the options for each stack are created one at a time.
+	var m sync.Mutex
+	return g.run(ctx, graphOpts, func(ctx context.Context, s *Stack, res *StackGraphStackResult) error {
+		var upOpts []optup.Option
+		if graphOpts.UpOptions != nil {
+			m.Lock()
+			upOpts = graphOpts.UpOptions(s.Name())
+			m.Unlock()
+		}
+		up, err := s.Up(ctx, upOpts...)
+		if err != nil {
+			return err
+		}
+		res.Up = &up
+		return nil
+	})
+}
+
+// Preview runs Stack.Preview on every stack in the graph in dependency order.
+// An error is returned if the graph contains a cycle or if the operation failed on any stack;
+// the result reports the outcome for every stack either way.
+func (g *StackGraph) Preview(ctx context.Context, opts ...optstackgraph.Option) (StackGraphResult, error) {
+	graphOpts := &optstackgraph.Options{}
+	for _, o := range opts {
+		o.ApplyOption(graphOpts)
+	}
+
+	// Stacks run in parallel, but the options for each stack are created one at a time.
+	var m sync.Mutex
+	return g.run(ctx, graphOpts, func(ctx context.Context, s *Stack, res *StackGraphStackResult) error {
+		var previewOpts []optpreview.Option
+		if graphOpts.PreviewOptions != nil {
+			m.Lock()
+			previewOpts = graphOpts.PreviewOptions(s.Name())
+			m.Unlock()
+		}
+		preview, err := s.Preview(ctx, previewOpts...)
+		if err != nil {
+			return err
+		}
+		res.Preview = &preview
+		return nil
+	})
+}
+
+// stackGraphOp performs an operation on a single stack, recording any operation-specific result in res.
+type stackGraphOp func(ctx context.Context, s *Stack, res *StackGraphStackResult) error
+
+// run schedules op on every stack in the graph. A stack is started once all of its dependencies have finished,
+// with at most opts.Parallel stacks running at once. Stacks that are ready at the same time are started in
+// topological order.
+func (g *StackGraph) run(
+	ctx context.Context,
+	opts *optstackgraph.Options,
+	op stackGraphOp,
+) (StackGraphResult, error) {
+	order, err := g.Order()
+	if err != nil {
+		return StackGraphResult{}, err
+	}
+
+	parallel := opts.Parallel
+	if parallel <= 0 || parallel > len(order) {
+		parallel = len(order)
+	}
+
+	index := make(map[string]int, len(order))
+	waiting := make(map[string]int, len(order))
+	dependents := make(map[string][]string, len(order))
+	results := make([]StackGraphStackResult, len(order))
+	var ready []string
+	for i, name := range order {
+		index[name] = i
+		results[i].Name = name
+		waiting[name] = len(g.deps[name])
+		for dep := range g.deps[name] {
+			dependents[dep] = append(dependents[dep], name)
+		}
+		if waiting[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	// finish marks the named stack as finished and makes any dependents whose dependencies are all finished ready.
+	finished := 0
+	finish := func(name string) {
+		finished++
+		for _, dependent := range dependents[name] {
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	// blocked returns true if any of the named stack's dependencies failed or were skipped.
+	blocked := func(name string) bool {
+		for dep := range g.deps[name] {
+			if results[index[dep]].Status != StackSucceeded {
+				return true
+			}
+		}
+		return false
+	}
+
+	type completion struct {
+		index int
+		err   error
+	}
+	completions := make(chan completion)
+	running, stopped := 0, false
+	for finished < len(order) {
+		if ctx.Err() != nil {
+			stopped = true
+		}
+
+		sort.Slice(ready, func(i, j int) bool { return index[ready[i]] < index[ready[j]] })
+		for len(ready) > 0 {
+			name := ready[0]
+			i := index[name]
+			if stopped || (opts.FailurePolicy != optstackgraph.ContinueOnFailure && blocked(name)) {
+				ready = ready[1:]
+				results[i].Status = StackSkipped
+				finish(name)
+				continue
+			}
+			if running >= parallel {
+				break
+			}
+
+			ready, running = ready[1:], running+1
+			go func(i int, s *Stack) {
+				completions <- completion{index: i, err: op(ctx, s, &results[i])}
+			}(i, g.stacks[name])
+		}
+
+		if running == 0 {
+			contract.Assertf(finished == len(order), "no stacks are running but %d are unfinished",
+				len(order)-finished)
+			break
+		}
+
+		c := <-completions
+		running--
+		if c.err != nil {
+			results[c.index].Status, results[c.index].Err = StackFailed, c.err
+			if opts.FailurePolicy == optstackgraph.StopOnFailure {
+				stopped = true
+			}
+		} else {
+			results[c.index].Status = StackSucceeded
+		}
+		finish(order[c.index])
+	}
+
+	res := StackGraphResult{Stacks: results}
+	var failures []string
+	for _, r := range results {
+		if r.Status == StackFailed {
+			failures = append(failures, fmt.Sprintf("%s: %v", r.Name, r.Err))
+		}
+	}
+	if len(failures) > 0 {
+		return res, fmt.Errorf("operation failed on %d stack(s):\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// names returns the names of the stacks in the graph, sorted by name.
+func (g *StackGraph) names() []string {
+	names := make([]string, 0, len(g.stacks))
+	for name := range g.stacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveStackReference returns the name of the stack in the graph that the given StackReference name refers to,
+// or the empty string if it refers to a stack outside of the graph.
+func (g *StackGraph) resolveStackReference(ref string) (string, error) {
+	var matches []string
+	for _, name := range g.names() {
+		if stackNamesMatch(name, ref) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("stack reference %q is ambiguous, it matches stacks %s",
+			ref, strings.Join(matches, ", "))
+	}
+}
+
+// stackNamesMatch returns true if the two stack names refer to the same stack, treating names that are not
+// fully qualified (i.e. "stack" or "project/stack") as matching any organization and project.
+func stackNamesMatch(a, b string) bool {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	n := len(as)
+	if len(bs) < n {
+		n = len(bs)
+	}
+	for i := 1; i <= n; i++ {
+		if as[len(as)-i] != bs[len(bs)-i] {
+			return false
+		}
+	}
+	return true
+}
+
+// stackReferences returns the names of the stacks referenced by StackReference resources in the given state.
+func stackReferences(state apitype.UntypedDeployment) ([]string, error) {
+	if len(state.Deployment) == 0 {
+		return nil, nil
+	}
+
+	var deployment apitype.DeploymentV3
+	if err := json.Unmarshal(state.Deployment, &deployment); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal stack state: %w", err)
+	}
+
+	var refs []string
+	for _, res := range deployment.Resources {
+		if string(res.Type) != stackReferenceType || res.Delete {
+			continue
+		}
+		if name, ok := res.Inputs["name"].(string); ok && name != "" {
+			refs = append(refs, name)
+		} else if res.ID != "" {
+			refs = append(refs, string(res.ID))
+		}
+	}
+	return refs, nil
+}
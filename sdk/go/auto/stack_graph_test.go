@@ -0,0 +1,313 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optremove"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstackgraph"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optup"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// newTestStackGraph creates a graph of workspace-less stacks with the given dependencies.
+func newTestStackGraph(t *testing.T, deps map[string][]string) *StackGraph {
+	var stacks []Stack
+	for name := range deps {
+		stacks = append(stacks, Stack{stackName: name})
+	}
+	g, err := NewStackGraph(stacks...)
+	require.NoError(t, err)
+	for name, ds := range deps {
+		for _, d := range ds {
+			require.NoError(t, g.AddDependency(name, d))
+		}
+	}
+	return g
+}
+
+func TestStackGraphOrder(t *testing.T) {
+	t.Parallel()
+
+	g := newTestStackGraph(t, map[string][]string{
+		"app":     {"network", "db"},
+		"db":      {"network"},
+		"network": nil,
+		"dns":     nil,
+	})
+	order, err := g.Order()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"network", "db", "app", "dns"}, order)
+
+	require.NoError(t, g.AddDependency("network", "app"))
+	_, err = g.Order()
+	assert.ErrorContains(t, err, "cycle")
+
+	assert.Error(t, g.AddDependency("app", "app"))
+	assert.Error(t, g.AddDependency("app", "missing"))
+
+	_, err = NewStackGraph(Stack{stackName: "a"}, Stack{stackName: "a"})
+	assert.Error(t, err)
+}
+
+func TestStackGraphRun(t *testing.T) {
+	t.Parallel()
+
+	deps := map[string][]string{
+		"a": nil,
+		"b": {"a"},
+		"c": {"b"},
+		"d": nil,
+		"e": {"d"},
+	}
+
+	tests := []struct {
+		name   string
+		policy optstackgraph.FailurePolicy
+		fail   string
+		want   map[string]StackGraphStatus
+	}{
+		{
+			name: "success",
+			want: map[string]StackGraphStatus{
+				"a": StackSucceeded, "b": StackSucceeded, "c": StackSucceeded, "d": StackSucceeded, "e": StackSucceeded,
+			},
+		},
+		{
+			name:   "skip dependents",
+			policy: optstackgraph.SkipDependents,
+			fail:   "a",
+			want: map[string]StackGraphStatus{
+				"a": StackFailed, "b": StackSkipped, "c": StackSkipped, "d": StackSucceeded, "e": StackSucceeded,
+			},
+		},
+		{
+			name:   "continue on failure",
+			policy: optstackgraph.ContinueOnFailure,
+			fail:   "b",
+			want: map[string]StackGraphStatus{
+				"a": StackSucceeded, "b": StackFailed, "c": StackSucceeded, "d": StackSucceeded, "e": StackSucceeded,
+			},
+		},
+	}
+
+	//nolint:paralleltest // false positive because range var isn't used directly in t.Run(name) arg
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			g := newTestStackGraph(t, deps)
+
+			var m sync.Mutex
+			done := map[string]bool{}
+			res, err := g.run(context.Background(), &optstackgraph.Options{FailurePolicy: tt.policy},
+				func(ctx context.Context, s *Stack, _ *StackGraphStackResult) error {
+					m.Lock()
+					defer m.Unlock()
+					for _, dep := range g.Dependencies(s.Name()) {
+						if tt.policy != optstackgraph.ContinueOnFailure {
+							assert.True(t, done[dep], "%v ran before its dependency %v", s.Name(), dep)
+						}
+					}
+					done[s.Name()] = true
+					if s.Name() == tt.fail {
+						return errors.New("boom")
+					}
+					return nil
+				})
+			if tt.fail != "" {
+				assert.ErrorContains(t, err, "boom")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			require.Len(t, res.Stacks, len(deps))
+			for name, status := range tt.want {
+				r, ok := res.Get(name)
+				require.True(t, ok)
+				assert.Equal(t, status, r.Status, name)
+			}
+		})
+	}
+}
+
+func TestStackGraphRunStopOnFailure(t *testing.T) {
+	t.Parallel()
+
+	g := newTestStackGraph(t, map[string][]string{
+		"a": nil,
+		"b": {"a"},
+		"c": nil,
+	})
+
+	var ran []string
+	res, err := g.run(context.Background(), &optstackgraph.Options{Parallel: 1},
+		func(ctx context.Context, s *Stack, _ *StackGraphStackResult) error {
+			ran = append(ran, s.Name())
+			return errors.New("boom")
+		})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"a"}, ran)
+
+	for name, status := range map[string]StackGraphStatus{"a": StackFailed, "b": StackSkipped, "c": StackSkipped} {
+		r, ok := res.Get(name)
+		require.True(t, ok)
+		assert.Equal(t, status, r.Status, name)
+	}
+}
+
+func TestStackGraphRunParallel(t *testing.T) {
+	t.Parallel()
+
+	g := newTestStackGraph(t, map[string][]string{
+		"a": nil, "b": nil, "c": nil, "d": nil, "e": nil, "f": nil,
+	})
+
+	var m sync.Mutex
+	running, maxRunning := 0, 0
+	_, err := g.run(context.Background(), &optstackgraph.Options{Parallel: 2},
+		func(ctx context.Context, s *Stack, _ *StackGraphStackResult) error {
+			m.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			m.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			m.Lock()
+			running--
+			m.Unlock()
+			return nil
+		})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, maxRunning, 2)
+}
+
+func TestStackReferences(t *testing.T) {
+	t.Parallel()
+
+	deployment, err := json.Marshal(apitype.DeploymentV3{
+		Resources: []apitype.ResourceV3{
+			{Type: "pulumi:pulumi:Stack", URN: "urn:pulumi:app::proj::pulumi:pulumi:Stack::proj-app"},
+			{
+				Type:   stackReferenceType,
+				ID:     "org/network/prod",
+				Inputs: map[string]interface{}{"name": "org/network/prod"},
+			},
+			{Type: stackReferenceType, ID: "org/db/prod"},
+		},
+	})
+	require.NoError(t, err)
+
+	refs, err := stackReferences(apitype.UntypedDeployment{Version: 3, Deployment: deployment})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org/network/prod", "org/db/prod"}, refs)
+
+	refs, err = stackReferences(apitype.UntypedDeployment{})
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+
+	g := newTestStackGraph(t, map[string][]string{
+		"org/network/prod": nil,
+		"db/prod":          nil,
+		"org/db/dev":       nil,
+	})
+	dep, err := g.resolveStackReference("org/network/prod")
+	require.NoError(t, err)
+	assert.Equal(t, "org/network/prod", dep)
+	dep, err = g.resolveStackReference("org/db/prod")
+	require.NoError(t, err)
+	assert.Equal(t, "db/prod", dep)
+	dep, err = g.resolveStackReference("other/app/prod")
+	require.NoError(t, err)
+	assert.Equal(t, "", dep)
+	_, err = g.resolveStackReference("prod")
+	assert.ErrorContains(t, err, "ambiguous")
+}
+
+func TestStackGraphUpEventStreams(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var stacks []Stack
+	for i := 0; i < 2; i++ {
+		stackName := FullyQualifiedStackName(pulumiOrg, pName, randomStackName())
+		s, err := NewStackInlineSource(ctx, stackName, pName, func(ctx *pulumi.Context) error {
+			ctx.Export("exp_static", pulumi.String("foo"))
+			return nil
+		})
+		require.NoError(t, err)
+		defer func() {
+			// -- pulumi stack rm --
+			err := s.Workspace().RemoveStack(ctx, s.Name(), optremove.Force())
+			assert.Nil(t, err, "failed to remove stack. Resources have leaked.")
+		}()
+		stacks = append(stacks, s)
+	}
+	g, err := NewStackGraph(stacks...)
+	require.NoError(t, err)
+
+	// Every stack gets its own event channel and progress writer, as the stacks run at the same time and each
+	// operation closes its channels once it completes.
+	var wg sync.WaitGroup
+	summaries := map[string]*bool{}
+	progress := map[string]*bytes.Buffer{}
+	upOptions := func(stackName string) []optup.Option {
+		ch, summary := make(chan events.EngineEvent), false
+		summaries[stackName] = &summary
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range ch {
+				if e.SummaryEvent != nil {
+					summary = true
+				}
+			}
+		}()
+
+		progress[stackName] = &bytes.Buffer{}
+		return []optup.Option{optup.EventStreams(ch), optup.ProgressStreams(progress[stackName])}
+	}
+
+	res, err := g.Up(ctx, optstackgraph.Parallel(2), optstackgraph.UpOptions(upOptions))
+	require.NoError(t, err)
+	wg.Wait()
+
+	require.Len(t, res.Stacks, 2)
+	for _, s := range stacks {
+		r, ok := res.Get(s.Name())
+		require.True(t, ok)
+		assert.Equal(t, StackSucceeded, r.Status)
+		require.NotNil(t, r.Up)
+		assert.Equal(t, "foo", r.Up.Outputs["exp_static"].Value)
+		assert.True(t, *summaries[s.Name()], "expected a summary event for %s", s.Name())
+		assert.Contains(t, progress[s.Name()].String(), "Updating")
+	}
+}
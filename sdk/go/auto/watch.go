@@ -0,0 +1,219 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optpreview"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optup"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optwatch"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// WatchResult is the outcome of a single iteration of Stack.Watch.
+type WatchResult struct {
+	// Iteration is the number of the iteration, starting at 1 for the run made when watching starts.
+	Iteration int
+	// ChangedPaths are the files that were added, modified or removed since the previous iteration.
+	// It is empty for the first iteration.
+	ChangedPaths []string
+	// Up is the result of the update, unless the watch is preview-only.
+	Up *UpResult
+	// Preview is the result of the preview if the watch is preview-only.
+	Preview *PreviewResult
+	// Err is the error returned by the operation, if any. An iteration failing does not stop the watch.
+	Err error
+}
+
+// ignoredWatchDirs are directories that are never descended into when looking for changes.
+var ignoredWatchDirs = map[string]bool{
+	".git":         true,
+	".pulumi":      true,
+	"node_modules": true,
+	"__pycache__":  true,
+	"venv":         true,
+}
+
+// ignoredWatchFile matches the names of stack settings files, such as Pulumi.dev.yaml, which Pulumi itself may
+// write during an operation. Changes to them would otherwise trigger another iteration.
+var ignoredWatchFile = regexp.MustCompile(`^Pulumi\..+\.(yaml|yml|json)$`)
+
+// Watch runs an update (or a preview, with optwatch.PreviewOnly) immediately and then again every time a file
+// in the watched paths changes, until ctx is canceled. The result of each iteration is sent on the returned
+// channel, which is closed once watching stops. Changes are detected by polling at optwatch.Interval.
+// Changes made while an operation is running trigger another iteration once it completes.
+//
+// Within watched directories, stack settings files (Pulumi.<stack>.yaml) and the .git, .pulumi, node_modules,
+// __pycache__ and venv directories are ignored, as they change without the program changing. Pass them to
+// optwatch.Paths explicitly to watch them.
+func (s *Stack) Watch(ctx context.Context, opts ...optwatch.Option) (<-chan WatchResult, error) {
+	watchOpts := &optwatch.Options{}
+	for _, o := range opts {
+		o.ApplyOption(watchOpts)
+	}
+
+	paths := make([]string, 0, len(watchOpts.Paths))
+	for _, p := range watchOpts.Paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(s.Workspace().WorkDir(), p)
+		}
+		paths = append(paths, p)
+	}
+	if len(paths) == 0 {
+		paths = []string{s.Workspace().WorkDir()}
+	}
+
+	var session *PreviewSession
+	op := func(ctx context.Context, res *WatchResult) {
+		var upOpts []optup.Option
+		if watchOpts.UpOptions != nil {
+			upOpts = watchOpts.UpOptions(res.Iteration)
+		}
+		up, err := s.Up(ctx, upOpts...)
+		res.Up, res.Err = &up, err
+	}
+	if watchOpts.PreviewOnly {
+		var err error
+		session, err = s.NewPreviewSession(ctx, watchOpts.SessionOptions...)
+		if err != nil {
+			return nil, err
+		}
+		op = func(ctx context.Context, res *WatchResult) {
+			var previewOpts []optpreview.Option
+			if watchOpts.PreviewOptions != nil {
+				previewOpts = watchOpts.PreviewOptions(res.Iteration)
+			}
+			preview, err := session.Preview(ctx, previewOpts...)
+			res.Preview, res.Err = &preview, err
+		}
+	}
+
+	interval := watchOpts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	results := make(chan WatchResult)
+	go func() {
+		defer close(results)
+		if session != nil {
+			defer contract.IgnoreClose(session)
+		}
+		watchLoop(ctx, interval, func() watchSnapshot { return snapshotPaths(paths) }, op, results)
+	}()
+	return results, nil
+}
+
+// watchSnapshot fingerprints a set of files by their modification time and size.
+type watchSnapshot map[string]watchFingerprint
+
+type watchFingerprint struct {
+	modTime int64
+	size    int64
+}
+
+// snapshotPaths walks the given paths and fingerprints every file beneath them, except for ignored directories and
+// files. Files that cannot be read are ignored.
+func snapshotPaths(paths []string) watchSnapshot {
+	snap := watchSnapshot{}
+	for _, root := range paths {
+		//nolint:errcheck // unreadable files are skipped by the walk function
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != root && ignoredWatchDirs[d.Name()] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if path != root && ignoredWatchFile.MatchString(d.Name()) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			snap[path] = watchFingerprint{modTime: info.ModTime().UnixNano(), size: info.Size()}
+			return nil
+		})
+	}
+	return snap
+}
+
+// changedPaths returns the sorted list of files that differ between two snapshots.
+func (s watchSnapshot) changedPaths(prev watchSnapshot) []string {
+	var changed []string
+	for path, fp := range s {
+		if old, ok := prev[path]; !ok || old != fp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := s[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// watchLoop runs op once and then each time snapshot reports a change, sending the results on results, until ctx
+// is canceled. The snapshot is taken before op runs so that changes made during the operation are not missed.
+func watchLoop(
+	ctx context.Context,
+	interval time.Duration,
+	snapshot func() watchSnapshot,
+	op func(context.Context, *WatchResult),
+	results chan<- WatchResult,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := snapshot()
+	var changed []string
+	for iteration := 1; ; iteration++ {
+		res := WatchResult{Iteration: iteration, ChangedPaths: changed}
+		op(ctx, &res)
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case results <- res:
+		case <-ctx.Done():
+			return
+		}
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			next := snapshot()
+			if changed = next.changedPaths(prev); len(changed) > 0 {
+				prev = next
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2016-2023, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optremove"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optup"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optwatch"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func TestSnapshotPaths(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	write("main.go", "package main")
+	write("Pulumi.yaml", "name: test")
+	write("node_modules/dep/index.js", "")
+	write(".git/HEAD", "")
+	write(".pulumi/stacks/dev.json", "")
+	write("__pycache__/main.pyc", "")
+	write("Pulumi.dev.yaml", "config: {}")
+
+	before := snapshotPaths([]string{dir})
+	assert.Len(t, before, 2)
+
+	write("main.go", "package main // changed")
+	write("util/util.go", "package util")
+	require.NoError(t, os.Remove(filepath.Join(dir, "Pulumi.yaml")))
+	write("node_modules/dep/other.js", "")
+	write("Pulumi.dev.yaml", "config: {changed: true}")
+	write(".pulumi/stacks/dev.json", "{}")
+
+	after := snapshotPaths([]string{dir})
+	assert.Equal(t, []string{
+		filepath.Join(dir, "Pulumi.yaml"),
+		filepath.Join(dir, "main.go"),
+		filepath.Join(dir, "util", "util.go"),
+	}, after.changedPaths(before))
+	assert.Empty(t, after.changedPaths(after))
+
+	// Ignored files are watched when they are listed explicitly.
+	assert.Len(t, snapshotPaths([]string{filepath.Join(dir, "Pulumi.dev.yaml")}), 1)
+}
+
+func TestWatchLoop(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Each snapshot reports a change to a file whose fingerprint is set by the test.
+	var m sync.Mutex
+	version := int64(0)
+	snapshot := func() watchSnapshot {
+		m.Lock()
+		defer m.Unlock()
+		return watchSnapshot{"main.go": {size: version}}
+	}
+	touch := func() {
+		m.Lock()
+		defer m.Unlock()
+		version++
+	}
+
+	op := func(ctx context.Context, res *WatchResult) {
+		if res.Iteration == 2 {
+			res.Err = errors.New("boom")
+		}
+	}
+
+	results := make(chan WatchResult)
+	done := make(chan struct{})
+	go func() {
+		watchLoop(ctx, time.Millisecond, snapshot, op, results)
+		close(done)
+	}()
+
+	res := <-results
+	assert.Equal(t, 1, res.Iteration)
+	assert.Empty(t, res.ChangedPaths)
+	assert.NoError(t, res.Err)
+
+	touch()
+	res = <-results
+	assert.Equal(t, 2, res.Iteration)
+	assert.Equal(t, []string{"main.go"}, res.ChangedPaths)
+	assert.ErrorContains(t, res.Err, "boom")
+
+	// A failed iteration does not stop the watch.
+	touch()
+	res = <-results
+	assert.Equal(t, 3, res.Iteration)
+	assert.NoError(t, res.Err)
+
+	cancel()
+	<-done
+}
+
+func TestWatchEventStreams(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stackName := FullyQualifiedStackName(pulumiOrg, pName, randomStackName())
+	s, err := NewStackInlineSource(ctx, stackName, pName, func(ctx *pulumi.Context) error {
+		ctx.Export("exp_static", pulumi.String("foo"))
+		return nil
+	})
+	require.NoError(t, err)
+	defer func() {
+		// -- pulumi stack rm --
+		err := s.Workspace().RemoveStack(context.Background(), s.Name(), optremove.Force())
+		assert.Nil(t, err, "failed to remove stack. Resources have leaked.")
+	}()
+
+	// Every iteration gets its own event channel, as each update closes its channels once it completes.
+	var wg sync.WaitGroup
+	var m sync.Mutex
+	summaries := map[int]bool{}
+	upOptions := func(iteration int) []optup.Option {
+		ch := make(chan events.EngineEvent)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range ch {
+				if e.SummaryEvent != nil {
+					m.Lock()
+					summaries[iteration] = true
+					m.Unlock()
+				}
+			}
+		}()
+		return []optup.Option{optup.EventStreams(ch)}
+	}
+
+	results, err := s.Watch(ctx, optwatch.Interval(10*time.Millisecond), optwatch.UpOptions(upOptions))
+	require.NoError(t, err)
+
+	res := <-results
+	assert.Equal(t, 1, res.Iteration)
+	require.NoError(t, res.Err)
+
+	changed := filepath.Join(s.Workspace().WorkDir(), "main.txt")
+	require.NoError(t, os.WriteFile(changed, []byte("changed"), 0o600))
+	res = <-results
+	assert.Equal(t, 2, res.Iteration)
+	assert.Equal(t, []string{changed}, res.ChangedPaths)
+	require.NoError(t, res.Err)
+	assert.Equal(t, "foo", res.Up.Outputs["exp_static"].Value)
+
+	cancel()
+	for range results {
+		// Wait for the watch to stop before checking the events of its iterations.
+	}
+	wg.Wait()
+	assert.Equal(t, map[int]bool{1: true, 2: true}, summaries)
+}
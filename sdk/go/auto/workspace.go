@@ -17,7 +17,13 @@ package auto
 import (
 	"context"
 
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optchangesecretsprovider"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optimport"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optremove"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstackrename"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstate"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstaterename"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optstateunprotect"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 
@@ -114,6 +120,11 @@ type Workspace interface {
 	SelectStack(context.Context, string) error
 	// RemoveStack deletes the stack and all associated configuration and history.
 	RemoveStack(context.Context, string, ...optremove.Option) error
+	// RenameStack renames the stack matching the stack name to the new stack name, moving its configuration.
+	RenameStack(context.Context, string, string, ...optstackrename.Option) error
+	// ChangeSecretsProvider changes the secrets provider of the stack matching the stack name,
+	// re-encrypting its configuration and state with the new provider.
+	ChangeSecretsProvider(context.Context, string, string, ...optchangesecretsprovider.Option) error
 	// ListStacks returns all Stacks created under the current Project.
 	// This queries underlying backend and may return stacks not present in the Workspace.
 	ListStacks(context.Context) ([]StackSummary, error)
@@ -138,6 +149,18 @@ type Workspace interface {
 	ImportStack(context.Context, string, apitype.UntypedDeployment) error
 	// StackOutputs gets the current set of Stack outputs from the last Stack.Up().
 	StackOutputs(context.Context, string) (OutputMap, error)
+	// ImportResources imports existing resources into the stack matching the given name,
+	// returning the resource declaration code generated for them.
+	ImportResources(context.Context, string, ...optimport.Option) (ImportResult, error)
+	// StateDelete deletes the resource with the given URN from the state of the stack matching the given name.
+	StateDelete(context.Context, string, string, ...optstate.Option) error
+	// StateUnprotect removes deletion protection from the resource with the given URN
+	// in the stack matching the given name.
+	StateUnprotect(context.Context, string, string, ...optstateunprotect.Option) error
+	// StateUnprotectAll removes deletion protection from every resource in the stack matching the given name.
+	StateUnprotectAll(context.Context, string, ...optstateunprotect.Option) error
+	// StateRename renames the resource with the given URN in the stack matching the given name.
+	StateRename(context.Context, string, string, string, ...optstaterename.Option) error
 }
 
 // ConfigValue is a configuration value used by a Pulumi program.
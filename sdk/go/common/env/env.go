@@ -62,6 +62,9 @@ var IgnoreAmbientPlugins = env.Bool("IGNORE_AMBIENT_PLUGINS",
 var DisableAutomaticPluginAcquisition = env.Bool("DISABLE_AUTOMATIC_PLUGIN_ACQUISITION",
 	"Disables the automatic installation of missing plugins.")
 
+var DisableSchemaCache = env.Bool("DISABLE_SCHEMA_CACHE",
+	"Disables the cache of provider schemas that is shared by Pulumi processes.")
+
 var SkipConfirmations = env.Bool("SKIP_CONFIRMATIONS",
 	`Whether or not confirmation prompts should be skipped. This should be used by pass any requirement
 that a --yes parameter has been set for non-interactive scenarios.
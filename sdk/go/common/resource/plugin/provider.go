@@ -110,6 +110,11 @@ type Provider interface {
 	// error) if it doesn't have any mappings for the given key.
 	// If a provider implements this method GetMapping will be called using the results from this method.
 	GetMappings(key string) ([]string, error)
+
+	// DiscoverResources lists the existing resources of the given type, within the given provider-specific scope, so
+	// that they can be imported. A provider that does not support discovery for the type returns an error with the
+	// gRPC code Unimplemented.
+	DiscoverResources(typ tokens.Type, scope resource.PropertyMap) ([]DiscoveredResource, error)
 }
 
 type GrpcProvider interface {
@@ -343,6 +348,14 @@ type ReadResult struct {
 	Outputs resource.PropertyMap
 }
 
+// DiscoveredResource is a resource that was found by a call to DiscoverResources.
+type DiscoveredResource struct {
+	// The ID of the resource, as it would be passed to Read or import.
+	ID resource.ID
+	// A suggested name for the resource, if the provider has one. This may be empty.
+	Name string
+}
+
 // ConstructInfo contains all of the information required to register resources as part of a call to Construct.
 type ConstructInfo struct {
 	Project          string                // the project name housing the program being run.
@@ -1967,3 +1967,42 @@ func (p *provider) GetMappings(key string) ([]string, error) {
 	}
 	return resp.Providers, nil
 }
+
+// DiscoverResources lists the existing resources of the given type that this provider can find within the given
+// scope. Unlike GetMapping, an Unimplemented error is returned to the caller, so that it can tell a provider that
+// does not support discovery apart from one that found no resources.
+func (p *provider) DiscoverResources(typ tokens.Type, scope resource.PropertyMap) ([]DiscoveredResource, error) {
+	label := fmt.Sprintf("%s.DiscoverResources(%s)", p.label(), typ)
+	logging.V(7).Infof("%s executing (#scope=%d)", label, len(scope))
+
+	// Ensure that the plugin is configured.
+	pcfg, err := p.configSource.Promise().Result(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	mscope, err := MarshalProperties(scope, MarshalOptions{
+		Label:        label + ".scope",
+		KeepSecrets:  pcfg.acceptSecrets,
+		KeepUnknowns: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.clientRaw.DiscoverResources(p.requestContext(), &pulumirpc.DiscoverResourcesRequest{
+		Type:  string(typ),
+		Scope: mscope,
+	})
+	if err != nil {
+		logging.V(7).Infof("%s failed: %v", label, rpcerror.Convert(err))
+		return nil, err
+	}
+
+	resources := make([]DiscoveredResource, len(resp.GetResources()))
+	for i, r := range resp.GetResources() {
+		resources[i] = DiscoveredResource{ID: resource.ID(r.GetId()), Name: r.GetName()}
+	}
+	logging.V(7).Infof("%s success: #resources=%d", label, len(resources))
+	return resources, nil
+}
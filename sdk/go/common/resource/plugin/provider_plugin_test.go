@@ -17,6 +17,7 @@ import (
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/testing/diagtest"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
 )
 
@@ -610,6 +611,8 @@ type stubClient struct {
 	ConstructF  func(*pulumirpc.ConstructRequest) (*pulumirpc.ConstructResponse, error)
 	ConfigureF  func(*pulumirpc.ConfigureRequest) (*pulumirpc.ConfigureResponse, error)
 	DeleteF     func(*pulumirpc.DeleteRequest) error
+
+	DiscoverResourcesF func(*pulumirpc.DiscoverResourcesRequest) (*pulumirpc.DiscoverResourcesResponse, error)
 }
 
 func (c *stubClient) DiffConfig(
@@ -657,6 +660,63 @@ func (c *stubClient) Delete(
 	return c.ResourceProviderClient.Delete(ctx, req, opts...)
 }
 
+func (c *stubClient) DiscoverResources(
+	ctx context.Context,
+	req *pulumirpc.DiscoverResourcesRequest,
+	opts ...grpc.CallOption,
+) (*pulumirpc.DiscoverResourcesResponse, error) {
+	if f := c.DiscoverResourcesF; f != nil {
+		return f(req)
+	}
+	return c.ResourceProviderClient.DiscoverResources(ctx, req, opts...)
+}
+
+// Checks that DiscoverResources round-trips through the provider client and server once the provider is configured,
+// and that an Unimplemented error is passed through to the caller rather than being treated as an empty result.
+func TestProvider_DiscoverResources(t *testing.T) {
+	t.Parallel()
+
+	stub := &stubProvider{
+		DiscoverResourcesFunc: func(typ tokens.Type, scope resource.PropertyMap) ([]DiscoveredResource, error) {
+			if typ != "pkg:index:Thing" {
+				return nil, status.Errorf(codes.Unimplemented, "cannot discover %v", typ)
+			}
+			assert.Equal(t, resource.PropertyMap{"region": resource.NewStringProperty("west")}, scope)
+			return []DiscoveredResource{{ID: "thing-1", Name: "first"}, {ID: "thing-2"}}, nil
+		},
+	}
+	server := NewProviderServer(stub)
+	client := &stubClient{
+		DiscoverResourcesF: func(req *pulumirpc.DiscoverResourcesRequest) (*pulumirpc.DiscoverResourcesResponse, error) {
+			return server.DiscoverResources(context.Background(), req)
+		},
+		ConfigureF: func(req *pulumirpc.ConfigureRequest) (*pulumirpc.ConfigureResponse, error) {
+			// The provider does not accept secrets, so the secret scope below is sent as a plain value.
+			return &pulumirpc.ConfigureResponse{AcceptSecrets: false}, nil
+		},
+	}
+	p := NewProviderWithClient(newTestContext(t), "pkg", client, false /* disablePreview */)
+	require.NoError(t, p.Configure(resource.PropertyMap{}))
+
+	resources, err := p.DiscoverResources("pkg:index:Thing",
+		resource.PropertyMap{"region": resource.MakeSecret(resource.NewStringProperty("west"))})
+	require.NoError(t, err)
+	assert.Equal(t, []DiscoveredResource{{ID: "thing-1", Name: "first"}, {ID: "thing-2"}}, resources)
+
+	_, err = p.DiscoverResources("pkg:index:Other", nil)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// Providers are configured in the background, so an error configuring the provider is returned by
+	// DiscoverResources.
+	client.ConfigureF = func(*pulumirpc.ConfigureRequest) (*pulumirpc.ConfigureResponse, error) {
+		return nil, status.Error(codes.Unauthenticated, "bad credentials")
+	}
+	p = NewProviderWithClient(newTestContext(t), "pkg", client, false /* disablePreview */)
+	require.NoError(t, p.Configure(resource.PropertyMap{}))
+	_, err = p.DiscoverResources("pkg:index:Thing", nil)
+	assert.ErrorContains(t, err, "bad credentials")
+}
+
 // Test for https://github.com/pulumi/pulumi/issues/14529, ensure a kubernetes DiffConfig error is ignored
 func TestKubernetesDiffError(t *testing.T) {
 	t.Parallel()
@@ -664,3 +664,23 @@ func (p *providerServer) GetMappings(ctx context.Context,
 	}
 	return &pulumirpc.GetMappingsResponse{Providers: providers}, nil
 }
+
+func (p *providerServer) DiscoverResources(ctx context.Context,
+	req *pulumirpc.DiscoverResourcesRequest,
+) (*pulumirpc.DiscoverResourcesResponse, error) {
+	scope, err := UnmarshalProperties(req.GetScope(), p.unmarshalOptions("scope"))
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := p.provider.DiscoverResources(tokens.Type(req.GetType()), scope)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcResources := make([]*pulumirpc.DiscoverResourcesResponse_Resource, len(resources))
+	for i, r := range resources {
+		rpcResources[i] = &pulumirpc.DiscoverResourcesResponse_Resource{Id: string(r.ID), Name: r.Name}
+	}
+	return &pulumirpc.DiscoverResourcesResponse{Resources: rpcResources}, nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -51,6 +52,18 @@ type stubProvider struct {
 	) (ReadResult, resource.Status, error)
 
 	ConfigureFunc func(resource.PropertyMap) error
+
+	DiscoverResourcesFunc func(tokens.Type, resource.PropertyMap) ([]DiscoveredResource, error)
+}
+
+func (p *stubProvider) DiscoverResources(
+	typ tokens.Type,
+	scope resource.PropertyMap,
+) ([]DiscoveredResource, error) {
+	if p.DiscoverResourcesFunc != nil {
+		return p.DiscoverResourcesFunc(typ, scope)
+	}
+	return p.Provider.DiscoverResources(typ, scope)
 }
 
 func (p *stubProvider) Configure(inputs resource.PropertyMap) error {
@@ -105,3 +105,9 @@ func (p *UnimplementedProvider) GetMapping(key, provider string) ([]byte, string
 func (p *UnimplementedProvider) GetMappings(key string) ([]string, error) {
 	return nil, status.Error(codes.Unimplemented, "GetMappings is not yet implemented")
 }
+
+func (p *UnimplementedProvider) DiscoverResources(typ tokens.Type,
+	scope resource.PropertyMap,
+) ([]DiscoveredResource, error) {
+	return nil, status.Error(codes.Unimplemented, "DiscoverResources is not yet implemented")
+}
@@ -98,6 +98,53 @@ func validateStackConfigValue(
 		return validationError
 	}
 
+	if err := validateConfigSchema("", projectConfigType.ItemsType(), content); err != nil {
+		return fmt.Errorf("Stack '%v' with configuration key '%v%v' %v",
+			stackName,
+			projectConfigKey,
+			err.path,
+			err.message)
+	}
+
+	return nil
+}
+
+// ValidateStackConfigKeys validates the stack values of the given config keys against the types declared for them
+// in the project, so that invalid values can be rejected when they are written rather than when the stack is next
+// deployed. Keys that are not declared in the project, or that have no value on the stack, are not checked. For
+// paths into an object only the key's root value is validated, since that is what the project declares.
+func ValidateStackConfigKeys(
+	stackName string,
+	project *Project,
+	stackConfig config.Map,
+	keys []config.Key,
+	dec config.Decrypter,
+) error {
+	projectName := project.Name.String()
+	declared := make(map[config.Key]string, len(project.Config))
+	for projectConfigKey := range project.Config {
+		key, err := parseConfigKey(projectName, projectConfigKey)
+		if err != nil {
+			return err
+		}
+		declared[key] = projectConfigKey
+	}
+
+	for _, key := range keys {
+		projectConfigKey, ok := declared[key]
+		if !ok {
+			continue
+		}
+		projectConfigType := project.Config[projectConfigKey]
+		stackValue, ok := stackConfig[key]
+		if !ok || !projectConfigType.IsExplicitlyTyped() {
+			continue
+		}
+		err := validateStackConfigValue(stackName, projectConfigKey, projectConfigType, stackValue, dec)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -23,6 +23,8 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -44,6 +46,7 @@ const (
 	integerTypeName = "integer"
 	stringTypeName  = "string"
 	booleanTypeName = "boolean"
+	objectTypeName  = "object"
 )
 
 //go:embed project.json
@@ -117,6 +120,18 @@ type Plugins struct {
 type ProjectConfigItemsType struct {
 	Type  string                  `json:"type,omitempty" yaml:"type,omitempty"`
 	Items *ProjectConfigItemsType `json:"items,omitempty" yaml:"items,omitempty"`
+
+	// Enum is an optional list of the values this item may take.
+	Enum []interface{} `json:"enum,omitempty" yaml:"enum,omitempty"`
+	// Pattern is an optional regular expression that string items must match.
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// Minimum and Maximum are optional inclusive bounds for integer items.
+	Minimum *float64 `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	// Properties declares the types of the properties of object items.
+	Properties map[string]*ProjectConfigItemsType `json:"properties,omitempty" yaml:"properties,omitempty"`
+	// Required lists the properties that object items must have.
+	Required []string `json:"required,omitempty" yaml:"required,omitempty"`
 }
 
 type ProjectConfigType struct {
@@ -126,6 +141,18 @@ type ProjectConfigType struct {
 	Default     interface{}             `json:"default,omitempty" yaml:"default,omitempty"`
 	Value       interface{}             `json:"value,omitempty" yaml:"value,omitempty"`
 	Secret      bool                    `json:"secret,omitempty" yaml:"secret,omitempty"`
+
+	// Enum is an optional list of the values this config key may take.
+	Enum []interface{} `json:"enum,omitempty" yaml:"enum,omitempty"`
+	// Pattern is an optional regular expression that string values must match.
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// Minimum and Maximum are optional inclusive bounds for integer values.
+	Minimum *float64 `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	// Properties declares the types of the properties of object values.
+	Properties map[string]*ProjectConfigItemsType `json:"properties,omitempty" yaml:"properties,omitempty"`
+	// Required lists the properties that object values must have.
+	Required []string `json:"required,omitempty" yaml:"required,omitempty"`
 }
 
 // IsExplicitlyTyped returns whether the project config type is explicitly typed.
@@ -143,6 +170,21 @@ func (configType *ProjectConfigType) TypeName() string {
 	return ""
 }
 
+// ItemsType returns the schema of the config type as a ProjectConfigItemsType, so that top-level values and nested
+// items can be validated the same way.
+func (configType *ProjectConfigType) ItemsType() *ProjectConfigItemsType {
+	return &ProjectConfigItemsType{
+		Type:       configType.TypeName(),
+		Items:      configType.Items,
+		Enum:       configType.Enum,
+		Pattern:    configType.Pattern,
+		Minimum:    configType.Minimum,
+		Maximum:    configType.Maximum,
+		Properties: configType.Properties,
+		Required:   configType.Required,
+	}
+}
+
 // Project is a Pulumi project manifest.
 //
 // We explicitly add yaml tags (instead of using the default behavior from https://github.com/ghodss/yaml which works
@@ -431,6 +473,11 @@ func ValidateConfigValue(typeName string, itemsType *ProjectConfigItemsType, val
 		return ok
 	}
 
+	if typeName == objectTypeName {
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+
 	items, isArray := value.([]interface{})
 
 	if !isArray || itemsType == nil {
@@ -449,6 +496,119 @@ func ValidateConfigValue(typeName string, itemsType *ProjectConfigItemsType, val
 	return true
 }
 
+// configSchemaError describes a config value that does not satisfy its config type definition. Path is the location
+// of the offending value relative to the config key, e.g. ".servers[0].port", and is empty for the value itself.
+type configSchemaError struct {
+	path    string
+	message string
+}
+
+func (err *configSchemaError) Error() string {
+	if err.path == "" {
+		return err.message
+	}
+	return fmt.Sprintf("%s %s", strings.TrimPrefix(err.path, "."), err.message)
+}
+
+// validateConfigSchema validates a config value against the type and constraints of its config type definition,
+// descending into the items of arrays and the properties of objects. It returns the first violation found.
+func validateConfigSchema(path string, configType *ProjectConfigItemsType, value interface{}) *configSchemaError {
+	errorf := func(path, format string, args ...interface{}) *configSchemaError {
+		return &configSchemaError{path: path, message: fmt.Sprintf(format, args...)}
+	}
+
+	if configType.Type != "" && !ValidateConfigValue(configType.Type, configType.Items, value) {
+		return errorf(path, "must be of type '%v'", InferFullTypeName(configType.Type, configType.Items))
+	}
+
+	if len(configType.Enum) > 0 {
+		// Values set from the command line are strings, so compare the text of scalar values.
+		_, isObject := value.(map[string]interface{})
+		found := false
+		allowed := make([]string, len(configType.Enum))
+		for i, e := range configType.Enum {
+			allowed[i] = fmt.Sprintf("%v", e)
+			if !isObject && !isArray(value) && allowed[i] == fmt.Sprintf("%v", value) {
+				found = true
+			}
+		}
+		if !found {
+			return errorf(path, "must be one of '%v'", strings.Join(allowed, "', '"))
+		}
+	}
+
+	if configType.Pattern != "" {
+		if s, ok := value.(string); ok {
+			re, err := regexp.Compile(configType.Pattern)
+			if err != nil {
+				return errorf(path, "has an invalid pattern '%v': %v", configType.Pattern, err)
+			}
+			if !re.MatchString(s) {
+				return errorf(path, "must match the pattern '%v'", configType.Pattern)
+			}
+		}
+	}
+
+	if configType.Minimum != nil || configType.Maximum != nil {
+		if n, ok := configNumber(value); ok {
+			if configType.Minimum != nil && n < *configType.Minimum {
+				return errorf(path, "must be at least %v", *configType.Minimum)
+			}
+			if configType.Maximum != nil && n > *configType.Maximum {
+				return errorf(path, "must be at most %v", *configType.Maximum)
+			}
+		}
+	}
+
+	switch value := value.(type) {
+	case []interface{}:
+		if configType.Items != nil {
+			for i, item := range value {
+				if err := validateConfigSchema(fmt.Sprintf("%s[%d]", path, i), configType.Items, item); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range configType.Required {
+			if _, ok := value[name]; !ok {
+				return errorf(path, "is missing the required property '%v'", name)
+			}
+		}
+		names := make([]string, 0, len(configType.Properties))
+		for name := range configType.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propertyType := configType.Properties[name]
+			if property, ok := value[name]; ok && propertyType != nil {
+				if err := validateConfigSchema(path+"."+name, propertyType, property); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// configNumber returns the numeric value of an integer config value, which may be a string if it came from the
+// command line.
+func configNumber(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case int:
+		return float64(value), true
+	case float64:
+		return value, true
+	case string:
+		n, err := strconv.ParseFloat(value, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
 func configKeyIsNamespacedByProject(projectName string, configKey string) bool {
 	return !strings.Contains(configKey, ":") || strings.HasPrefix(configKey, projectName+":")
 }
@@ -476,6 +636,15 @@ func (proj *Project) Validate() error {
 					"but does not specify the underlying type via the 'items' attribute", configKey)
 			}
 
+			if configType.IsExplicitlyTyped() && configType.TypeName() == objectTypeName && configType.Items != nil {
+				return fmt.Errorf("The configuration key '%v' declares an object "+
+					"and must specify its properties via the 'properties' attribute rather than 'items'", configKey)
+			}
+
+			if err := validateConfigPatterns(configKey, configType.ItemsType()); err != nil {
+				return err
+			}
+
 			// when we have a config _type_ with a schema
 			if configType.IsExplicitlyTyped() && configType.Default != nil {
 				if !ValidateConfigValue(configTypeName, configType.Items, configType.Default) {
@@ -484,6 +653,10 @@ func (proj *Project) Validate() error {
 						configKey,
 						inferredTypeName)
 				}
+				if err := validateConfigSchema("", configType.ItemsType(), configType.Default); err != nil {
+					return fmt.Errorf("The default value specified for configuration key '%v%v' %v",
+						configKey, err.path, err.message)
+				}
 			}
 
 		} else {
@@ -511,6 +684,28 @@ func (proj *Project) Validate() error {
 	return nil
 }
 
+// validateConfigPatterns checks that every pattern in a config type definition is a valid regular expression.
+func validateConfigPatterns(path string, configType *ProjectConfigItemsType) error {
+	if configType == nil {
+		return nil
+	}
+	if configType.Pattern != "" {
+		if _, err := regexp.Compile(configType.Pattern); err != nil {
+			return fmt.Errorf("The configuration key '%v' declares an invalid pattern '%v': %w",
+				path, configType.Pattern, err)
+		}
+	}
+	if err := validateConfigPatterns(path+"[]", configType.Items); err != nil {
+		return err
+	}
+	for name, propertyType := range configType.Properties {
+		if err := validateConfigPatterns(path+"."+name, propertyType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // TrustResourceDependencies returns whether this project's runtime can be trusted to accurately report
 // dependencies. All languages supported by Pulumi today do this correctly. This option remains useful when bringing
 // up new Pulumi languages.
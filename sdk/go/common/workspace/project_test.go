@@ -808,6 +808,197 @@ config:
 		"Stack 'dev' with configuration key 'importantNumber' must be of type 'integer'")
 }
 
+func TestStackConfigConstraintsAreValidated(t *testing.T) {
+	t.Parallel()
+	projectYaml := `
+name: test
+runtime: dotnet
+config:
+  size:
+    type: string
+    enum: [small, large]
+    default: small
+  region:
+    type: string
+    pattern: ^[a-z]+-[a-z]+-[0-9]$
+    default: us-west-2
+  replicas:
+    type: integer
+    minimum: 1
+    maximum: 5
+    default: 1
+  database:
+    type: object
+    required: [host]
+    properties:
+      host:
+        type: string
+      port:
+        type: integer
+        minimum: 1024
+    default:
+      host: localhost
+`
+
+	project, projectError := loadProjectFromText(t, projectYaml)
+	require.NoError(t, projectError, "Should be able to load the project")
+
+	tests := []struct {
+		name     string
+		stack    string
+		expected string
+	}{
+		{
+			name: "valid",
+			stack: `
+config:
+  test:size: large
+  test:region: eu-west-1
+  test:replicas: 5
+  test:database:
+    host: db.internal
+    port: 5432
+`,
+		},
+		{
+			name:     "enum",
+			stack:    "config:\n  test:size: medium\n",
+			expected: "Stack 'dev' with configuration key 'size' must be one of 'small', 'large'",
+		},
+		{
+			name:     "pattern",
+			stack:    "config:\n  test:region: moon\n",
+			expected: "Stack 'dev' with configuration key 'region' must match the pattern '^[a-z]+-[a-z]+-[0-9]$'",
+		},
+		{
+			name:     "minimum",
+			stack:    "config:\n  test:replicas: 0\n",
+			expected: "Stack 'dev' with configuration key 'replicas' must be at least 1",
+		},
+		{
+			name:     "maximum",
+			stack:    "config:\n  test:replicas: 6\n",
+			expected: "Stack 'dev' with configuration key 'replicas' must be at most 5",
+		},
+		{
+			name:     "required",
+			stack:    "config:\n  test:database:\n    port: 5432\n",
+			expected: "Stack 'dev' with configuration key 'database' is missing the required property 'host'",
+		},
+		{
+			name:     "nested type",
+			stack:    "config:\n  test:database:\n    host: db\n    port: http\n",
+			expected: "Stack 'dev' with configuration key 'database.port' must be of type 'integer'",
+		},
+		{
+			name:     "nested constraint",
+			stack:    "config:\n  test:database:\n    host: db\n    port: 80\n",
+			expected: "Stack 'dev' with configuration key 'database.port' must be at least 1024",
+		},
+		{
+			name:     "object type",
+			stack:    "config:\n  test:database: db.internal\n",
+			expected: "Stack 'dev' with configuration key 'database' must be of type 'object'",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stack, stackError := loadProjectStackFromText(t, project, tt.stack)
+			require.NoError(t, stackError, "Should be able to read the stack")
+			configError := ValidateStackConfigAndApplyProjectConfig(
+				"dev",
+				project,
+				esc.Value{},
+				stack.Config,
+				config.NewPanicCrypter(),
+				config.NewPanicCrypter())
+			if tt.expected == "" {
+				assert.NoError(t, configError)
+			} else {
+				assert.EqualError(t, configError, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProjectConfigDefaultMustSatisfyConstraints(t *testing.T) {
+	t.Parallel()
+	projectYaml := `
+name: test
+runtime: dotnet
+config:
+  size:
+    type: string
+    enum: [small, large]
+    default: medium
+`
+
+	project, projectError := loadProjectFromText(t, projectYaml)
+	assert.Nil(t, project, "Should NOT be able to load the project")
+	assert.ErrorContains(t, projectError,
+		"The default value specified for configuration key 'size' must be one of 'small', 'large'")
+}
+
+func TestProjectConfigInvalidPatternErrorsOut(t *testing.T) {
+	t.Parallel()
+	projectYaml := `
+name: test
+runtime: dotnet
+config:
+  names:
+    type: array
+    items:
+      type: string
+      pattern: "[a-z"
+`
+
+	project, projectError := loadProjectFromText(t, projectYaml)
+	assert.Nil(t, project, "Should NOT be able to load the project")
+	assert.ErrorContains(t, projectError, "The configuration key 'names[]' declares an invalid pattern '[a-z'")
+}
+
+func TestValidateStackConfigKeysOnlyChecksGivenKeys(t *testing.T) {
+	t.Parallel()
+	projectYaml := `
+name: test
+runtime: dotnet
+config:
+  size:
+    type: string
+    enum: [small, large]
+  replicas:
+    type: integer
+    maximum: 5
+`
+
+	projectStackYaml := `
+config:
+  test:size: medium
+  test:replicas: 3
+  test:other: anything
+`
+
+	project, projectError := loadProjectFromText(t, projectYaml)
+	require.NoError(t, projectError, "Should be able to load the project")
+	stack, stackError := loadProjectStackFromText(t, project, projectStackYaml)
+	require.NoError(t, stackError, "Should be able to read the stack")
+
+	err := ValidateStackConfigKeys("dev", project, stack.Config, []config.Key{
+		config.MustMakeKey("test", "replicas"),
+		config.MustMakeKey("test", "other"),
+		config.MustMakeKey("test", "missing"),
+	}, config.NewPanicCrypter())
+	assert.NoError(t, err)
+
+	err = ValidateStackConfigKeys("dev", project, stack.Config, []config.Key{
+		config.MustMakeKey("test", "size"),
+	}, config.NewPanicCrypter())
+	assert.EqualError(t, err, "Stack 'dev' with configuration key 'size' must be one of 'small', 'large'")
+}
+
 func TestStackConfigErrorsWhenMissingStackValueForConfigTypeWithNoDefault(t *testing.T) {
 	t.Parallel()
 	projectYaml := `
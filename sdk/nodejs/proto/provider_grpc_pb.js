@@ -177,6 +177,28 @@ function deserialize_pulumirpc_DiffResponse(buffer_arg) {
   return pulumi_provider_pb.DiffResponse.deserializeBinary(new Uint8Array(buffer_arg));
 }
 
+function serialize_pulumirpc_DiscoverResourcesRequest(arg) {
+  if (!(arg instanceof pulumi_provider_pb.DiscoverResourcesRequest)) {
+    throw new Error('Expected argument of type pulumirpc.DiscoverResourcesRequest');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_DiscoverResourcesRequest(buffer_arg) {
+  return pulumi_provider_pb.DiscoverResourcesRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_DiscoverResourcesResponse(arg) {
+  if (!(arg instanceof pulumi_provider_pb.DiscoverResourcesResponse)) {
+    throw new Error('Expected argument of type pulumirpc.DiscoverResourcesResponse');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_DiscoverResourcesResponse(buffer_arg) {
+  return pulumi_provider_pb.DiscoverResourcesResponse.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
 function serialize_pulumirpc_GetMappingRequest(arg) {
   if (!(arg instanceof pulumi_provider_pb.GetMappingRequest)) {
     throw new Error('Expected argument of type pulumirpc.GetMappingRequest');
@@ -577,6 +599,20 @@ getMappings: {
     responseSerialize: serialize_pulumirpc_GetMappingsResponse,
     responseDeserialize: deserialize_pulumirpc_GetMappingsResponse,
   },
+  // DiscoverResources is an optional method that lists the existing resources of a type, so that they can be
+// imported. A provider that does not support discovery, or does not support it for the requested type, should
+// return UNIMPLEMENTED.
+discoverResources: {
+    path: '/pulumirpc.ResourceProvider/DiscoverResources',
+    requestStream: false,
+    responseStream: false,
+    requestType: pulumi_provider_pb.DiscoverResourcesRequest,
+    responseType: pulumi_provider_pb.DiscoverResourcesResponse,
+    requestSerialize: serialize_pulumirpc_DiscoverResourcesRequest,
+    requestDeserialize: deserialize_pulumirpc_DiscoverResourcesRequest,
+    responseSerialize: serialize_pulumirpc_DiscoverResourcesResponse,
+    responseDeserialize: deserialize_pulumirpc_DiscoverResourcesResponse,
+  },
 };
 
 exports.ResourceProviderClient = grpc.makeGenericClientConstructor(ResourceProviderService);
@@ -75,6 +75,10 @@ type ResourceProviderClient interface {
 	// implement this method the engine falls back to the old behaviour of just calling GetMapping without a name.
 	// If this method is implemented than the engine will then call GetMapping only with the names returned from this method.
 	GetMappings(ctx context.Context, in *GetMappingsRequest, opts ...grpc.CallOption) (*GetMappingsResponse, error)
+	// DiscoverResources is an optional method that lists the existing resources of a type, so that they can be
+	// imported. A provider that does not support discovery, or does not support it for the requested type, should
+	// return UNIMPLEMENTED.
+	DiscoverResources(ctx context.Context, in *DiscoverResourcesRequest, opts ...grpc.CallOption) (*DiscoverResourcesResponse, error)
 }
 
 type resourceProviderClient struct {
@@ -279,6 +283,15 @@ func (c *resourceProviderClient) GetMappings(ctx context.Context, in *GetMapping
 	return out, nil
 }
 
+func (c *resourceProviderClient) DiscoverResources(ctx context.Context, in *DiscoverResourcesRequest, opts ...grpc.CallOption) (*DiscoverResourcesResponse, error) {
+	out := new(DiscoverResourcesResponse)
+	err := c.cc.Invoke(ctx, "/pulumirpc.ResourceProvider/DiscoverResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ResourceProviderServer is the server API for ResourceProvider service.
 // All implementations must embed UnimplementedResourceProviderServer
 // for forward compatibility
@@ -335,6 +348,10 @@ type ResourceProviderServer interface {
 	// implement this method the engine falls back to the old behaviour of just calling GetMapping without a name.
 	// If this method is implemented than the engine will then call GetMapping only with the names returned from this method.
 	GetMappings(context.Context, *GetMappingsRequest) (*GetMappingsResponse, error)
+	// DiscoverResources is an optional method that lists the existing resources of a type, so that they can be
+	// imported. A provider that does not support discovery, or does not support it for the requested type, should
+	// return UNIMPLEMENTED.
+	DiscoverResources(context.Context, *DiscoverResourcesRequest) (*DiscoverResourcesResponse, error)
 	mustEmbedUnimplementedResourceProviderServer()
 }
 
@@ -399,6 +416,9 @@ func (UnimplementedResourceProviderServer) GetMapping(context.Context, *GetMappi
 func (UnimplementedResourceProviderServer) GetMappings(context.Context, *GetMappingsRequest) (*GetMappingsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMappings not implemented")
 }
+func (UnimplementedResourceProviderServer) DiscoverResources(context.Context, *DiscoverResourcesRequest) (*DiscoverResourcesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiscoverResources not implemented")
+}
 func (UnimplementedResourceProviderServer) mustEmbedUnimplementedResourceProviderServer() {}
 
 // UnsafeResourceProviderServer may be embedded to opt out of forward compatibility for this service.
@@ -757,6 +777,24 @@ func _ResourceProvider_GetMappings_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ResourceProvider_DiscoverResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiscoverResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceProviderServer).DiscoverResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceProvider/DiscoverResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceProviderServer).DiscoverResources(ctx, req.(*DiscoverResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ResourceProvider_ServiceDesc is the grpc.ServiceDesc for ResourceProvider service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -836,6 +874,10 @@ var ResourceProvider_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMappings",
 			Handler:    _ResourceProvider_GetMappings_Handler,
 		},
+		{
+			MethodName: "DiscoverResources",
+			Handler:    _ResourceProvider_DiscoverResources_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1352,3 +1352,64 @@ class GetMappingsResponse(google.protobuf.message.Message):
     def ClearField(self, field_name: typing_extensions.Literal["providers", b"providers"]) -> None: ...
 
 global___GetMappingsResponse = GetMappingsResponse
+
+@typing_extensions.final
+class DiscoverResourcesRequest(google.protobuf.message.Message):
+    """DiscoverResourcesRequest asks a provider for the existing resources of a type."""
+
+    DESCRIPTOR: google.protobuf.descriptor.Descriptor
+
+    TYPE_FIELD_NUMBER: builtins.int
+    SCOPE_FIELD_NUMBER: builtins.int
+    type: builtins.str
+    """the type token of the resources to list."""
+    @property
+    def scope(self) -> google.protobuf.struct_pb2.Struct:
+        """provider-specific filters, such as a region, that limit the resources listed."""
+    def __init__(
+        self,
+        *,
+        type: builtins.str = ...,
+        scope: google.protobuf.struct_pb2.Struct | None = ...,
+    ) -> None: ...
+    def HasField(self, field_name: typing_extensions.Literal["scope", b"scope"]) -> builtins.bool: ...
+    def ClearField(self, field_name: typing_extensions.Literal["scope", b"scope", "type", b"type"]) -> None: ...
+
+global___DiscoverResourcesRequest = DiscoverResourcesRequest
+
+@typing_extensions.final
+class DiscoverResourcesResponse(google.protobuf.message.Message):
+    """DiscoverResourcesResponse lists the existing resources of a type."""
+
+    DESCRIPTOR: google.protobuf.descriptor.Descriptor
+
+    @typing_extensions.final
+    class Resource(google.protobuf.message.Message):
+        DESCRIPTOR: google.protobuf.descriptor.Descriptor
+
+        ID_FIELD_NUMBER: builtins.int
+        NAME_FIELD_NUMBER: builtins.int
+        id: builtins.str
+        """the ID of the resource, as accepted by Read and by import."""
+        name: builtins.str
+        """a name for the resource, if the provider knows one (for example, from a name tag)."""
+        def __init__(
+            self,
+            *,
+            id: builtins.str = ...,
+            name: builtins.str = ...,
+        ) -> None: ...
+        def ClearField(self, field_name: typing_extensions.Literal["id", b"id", "name", b"name"]) -> None: ...
+
+    RESOURCES_FIELD_NUMBER: builtins.int
+    @property
+    def resources(self) -> google.protobuf.internal.containers.RepeatedCompositeFieldContainer[global___DiscoverResourcesResponse.Resource]:
+        """the resources that were found."""
+    def __init__(
+        self,
+        *,
+        resources: collections.abc.Iterable[global___DiscoverResourcesResponse.Resource] | None = ...,
+    ) -> None: ...
+    def ClearField(self, field_name: typing_extensions.Literal["resources", b"resources"]) -> None: ...
+
+global___DiscoverResourcesResponse = DiscoverResourcesResponse
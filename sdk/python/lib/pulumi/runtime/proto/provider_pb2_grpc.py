@@ -113,6 +113,11 @@ class ResourceProviderStub(object):
                 request_serializer=pulumi_dot_provider__pb2.GetMappingsRequest.SerializeToString,
                 response_deserializer=pulumi_dot_provider__pb2.GetMappingsResponse.FromString,
                 )
+        self.DiscoverResources = channel.unary_unary(
+                '/pulumirpc.ResourceProvider/DiscoverResources',
+                request_serializer=pulumi_dot_provider__pb2.DiscoverResourcesRequest.SerializeToString,
+                response_deserializer=pulumi_dot_provider__pb2.DiscoverResourcesResponse.FromString,
+                )
 
 
 class ResourceProviderServicer(object):
@@ -267,6 +272,15 @@ class ResourceProviderServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def DiscoverResources(self, request, context):
+        """DiscoverResources is an optional method that lists the existing resources of a type, so that they can be
+        imported. A provider that does not support discovery, or does not support it for the requested type, should
+        return UNIMPLEMENTED.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_ResourceProviderServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -365,6 +379,11 @@ def add_ResourceProviderServicer_to_server(servicer, server):
                     request_deserializer=pulumi_dot_provider__pb2.GetMappingsRequest.FromString,
                     response_serializer=pulumi_dot_provider__pb2.GetMappingsResponse.SerializeToString,
             ),
+            'DiscoverResources': grpc.unary_unary_rpc_method_handler(
+                    servicer.DiscoverResources,
+                    request_deserializer=pulumi_dot_provider__pb2.DiscoverResourcesRequest.FromString,
+                    response_serializer=pulumi_dot_provider__pb2.DiscoverResourcesResponse.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'pulumirpc.ResourceProvider', rpc_method_handlers)
@@ -699,3 +718,20 @@ class ResourceProvider(object):
             pulumi_dot_provider__pb2.GetMappingsResponse.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def DiscoverResources(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/pulumirpc.ResourceProvider/DiscoverResources',
+            pulumi_dot_provider__pb2.DiscoverResourcesRequest.SerializeToString,
+            pulumi_dot_provider__pb2.DiscoverResourcesResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
@@ -146,6 +146,14 @@ class ResourceProviderStub:
     implement this method the engine falls back to the old behaviour of just calling GetMapping without a name.
     If this method is implemented than the engine will then call GetMapping only with the names returned from this method.
     """
+    DiscoverResources: grpc.UnaryUnaryMultiCallable[
+        pulumi.provider_pb2.DiscoverResourcesRequest,
+        pulumi.provider_pb2.DiscoverResourcesResponse,
+    ]
+    """DiscoverResources is an optional method that lists the existing resources of a type, so that they can be
+    imported. A provider that does not support discovery, or does not support it for the requested type, should
+    return UNIMPLEMENTED.
+    """
 
 class ResourceProviderServicer(metaclass=abc.ABCMeta):
     """ResourceProvider is a service that understands how to create, read, update, or delete resources for types defined
@@ -306,5 +314,15 @@ class ResourceProviderServicer(metaclass=abc.ABCMeta):
         implement this method the engine falls back to the old behaviour of just calling GetMapping without a name.
         If this method is implemented than the engine will then call GetMapping only with the names returned from this method.
         """
+    
+    def DiscoverResources(
+        self,
+        request: pulumi.provider_pb2.DiscoverResourcesRequest,
+        context: grpc.ServicerContext,
+    ) -> pulumi.provider_pb2.DiscoverResourcesResponse:
+        """DiscoverResources is an optional method that lists the existing resources of a type, so that they can be
+        imported. A provider that does not support discovery, or does not support it for the requested type, should
+        return UNIMPLEMENTED.
+        """
 
 def add_ResourceProviderServicer_to_server(servicer: ResourceProviderServicer, server: typing.Union[grpc.Server, grpc.aio.Server]) -> None: ...